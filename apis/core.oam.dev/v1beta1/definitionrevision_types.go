@@ -30,6 +30,10 @@ type DefinitionRevisionSpec struct {
 	// RevisionHash record the hash value of the spec of DefinitionRevision object.
 	RevisionHash string `json:"revisionHash"`
 
+	// RevisionHashAlgorithm records the version of the algorithm computing the RevisionHash, empty for the
+	// DefinitionRevisions created before the version is recorded, which use the first version.
+	RevisionHashAlgorithm string `json:"revisionHashAlgorithm,omitempty"`
+
 	// DefinitionType
 	DefinitionType common.DefinitionType `json:"definitionType"`
 
@@ -211,15 +211,28 @@ func GatherRevisionInfo(def runtime.Object) (*v1beta1.DefinitionRevision, *commo
 		return nil, nil, fmt.Errorf("unsupported type %v", definition)
 	}
 
-	defHash, err := computeDefinitionRevisionHash(defRev)
+	defHash, err := ComputeDefinitionRevisionHash(defRev, CurrentRevisionHashAlgorithm)
 	if err != nil {
 		return nil, nil, err
 	}
 	defRev.Spec.RevisionHash = defHash
+	defRev.Spec.RevisionHashAlgorithm = CurrentRevisionHashAlgorithm
 	return defRev, LastRevision, nil
 }
 
-func computeDefinitionRevisionHash(defRev *v1beta1.DefinitionRevision) (string, error) {
+const (
+	// RevisionHashAlgorithmV1 hashes the spec of the definition by hashstructure
+	RevisionHashAlgorithmV1 = "v1"
+	// CurrentRevisionHashAlgorithm is the algorithm computing the hash of the new DefinitionRevisions
+	CurrentRevisionHashAlgorithm = RevisionHashAlgorithmV1
+)
+
+// ComputeDefinitionRevisionHash computes the hash of the DefinitionRevision by the algorithm, the empty algorithm
+// of the DefinitionRevisions created before the algorithm is recorded is RevisionHashAlgorithmV1.
+func ComputeDefinitionRevisionHash(defRev *v1beta1.DefinitionRevision, algorithm string) (string, error) {
+	if algorithm != "" && algorithm != RevisionHashAlgorithmV1 {
+		return "", fmt.Errorf("unknown revision hash algorithm %s", algorithm)
+	}
 	var defHash string
 	var err error
 	switch defRev.Spec.DefinitionType {
@@ -287,7 +300,9 @@ func compareWithLastDefRevisionSpec(ctx context.Context, cli client.Client,
 	return true, nil
 }
 
-// DeepEqualDefRevision deep compare the spec of definitionRevisions
+// DeepEqualDefRevision deep compare the spec of definitionRevisions. Only the specs of the definitions are compared,
+// the revision number and hash of the definitionRevisions and the metadata and status of the definitions, which are
+// set by the controller or the server, are ignored.
 func DeepEqualDefRevision(old, new *v1beta1.DefinitionRevision) bool {
 	if !apiequality.Semantic.DeepEqual(old.Spec.ComponentDefinition.Spec, new.Spec.ComponentDefinition.Spec) {
 		return false
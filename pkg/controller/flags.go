@@ -28,6 +28,7 @@ import (
 	"github.com/oam-dev/kubevela/pkg/component"
 	"github.com/oam-dev/kubevela/pkg/controller/core.oam.dev/v1beta1/application"
 	"github.com/oam-dev/kubevela/pkg/resourcekeeper"
+	webhookutils "github.com/oam-dev/kubevela/pkg/webhook/utils"
 )
 
 // AddOptimizeFlags add optimize flags
@@ -50,6 +51,21 @@ func AddOptimizeFlags(fs *pflag.FlagSet) {
 func AddAdmissionFlags(fs *pflag.FlagSet) {
 	fs.BoolVar(&resourcekeeper.AllowCrossNamespaceResource, "allow-cross-namespace-resource", true, "If set to false, application can only apply resources within its namespace. Default to be true.")
 	fs.StringVar(&resourcekeeper.AllowResourceTypes, "allow-resource-types", "", "If not empty, application can only apply resources with specified types. For example, --allow-resource-types=whitelist:Deployment.v1.apps,Job.v1.batch")
+	fs.IntVar(&webhookutils.CueTemplateCacheSize, "cue-template-validation-cache-size", webhookutils.CueTemplateCacheSize, "The max number of cue template validation results cached by the admission webhook. Set it to 0 to disable the cache.")
+	fs.IntVar(&webhookutils.CueTemplateMaxBytes, "cue-template-max-bytes", webhookutils.CueTemplateMaxBytes, "The max size in bytes of a cue template validated by the admission webhook, the larger templates are rejected before they are compiled. Set it to 0 to disable the limit.")
+	fs.IntVar(&webhookutils.DefinitionMaxBytes, "definition-max-bytes", webhookutils.DefinitionMaxBytes, "The max size in bytes of a definition serialized for the storage, the larger definitions are rejected by the admission webhook. Set it to 0 to disable the limit.")
+	fs.IntVar(&webhookutils.DefinitionRevisionGetRetries, "definition-revision-get-retries", webhookutils.DefinitionRevisionGetRetries, "The max number of retries of getting the definitionRevision by the admission webhook on the server timeouts and the throttling of the API server. Set it to 0 to disable the retries.")
+	fs.StringSliceVar(&webhookutils.DefaultCueImportAllowlist, "cue-import-allowlist", webhookutils.DefaultCueImportAllowlist, "The import prefixes permitted in the cue templates of the definitions outside the vela-system namespace, overriding the public providers built in CueX. The CUE standard library is always permitted.")
+	fs.IntVar(&webhookutils.CueImportMaxDepth, "cue-template-import-max-depth", webhookutils.CueImportMaxDepth, "The max depth of the imports of a cue template followed through the CueX packages by the admission webhook, the deeper imports are rejected. Set it to 0 to disable the limit.")
+	fs.StringToStringVar(&webhookutils.CuePackageMinVersions, "cue-package-min-versions", webhookutils.CuePackageMinVersions, "The minimum versions of the CueX packages by their import paths, e.g. vela/kube=v1.9.0. The cue templates importing an older package, or an external package which carries no version, are rejected by the admission webhook.")
+	fs.StringToStringVar(&webhookutils.DefinitionCheckSeverities, "definition-check-severities", webhookutils.DefinitionCheckSeverities, "The severities of the optional checks of the definitions by their names, one of off, warn or error, e.g. parameter-disjunctions=error,open-structs=warn. The findings of the checks at error are rejected by the admission webhook, the ones at warn are returned as warnings.")
+	fs.IntVar(&webhookutils.ParameterMaxDisjunctions, "parameter-max-disjunctions", webhookutils.ParameterMaxDisjunctions, "The max number of the disjunction branches of a field of the parameter of a cue template, the larger disjunctions are reported by the parameter-disjunctions check, whose severity is set by --definition-check-severities. Set it to 0 to disable the limit.")
+	fs.StringSliceVar(&webhookutils.DefinitionRequiredMetadata.Labels, "definition-required-labels", webhookutils.DefinitionRequiredMetadata.Labels, "The keys of the labels every definition must carry, e.g. owner,team. The definitions missing any of them are rejected by the admission webhook.")
+	fs.StringSliceVar(&webhookutils.DefinitionRequiredMetadata.Annotations, "definition-required-annotations", webhookutils.DefinitionRequiredMetadata.Annotations, "The keys of the annotations every definition must carry. The definitions missing any of them are rejected by the admission webhook.")
+	fs.StringToStringVar(&webhookutils.DeprecatedDefinitionFields, "definition-deprecated-fields", webhookutils.DeprecatedDefinitionFields, "The deprecated fields of the definitions by their paths and the messages telling the replacements, e.g. spec.extension=use spec.schematic. The definitions setting them are warned rather than denied.")
+	fs.BoolVar(&webhookutils.RequireVersionChangelog, "definition-require-changelog", webhookutils.RequireVersionChangelog, "If set to true, the definitions bumping their spec.version must carry a non-empty annotation definition.oam.dev/changelog, otherwise they are rejected by the admission webhook.")
+	fs.IntVar(&webhookutils.ComponentMaxTraits, "component-max-traits", webhookutils.ComponentMaxTraits, "The max number of the traits of a component of an application, the applications with more traits in a component are rejected by the admission webhook. Set it to 0 to disable the limit.")
+	fs.BoolVar(&webhookutils.AllowDefinitionRevisionMutation, "definition-revision-allow-mutation", webhookutils.AllowDefinitionRevisionMutation, "If set to true, the definitions with the annotation definitionrevision.oam.dev/allow-mutation=true are allowed to modify their existing definitionRevisions by the admission webhook. It's meant for the development clusters only.")
 	fs.StringVar(&component.RefObjectsAvailableScope, "ref-objects-available-scope", component.RefObjectsAvailableScopeGlobal, "The available scope for ref-objects component to refer objects. Should be one of `namespace`, `cluster`, `global`")
 
 	// auth flags
@@ -114,6 +114,10 @@ const (
 
 	// EnableCueValidation enable strict cue validation fields for the required parameter field verification
 	EnableCueValidation = "EnableCueValidation"
+
+	// ValidateApplicationDefinitions validate the application against the definitions of its components, traits,
+	// policies and workflow steps in the webhook, and warn about the traits patching the same fields differently
+	ValidateApplicationDefinitions = "ValidateApplicationDefinitions"
 )
 
 var defaultFeatureGates = map[featuregate.Feature]featuregate.FeatureSpec{
@@ -139,6 +143,7 @@ var defaultFeatureGates = map[featuregate.Feature]featuregate.FeatureSpec{
 	SharedDefinitionStorageForApplicationRevision: {Default: true, PreRelease: featuregate.Alpha},
 	DisableWorkflowContextConfigMapCache:          {Default: true, PreRelease: featuregate.Alpha},
 	EnableCueValidation:                           {Default: false, PreRelease: featuregate.Beta},
+	ValidateApplicationDefinitions:                {Default: false, PreRelease: featuregate.Alpha},
 }
 
 func init() {
@@ -0,0 +1,46 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+
+	velametrics "github.com/kubevela/pkg/monitor/metrics"
+)
+
+var (
+	// CueTemplateValidationCacheCounter report the hit and miss number of the cue template validation cache.
+	CueTemplateValidationCacheCounter = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "cue_template_validation_cache_total",
+		Help: "cue template validation cache hit and miss times.",
+	}, []string{"result"})
+
+	// DefinitionValidationCounter report the number of definition validations by the validator, the definition kind
+	// and the outcome, the kind is empty for the validators that only receive the cue template.
+	DefinitionValidationCounter = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "definition_validation_total",
+		Help: "definition validation times.",
+	}, []string{"validator", "kind", "outcome"})
+
+	// DefinitionValidationDurationHistogram report the time cost of definition validations
+	DefinitionValidationDurationHistogram = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:        "definition_validation_time_seconds",
+		Help:        "definition validation duration distributions.",
+		Buckets:     velametrics.FineGrainedBuckets,
+		ConstLabels: prometheus.Labels{},
+	}, []string{"validator", "kind"})
+)
@@ -51,6 +51,9 @@ var collectorGroup = []prometheus.Collector{
 	ClusterPodAllocatableGauge,
 	ClusterMemoryUsageGauge,
 	ClusterCPUUsageGauge,
+	CueTemplateValidationCacheCounter,
+	DefinitionValidationCounter,
+	DefinitionValidationDurationHistogram,
 }
 
 func init() {
@@ -147,6 +147,25 @@ const (
 	// AnnotationDefinitionRevisionName is used to specify the name of DefinitionRevision in component/trait definition
 	AnnotationDefinitionRevisionName = "definitionrevision.oam.dev/name"
 
+	// AnnotationAllowDefinitionRevisionMutation is used to allow the definition to modify its existing DefinitionRevision,
+	// it's only honoured if the controller runs with --definition-revision-allow-mutation
+	AnnotationAllowDefinitionRevisionMutation = "definitionrevision.oam.dev/allow-mutation"
+
+	// AnnotationAllowVersionBackport is used to allow the definition to publish a version lower than the existing
+	// DefinitionRevisions, it's off by default
+	AnnotationAllowVersionBackport = "definition.oam.dev/allow-version-backport"
+
+	// AnnotationDefinitionChangelog is used to describe the changes of the version of the definition, which is
+	// required on the version bumps if the webhook is configured to
+	AnnotationDefinitionChangelog = "definition.oam.dev/changelog"
+
+	// AnnotationValidationLanguage is used to specify the language of the messages of the validation errors returned
+	// by the webhook for the definition, e.g. zh, English by default
+	AnnotationValidationLanguage = "definition.oam.dev/validation-language"
+
+	// AnnotationAllowReservedPolicyName is used to allow the PolicyDefinition to take the name of a built-in policy type outside the system namespace
+	AnnotationAllowReservedPolicyName = "policydefinition.oam.dev/allow-reserved-name"
+
 	// AnnotationLastAppliedConfiguration is kubectl annotations for 3-way merge
 	AnnotationLastAppliedConfiguration = "kubectl.kubernetes.io/last-applied-configuration"
 
@@ -0,0 +1,172 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package application
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	admissionv1 "k8s.io/api/admission/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilfeature "k8s.io/apiserver/pkg/util/feature"
+	featuregatetesting "k8s.io/component-base/featuregate/testing"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/common"
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
+	"github.com/oam-dev/kubevela/pkg/features"
+	"github.com/oam-dev/kubevela/pkg/oam"
+	velacommon "github.com/oam-dev/kubevela/pkg/utils/common"
+	"github.com/oam-dev/kubevela/pkg/webhook/testutil"
+	webhookutils "github.com/oam-dev/kubevela/pkg/webhook/utils"
+)
+
+// newTestHandler returns the handler with the definitions of a worker component and the scaler and labels traits
+func newTestHandler() *ValidatingHandler {
+	worker := &v1beta1.ComponentDefinition{
+		ObjectMeta: metav1.ObjectMeta{Name: "worker", Namespace: oam.SystemDefinitionNamespace},
+		Spec: v1beta1.ComponentDefinitionSpec{
+			Workload: common.WorkloadTypeDescriptor{Definition: common.WorkloadGVK{APIVersion: "apps/v1", Kind: "Deployment"}},
+			Schematic: &common.Schematic{CUE: &common.CUE{Template: `
+output: {
+	apiVersion: "apps/v1"
+	kind:       "Deployment"
+	spec: replicas: *1 | int
+}
+parameter: {}
+`}},
+		},
+	}
+	newTrait := func(name, template string) *v1beta1.TraitDefinition {
+		return &v1beta1.TraitDefinition{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: oam.SystemDefinitionNamespace},
+			Spec: v1beta1.TraitDefinitionSpec{
+				Schematic: &common.Schematic{CUE: &common.CUE{Template: template}},
+			},
+		}
+	}
+	return &ValidatingHandler{
+		Client: fake.NewClientBuilder().WithScheme(velacommon.Scheme).WithObjects(
+			worker,
+			newTrait("scaler", "patch: spec: replicas: parameter.replicas\nparameter: replicas: *1 | int\n"),
+			newTrait("labels", "patch: metadata: labels: parameter\nparameter: [string]: string\n"),
+		).Build(),
+		Decoder: admission.NewDecoder(velacommon.Scheme),
+	}
+}
+
+// newApplication returns the application of a worker component scaled and labeled by its traits
+func newApplication() *v1beta1.Application {
+	app := &v1beta1.Application{
+		ObjectMeta: metav1.ObjectMeta{Name: "app", Namespace: "default"},
+		Spec: v1beta1.ApplicationSpec{Components: []common.ApplicationComponent{{
+			Name: "backend",
+			Type: "worker",
+			Traits: []common.ApplicationTrait{
+				{Type: "scaler", Properties: &runtime.RawExtension{Raw: []byte(`{"replicas":2}`)}},
+				{Type: "labels", Properties: &runtime.RawExtension{Raw: []byte(`{"team":"platform"}`)}},
+			},
+		}}},
+	}
+	app.SetGroupVersionKind(v1beta1.ApplicationKindVersionKind)
+	return app
+}
+
+func TestValidatingHandlerValidateApplication(t *testing.T) {
+	invalidPolicy := newApplication()
+	invalidPolicy.Spec.Policies = []v1beta1.AppPolicy{{
+		Name:       "gc",
+		Type:       "garbage-collect",
+		Properties: &runtime.RawExtension{Raw: []byte(`{"keepLegacyResource":"yes"}`)},
+	}}
+
+	cases := map[string]struct {
+		app     *v1beta1.Application
+		wantErr string
+	}{
+		"valid": {
+			app: newApplication(),
+		},
+		"invalidPolicyProperties": {
+			app:     invalidPolicy,
+			wantErr: "policy gc of type garbage-collect has invalid properties",
+		},
+	}
+
+	featuregatetesting.SetFeatureGateDuringTest(t, utilfeature.DefaultFeatureGate, features.ValidateApplicationDefinitions, true)
+	for caseName, cs := range cases {
+		t.Run(caseName, func(t *testing.T) {
+			resp := newTestHandler().Handle(context.Background(), testutil.NewAdmissionRequest(t, admissionv1.Create, cs.app, nil))
+			if cs.wantErr == "" {
+				assert.True(t, resp.Allowed, resp.Result.Message)
+				return
+			}
+			assert.False(t, resp.Allowed)
+			assert.Contains(t, resp.Result.Message, cs.wantErr)
+		})
+	}
+}
+
+func TestValidatingHandlerValidateApplicationDisabled(t *testing.T) {
+	app := newApplication()
+	app.Spec.Policies = []v1beta1.AppPolicy{{
+		Name:       "gc",
+		Type:       "garbage-collect",
+		Properties: &runtime.RawExtension{Raw: []byte(`{"keepLegacyResource":"yes"}`)},
+	}}
+	resp := newTestHandler().Handle(context.Background(), testutil.NewAdmissionRequest(t, admissionv1.Create, app, nil))
+	assert.True(t, resp.Allowed, resp.Result.Message)
+}
+
+func TestValidatingHandlerGetDefinitionsOnce(t *testing.T) {
+	featuregatetesting.SetFeatureGateDuringTest(t, utilfeature.DefaultFeatureGate, features.ValidateApplicationDefinitions, true)
+	gets := map[string]int{}
+	h := newTestHandler()
+	h.Client = interceptor.NewClient(h.Client.(client.WithWatch), interceptor.Funcs{
+		Get: func(ctx context.Context, cli client.WithWatch, key client.ObjectKey, obj client.Object, opts ...client.GetOption) error {
+			if _, ok := obj.(*v1beta1.TraitDefinition); ok {
+				gets[key.String()]++
+			}
+			return cli.Get(ctx, key, obj, opts...)
+		},
+	})
+	resp := h.Handle(context.Background(), testutil.NewAdmissionRequest(t, admissionv1.Create, newApplication(), nil))
+	assert.True(t, resp.Allowed, resp.Result.Message)
+	assert.NotEmpty(t, gets)
+	for key, n := range gets {
+		assert.Equal(t, 1, n, key)
+	}
+}
+
+func TestValidatingHandlerComponentMaxTraits(t *testing.T) {
+	defer func(maxTraits int) { webhookutils.ComponentMaxTraits = maxTraits }(webhookutils.ComponentMaxTraits)
+	req := testutil.NewAdmissionRequest(t, admissionv1.Create, newApplication(), nil)
+
+	webhookutils.ComponentMaxTraits = 2
+	resp := newTestHandler().Handle(context.Background(), req)
+	assert.True(t, resp.Allowed, resp.Result.Message)
+
+	webhookutils.ComponentMaxTraits = 1
+	resp = newTestHandler().Handle(context.Background(), req)
+	assert.False(t, resp.Allowed)
+	assert.Contains(t, resp.Result.Message, "component backend has 2 traits, exceeds limit 1")
+}
@@ -22,8 +22,10 @@ import (
 	"fmt"
 	"net/http"
 
+	"github.com/kubevela/pkg/controller/sharding"
 	admissionv1 "k8s.io/api/admission/v1"
 	"k8s.io/apimachinery/pkg/util/validation/field"
+	utilfeature "k8s.io/apiserver/pkg/util/feature"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
 	"sigs.k8s.io/controller-runtime/pkg/webhook"
@@ -31,7 +33,9 @@ import (
 
 	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
 	controller "github.com/oam-dev/kubevela/pkg/controller/core.oam.dev"
+	"github.com/oam-dev/kubevela/pkg/features"
 	"github.com/oam-dev/kubevela/pkg/oam/util"
+	webhookutils "github.com/oam-dev/kubevela/pkg/webhook/utils"
 )
 
 var _ admission.Handler = &ValidatingHandler{}
@@ -71,6 +75,7 @@ func (h *ValidatingHandler) Handle(ctx context.Context, req admission.Request) a
 	}
 
 	ctx = util.SetNamespaceInCtx(ctx, app.Namespace)
+	h = h.withDefinitionCache()
 	switch req.Operation {
 	case admissionv1.Create:
 		if allErrs := h.ValidateCreate(ctx, app); len(allErrs) > 0 {
@@ -78,6 +83,7 @@ func (h *ValidatingHandler) Handle(ctx context.Context, req admission.Request) a
 			// to the client, use generic http.StatusBadRequest instead.
 			return admission.Errored(http.StatusBadRequest, mergeErrors(allErrs))
 		}
+		return h.validateApplication(ctx, app)
 	case admissionv1.Update:
 		oldApp := &v1beta1.Application{}
 		if err := h.Decoder.DecodeRaw(req.AdmissionRequest.OldObject, oldApp); err != nil {
@@ -87,6 +93,7 @@ func (h *ValidatingHandler) Handle(ctx context.Context, req admission.Request) a
 			if allErrs := h.ValidateUpdate(ctx, app, oldApp); len(allErrs) > 0 {
 				return admission.Errored(http.StatusBadRequest, mergeErrors(allErrs))
 			}
+			return h.validateApplication(ctx, app)
 		}
 	default:
 		// Do nothing for DELETE and CONNECT
@@ -94,6 +101,26 @@ func (h *ValidatingHandler) Handle(ctx context.Context, req admission.Request) a
 	return admission.ValidationResponse(true, "")
 }
 
+// validateApplication checks the application against the definitions of its components, traits, policies and
+// workflow steps if the ValidateApplicationDefinitions feature is enabled, see webhookutils.ValidateApplication,
+// and returns the warnings of the checks with the response. Otherwise only the trait count is checked.
+func (h *ValidatingHandler) validateApplication(ctx context.Context, app *v1beta1.Application) admission.Response {
+	if !utilfeature.DefaultMutableFeatureGate.Enabled(features.ValidateApplicationDefinitions) {
+		if err := webhookutils.ValidateComponentTraitCount(app); err != nil {
+			return admission.Errored(http.StatusBadRequest, err)
+		}
+		return admission.ValidationResponse(true, "")
+	}
+	if sharding.EnableSharding && !utilfeature.DefaultMutableFeatureGate.Enabled(features.ValidateComponentWhenSharding) {
+		return admission.ValidationResponse(true, "")
+	}
+	warnings, err := webhookutils.ValidateApplication(ctx, h.Client, app)
+	if err != nil {
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+	return admission.ValidationResponse(true, "").WithWarnings(warnings...)
+}
+
 // RegisterValidatingHandler will register application validate handler to the webhook
 func RegisterValidatingHandler(mgr manager.Manager, _ controller.Args) {
 	server := mgr.GetWebhookServer()
@@ -19,10 +19,13 @@ package application
 import (
 	"context"
 	"fmt"
+	"reflect"
+	"sync"
 	"time"
 
 	"github.com/kubevela/pkg/controller/sharding"
 	"github.com/kubevela/pkg/util/singleton"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/util/validation/field"
 	utilfeature "k8s.io/apiserver/pkg/util/feature"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -83,6 +86,64 @@ func (in *appRevBypassCacheClient) Get(ctx context.Context, key client.ObjectKey
 	return in.Client.Get(ctx, key, obj)
 }
 
+// definitionCacheClient keeps the definitions got by the checks of a request, so that ValidateComponents and
+// webhookutils.ValidateApplication load each definition once
+type definitionCacheClient struct {
+	client.Client
+	mu          sync.Mutex
+	definitions map[definitionCacheKey]definitionCacheEntry
+}
+
+type definitionCacheKey struct {
+	kind string
+	key  client.ObjectKey
+}
+
+type definitionCacheEntry struct {
+	obj client.Object
+	err error
+}
+
+// withDefinitionCache returns the handler of a request whose checks share the definitions they get
+func (h *ValidatingHandler) withDefinitionCache() *ValidatingHandler {
+	return &ValidatingHandler{
+		Client:  &definitionCacheClient{Client: h.Client, definitions: map[definitionCacheKey]definitionCacheEntry{}},
+		Decoder: h.Decoder,
+	}
+}
+
+// Get retrieve the definitions from the cache, and the definitions not found are cached as well
+func (in *definitionCacheClient) Get(ctx context.Context, key client.ObjectKey, obj client.Object, opts ...client.GetOption) error {
+	switch obj.(type) {
+	case *v1beta1.ComponentDefinition, *v1beta1.TraitDefinition, *v1beta1.PolicyDefinition,
+		*v1beta1.WorkflowStepDefinition, *v1beta1.WorkloadDefinition:
+	default:
+		return in.Client.Get(ctx, key, obj, opts...)
+	}
+	cacheKey := definitionCacheKey{kind: reflect.TypeOf(obj).String(), key: key}
+	in.mu.Lock()
+	defer in.mu.Unlock()
+	entry, ok := in.definitions[cacheKey]
+	if !ok {
+		err := in.Client.Get(ctx, key, obj, opts...)
+		switch {
+		case err == nil:
+			entry.obj = obj.DeepCopyObject().(client.Object)
+		case apierrors.IsNotFound(err):
+			entry.err = err
+		default:
+			return err
+		}
+		in.definitions[cacheKey] = entry
+		return entry.err
+	}
+	if entry.err != nil {
+		return entry.err
+	}
+	reflect.ValueOf(obj).Elem().Set(reflect.ValueOf(entry.obj.DeepCopyObject()).Elem())
+	return nil
+}
+
 // ValidateComponents validates the Application components
 func (h *ValidatingHandler) ValidateComponents(ctx context.Context, app *v1beta1.Application) field.ErrorList {
 	if sharding.EnableSharding && !utilfeature.DefaultMutableFeatureGate.Enabled(features.ValidateComponentWhenSharding) {
@@ -0,0 +1,153 @@
+/*
+ Copyright 2021. The KubeVela Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package componentdefinition
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	admissionv1 "k8s.io/api/admission/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	velacommon "github.com/oam-dev/kubevela/pkg/utils/common"
+	"github.com/oam-dev/kubevela/pkg/webhook/testutil"
+	webhookutils "github.com/oam-dev/kubevela/pkg/webhook/utils"
+)
+
+func newTestHandler() *ValidatingHandler {
+	mapper := meta.NewDefaultRESTMapper(nil)
+	mapper.Add(schema.GroupVersionKind{Group: "apps", Version: "v1", Kind: "Deployment"}, meta.RESTScopeNamespace)
+	return &ValidatingHandler{
+		Client:  fake.NewClientBuilder().WithScheme(velacommon.Scheme).WithRESTMapper(mapper).Build(),
+		Decoder: admission.NewDecoder(velacommon.Scheme),
+	}
+}
+
+func TestValidatingHandlerSkipsUnchangedSpec(t *testing.T) {
+	const invalidTemplate = `output: {apiVersion: "apps/v1", kind: "Deployment"}
+parameter: replicas: int & "1"`
+	stored := testutil.NewComponentDefinition(invalidTemplate)
+	relabeled := stored.DeepCopy()
+	relabeled.SetLabels(map[string]string{"team": "platform"})
+	changed := stored.DeepCopy()
+	changed.Spec.Schematic.CUE.Template += "\nparameter: image: string\n"
+
+	cases := map[string]struct {
+		op          admissionv1.Operation
+		obj, old    runtime.Object
+		wantAllowed bool
+	}{
+		"createInvalid": {
+			op:  admissionv1.Create,
+			obj: stored,
+		},
+		"updateUnchangedSpec": {
+			op:          admissionv1.Update,
+			obj:         relabeled,
+			old:         stored,
+			wantAllowed: true,
+		},
+		"updateChangedSpec": {
+			op:  admissionv1.Update,
+			obj: changed,
+			old: stored,
+		},
+		"createValid": {
+			op:          admissionv1.Create,
+			obj:         testutil.NewComponentDefinition(testutil.ValidComponentTemplate),
+			wantAllowed: true,
+		},
+	}
+
+	for caseName, cs := range cases {
+		t.Run(caseName, func(t *testing.T) {
+			resp := newTestHandler().Handle(context.Background(), testutil.NewAdmissionRequest(t, cs.op, cs.obj, cs.old))
+			assert.Equal(t, cs.wantAllowed, resp.Allowed, resp.Result.Message)
+			if !cs.wantAllowed {
+				assert.Contains(t, resp.Result.Message, "parameter.replicas")
+			}
+		})
+	}
+}
+
+func TestValidatingHandlerRequiredMetadata(t *testing.T) {
+	defer func(required webhookutils.RequiredMetadata) { webhookutils.DefinitionRequiredMetadata = required }(webhookutils.DefinitionRequiredMetadata)
+	webhookutils.DefinitionRequiredMetadata = webhookutils.RequiredMetadata{Labels: []string{"owner"}}
+
+	missing := testutil.NewComponentDefinition(testutil.ValidComponentTemplate)
+	resp := newTestHandler().Handle(context.Background(), testutil.NewAdmissionRequest(t, admissionv1.Create, missing, nil))
+	assert.False(t, resp.Allowed)
+	assert.Contains(t, resp.Result.Message, "owner")
+
+	labeled := missing.DeepCopy()
+	labeled.SetLabels(map[string]string{"owner": "platform"})
+	resp = newTestHandler().Handle(context.Background(), testutil.NewAdmissionRequest(t, admissionv1.Create, labeled, nil))
+	assert.True(t, resp.Allowed, resp.Result.Message)
+}
+
+func TestValidatingHandlerDeprecatedFields(t *testing.T) {
+	defer func(deprecated map[string]string) { webhookutils.DeprecatedDefinitionFields = deprecated }(webhookutils.DeprecatedDefinitionFields)
+	webhookutils.DeprecatedDefinitionFields = map[string]string{"spec.workload.type": "set spec.workload.definition instead"}
+
+	resp := newTestHandler().Handle(context.Background(), testutil.NewAdmissionRequest(t, admissionv1.Create, testutil.NewComponentDefinition(testutil.ValidComponentTemplate), nil))
+	assert.True(t, resp.Allowed, resp.Result.Message)
+	assert.Len(t, resp.Warnings, 1)
+	assert.Contains(t, resp.Warnings[0], "spec.workload.type")
+}
+
+func TestValidatingHandlerParameterDisjunctions(t *testing.T) {
+	defer func(severities map[string]string, maxDisjunctions int) {
+		webhookutils.DefinitionCheckSeverities, webhookutils.ParameterMaxDisjunctions = severities, maxDisjunctions
+	}(webhookutils.DefinitionCheckSeverities, webhookutils.ParameterMaxDisjunctions)
+	webhookutils.ParameterMaxDisjunctions = 2
+	cd := testutil.NewComponentDefinition(testutil.ValidComponentTemplate + `parameter: zone: "a" | "b" | "c"` + "\n")
+
+	cases := map[string]struct {
+		severities   map[string]string
+		wantAllowed  bool
+		wantWarnings int
+	}{
+		"defaultOff": {
+			wantAllowed: true,
+		},
+		"warn": {
+			severities:   map[string]string{webhookutils.CheckParameterDisjunctions: "warn"},
+			wantAllowed:  true,
+			wantWarnings: 1,
+		},
+		"error": {
+			severities: map[string]string{webhookutils.CheckParameterDisjunctions: "error"},
+		},
+	}
+
+	for caseName, cs := range cases {
+		t.Run(caseName, func(t *testing.T) {
+			webhookutils.DefinitionCheckSeverities = cs.severities
+			resp := newTestHandler().Handle(context.Background(), testutil.NewAdmissionRequest(t, admissionv1.Create, cd, nil))
+			assert.Equal(t, cs.wantAllowed, resp.Allowed, resp.Result.Message)
+			assert.Len(t, resp.Warnings, cs.wantWarnings)
+			if !cs.wantAllowed {
+				assert.Contains(t, resp.Result.Message, "parameter.zone has 3 disjunction branches")
+			}
+		})
+	}
+}
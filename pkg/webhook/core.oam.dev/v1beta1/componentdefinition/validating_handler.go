@@ -30,7 +30,6 @@ import (
 
 	"github.com/oam-dev/kubevela/apis/core.oam.dev/common"
 	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
-	"github.com/oam-dev/kubevela/pkg/oam"
 	"github.com/oam-dev/kubevela/pkg/oam/util"
 	webhookutils "github.com/oam-dev/kubevela/pkg/webhook/utils"
 )
@@ -58,40 +57,35 @@ func (h *ValidatingHandler) Handle(ctx context.Context, req admission.Request) a
 		if err != nil {
 			return admission.Errored(http.StatusBadRequest, err)
 		}
+		if err := webhookutils.ValidateDefinitionSize(obj); err != nil {
+			return admission.Denied(webhookutils.LocalizeError(err, webhookutils.PreferredLanguage(obj)))
+		}
 		err = ValidateWorkload(h.Client.RESTMapper(), obj)
 		if err != nil {
-			return admission.Denied(err.Error())
+			return admission.Denied(webhookutils.LocalizeError(err, webhookutils.PreferredLanguage(obj)))
 		}
-
 		// validate cueTemplate
-		if obj.Spec.Schematic != nil && obj.Spec.Schematic.CUE != nil {
-			err = webhookutils.ValidateCuexTemplate(ctx, obj.Spec.Schematic.CUE.Template)
-			if err != nil {
-				return admission.Denied(err.Error())
-			}
-		}
-
-		if obj.Spec.Version != "" {
-			err = webhookutils.ValidateSemanticVersion(obj.Spec.Version)
-			if err != nil {
-				return admission.Denied(err.Error())
-			}
-		}
-
-		revisionName := obj.GetAnnotations()[oam.AnnotationDefinitionRevisionName]
-		if len(revisionName) != 0 {
-			defRevName := fmt.Sprintf("%s-v%s", obj.Name, revisionName)
-			err = webhookutils.ValidateDefinitionRevision(ctx, h.Client, obj, client.ObjectKey{Namespace: obj.Namespace, Name: defRevName})
+		var warnings []string
+		if obj.Spec.Schematic != nil && obj.Spec.Schematic.CUE != nil && !webhookutils.IsDefinitionUpdateUnchanged(h.Decoder, req, obj) {
+			// the imports are restricted by the allowlist of the definition's namespace
+			result, err := webhookutils.ValidateCuexTemplateWithResult(webhookutils.WithDefinitionKind(webhookutils.WithDefinitionName(util.SetNamespaceInCtx(ctx, obj.Namespace), obj.Name), v1beta1.ComponentDefinitionKind), obj.Spec.Schematic.CUE.Template)
 			if err != nil {
-				return admission.Denied(err.Error())
+				if len(result.Errors) != 0 {
+					// the positioned errors render the offending lines of the template
+					err = result.Err()
+				}
+				return admission.Denied(webhookutils.FormatCueError(obj.Spec.Schematic.CUE.Template, err))
 			}
+			warnings = append(warnings, result.WarningMessages()...)
 		}
 
-		version := obj.Spec.Version
-		err = webhookutils.ValidateMultipleDefVersionsNotPresent(version, revisionName, obj.Kind)
+		// the template is validated above, the registry runs the version checks and the configured ones
+		ws, err := webhookutils.ValidateDefinitionWithWarnings(webhookutils.WithCueTemplateValidated(util.SetNamespaceInCtx(ctx, obj.Namespace)), h.Client, obj)
+		warnings = append(warnings, ws...)
 		if err != nil {
-			return admission.Denied(err.Error())
+			return admission.Denied(webhookutils.LocalizeError(err, webhookutils.PreferredLanguage(obj)))
 		}
+		return admission.ValidationResponse(true, "").WithWarnings(warnings...)
 	}
 	return admission.ValidationResponse(true, "")
 }
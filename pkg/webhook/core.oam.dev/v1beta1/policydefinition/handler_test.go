@@ -0,0 +1,139 @@
+/*
+ Copyright 2021. The KubeVela Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package policydefinition
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	admissionv1 "k8s.io/api/admission/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
+	"github.com/oam-dev/kubevela/pkg/oam"
+	velacommon "github.com/oam-dev/kubevela/pkg/utils/common"
+	"github.com/oam-dev/kubevela/pkg/webhook/testutil"
+	webhookutils "github.com/oam-dev/kubevela/pkg/webhook/utils"
+)
+
+func newTestHandler() *ValidatingHandler {
+	return &ValidatingHandler{
+		Client:  fake.NewClientBuilder().WithScheme(velacommon.Scheme).Build(),
+		Decoder: admission.NewDecoder(velacommon.Scheme),
+	}
+}
+
+func TestValidatingHandlerSkipsUnchangedSpec(t *testing.T) {
+	stored := testutil.NewPolicyDefinition(`
+output: {
+	apiVersion: "v1"
+	kind:       "ConfigMap"
+}
+parameter: region: int & "us-east-1"
+`)
+	relabeled := stored.DeepCopy()
+	relabeled.SetLabels(map[string]string{"team": "platform"})
+	changed := stored.DeepCopy()
+	changed.Spec.Schematic.CUE.Template += "\nparameter: zone: string\n"
+
+	cases := map[string]struct {
+		op          admissionv1.Operation
+		obj, old    runtime.Object
+		wantAllowed bool
+	}{
+		"createInvalid": {
+			op:  admissionv1.Create,
+			obj: stored,
+		},
+		"updateUnchangedSpec": {
+			op:          admissionv1.Update,
+			obj:         relabeled,
+			old:         stored,
+			wantAllowed: true,
+		},
+		"updateChangedSpec": {
+			op:  admissionv1.Update,
+			obj: changed,
+			old: stored,
+		},
+		"createValid": {
+			op:          admissionv1.Create,
+			obj:         testutil.NewPolicyDefinition(testutil.ValidPolicyTemplate),
+			wantAllowed: true,
+		},
+	}
+
+	for caseName, cs := range cases {
+		t.Run(caseName, func(t *testing.T) {
+			resp := newTestHandler().Handle(context.Background(), testutil.NewAdmissionRequest(t, cs.op, cs.obj, cs.old))
+			assert.Equal(t, cs.wantAllowed, resp.Allowed, resp.Result.Message)
+			if !cs.wantAllowed {
+				assert.Contains(t, resp.Result.Message, "parameter.region")
+			}
+		})
+	}
+}
+
+func TestValidatingHandlerRequiredMetadata(t *testing.T) {
+	defer func(required webhookutils.RequiredMetadata) { webhookutils.DefinitionRequiredMetadata = required }(webhookutils.DefinitionRequiredMetadata)
+	webhookutils.DefinitionRequiredMetadata = webhookutils.RequiredMetadata{Labels: []string{"owner"}}
+
+	missing := testutil.NewPolicyDefinition(testutil.ValidPolicyTemplate)
+	resp := newTestHandler().Handle(context.Background(), testutil.NewAdmissionRequest(t, admissionv1.Create, missing, nil))
+	assert.False(t, resp.Allowed)
+	assert.Contains(t, resp.Result.Message, "owner")
+
+	labeled := missing.DeepCopy()
+	labeled.SetLabels(map[string]string{"owner": "platform"})
+	resp = newTestHandler().Handle(context.Background(), testutil.NewAdmissionRequest(t, admissionv1.Create, labeled, nil))
+	assert.True(t, resp.Allowed, resp.Result.Message)
+}
+
+func TestValidatingHandlerShadowsBuiltinPolicy(t *testing.T) {
+	reserved := testutil.NewPolicyDefinition(testutil.ValidPolicyTemplate)
+	reserved.Name = "topology"
+	shadowing := testutil.NewPolicyDefinition(`parameter: {
+	keys: [...string]
+	selector?: [...string]
+}`)
+
+	for caseName, pd := range map[string]*v1beta1.PolicyDefinition{"reservedName": reserved, "sameParameterShape": shadowing} {
+		t.Run(caseName, func(t *testing.T) {
+			resp := newTestHandler().Handle(context.Background(), testutil.NewAdmissionRequest(t, admissionv1.Create, pd, nil))
+			assert.False(t, resp.Allowed)
+			assert.Contains(t, resp.Result.Message, "built-in policy type")
+
+			pd = pd.DeepCopy()
+			pd.SetAnnotations(map[string]string{oam.AnnotationAllowReservedPolicyName: "true"})
+			resp = newTestHandler().Handle(context.Background(), testutil.NewAdmissionRequest(t, admissionv1.Create, pd, nil))
+			assert.True(t, resp.Allowed, resp.Result.Message)
+		})
+	}
+}
+
+func TestValidatingHandlerImportAllowlist(t *testing.T) {
+	webhookutils.SetCueImportAllowlist("default", []string{"vela/kube"})
+	defer webhookutils.SetCueImportAllowlist("default", nil)
+	pd := testutil.NewPolicyDefinition("import \"vela/http\"\n" + testutil.ValidPolicyTemplate)
+
+	resp := newTestHandler().Handle(context.Background(), testutil.NewAdmissionRequest(t, admissionv1.Create, pd, nil))
+	assert.False(t, resp.Allowed)
+	assert.Contains(t, resp.Result.Message, "import vela/http is not permitted")
+}
@@ -32,7 +32,7 @@ import (
 	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
 	"github.com/oam-dev/kubevela/pkg/appfile"
 	controller "github.com/oam-dev/kubevela/pkg/controller/core.oam.dev"
-	"github.com/oam-dev/kubevela/pkg/oam"
+	"github.com/oam-dev/kubevela/pkg/oam/util"
 	webhookutils "github.com/oam-dev/kubevela/pkg/webhook/utils"
 )
 
@@ -81,44 +81,40 @@ func (h *ValidatingHandler) Handle(ctx context.Context, req admission.Request) a
 		if err != nil {
 			return admission.Errored(http.StatusBadRequest, err)
 		}
+		if err := webhookutils.ValidateDefinitionSize(obj); err != nil {
+			return admission.Denied(webhookutils.LocalizeError(err, webhookutils.PreferredLanguage(obj)))
+		}
 		klog.Info("validating ", " name: ", obj.Name, " operation: ", string(req.Operation))
 		for _, validator := range h.Validators {
 			if err := validator.Validate(ctx, *obj); err != nil {
 				klog.Info("validation failed ", " name: ", obj.Name, " errMsgi: ", err.Error())
-				return admission.Denied(err.Error())
+				return admission.Denied(webhookutils.LocalizeError(err, webhookutils.PreferredLanguage(obj)))
 			}
 		}
 
 		// validate cueTemplate
-		if obj.Spec.Schematic != nil && obj.Spec.Schematic.CUE != nil {
-			err = webhookutils.ValidateCuexTemplate(ctx, obj.Spec.Schematic.CUE.Template)
-			if err != nil {
-				return admission.Denied(err.Error())
-			}
-		}
-
-		if obj.Spec.Version != "" {
-			err = webhookutils.ValidateSemanticVersion(obj.Spec.Version)
-			if err != nil {
-				return admission.Denied(err.Error())
-			}
-		}
-
-		revisionName := obj.GetAnnotations()[oam.AnnotationDefinitionRevisionName]
-		if len(revisionName) != 0 {
-			defRevName := fmt.Sprintf("%s-v%s", obj.Name, revisionName)
-			err = webhookutils.ValidateDefinitionRevision(ctx, h.Client, obj, client.ObjectKey{Namespace: obj.Namespace, Name: defRevName})
+		var warnings []string
+		if obj.Spec.Schematic != nil && obj.Spec.Schematic.CUE != nil && !webhookutils.IsDefinitionUpdateUnchanged(h.Decoder, req, obj) {
+			// the imports are restricted by the allowlist of the definition's namespace
+			result, err := webhookutils.ValidateCuexTemplateWithResult(webhookutils.WithDefinitionKind(webhookutils.WithDefinitionName(util.SetNamespaceInCtx(ctx, obj.Namespace), obj.Name), v1beta1.TraitDefinitionKind), obj.Spec.Schematic.CUE.Template)
 			if err != nil {
-				return admission.Denied(err.Error())
+				if len(result.Errors) != 0 {
+					// the positioned errors render the offending lines of the template
+					err = result.Err()
+				}
+				return admission.Denied(webhookutils.FormatCueError(obj.Spec.Schematic.CUE.Template, err))
 			}
+			warnings = append(warnings, result.WarningMessages()...)
 		}
 
-		version := obj.Spec.Version
-		err = webhookutils.ValidateMultipleDefVersionsNotPresent(version, revisionName, obj.Kind)
+		// the template is validated above, the registry runs the version checks and the configured ones
+		ws, err := webhookutils.ValidateDefinitionWithWarnings(webhookutils.WithCueTemplateValidated(util.SetNamespaceInCtx(ctx, obj.Namespace)), h.Client, obj)
+		warnings = append(warnings, ws...)
 		if err != nil {
-			return admission.Denied(err.Error())
+			return admission.Denied(webhookutils.LocalizeError(err, webhookutils.PreferredLanguage(obj)))
 		}
 		klog.Info("validation passed ", " name: ", obj.Name, " operation: ", string(req.Operation))
+		return admission.ValidationResponse(true, "").WithWarnings(warnings...)
 	}
 	return admission.ValidationResponse(true, "")
 }
@@ -0,0 +1,140 @@
+/*
+Copyright 2024 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workflowstepdefinition
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	admissionv1 "k8s.io/api/admission/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/common"
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
+	"github.com/oam-dev/kubevela/pkg/oam"
+	"github.com/oam-dev/kubevela/pkg/oam/util"
+	velacommon "github.com/oam-dev/kubevela/pkg/utils/common"
+	"github.com/oam-dev/kubevela/pkg/webhook/testutil"
+	webhookutils "github.com/oam-dev/kubevela/pkg/webhook/utils"
+)
+
+func newTestHandler(objs ...client.Object) *ValidatingHandler {
+	return &ValidatingHandler{
+		Client:  fake.NewClientBuilder().WithScheme(velacommon.Scheme).WithObjects(objs...).Build(),
+		Decoder: admission.NewDecoder(velacommon.Scheme),
+	}
+}
+
+func TestValidatingHandlerTemplate(t *testing.T) {
+	const invalidTemplate = `parameter: retries: int & "1"`
+	stored := testutil.NewWorkflowStepDefinition(invalidTemplate)
+	relabeled := stored.DeepCopy()
+	relabeled.SetLabels(map[string]string{"team": "platform"})
+
+	cases := map[string]struct {
+		op          admissionv1.Operation
+		obj, old    runtime.Object
+		wantAllowed bool
+	}{
+		"createInvalid": {
+			op:  admissionv1.Create,
+			obj: stored,
+		},
+		"updateUnchangedSpec": {
+			op:          admissionv1.Update,
+			obj:         relabeled,
+			old:         stored,
+			wantAllowed: true,
+		},
+		"createValid": {
+			op:          admissionv1.Create,
+			obj:         testutil.NewWorkflowStepDefinition(testutil.ValidWorkflowStepTemplate),
+			wantAllowed: true,
+		},
+	}
+
+	for caseName, cs := range cases {
+		t.Run(caseName, func(t *testing.T) {
+			resp := newTestHandler().Handle(context.Background(), testutil.NewAdmissionRequest(t, cs.op, cs.obj, cs.old))
+			assert.Equal(t, cs.wantAllowed, resp.Allowed, resp.Result.Message)
+			if !cs.wantAllowed {
+				assert.Contains(t, resp.Result.Message, "parameter.retries")
+			}
+		})
+	}
+}
+
+func TestValidatingHandlerRequiredMetadata(t *testing.T) {
+	defer func(required webhookutils.RequiredMetadata) { webhookutils.DefinitionRequiredMetadata = required }(webhookutils.DefinitionRequiredMetadata)
+	webhookutils.DefinitionRequiredMetadata = webhookutils.RequiredMetadata{Labels: []string{"owner"}}
+
+	missing := testutil.NewWorkflowStepDefinition(testutil.ValidWorkflowStepTemplate)
+	resp := newTestHandler().Handle(context.Background(), testutil.NewAdmissionRequest(t, admissionv1.Create, missing, nil))
+	assert.False(t, resp.Allowed)
+	assert.Contains(t, resp.Result.Message, "owner")
+
+	labeled := missing.DeepCopy()
+	labeled.SetLabels(map[string]string{"owner": "platform"})
+	resp = newTestHandler().Handle(context.Background(), testutil.NewAdmissionRequest(t, admissionv1.Create, labeled, nil))
+	assert.True(t, resp.Allowed, resp.Result.Message)
+}
+
+func TestValidatingHandlerUnknownContextFields(t *testing.T) {
+	wd := testutil.NewWorkflowStepDefinition(testutil.ValidWorkflowStepTemplate + "log: app: context.appname\n")
+	resp := newTestHandler().Handle(context.Background(), testutil.NewAdmissionRequest(t, admissionv1.Create, wd, nil))
+	assert.True(t, resp.Allowed, resp.Result.Message)
+	assert.Equal(t, []string{"WorkflowStepDefinition notify references context.appname which is not provided by the workflow runtime"}, resp.Warnings)
+
+	wd = testutil.NewWorkflowStepDefinition(testutil.ValidWorkflowStepTemplate + "log: app: context.appName\n")
+	resp = newTestHandler().Handle(context.Background(), testutil.NewAdmissionRequest(t, admissionv1.Create, wd, nil))
+	assert.True(t, resp.Allowed, resp.Result.Message)
+	assert.Empty(t, resp.Warnings)
+}
+
+func TestValidatingHandlerRequireVersionChangelog(t *testing.T) {
+	defer func(required bool) { webhookutils.RequireVersionChangelog = required }(webhookutils.RequireVersionChangelog)
+	webhookutils.RequireVersionChangelog = true
+	published := testutil.NewWorkflowStepDefinition(testutil.ValidWorkflowStepTemplate)
+	published.Spec.Version = "1.0.0"
+	rev := &v1beta1.DefinitionRevision{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "notify-v1",
+			Namespace: published.Namespace,
+			Labels:    map[string]string{util.DefinitionKindToNameLabel[common.WorkflowStepType]: published.Name},
+		},
+		Spec: v1beta1.DefinitionRevisionSpec{
+			Revision:               1,
+			DefinitionType:         common.WorkflowStepType,
+			WorkflowStepDefinition: *published,
+		},
+	}
+	bumped := published.DeepCopy()
+	bumped.Spec.Version = "1.1.0"
+
+	resp := newTestHandler(rev).Handle(context.Background(), testutil.NewAdmissionRequest(t, admissionv1.Update, bumped, published))
+	assert.False(t, resp.Allowed)
+	assert.Contains(t, resp.Result.Message, "without a changelog")
+
+	bumped.SetAnnotations(map[string]string{oam.AnnotationDefinitionChangelog: "add the message parameter"})
+	resp = newTestHandler(rev).Handle(context.Background(), testutil.NewAdmissionRequest(t, admissionv1.Update, bumped, published))
+	assert.True(t, resp.Allowed, resp.Result.Message)
+}
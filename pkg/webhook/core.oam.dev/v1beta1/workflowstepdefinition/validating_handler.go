@@ -28,7 +28,7 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
 
 	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
-	"github.com/oam-dev/kubevela/pkg/oam"
+	"github.com/oam-dev/kubevela/pkg/oam/util"
 	webhookutils "github.com/oam-dev/kubevela/pkg/webhook/utils"
 )
 
@@ -54,7 +54,7 @@ func (h *ValidatingHandler) InjectDecoder(d admission.Decoder) error {
 }
 
 // Handle validate WorkflowStepDefinition Spec here
-func (h *ValidatingHandler) Handle(_ context.Context, req admission.Request) admission.Response {
+func (h *ValidatingHandler) Handle(ctx context.Context, req admission.Request) admission.Response {
 	obj := &v1beta1.WorkflowStepDefinition{}
 	if req.Resource.String() != workflowStepDefGVR.String() {
 		return admission.Errored(http.StatusBadRequest, fmt.Errorf("expect resource to be %s", workflowStepDefGVR))
@@ -65,19 +65,19 @@ func (h *ValidatingHandler) Handle(_ context.Context, req admission.Request) adm
 		if err != nil {
 			return admission.Errored(http.StatusBadRequest, err)
 		}
+		if err := webhookutils.ValidateDefinitionSize(obj); err != nil {
+			return admission.Denied(webhookutils.LocalizeError(err, webhookutils.PreferredLanguage(obj)))
+		}
 
-		if obj.Spec.Version != "" {
-			err = webhookutils.ValidateSemanticVersion(obj.Spec.Version)
-			if err != nil {
-				return admission.Denied(err.Error())
-			}
+		validateCtx := util.SetNamespaceInCtx(ctx, obj.Namespace)
+		if webhookutils.IsDefinitionUpdateUnchanged(h.Decoder, req, obj) {
+			validateCtx = webhookutils.WithCueTemplateValidated(validateCtx)
 		}
-		revisionName := obj.Annotations[oam.AnnotationDefinitionRevisionName]
-		version := obj.Spec.Version
-		err = webhookutils.ValidateMultipleDefVersionsNotPresent(version, revisionName, obj.Kind)
+		warnings, err := webhookutils.ValidateDefinitionWithWarnings(validateCtx, h.Client, obj)
 		if err != nil {
-			return admission.Denied(err.Error())
+			return admission.Denied(webhookutils.LocalizeError(err, webhookutils.PreferredLanguage(obj)))
 		}
+		return admission.ValidationResponse(true, "").WithWarnings(warnings...)
 	}
 	return admission.ValidationResponse(true, "")
 }
@@ -85,5 +85,8 @@ func (h *ValidatingHandler) Handle(_ context.Context, req admission.Request) adm
 // RegisterValidatingHandler will register WorkflowStepDefinition validation to webhook
 func RegisterValidatingHandler(mgr manager.Manager) {
 	server := mgr.GetWebhookServer()
-	server.Register("/validating-core-oam-dev-v1beta1-workflowstepdefinitions", &webhook.Admission{Handler: &ValidatingHandler{}})
+	server.Register("/validating-core-oam-dev-v1beta1-workflowstepdefinitions", &webhook.Admission{Handler: &ValidatingHandler{
+		Client:  mgr.GetClient(),
+		Decoder: admission.NewDecoder(mgr.GetScheme()),
+	}})
 }
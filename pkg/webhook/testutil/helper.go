@@ -0,0 +1,141 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package testutil
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/common"
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
+)
+
+const (
+	// ValidComponentTemplate is the cue template of a ComponentDefinition outputting a deployment
+	ValidComponentTemplate = `
+output: {
+	apiVersion: "apps/v1"
+	kind:       "Deployment"
+}
+parameter: replicas: *1 | int
+`
+	// ValidTraitTemplate is the cue template of a TraitDefinition patching the replicas of the workload
+	ValidTraitTemplate = `
+patch: spec: replicas: parameter.replicas
+parameter: replicas: *1 | int
+`
+	// ValidPolicyTemplate is the cue template of a PolicyDefinition outputting a ConfigMap
+	ValidPolicyTemplate = `
+output: {
+	apiVersion: "v1"
+	kind:       "ConfigMap"
+	data: region: parameter.region
+}
+parameter: region: *"us-east-1" | string
+`
+	// ValidWorkflowStepTemplate is the cue template of a WorkflowStepDefinition logging a message
+	ValidWorkflowStepTemplate = `
+parameter: message: *"hello" | string
+log: message: parameter.message
+`
+)
+
+// NewComponentDefinition returns the ComponentDefinition worker of a deployment with the cue template in the default
+// namespace
+func NewComponentDefinition(template string) *v1beta1.ComponentDefinition {
+	cd := &v1beta1.ComponentDefinition{
+		ObjectMeta: metav1.ObjectMeta{Name: "worker", Namespace: "default"},
+		Spec: v1beta1.ComponentDefinitionSpec{
+			Workload:  common.WorkloadTypeDescriptor{Type: "deployments.apps"},
+			Schematic: &common.Schematic{CUE: &common.CUE{Template: template}},
+		},
+	}
+	cd.SetGroupVersionKind(v1beta1.ComponentDefinitionGroupVersionKind)
+	return cd
+}
+
+// NewTraitDefinition returns the TraitDefinition scaler with the cue template in the default namespace
+func NewTraitDefinition(template string) *v1beta1.TraitDefinition {
+	td := &v1beta1.TraitDefinition{
+		ObjectMeta: metav1.ObjectMeta{Name: "scaler", Namespace: "default"},
+		Spec: v1beta1.TraitDefinitionSpec{
+			Schematic: &common.Schematic{CUE: &common.CUE{Template: template}},
+		},
+	}
+	td.SetGroupVersionKind(v1beta1.TraitDefinitionGroupVersionKind)
+	return td
+}
+
+// NewPolicyDefinition returns the PolicyDefinition region with the cue template in the default namespace
+func NewPolicyDefinition(template string) *v1beta1.PolicyDefinition {
+	pd := &v1beta1.PolicyDefinition{
+		ObjectMeta: metav1.ObjectMeta{Name: "region", Namespace: "default"},
+		Spec: v1beta1.PolicyDefinitionSpec{
+			Schematic: &common.Schematic{CUE: &common.CUE{Template: template}},
+		},
+	}
+	pd.SetGroupVersionKind(v1beta1.PolicyDefinitionGroupVersionKind)
+	return pd
+}
+
+// NewWorkflowStepDefinition returns the WorkflowStepDefinition notify with the cue template in the default namespace
+func NewWorkflowStepDefinition(template string) *v1beta1.WorkflowStepDefinition {
+	wd := &v1beta1.WorkflowStepDefinition{
+		ObjectMeta: metav1.ObjectMeta{Name: "notify", Namespace: "default"},
+		Spec: v1beta1.WorkflowStepDefinitionSpec{
+			Schematic: &common.Schematic{CUE: &common.CUE{Template: template}},
+		},
+	}
+	wd.SetGroupVersionKind(v1beta1.WorkflowStepDefinitionGroupVersionKind)
+	return wd
+}
+
+// NewAdmissionRequest returns the request of the operation on the object, whose resource and namespace are taken from
+// the object. The old object is only set for the updates.
+func NewAdmissionRequest(t testing.TB, op admissionv1.Operation, obj, old runtime.Object) admission.Request {
+	t.Helper()
+	gvk := obj.GetObjectKind().GroupVersionKind()
+	accessor, err := meta.Accessor(obj)
+	if err != nil {
+		t.Fatalf("failed to access the object: %v", err)
+	}
+	req := admission.Request{AdmissionRequest: admissionv1.AdmissionRequest{
+		Operation: op,
+		Resource:  metav1.GroupVersionResource{Group: gvk.Group, Version: gvk.Version, Resource: strings.ToLower(gvk.Kind) + "s"},
+		Namespace: accessor.GetNamespace(),
+		Object:    runtime.RawExtension{Raw: marshal(t, obj)},
+	}}
+	if old != nil {
+		req.OldObject = runtime.RawExtension{Raw: marshal(t, old)}
+	}
+	return req
+}
+
+func marshal(t testing.TB, obj runtime.Object) []byte {
+	raw, err := json.Marshal(obj)
+	if err != nil {
+		t.Fatalf("failed to marshal the object: %v", err)
+	}
+	return raw
+}
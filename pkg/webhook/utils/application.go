@@ -0,0 +1,161 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
+	"github.com/oam-dev/kubevela/pkg/oam"
+	"github.com/oam-dev/kubevela/pkg/oam/util"
+)
+
+// ValidateApplicationComponents checks that the type of every component and trait in the application references
+// an existing definition in the application namespace or the system namespace. The unresolvable types are reported
+// together, each with the nearest definition name as suggestion.
+// Versioned types like webservice@v1 are resolved by the definition name.
+// The traits of a component conflicting with each other, or dispatched before the outputs of the others they depend
+// on, are reported as well, see ValidateTraitConflicts and ValidateTraitOrdering.
+func ValidateApplicationComponents(ctx context.Context, cli client.Client, app *v1beta1.Application) error {
+	ctx = util.SetNamespaceInCtx(ctx, app.Namespace)
+	suggester := &definitionNameSuggester{cli: cli}
+	var errs []error
+	for _, comp := range app.Spec.Components {
+		compType := definitionNameOfType(comp.Type)
+		found, err := workloadDefinitionExists(ctx, cli, compType)
+		if err != nil {
+			return err
+		}
+		if !found {
+			msg := fmt.Sprintf("component %s references ComponentDefinition %s that is not found", comp.Name, compType)
+			errs = append(errs, errors.New(msg+suggester.suggest(ctx, &v1beta1.ComponentDefinitionList{}, compType)))
+		}
+		for _, trait := range comp.Traits {
+			traitType := definitionNameOfType(trait.Type)
+			found, err := definitionExists(ctx, cli, &v1beta1.TraitDefinition{}, traitType)
+			if err != nil {
+				return err
+			}
+			if !found {
+				msg := fmt.Sprintf("trait of component %s references TraitDefinition %s that is not found", comp.Name, traitType)
+				errs = append(errs, errors.New(msg+suggester.suggest(ctx, &v1beta1.TraitDefinitionList{}, traitType)))
+			}
+		}
+		if err := ValidateTraitConflicts(ctx, cli, comp); err != nil {
+			errs = append(errs, err)
+		}
+		if err := ValidateTraitOrdering(ctx, cli, comp); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return aggregateErrors(errs)
+}
+
+// definitionNameOfType strips the version of the type, e.g. webservice@v1 to webservice
+func definitionNameOfType(typ string) string {
+	if i := strings.Index(typ, "@v"); i > 0 {
+		return typ[:i]
+	}
+	return typ
+}
+
+// definitionNameSuggester lists the definitions of a kind lazily and suggests the nearest name for a missing one
+type definitionNameSuggester struct {
+	cli   client.Client
+	names map[string][]string
+}
+
+// suggest returns the hint with the nearest definition name, or empty if no name is close enough
+func (s *definitionNameSuggester) suggest(ctx context.Context, list client.ObjectList, name string) string {
+	kind := fmt.Sprintf("%T", list)
+	if s.names == nil {
+		s.names = map[string][]string{}
+	}
+	candidates, ok := s.names[kind]
+	if !ok {
+		listed := map[string]bool{}
+		for _, ns := range []string{util.GetDefinitionNamespaceWithCtx(ctx), util.GetXDefinitionNamespaceWithCtx(ctx), oam.SystemDefinitionNamespace} {
+			if listed[ns] {
+				continue
+			}
+			listed[ns] = true
+			if err := s.cli.List(ctx, list, client.InNamespace(ns)); err != nil {
+				// the suggestion is best effort
+				continue
+			}
+			candidates = append(candidates, definitionNamesOf(list)...)
+		}
+		s.names[kind] = candidates
+	}
+	if nearest := nearestName(name, candidates); nearest != "" {
+		return fmt.Sprintf(", did you mean %s?", nearest)
+	}
+	return ""
+}
+
+func definitionNamesOf(list client.ObjectList) []string {
+	var names []string
+	switch l := list.(type) {
+	case *v1beta1.ComponentDefinitionList:
+		for _, item := range l.Items {
+			names = append(names, item.Name)
+		}
+	case *v1beta1.TraitDefinitionList:
+		for _, item := range l.Items {
+			names = append(names, item.Name)
+		}
+	}
+	return names
+}
+
+// nearestName returns the candidate with the minimum edit distance to the name, candidates differing in more than
+// half of the name are not considered close enough
+func nearestName(name string, candidates []string) string {
+	nearest, minDistance := "", len(name)/2+1
+	for _, candidate := range candidates {
+		if d := editDistance(name, candidate); d < minDistance {
+			nearest, minDistance = candidate, d
+		}
+	}
+	return nearest
+}
+
+// editDistance computes the Levenshtein distance between a and b
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = util.Min(util.Min(prev[j]+1, curr[j-1]+1), prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(b)]
+}
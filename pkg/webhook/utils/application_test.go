@@ -0,0 +1,109 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	apicommon "github.com/oam-dev/kubevela/apis/core.oam.dev/common"
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
+	"github.com/oam-dev/kubevela/pkg/oam"
+	"github.com/oam-dev/kubevela/pkg/utils/common"
+)
+
+func TestValidateApplicationComponents(t *testing.T) {
+	cli := fake.NewClientBuilder().WithScheme(common.Scheme).WithObjects(
+		&v1beta1.ComponentDefinition{ObjectMeta: metav1.ObjectMeta{Name: "webservice", Namespace: oam.SystemDefinitionNamespace}},
+		&v1beta1.ComponentDefinition{ObjectMeta: metav1.ObjectMeta{Name: "worker", Namespace: "default"}},
+		&v1beta1.TraitDefinition{ObjectMeta: metav1.ObjectMeta{Name: "scaler", Namespace: oam.SystemDefinitionNamespace}},
+		&v1beta1.TraitDefinition{ObjectMeta: metav1.ObjectMeta{Name: "gateway", Namespace: "default"}},
+		&v1beta1.TraitDefinition{
+			ObjectMeta: metav1.ObjectMeta{Name: "hpa", Namespace: oam.SystemDefinitionNamespace},
+			Spec:       v1beta1.TraitDefinitionSpec{ConflictsWith: []string{"scaler"}},
+		},
+	).Build()
+
+	cases := map[string]struct {
+		components []apicommon.ApplicationComponent
+		wantErr    string
+	}{
+		"allTypesResolve": {
+			components: []apicommon.ApplicationComponent{{
+				Name:   "api",
+				Type:   "webservice",
+				Traits: []apicommon.ApplicationTrait{{Type: "scaler"}, {Type: "gateway"}},
+			}, {
+				Name: "consumer",
+				Type: "worker@v1",
+			}},
+		},
+		"conflictingTraits": {
+			components: []apicommon.ApplicationComponent{{
+				Name:   "api",
+				Type:   "webservice",
+				Traits: []apicommon.ApplicationTrait{{Type: "scaler"}, {Type: "gateway"}, {Type: "hpa"}},
+			}},
+			wantErr: "trait scaler conflicts with trait hpa in component api",
+		},
+		"unknownComponentType": {
+			components: []apicommon.ApplicationComponent{{Name: "api", Type: "webservise"}},
+			wantErr:    "component api references ComponentDefinition webservise that is not found, did you mean webservice?",
+		},
+		"unknownTypesAggregated": {
+			components: []apicommon.ApplicationComponent{{
+				Name:   "api",
+				Type:   "webservice",
+				Traits: []apicommon.ApplicationTrait{{Type: "scalar"}, {Type: "something-else"}},
+			}, {
+				Name: "consumer",
+				Type: "wrker",
+			}},
+			wantErr: "[trait of component api references TraitDefinition scalar that is not found, did you mean scaler?, " +
+				"trait of component api references TraitDefinition something-else that is not found, " +
+				"component consumer references ComponentDefinition wrker that is not found, did you mean worker?]",
+		},
+	}
+
+	for caseName, cs := range cases {
+		t.Run(caseName, func(t *testing.T) {
+			app := &v1beta1.Application{
+				ObjectMeta: metav1.ObjectMeta{Name: "app", Namespace: "default"},
+				Spec:       v1beta1.ApplicationSpec{Components: cs.components},
+			}
+			err := ValidateApplicationComponents(context.Background(), cli, app)
+			if cs.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.EqualError(t, err, cs.wantErr)
+		})
+	}
+}
+
+func TestNearestName(t *testing.T) {
+	candidates := []string{"webservice", "worker", "task", "cron-task"}
+	assert.Equal(t, "webservice", nearestName("webservise", candidates))
+	assert.Equal(t, "worker", nearestName("wroker", candidates))
+	assert.Equal(t, "task", nearestName("tsk", candidates))
+	assert.Equal(t, "", nearestName("k8s-objects", candidates))
+	assert.Equal(t, "", nearestName("worker", nil))
+}
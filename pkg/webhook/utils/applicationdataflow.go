@@ -0,0 +1,129 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/common"
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
+)
+
+// ValidateApplicationDataFlow checks the dependencies between the components of the application without a client.
+// Every component in dependsOn must exist, every input must come from an output of another component or of a
+// workflow step, and the components must not depend on each other in a cycle, either by dependsOn or by the
+// inputs taken from the outputs of the others. The errors are reported together.
+func ValidateApplicationDataFlow(app *v1beta1.Application) error {
+	var errs []error
+	components := map[string]bool{}
+	for _, comp := range app.Spec.Components {
+		components[comp.Name] = true
+	}
+	// producers are the components producing the outputs, the outputs of the workflow steps have no producer
+	producers := map[string][]string{}
+	for _, comp := range app.Spec.Components {
+		for _, output := range comp.Outputs {
+			producers[output.Name] = append(producers[output.Name], comp.Name)
+		}
+	}
+	if app.Spec.Workflow != nil {
+		for _, step := range app.Spec.Workflow.Steps {
+			for _, output := range step.Outputs {
+				producers[output.Name] = append(producers[output.Name], "")
+			}
+			for _, subStep := range step.SubSteps {
+				for _, output := range subStep.Outputs {
+					producers[output.Name] = append(producers[output.Name], "")
+				}
+			}
+		}
+	}
+
+	graph := map[string][]string{}
+	for _, comp := range app.Spec.Components {
+		for _, dep := range comp.DependsOn {
+			if !components[dep] {
+				errs = append(errs, fmt.Errorf("component %s depends on component %s that is not found", comp.Name, dep))
+				continue
+			}
+			graph[comp.Name] = append(graph[comp.Name], dep)
+		}
+		for _, input := range comp.Inputs {
+			found := false
+			for _, producer := range producers[input.From] {
+				if producer == comp.Name {
+					continue
+				}
+				found = true
+				if producer != "" {
+					graph[comp.Name] = append(graph[comp.Name], producer)
+				}
+			}
+			if !found {
+				errs = append(errs, fmt.Errorf("input %s of component %s is not an output of any other component or workflow step", input.From, comp.Name))
+			}
+		}
+	}
+	if err := checkComponentDependencyCycles(app.Spec.Components, graph); err != nil {
+		errs = append(errs, err)
+	}
+	return aggregateErrors(errs)
+}
+
+// checkComponentDependencyCycles walks the dependencies from the components in order and returns the first cycle
+func checkComponentDependencyCycles(components []common.ApplicationComponent, graph map[string][]string) error {
+	const (
+		visiting = 1
+		visited  = 2
+	)
+	state := map[string]int{}
+	var stack []string
+	var visit func(name string) error
+	visit = func(name string) error {
+		switch state[name] {
+		case visited:
+			return nil
+		case visiting:
+			start := 0
+			for i, n := range stack {
+				if n == name {
+					start = i
+					break
+				}
+			}
+			cycle := append(append([]string{}, stack[start:]...), name)
+			return fmt.Errorf("dependency cycle detected: component %s", strings.Join(cycle, " depends on "))
+		}
+		state[name] = visiting
+		stack = append(stack, name)
+		for _, next := range graph[name] {
+			if err := visit(next); err != nil {
+				return err
+			}
+		}
+		stack = stack[:len(stack)-1]
+		state[name] = visited
+		return nil
+	}
+	for _, comp := range components {
+		if err := visit(comp.Name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
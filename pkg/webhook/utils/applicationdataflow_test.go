@@ -0,0 +1,105 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"testing"
+
+	workflowv1alpha1 "github.com/kubevela/workflow/api/v1alpha1"
+	"github.com/stretchr/testify/assert"
+
+	apicommon "github.com/oam-dev/kubevela/apis/core.oam.dev/common"
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
+)
+
+func TestValidateApplicationDataFlow(t *testing.T) {
+	input := func(from string) workflowv1alpha1.StepInputs {
+		return workflowv1alpha1.StepInputs{{From: from, ParameterKey: "env"}}
+	}
+	output := func(name string) workflowv1alpha1.StepOutputs {
+		return workflowv1alpha1.StepOutputs{{Name: name, ValueFrom: "output.status.podIP"}}
+	}
+	cases := map[string]struct {
+		components []apicommon.ApplicationComponent
+		workflow   *v1beta1.Workflow
+		wantErr    string
+	}{
+		"valid": {
+			components: []apicommon.ApplicationComponent{
+				{Name: "db", Outputs: output("db-ip")},
+				{Name: "api", DependsOn: []string{"db"}, Inputs: input("db-ip"), Outputs: output("api-ip")},
+				{Name: "web", Inputs: input("api-ip")},
+			},
+		},
+		"inputFromWorkflowStep": {
+			components: []apicommon.ApplicationComponent{{Name: "api", Inputs: input("token")}},
+			workflow: &v1beta1.Workflow{Steps: []workflowv1alpha1.WorkflowStep{{
+				WorkflowStepBase: workflowv1alpha1.WorkflowStepBase{Name: "prepare"},
+				SubSteps:         []workflowv1alpha1.WorkflowStepBase{{Name: "gen", Outputs: output("token")}},
+			}}},
+		},
+		"dependsOnNotFound": {
+			components: []apicommon.ApplicationComponent{{Name: "api", DependsOn: []string{"dbb"}}},
+			wantErr:    "component api depends on component dbb that is not found",
+		},
+		"inputNotProduced": {
+			components: []apicommon.ApplicationComponent{
+				{Name: "db", Outputs: output("db-ip")},
+				{Name: "api", Inputs: input("db-addr")},
+			},
+			wantErr: "input db-addr of component api is not an output of any other component or workflow step",
+		},
+		"inputFromItself": {
+			components: []apicommon.ApplicationComponent{{Name: "api", Inputs: input("api-ip"), Outputs: output("api-ip")}},
+			wantErr:    "input api-ip of component api is not an output of any other component or workflow step",
+		},
+		"dependsOnCycle": {
+			components: []apicommon.ApplicationComponent{
+				{Name: "a", DependsOn: []string{"b"}},
+				{Name: "b", DependsOn: []string{"c"}},
+				{Name: "c", DependsOn: []string{"a"}},
+			},
+			wantErr: "dependency cycle detected: component a depends on b depends on c depends on a",
+		},
+		"dataPassingCycle": {
+			components: []apicommon.ApplicationComponent{
+				{Name: "a", Inputs: input("b-ip"), Outputs: output("a-ip")},
+				{Name: "b", DependsOn: []string{"a"}, Outputs: output("b-ip")},
+			},
+			wantErr: "dependency cycle detected: component a depends on b depends on a",
+		},
+		"errorsAggregated": {
+			components: []apicommon.ApplicationComponent{
+				{Name: "a", DependsOn: []string{"a", "x"}, Inputs: input("y")},
+			},
+			wantErr: "[component a depends on component x that is not found, " +
+				"input y of component a is not an output of any other component or workflow step, " +
+				"dependency cycle detected: component a depends on a]",
+		},
+	}
+	for caseName, cs := range cases {
+		t.Run(caseName, func(t *testing.T) {
+			app := &v1beta1.Application{Spec: v1beta1.ApplicationSpec{Components: cs.components, Workflow: cs.workflow}}
+			err := ValidateApplicationDataFlow(app)
+			if cs.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.EqualError(t, err, cs.wantErr)
+		})
+	}
+}
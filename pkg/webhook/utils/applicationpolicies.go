@@ -0,0 +1,115 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"context"
+	"embed"
+	"fmt"
+
+	"cuelang.org/go/cue"
+	"github.com/kubevela/pkg/cue/cuex"
+	"github.com/kubevela/workflow/pkg/cue/model"
+	"github.com/pkg/errors"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
+	"github.com/oam-dev/kubevela/pkg/oam/util"
+)
+
+// builtinPolicySchemas are the templates of the built-in policy types, so that their properties are validated
+// without the PolicyDefinitions installed
+//
+//go:embed policies/*.cue
+var builtinPolicySchemas embed.FS
+
+// builtinPolicySchemaOf returns the embedded template of the built-in policy type, or empty if there's none
+func builtinPolicySchemaOf(policyType string) string {
+	data, err := builtinPolicySchemas.ReadFile("policies/" + policyType + ".cue")
+	if err != nil {
+		return ""
+	}
+	return string(data)
+}
+
+// ValidateApplicationPolicies checks that the properties of every policy in the application unify with the parameter
+// of its PolicyDefinition in the application namespace or the system namespace. The built-in policy types fall back
+// to their embedded templates if the definition is not installed, and the ones without a schema, e.g. debug, are
+// not checked. The errors of the policies are reported together, each with the name and the type of the policy.
+func ValidateApplicationPolicies(ctx context.Context, cli client.Client, app *v1beta1.Application) error {
+	ctx = util.SetNamespaceInCtx(ctx, app.Namespace)
+	var errs []error
+	for _, policy := range app.Spec.Policies {
+		policyType := definitionNameOfType(policy.Type)
+		template, found, err := policyTemplateOf(ctx, cli, policyType)
+		if err != nil {
+			return err
+		}
+		if !found {
+			errs = append(errs, errors.Errorf("policy %s references PolicyDefinition %s that is not found", policy.Name, policyType))
+			continue
+		}
+		if template == "" {
+			continue
+		}
+		if err := validatePolicyProperties(ctx, template, policy.Properties); err != nil {
+			errs = append(errs, fmt.Errorf("policy %s of type %s has invalid properties: %w", policy.Name, policyType, err))
+		}
+	}
+	return aggregateErrors(errs)
+}
+
+// policyTemplateOf returns the cue template of the policy type, which is empty if the policy has no cue schematic or
+// is a built-in type without a schema. found is false if the type is neither defined nor built in.
+func policyTemplateOf(ctx context.Context, cli client.Client, policyType string) (template string, found bool, err error) {
+	pd := &v1beta1.PolicyDefinition{}
+	if err := util.GetDefinition(ctx, cli, pd, policyType); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return "", false, err
+		}
+		return builtinPolicySchemaOf(policyType), isReservedPolicyName(policyType), nil
+	}
+	if pd.Spec.Schematic == nil || pd.Spec.Schematic.CUE == nil {
+		return "", true, nil
+	}
+	return pd.Spec.Schematic.CUE.Template, true, nil
+}
+
+// validatePolicyProperties unifies the properties with the parameter of the template and checks the result is
+// concrete, as the policy is rendered or parsed by the controller
+func validatePolicyProperties(ctx context.Context, template string, properties *runtime.RawExtension) error {
+	val, err := cuex.DefaultCompiler.Get().CompileStringWithOptions(ctx, template+contextStub, cuex.DisableResolveProviderFunctions{})
+	if err != nil {
+		return err
+	}
+	param := val.LookupPath(cue.ParsePath(model.ParameterFieldName))
+	if !param.Exists() || param.Err() != nil {
+		// the template is validated when the definition is applied
+		return nil
+	}
+	props := []byte("{}")
+	if properties != nil && len(properties.Raw) != 0 {
+		props = properties.Raw
+	}
+	propsVal := val.Context().CompileBytes(props)
+	if propsVal.Err() != nil {
+		return checkError(propsVal.Err())
+	}
+	return checkError(param.Unify(propsVal).Validate(cue.Concrete(true)))
+}
@@ -0,0 +1,136 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	apicommon "github.com/oam-dev/kubevela/apis/core.oam.dev/common"
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1alpha1"
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
+	"github.com/oam-dev/kubevela/pkg/oam"
+	"github.com/oam-dev/kubevela/pkg/utils/common"
+)
+
+func TestBuiltinPolicySchemas(t *testing.T) {
+	for _, policyType := range ReservedPolicyNames {
+		schema := builtinPolicySchemaOf(policyType)
+		if schema == "" {
+			continue
+		}
+		assert.NoError(t, ValidateCueTemplate(schema), policyType)
+	}
+	assert.NotEmpty(t, builtinPolicySchemaOf(v1alpha1.TopologyPolicyType))
+	assert.Empty(t, builtinPolicySchemaOf(v1alpha1.DebugPolicyType))
+}
+
+func TestValidateApplicationPolicies(t *testing.T) {
+	defer setFakeCuexCompiler()()
+	cli := fake.NewClientBuilder().WithScheme(common.Scheme).WithObjects(
+		&v1beta1.PolicyDefinition{
+			ObjectMeta: metav1.ObjectMeta{Name: "scaling", Namespace: "default"},
+			Spec: v1beta1.PolicyDefinitionSpec{Schematic: &apicommon.Schematic{CUE: &apicommon.CUE{
+				Template: `parameter: replicas: int & >0`,
+			}}},
+		},
+		&v1beta1.PolicyDefinition{
+			ObjectMeta: metav1.ObjectMeta{Name: "read-only", Namespace: oam.SystemDefinitionNamespace},
+			Spec: v1beta1.PolicyDefinitionSpec{Schematic: &apicommon.Schematic{CUE: &apicommon.CUE{
+				Template: `parameter: rules: [...{selector: componentNames: [...string]}]`,
+			}}},
+		},
+	).Build()
+
+	raw := func(s string) *runtime.RawExtension {
+		return &runtime.RawExtension{Raw: []byte(s)}
+	}
+	cases := map[string]struct {
+		policies []v1beta1.AppPolicy
+		wantErrs []string
+	}{
+		"valid": {
+			policies: []v1beta1.AppPolicy{
+				{Name: "topo", Type: "topology", Properties: raw(`{"clusters": ["local"], "namespace": "prod"}`)},
+				{Name: "override", Type: "override", Properties: raw(`{"components": [{"name": "api", "traits": [{"type": "scaler"}]}]}`)},
+				{Name: "gc", Type: "garbage-collect"},
+				{Name: "debug", Type: "debug", Properties: raw(`{"anything": true}`)},
+				{Name: "scaling", Type: "scaling@v1", Properties: raw(`{"replicas": 3}`)},
+			},
+		},
+		"builtinMismatchedType": {
+			policies: []v1beta1.AppPolicy{{Name: "topo", Type: "topology", Properties: raw(`{"clusters": "local"}`)}},
+			wantErrs: []string{`policy topo of type topology has invalid properties: parameter.clusters: conflicting values "local" and [...string] (mismatched types string and list)`},
+		},
+		"builtinMissingRequiredField": {
+			policies: []v1beta1.AppPolicy{{Name: "override", Type: "override", Properties: raw(`{"components": [{"traits": [{"properties": {}}]}]}`)}},
+			wantErrs: []string{"policy override of type override has invalid properties: parameter.components.0.traits.0.type: incomplete value string"},
+		},
+		"builtinDisallowedValue": {
+			policies: []v1beta1.AppPolicy{{Name: "gc", Type: "garbage-collect", Properties: raw(`{"rules": [{"selector": {}, "strategy": "always"}]}`)}},
+			wantErrs: []string{"policy gc of type garbage-collect has invalid properties", `parameter.rules.0.strategy: conflicting values "never" and "always"`},
+		},
+		"definitionOverridesBuiltin": {
+			policies: []v1beta1.AppPolicy{{Name: "ro", Type: "read-only", Properties: raw(`{"rules": [{"selector": {"componentNames": "api"}}]}`)}},
+			wantErrs: []string{"policy ro of type read-only has invalid properties: parameter.rules.0.selector.componentNames: conflicting values"},
+		},
+		"customDefinition": {
+			policies: []v1beta1.AppPolicy{{Name: "scaling", Type: "scaling", Properties: raw(`{"replicas": 0}`)}},
+			wantErrs: []string{"policy scaling of type scaling has invalid properties: parameter.replicas: invalid value 0 (out of bound >0)"},
+		},
+		"customDefinitionMissingProperties": {
+			policies: []v1beta1.AppPolicy{{Name: "scaling", Type: "scaling"}},
+			wantErrs: []string{"policy scaling of type scaling has invalid properties: parameter.replicas: incomplete value >0 & int"},
+		},
+		"definitionNotFound": {
+			policies: []v1beta1.AppPolicy{{Name: "canary", Type: "canary"}},
+			wantErrs: []string{"policy canary references PolicyDefinition canary that is not found"},
+		},
+		"errorsAggregated": {
+			policies: []v1beta1.AppPolicy{
+				{Name: "topo", Type: "topology", Properties: raw(`{"allowEmpty": "yes"}`)},
+				{Name: "canary", Type: "canary"},
+				{Name: "scaling", Type: "scaling", Properties: raw(`{"replicas": 2}`)},
+			},
+			wantErrs: []string{
+				"policy topo of type topology has invalid properties: parameter.allowEmpty: conflicting values",
+				"policy canary references PolicyDefinition canary that is not found",
+			},
+		},
+	}
+	for caseName, cs := range cases {
+		t.Run(caseName, func(t *testing.T) {
+			app := &v1beta1.Application{
+				ObjectMeta: metav1.ObjectMeta{Name: "app", Namespace: "default"},
+				Spec:       v1beta1.ApplicationSpec{Policies: cs.policies},
+			}
+			err := ValidateApplicationPolicies(context.Background(), cli, app)
+			if len(cs.wantErrs) == 0 {
+				assert.NoError(t, err)
+				return
+			}
+			for _, msg := range cs.wantErrs {
+				assert.ErrorContains(t, err, msg)
+			}
+		})
+	}
+}
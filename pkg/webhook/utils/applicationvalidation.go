@@ -0,0 +1,111 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"context"
+
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
+	"github.com/oam-dev/kubevela/pkg/oam/util"
+)
+
+// ValidateApplication runs the checks of the application in the order of their cost, and stops after the first
+// stage reporting errors: the data flow and the trait count of the components, then the definitions of the
+// components and the traits, and then the properties of the policies and the workflow steps. The patches of the
+// traits conflicting with each other are returned as warnings once the application passes.
+// The errors of a stage are reported together, and the same error or warning is only reported once.
+func ValidateApplication(ctx context.Context, cli client.Client, app *v1beta1.Application) ([]string, error) {
+	ctx = util.SetNamespaceInCtx(ctx, app.Namespace)
+	stages := [][]func() error{{
+		func() error { return ValidateApplicationDataFlow(app) },
+		func() error { return ValidateComponentTraitCount(app) },
+	}, {
+		func() error { return ValidateApplicationComponents(ctx, cli, app) },
+	}, {
+		func() error { return ValidateApplicationPolicies(ctx, cli, app) },
+		func() error { return validateApplicationWorkflow(ctx, cli, app) },
+	}}
+	for _, stage := range stages {
+		var errs []error
+		for _, check := range stage {
+			if err := check(); err != nil {
+				errs = append(errs, err)
+			}
+		}
+		if len(errs) != 0 {
+			return nil, aggregateErrors(dedupErrors(errs))
+		}
+	}
+
+	var warnings []string
+	seen := map[string]bool{}
+	for _, comp := range app.Spec.Components {
+		result, err := ValidateTraitPatchConflicts(ctx, cli, comp, TraitPatchConflictOptions{})
+		if err != nil {
+			return warnings, err
+		}
+		for _, msg := range result.WarningMessages() {
+			if !seen[msg] {
+				seen[msg] = true
+				warnings = append(warnings, msg)
+			}
+		}
+	}
+	return warnings, nil
+}
+
+// validateApplicationWorkflow validates the inline steps of the workflow of the application, or the steps of the
+// Workflow it references, as ValidateWorkflowRun does
+func validateApplicationWorkflow(ctx context.Context, cli client.Client, app *v1beta1.Application) error {
+	if app.Spec.Workflow == nil {
+		return nil
+	}
+	steps := app.Spec.Workflow.Steps
+	if len(steps) == 0 {
+		var err error
+		if steps, err = referencedWorkflowSteps(ctx, cli, app.Namespace, app.Spec.Workflow.Ref, "Application"); err != nil {
+			return err
+		}
+	}
+	return validateWorkflowSteps(ctx, &workflowStepValidator{cli: cli, definitions: map[string]*stepDefinition{}}, steps)
+}
+
+// dedupErrors splits the aggregated errors and drops the ones with the same message as an earlier one
+func dedupErrors(errs []error) []error {
+	var deduped []error
+	seen := map[string]bool{}
+	var collect func(err error)
+	collect = func(err error) {
+		if agg, ok := err.(utilerrors.Aggregate); ok {
+			for _, e := range agg.Errors() {
+				collect(e)
+			}
+			return
+		}
+		if !seen[err.Error()] {
+			seen[err.Error()] = true
+			deduped = append(deduped, err)
+		}
+	}
+	for _, err := range errs {
+		collect(err)
+	}
+	return deduped
+}
@@ -0,0 +1,139 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"context"
+	"testing"
+
+	workflowv1alpha1 "github.com/kubevela/workflow/api/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	apicommon "github.com/oam-dev/kubevela/apis/core.oam.dev/common"
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
+	"github.com/oam-dev/kubevela/pkg/oam"
+	"github.com/oam-dev/kubevela/pkg/utils/common"
+)
+
+func TestValidateApplication(t *testing.T) {
+	defer setFakeCuexCompiler()()
+	cli := fake.NewClientBuilder().WithScheme(common.Scheme).WithObjects(
+		&v1beta1.ComponentDefinition{ObjectMeta: metav1.ObjectMeta{Name: "webservice", Namespace: oam.SystemDefinitionNamespace}},
+		newTraitDefinition("sidecar", oam.SystemDefinitionNamespace, `
+parameter: name: string
+patch: spec: template: spec: {
+	// +patchKey=name
+	containers: [{name: parameter.name}]
+}
+`),
+		newTraitDefinition("command", oam.SystemDefinitionNamespace, `
+parameter: cmd: [...string]
+patch: spec: template: spec: {
+	// +patchStrategy=replace
+	containers: [{command: parameter.cmd}]
+}
+`),
+		&v1beta1.PolicyDefinition{
+			ObjectMeta: metav1.ObjectMeta{Name: "replication", Namespace: oam.SystemDefinitionNamespace},
+			Spec: v1beta1.PolicyDefinitionSpec{
+				Schematic: &apicommon.Schematic{CUE: &apicommon.CUE{Template: `parameter: replicas: int`}},
+			},
+		},
+		&v1beta1.WorkflowStepDefinition{
+			ObjectMeta: metav1.ObjectMeta{Name: "notify", Namespace: oam.SystemDefinitionNamespace},
+			Spec: v1beta1.WorkflowStepDefinitionSpec{
+				Schematic: &apicommon.Schematic{CUE: &apicommon.CUE{Template: `parameter: message: string`}},
+			},
+		},
+	).Build()
+	raw := func(s string) *runtime.RawExtension { return &runtime.RawExtension{Raw: []byte(s)} }
+	step := func(name, typ string) workflowv1alpha1.WorkflowStep {
+		return workflowv1alpha1.WorkflowStep{WorkflowStepBase: workflowv1alpha1.WorkflowStepBase{Name: name, Type: typ, Properties: raw(`{"message":"done"}`)}}
+	}
+	app := func(components []apicommon.ApplicationComponent, policies []v1beta1.AppPolicy, steps ...workflowv1alpha1.WorkflowStep) *v1beta1.Application {
+		a := &v1beta1.Application{
+			ObjectMeta: metav1.ObjectMeta{Name: "app", Namespace: "default"},
+			Spec:       v1beta1.ApplicationSpec{Components: components, Policies: policies},
+		}
+		if len(steps) != 0 {
+			a.Spec.Workflow = &v1beta1.Workflow{Steps: steps}
+		}
+		return a
+	}
+	api := apicommon.ApplicationComponent{Name: "api", Type: "webservice"}
+
+	cases := map[string]struct {
+		app          *v1beta1.Application
+		wantWarnings []string
+		wantErr      string
+	}{
+		"valid": {
+			app: app([]apicommon.ApplicationComponent{{
+				Name:   "api",
+				Type:   "webservice",
+				Traits: []apicommon.ApplicationTrait{{Type: "sidecar"}, {Type: "command"}},
+			}}, []v1beta1.AppPolicy{{Name: "replicas", Type: "replication", Properties: raw(`{"replicas":2}`)}}, step("done", "notify")),
+			wantWarnings: []string{"trait sidecar patches patch.spec.template.spec.containers with merge " +
+				"but trait command patches patch.spec.template.spec.containers with replace in component api"},
+		},
+		"dataFlowFirst": {
+			app:     app([]apicommon.ApplicationComponent{{Name: "api", Type: "not-exist", DependsOn: []string{"db"}}}, nil),
+			wantErr: "component api depends on component db that is not found",
+		},
+		"resolutionBeforeProperties": {
+			app: app([]apicommon.ApplicationComponent{{Name: "api", Type: "not-exist"}},
+				[]v1beta1.AppPolicy{{Name: "replicas", Type: "replication", Properties: raw(`{"replicas":"2"}`)}}),
+			wantErr: "component api references ComponentDefinition not-exist that is not found",
+		},
+		"policiesAndWorkflowAggregated": {
+			app: app([]apicommon.ApplicationComponent{api},
+				[]v1beta1.AppPolicy{{Name: "replicas", Type: "replication", Properties: raw(`{"replicas":"2"}`)}},
+				step("done", "notify"), step("deploy", "not-exist")),
+			wantErr: "[policy replicas of type replication has invalid properties: parameter.replicas: conflicting values int and \"2\" (mismatched types int and string), " +
+				"step deploy references WorkflowStepDefinition not-exist that is not found]",
+		},
+		"deduplicated": {
+			app:     app([]apicommon.ApplicationComponent{api}, nil, step("deploy", "not-exist"), step("deploy", "not-exist")),
+			wantErr: "step deploy references WorkflowStepDefinition not-exist that is not found",
+		},
+		"workflowRefNotFound": {
+			app: &v1beta1.Application{
+				ObjectMeta: metav1.ObjectMeta{Name: "app", Namespace: "default"},
+				Spec: v1beta1.ApplicationSpec{
+					Components: []apicommon.ApplicationComponent{api},
+					Workflow:   &v1beta1.Workflow{Ref: "not-exist"},
+				},
+			},
+			wantErr: "workflow not-exist referenced by the Application is not found",
+		},
+	}
+
+	for caseName, cs := range cases {
+		t.Run(caseName, func(t *testing.T) {
+			warnings, err := ValidateApplication(context.Background(), cli, cs.app)
+			assert.Equal(t, cs.wantWarnings, warnings)
+			if cs.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.EqualError(t, err, cs.wantErr)
+		})
+	}
+}
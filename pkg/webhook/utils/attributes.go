@@ -0,0 +1,116 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"cuelang.org/go/cue/ast"
+	cueErrors "cuelang.org/go/cue/errors"
+	"cuelang.org/go/cue/literal"
+	"cuelang.org/go/cue/parser"
+
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
+)
+
+// attributesFieldName is the field of the header of a definition file holding the spec of the definition except the
+// schematic, e.g. `webservice: {type: "component", attributes: {workload: ...}}`
+const attributesFieldName = "attributes"
+
+// knownAttributeKeys are the keys of the attributes by the types of the definitions declared in the headers of the
+// definition files, which are the fields of the specs of the definitions except the schematic built from the template
+var knownAttributeKeys = map[string]map[string]bool{
+	"component":     specFieldsOf(v1beta1.ComponentDefinitionSpec{}),
+	"trait":         specFieldsOf(v1beta1.TraitDefinitionSpec{}),
+	"policy":        specFieldsOf(v1beta1.PolicyDefinitionSpec{}),
+	"workflow-step": specFieldsOf(v1beta1.WorkflowStepDefinitionSpec{}),
+	"workload":      specFieldsOf(v1beta1.WorkloadDefinitionSpec{}),
+}
+
+// specFieldsOf returns the json names of the fields of the spec except the schematic
+func specFieldsOf(spec interface{}) map[string]bool {
+	fields := map[string]bool{}
+	t := reflect.TypeOf(spec)
+	for i := 0; i < t.NumField(); i++ {
+		name := strings.Split(t.Field(i).Tag.Get("json"), ",")[0]
+		if name != "" && name != "-" && name != "schematic" {
+			fields[name] = true
+		}
+	}
+	return fields
+}
+
+// unknownAttributesOf returns a warning for each key of the attributes in the header of the definition file that is
+// not known for the type of the definition, e.g. a misspelled woorkload, which would be silently dropped. The nearest
+// known key is suggested. The template without a header, e.g. the inline template, has no attributes.
+func unknownAttributesOf(cueTemplate string) []CueValidationError {
+	f, err := parser.ParseFile("-", cueTemplate)
+	if err != nil {
+		// the syntax error is reported by the validation
+		return nil
+	}
+	var warnings cueErrors.Error
+	for _, decl := range f.Decls {
+		header, ok := decl.(*ast.Field)
+		if !ok || !isDefinitionHeader(header) {
+			continue
+		}
+		defName, _, _ := ast.LabelName(header.Label)
+		defType, attributes := definitionHeaderOf(header)
+		known, ok := knownAttributeKeys[defType]
+		if !ok || attributes == nil {
+			continue
+		}
+		candidates := make([]string, 0, len(known))
+		for key := range known {
+			candidates = append(candidates, key)
+		}
+		sort.Strings(candidates)
+		for _, field := range fieldsOf(structLitsOf(attributes)) {
+			key, _, err := ast.LabelName(field.Label)
+			if err != nil || known[key] {
+				continue
+			}
+			msg := fmt.Sprintf("attribute %s of %s definition %s is unknown and ignored", key, defType, defName)
+			if nearest := nearestName(key, candidates); nearest != "" {
+				msg += fmt.Sprintf(", did you mean %s?", nearest)
+			}
+			warnings = cueErrors.Append(warnings, cueErrors.Newf(field.Pos(), "%s", msg))
+		}
+	}
+	return collectCueValidationErrors(warnings)
+}
+
+// definitionHeaderOf returns the type and the value of the attributes declared in the header of the definition file
+func definitionHeaderOf(header *ast.Field) (string, ast.Expr) {
+	var defType string
+	var attributes ast.Expr
+	for _, field := range fieldsOf(structLitsOf(header.Value)) {
+		switch name, _, _ := ast.LabelName(field.Label); name {
+		case "type":
+			if lit, ok := field.Value.(*ast.BasicLit); ok {
+				defType, _ = literal.Unquote(lit.Value)
+			}
+		case attributesFieldName:
+			attributes = field.Value
+		}
+	}
+	return defType, attributes
+}
@@ -0,0 +1,249 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sync"
+
+	"github.com/kubevela/pkg/cue/cuex"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
+	"github.com/oam-dev/kubevela/pkg/oam"
+	"github.com/oam-dev/kubevela/pkg/oam/util"
+	"github.com/oam-dev/kubevela/pkg/workflow/providers"
+)
+
+// BatchValidationConcurrency is the max number of definitions validated concurrently by ValidateDefinitionsBatch
+var BatchValidationConcurrency = 8
+
+// ValidateDefinitionsBatch validates the definitions with a bounded worker pool and returns the validation
+// error of each definition in the same order as defs. The returned error is only set when the batch itself
+// cannot be processed, e.g. the context is canceled.
+// Each definition is validated as ValidateDefinition does in its namespace, with the CueX imports loaded once by the
+// compiler and the definitionRevisions listed once per namespace. The identical cue templates of the definitions of
+// the same kind and namespace are validated once for the batch, and those definitions get the same result of it.
+func ValidateDefinitionsBatch(ctx context.Context, cli client.Client, defs []runtime.Object) ([]error, error) {
+	return newBatchValidator(ctx, cli, defs).validateAll(ctx, defs)
+}
+
+func newBatchValidator(ctx context.Context, cli client.Client, defs []runtime.Object) *batchValidator {
+	return &batchValidator{
+		cli:       cli,
+		revIndex:  listDefinitionRevisions(ctx, cli, defs),
+		templates: map[string]*batchTemplateResult{},
+	}
+}
+
+// validateAll validates the definitions by the bounded worker pool
+func (v *batchValidator) validateAll(ctx context.Context, defs []runtime.Object) ([]error, error) {
+	errs := make([]error, len(defs))
+	workers := BatchValidationConcurrency
+	if workers < 1 {
+		workers = 1
+	}
+	if workers > len(defs) {
+		workers = len(defs)
+	}
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for idx := range jobs {
+				errs[idx] = v.validate(ctx, defs[idx])
+			}
+		}()
+	}
+	var err error
+	for idx := range defs {
+		if err = ctx.Err(); err != nil {
+			break
+		}
+		jobs <- idx
+	}
+	close(jobs)
+	wg.Wait()
+	if err != nil {
+		return nil, err
+	}
+	return errs, nil
+}
+
+// batchValidator holds the states shared across the definitions of a batch
+type batchValidator struct {
+	cli client.Client
+	// revIndex is the definitionRevisions of the namespaces of the batch, nil if they can't be listed
+	revIndex map[types.NamespacedName]*v1beta1.DefinitionRevision
+
+	templatesLock sync.Mutex
+	// templates are the results of the cue templates validated in the batch, by the namespace and the kind of the
+	// definitions and the hashes of the templates
+	templates map[string]*batchTemplateResult
+}
+
+// batchTemplateResult is the result of a cue template validated once for the definitions of the batch sharing it
+type batchTemplateResult struct {
+	once sync.Once
+	err  error
+}
+
+// validateTemplateOnce runs validate for the first definition of the batch with the cue template under the key, and
+// returns the same result to the others, which wait for the validation if it's running
+func (v *batchValidator) validateTemplateOnce(key, cueTemplate string, validate func() error) error {
+	key += "/" + hashCueTemplate(cueTemplate)
+	v.templatesLock.Lock()
+	result, ok := v.templates[key]
+	if !ok {
+		result = &batchTemplateResult{}
+		v.templates[key] = result
+	}
+	v.templatesLock.Unlock()
+	result.once.Do(func() { result.err = validate() })
+	return result.err
+}
+
+// listDefinitionRevisions lists the definitionRevisions of the namespaces of the definitions with the revision name
+// annotation at once, so that they're not fetched one by one. It returns nil if any of the lists fails.
+func listDefinitionRevisions(ctx context.Context, cli client.Client, defs []runtime.Object) map[types.NamespacedName]*v1beta1.DefinitionRevision {
+	namespaces := map[string]bool{}
+	for _, def := range defs {
+		obj, ok := def.(client.Object)
+		if ok && obj.GetAnnotations()[oam.AnnotationDefinitionRevisionName] != "" {
+			namespaces[obj.GetNamespace()] = true
+		}
+	}
+	revIndex := map[types.NamespacedName]*v1beta1.DefinitionRevision{}
+	for namespace := range namespaces {
+		revs := &v1beta1.DefinitionRevisionList{}
+		if err := cli.List(ctx, revs, client.InNamespace(namespace)); err != nil {
+			klog.ErrorS(err, "Failed to list the definitionRevisions, they're fetched one by one instead", "namespace", namespace)
+			return nil
+		}
+		for i := range revs.Items {
+			revIndex[client.ObjectKeyFromObject(&revs.Items[i])] = &revs.Items[i]
+		}
+	}
+	return revIndex
+}
+
+// validate runs the same checks as the admission webhook of the definition, see ValidateDefinitionWithWarnings, with
+// the cue template validated once for the definitions of the batch sharing it
+func (v *batchValidator) validate(ctx context.Context, def runtime.Object) error {
+	obj, _, err := definitionVersionOf(def)
+	if err != nil {
+		return err
+	}
+	ctx = withDefinitionRevisionIndex(util.SetNamespaceInCtx(ctx, obj.GetNamespace()), v.revIndex)
+	if err := ValidateDefinitionSize(def); err != nil {
+		return err
+	}
+	if err := v.validateTemplate(ctx, obj); err != nil {
+		return err
+	}
+	_, err = ValidateDefinitionWithWarnings(WithCueTemplateValidated(ctx), v.cli, def)
+	return err
+}
+
+// validateTemplate validates the cue template of the definition as its kind-specific validator does. The result is
+// shared by the definitions of the same kind and namespace, as the permitted imports and functions depend on the
+// namespace, so the reference of the template to the definition itself is checked for each of them.
+func (v *batchValidator) validateTemplate(ctx context.Context, def client.Object) error {
+	_, schematic, err := definitionSchematicOf(def)
+	if err != nil || schematic == nil || schematic.CUE == nil {
+		return err
+	}
+	cueTemplate := schematic.CUE.Template
+	kind := definitionKind(def)
+	ctx = WithDefinitionKind(ctx, kind)
+	var validate func() error
+	switch def.(type) {
+	case *v1beta1.WorkflowStepDefinition:
+		if err := checkImportCycles(def.GetName(), cueTemplate, providers.DefaultCompiler.Get().GetImports()); err != nil {
+			return err
+		}
+		validate = func() error { return validateWorkflowStepTemplate(ctx, "", cueTemplate) }
+	case *v1beta1.PolicyDefinition:
+		if err := checkImportCycles(def.GetName(), cueTemplate, cuex.DefaultCompiler.Get().GetImports()); err != nil {
+			return err
+		}
+		validate = func() error {
+			_, err := ValidateCueTemplateDetailedWithContext(ctx, cueTemplate)
+			return err
+		}
+	default:
+		if err := checkImportCycles(def.GetName(), cueTemplate, cuex.DefaultCompiler.Get().GetImports()); err != nil {
+			return err
+		}
+		validate = func() error { return ValidateCuexTemplate(ctx, cueTemplate) }
+	}
+	return v.validateTemplateOnce(def.GetNamespace()+"/"+kind, cueTemplate, validate)
+}
+
+type definitionRevisionIndexCtxKey struct{}
+
+// withDefinitionRevisionIndex returns the context carrying the definitionRevisions pre-fetched for a batch, which the
+// definitionRevisions of the definitions validated with it are looked up in
+func withDefinitionRevisionIndex(ctx context.Context, revIndex map[types.NamespacedName]*v1beta1.DefinitionRevision) context.Context {
+	return context.WithValue(ctx, definitionRevisionIndexCtxKey{}, revIndex)
+}
+
+// definitionRevisionIndexOf returns the definitionRevisions carried by the context, nil if it's not set
+func definitionRevisionIndexOf(ctx context.Context) map[types.NamespacedName]*v1beta1.DefinitionRevision {
+	revIndex, _ := ctx.Value(definitionRevisionIndexCtxKey{}).(map[types.NamespacedName]*v1beta1.DefinitionRevision)
+	return revIndex
+}
+
+// validateDefinitionVersions validates the version, its monotonicity, the collision of the versioned and unversioned
+// forms of the name and the revision name annotation of the definition, the definitionRevision is looked up in revIndex if it's not nil.
+// The warnings of the definitionRevision, e.g. the mutation allowed by annotation, are returned.
+func validateDefinitionVersions(ctx context.Context, cli client.Client, revIndex map[types.NamespacedName]*v1beta1.DefinitionRevision, def client.Object, version string) ([]string, error) {
+	if err := validateDefinitionVersionsOffline(def, version); err != nil {
+		return nil, err
+	}
+	if err := ValidateDefinitionVersionMonotonic(ctx, cli, def, version); err != nil {
+		return nil, err
+	}
+	if err := ValidateDefinitionNameCollision(ctx, cli, def); err != nil {
+		return nil, err
+	}
+	revisionName := def.GetAnnotations()[oam.AnnotationDefinitionRevisionName]
+	if len(revisionName) != 0 {
+		defRevKey := client.ObjectKey{Namespace: def.GetNamespace(), Name: fmt.Sprintf("%s-v%s", def.GetName(), revisionName)}
+		if revIndex != nil {
+			return nil, ValidateDefinitionRevisionWithCache(ctx, revIndex, def, defRevKey)
+		}
+		result, err := ValidateDefinitionRevisionWithResult(ctx, cli, def, defRevKey)
+		return result.Warnings, err
+	}
+	return nil, nil
+}
+
+// definitionKind returns the kind of the definition, typed objects may have empty TypeMeta
+func definitionKind(def runtime.Object) string {
+	if kind := def.GetObjectKind().GroupVersionKind().Kind; kind != "" {
+		return kind
+	}
+	return reflect.Indirect(reflect.ValueOf(def)).Type().Name()
+}
@@ -0,0 +1,168 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/common"
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
+	"github.com/oam-dev/kubevela/pkg/oam"
+	pkgcommon "github.com/oam-dev/kubevela/pkg/utils/common"
+)
+
+func TestValidateDefinitionsBatch(t *testing.T) {
+	defer setFakeCuexCompiler()()
+	cli := fake.NewClientBuilder().WithScheme(pkgcommon.Scheme).WithRESTMapper(newWorkloadRESTMapper()).Build()
+	schematic := func(template string) *common.Schematic {
+		return &common.Schematic{CUE: &common.CUE{Template: template}}
+	}
+	defs := []runtime.Object{
+		&v1beta1.ComponentDefinition{
+			ObjectMeta: metav1.ObjectMeta{Name: "worker"},
+			Spec: v1beta1.ComponentDefinitionSpec{
+				Schematic: schematic(`output: {apiVersion: "v1", kind: "ConfigMap", metadata: name: context.name}`),
+				Version:   "1.0.0",
+			},
+		},
+		&v1beta1.ComponentDefinition{
+			ObjectMeta: metav1.ObjectMeta{Name: "broken"},
+			Spec: v1beta1.ComponentDefinitionSpec{
+				Schematic: schematic(`output: hello: world`),
+			},
+		},
+		&v1beta1.TraitDefinition{
+			ObjectMeta: metav1.ObjectMeta{Name: "scaler"},
+			Spec: v1beta1.TraitDefinitionSpec{
+				Schematic: schematic(`parameter: replicas: *1.5 | int`),
+			},
+		},
+		&v1beta1.PolicyDefinition{
+			ObjectMeta: metav1.ObjectMeta{Name: "policy"},
+			Spec: v1beta1.PolicyDefinitionSpec{
+				Schematic: schematic(`output: name: context.name`),
+				Version:   "1.2",
+			},
+		},
+		&v1beta1.WorkflowStepDefinition{
+			ObjectMeta: metav1.ObjectMeta{Name: "step", Annotations: map[string]string{oam.AnnotationDefinitionRevisionName: "1"}},
+			Spec:       v1beta1.WorkflowStepDefinitionSpec{Version: "1.0.0"},
+		},
+		&v1beta1.WorkflowStepDefinition{
+			ObjectMeta: metav1.ObjectMeta{Name: "apply"},
+			Spec:       v1beta1.WorkflowStepDefinitionSpec{Schematic: schematic(`apply: hello: world`)},
+		},
+		&v1beta1.ComponentDefinition{
+			ObjectMeta: metav1.ObjectMeta{Name: "secrets", Namespace: "tenant"},
+			Spec: v1beta1.ComponentDefinitionSpec{
+				Schematic: schematic("import \"vela/internal/secrets\"\noutput: secrets.#Read"),
+			},
+		},
+		&v1beta1.Application{},
+	}
+	want := []string{
+		"",
+		"output.hello: reference \"world\" not found",
+		"parameter.replicas default '1.5' is not an int",
+		"Not a valid version",
+		"WorkflowStepDefinition has both spec.version and revision name annotation. Only one can be present",
+		"apply.hello: reference \"world\" not found",
+		"import vela/internal/secrets is not permitted",
+		"unsupported definition type *v1beta1.Application",
+	}
+
+	errs, err := ValidateDefinitionsBatch(context.Background(), cli, defs)
+	assert.NoError(t, err)
+	assert.Equal(t, len(want), len(errs))
+	for i := range want {
+		if want[i] == "" {
+			assert.NoError(t, errs[i])
+		} else {
+			assert.EqualError(t, errs[i], want[i])
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = ValidateDefinitionsBatch(ctx, cli, defs)
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+func TestValidateDefinitionsBatchDedup(t *testing.T) {
+	defer setFakeCuexCompiler()()
+	cli := fake.NewClientBuilder().WithScheme(pkgcommon.Scheme).WithRESTMapper(newWorkloadRESTMapper()).Build()
+	const (
+		valid      = `output: {apiVersion: "v1", kind: "ConfigMap", metadata: name: context.name}`
+		broken     = `output: hello: world`
+		selfImport = "import \"worker\"\noutput: value: worker.x"
+	)
+	component := func(name, template, version string) *v1beta1.ComponentDefinition {
+		return &v1beta1.ComponentDefinition{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Spec: v1beta1.ComponentDefinitionSpec{
+				Schematic: &common.Schematic{CUE: &common.CUE{Template: template}},
+				Version:   version,
+			},
+		}
+	}
+	inNamespace := func(cd *v1beta1.ComponentDefinition, namespace string) *v1beta1.ComponentDefinition {
+		cd.Namespace = namespace
+		return cd
+	}
+	defs := []runtime.Object{
+		component("a", valid, ""),
+		component("b", valid, "1.0.0"),
+		component("c", valid, "1.x"),
+		component("d", broken, ""),
+		&v1beta1.TraitDefinition{
+			ObjectMeta: metav1.ObjectMeta{Name: "e"},
+			Spec:       v1beta1.TraitDefinitionSpec{Schematic: &common.Schematic{CUE: &common.CUE{Template: broken}}},
+		},
+		&v1beta1.PolicyDefinition{
+			ObjectMeta: metav1.ObjectMeta{Name: "f"},
+			Spec:       v1beta1.PolicyDefinitionSpec{Schematic: &common.Schematic{CUE: &common.CUE{Template: valid}}},
+		},
+		component("worker", selfImport, ""),
+		component("other", selfImport, ""),
+		inNamespace(component("g", valid, ""), "tenant"),
+	}
+
+	v := newBatchValidator(context.Background(), cli, defs)
+	errs, err := v.validateAll(context.Background(), defs)
+	assert.NoError(t, err)
+	assert.NoError(t, errs[0])
+	assert.NoError(t, errs[1])
+	// the definitions sharing a template keep their own results of the other checks
+	assert.EqualError(t, errs[2], "Not a valid version")
+	assert.EqualError(t, errs[3], "output.hello: reference \"world\" not found")
+	assert.EqualError(t, errs[4], "output.hello: reference \"world\" not found")
+	assert.NoError(t, errs[5])
+	assert.EqualError(t, errs[6], "definition worker references itself")
+	assert.Error(t, errs[7])
+	assert.NotEqual(t, errs[6].Error(), errs[7].Error())
+	assert.NoError(t, errs[8])
+
+	// valid, broken and selfImport of the components, which is only validated for other, broken of the trait, valid
+	// of the policy and valid of the component in another namespace
+	assert.Len(t, v.templates, 6)
+}
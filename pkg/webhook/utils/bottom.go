@@ -0,0 +1,76 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"fmt"
+	"strings"
+
+	"cuelang.org/go/cue"
+	"cuelang.org/go/cue/cuecontext"
+	cueErrors "cuelang.org/go/cue/errors"
+	"cuelang.org/go/cue/parser"
+	"github.com/kubevela/workflow/pkg/cue/model"
+)
+
+// ValidateBottomValues evaluates the template with the context filled by concreteContextStub and reports the path of
+// every reachable field resolving to bottom, i.e. an explicit _|_ or a contradiction of the unification, which the
+// validation may not surface when the field isn't needed to evaluate the rest of the template. The fields under a
+// bottom field are not reported again, and the values incomplete until the rendering are not bottom.
+// The bottom values are returned as warnings since some of them are intentional, e.g. a field disabled on purpose.
+// The check is opt-in, see CheckBottomValues.
+// Nothing is reported if the template can't be parsed, which is reported by the validation of the template.
+func ValidateBottomValues(cueTemplate string) (*ValidationResult, error) {
+	result := &ValidationResult{}
+	if _, err := parser.ParseFile("-", cueTemplate); err != nil {
+		return result, nil
+	}
+	val := cuecontext.New().CompileString(cueTemplate + contextStub)
+	val = val.FillPath(cue.ParsePath(model.ContextFieldName), val.Context().CompileString(concreteContextStub))
+	iter, err := val.Fields()
+	if err != nil {
+		return result, nil
+	}
+	for iter.Next() {
+		if iter.Selector().String() == model.ContextFieldName {
+			continue
+		}
+		result.Warnings = append(result.Warnings, bottomValuesOf(iter.Value())...)
+	}
+	return result, nil
+}
+
+// bottomValuesOf returns the bottom values found by validating every field under v, the values incomplete until the
+// rendering are not bottom
+func bottomValuesOf(v cue.Value) []CueValidationError {
+	var errs []CueValidationError
+	for _, e := range cueErrors.Errors(v.Validate(cue.All())) {
+		msg, args := e.Msg()
+		if incompleteErrorRegex.MatchString(e.Error()) || contextNotFoundRegex.MatchString(e.Error()) {
+			continue
+		}
+		ve := CueValidationError{Message: fmt.Sprintf("%s resolves to bottom: %s", strings.Join(e.Path(), "."), fmt.Sprintf(msg, args...))}
+		for _, pos := range append(cueErrors.Positions(e), v.Pos()) {
+			if pos.IsValid() {
+				ve.Line, ve.Column = pos.Line(), pos.Column()
+				break
+			}
+		}
+		errs = append(errs, ve)
+	}
+	return errs
+}
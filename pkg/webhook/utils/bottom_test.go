@@ -0,0 +1,78 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateBottomValues(t *testing.T) {
+	cases := map[string]struct {
+		cueTemplate  string
+		wantWarnings []string
+	}{
+		"noBottom": {
+			cueTemplate: `
+parameter: {
+	name:  string
+	port?: int
+}
+output: {
+	name: parameter.name
+	if parameter.port != _|_ {
+		port: parameter.port
+	}
+}`,
+		},
+		"explicitBottom": {
+			cueTemplate: `
+output: {
+	name: "test"
+	port: _|_
+}`,
+			wantWarnings: []string{"line 4: output resolves to bottom: explicit error (_|_ literal) in source"},
+		},
+		"conflict": {
+			cueTemplate: `
+#Port: port: 80
+outputs: service: spec: #Port & {port: 8080}`,
+			wantWarnings: []string{"line 2: outputs.service.spec.port resolves to bottom: conflicting values 8080 and 80"},
+		},
+		"concreteContext": {
+			cueTemplate: `
+output: metadata: name: context.name + 1`,
+			wantWarnings: []string{"line 2: output.metadata.name resolves to bottom: invalid operands \"validation\" and 1 to '+' (type string and int)"},
+		},
+		"unreferencedDefinition": {
+			cueTemplate: `
+#Disabled: _|_
+output: name: "test"`,
+		},
+		"syntaxError": {
+			cueTemplate: `output: {`,
+		},
+	}
+	for caseName, cs := range cases {
+		t.Run(caseName, func(t *testing.T) {
+			result, err := ValidateBottomValues(cs.cueTemplate)
+			assert.NoError(t, err)
+			assert.Equal(t, cs.wantWarnings, result.WarningMessages())
+		})
+	}
+}
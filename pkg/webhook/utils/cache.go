@@ -0,0 +1,92 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"sync"
+
+	"github.com/bluele/gcache"
+
+	"github.com/oam-dev/kubevela/pkg/monitor/metrics"
+)
+
+// CueTemplateCacheSize is the max number of cue template validation results kept in the LRU cache.
+// Setting it to 0 or less disables the cache.
+var CueTemplateCacheSize = 1000
+
+const (
+	cacheResultHit  = "hit"
+	cacheResultMiss = "miss"
+)
+
+// cueValidationResult is the memoized result of validating a cue template
+type cueValidationResult struct {
+	errs []CueValidationError
+	err  error
+}
+
+var (
+	cueTemplateCache     gcache.Cache
+	cueTemplateCacheLock sync.Mutex
+)
+
+// getCueTemplateCache lazily builds the cache, so the size can be configured by flags before the first use.
+// It returns nil if the cache is disabled.
+func getCueTemplateCache() gcache.Cache {
+	cueTemplateCacheLock.Lock()
+	defer cueTemplateCacheLock.Unlock()
+	if cueTemplateCache == nil && CueTemplateCacheSize > 0 {
+		cueTemplateCache = gcache.New(CueTemplateCacheSize).LRU().Build()
+	}
+	return cueTemplateCache
+}
+
+// cachedValidation returns the cached validation result of the cue template, or run validate and cache its result
+func cachedValidation(cueTemplate string, validate func(string) ([]CueValidationError, error)) ([]CueValidationError, error) {
+	cache := getCueTemplateCache()
+	if cache == nil {
+		return validate(cueTemplate)
+	}
+	key := hashCueTemplate(cueTemplate)
+	if v, err := cache.Get(key); err == nil {
+		metrics.CueTemplateValidationCacheCounter.WithLabelValues(cacheResultHit).Inc()
+		result := v.(cueValidationResult)
+		return result.errs, result.err
+	}
+	metrics.CueTemplateValidationCacheCounter.WithLabelValues(cacheResultMiss).Inc()
+	errs, err := validate(cueTemplate)
+	_ = cache.Set(key, cueValidationResult{errs: errs, err: err})
+	return errs, err
+}
+
+// InvalidateCueTemplateCache drops the cached validation results of the plain cue templates. The results of the
+// CueX templates are not cached, as they depend on the providers and the imports of the compiler which can be
+// changed at runtime, so they never need to be dropped.
+func InvalidateCueTemplateCache() {
+	cueTemplateCacheLock.Lock()
+	defer cueTemplateCacheLock.Unlock()
+	if cueTemplateCache != nil {
+		cueTemplateCache.Purge()
+	}
+}
+
+func hashCueTemplate(cueTemplate string) string {
+	sum := sha256.Sum256([]byte(cueTemplate))
+	return hex.EncodeToString(sum[:])
+}
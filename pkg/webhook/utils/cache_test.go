@@ -0,0 +1,95 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/oam-dev/kubevela/pkg/monitor/metrics"
+)
+
+func TestCueTemplateValidationCache(t *testing.T) {
+	hits := func() float64 {
+		return testutil.ToFloat64(metrics.CueTemplateValidationCacheCounter.WithLabelValues(cacheResultHit))
+	}
+	misses := func() float64 {
+		return testutil.ToFloat64(metrics.CueTemplateValidationCacheCounter.WithLabelValues(cacheResultMiss))
+	}
+	calls := 0
+	validate := func(cueTemplate string) ([]CueValidationError, error) {
+		calls++
+		return validateCueTemplate(cueTemplate)
+	}
+
+	hit, miss := hits(), misses()
+	invalid := "output: hello: world"
+	_, err := cachedValidation(invalid, validate)
+	assert.EqualError(t, err, "output.hello: reference \"world\" not found")
+	_, err = cachedValidation(invalid, validate)
+	assert.EqualError(t, err, "output.hello: reference \"world\" not found")
+	assert.Equal(t, 1, calls)
+	assert.Equal(t, hit+1, hits())
+	assert.Equal(t, miss+1, misses())
+
+	_, err = cachedValidation("output: hello: \"world\"", validate)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, calls)
+	assert.Equal(t, miss+2, misses())
+}
+
+func TestCueTemplateValidationCacheDisabled(t *testing.T) {
+	cueTemplateCacheLock.Lock()
+	originSize, originCache := CueTemplateCacheSize, cueTemplateCache
+	CueTemplateCacheSize, cueTemplateCache = 0, nil
+	cueTemplateCacheLock.Unlock()
+	defer func() {
+		cueTemplateCacheLock.Lock()
+		CueTemplateCacheSize, cueTemplateCache = originSize, originCache
+		cueTemplateCacheLock.Unlock()
+	}()
+
+	calls := 0
+	validate := func(cueTemplate string) ([]CueValidationError, error) {
+		calls++
+		return validateCueTemplate(cueTemplate)
+	}
+	for i := 0; i < 2; i++ {
+		_, err := cachedValidation("name: 'name'", validate)
+		assert.NoError(t, err)
+	}
+	assert.Equal(t, 2, calls)
+}
+
+func TestInvalidateCueTemplateCache(t *testing.T) {
+	calls := 0
+	validate := func(cueTemplate string) ([]CueValidationError, error) {
+		calls++
+		return validateCueTemplate(cueTemplate)
+	}
+	cueTemplate := "output: kind: \"ConfigMap\""
+
+	_, _ = cachedValidation(cueTemplate, validate)
+	_, _ = cachedValidation(cueTemplate, validate)
+	assert.Equal(t, 1, calls)
+
+	InvalidateCueTemplateCache()
+	_, _ = cachedValidation(cueTemplate, validate)
+	assert.Equal(t, 2, calls)
+}
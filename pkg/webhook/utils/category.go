@@ -0,0 +1,95 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"regexp"
+
+	"github.com/pkg/errors"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+)
+
+// The categories of the validation errors returned by ErrorCategory, which are stable so that the callers can
+// map them to e.g. the exit codes of a CLI
+const (
+	// CategorySyntax is the category of the syntax errors of the cue template
+	CategorySyntax = "syntax"
+	// CategoryType is the category of the evaluation errors of the cue template, e.g. conflicting values
+	CategoryType = "type"
+	// CategorySchemaDrift is the category of the changes to the spec of an existing definitionRevision
+	CategorySchemaDrift = "schema-drift"
+	// CategoryNaming is the category of the invalid names
+	CategoryNaming = "naming"
+	// CategoryVersion is the category of the invalid or conflicting versions of the definition
+	CategoryVersion = "version"
+	// CategoryUnknown is the category of the errors not classified
+	CategoryUnknown = "unknown"
+)
+
+// sentinelCategories maps the sentinel errors to their categories
+var sentinelCategories = []struct {
+	sentinel error
+	category string
+}{
+	{ErrRevisionSpecDrift, CategorySchemaDrift},
+	{ErrRevisionHashMismatch, CategorySchemaDrift},
+	{ErrInvalidRevisionName, CategoryNaming},
+	{ErrConcreteEvaluation, CategoryType},
+	{ErrInvalidVersion, CategoryVersion},
+	{ErrInvalidVersionConstraint, CategoryVersion},
+	{ErrVersionConflict, CategoryVersion},
+	{ErrVersionNotIncreasing, CategoryVersion},
+}
+
+var (
+	// cueSyntaxErrorRegex matches the messages of the errors reported by the cue scanner and parser
+	cueSyntaxErrorRegex = regexp.MustCompile(`expected .+, found |missing ',' in |illegal (character|hexadecimal|octal|binary|number)|literal not terminated|comment not terminated`)
+	// cueTypeErrorRegex matches the messages of the errors reported by the cue evaluation
+	cueTypeErrorRegex = regexp.MustCompile(`conflicting values|mismatched types|invalid operands?|invalid value|out of bound|cannot use|cannot convert|field not allowed|reference ".+" not found|undefined field|incomplete value|non-concrete value|default '.+' is not an? `)
+)
+
+// ErrorCategory classifies the error returned by the validation into one of the Category constants, it returns
+// empty for nil. The errors wrapping the sentinel errors are classified by them, and the cue errors, which carry
+// no type, by their messages. An aggregate takes the category of the first error classified in it.
+func ErrorCategory(err error) string {
+	if err == nil {
+		return ""
+	}
+	var agg utilerrors.Aggregate
+	if errors.As(err, &agg) {
+		for _, e := range agg.Errors() {
+			if category := ErrorCategory(e); category != CategoryUnknown {
+				return category
+			}
+		}
+		return CategoryUnknown
+	}
+	for _, c := range sentinelCategories {
+		if errors.Is(err, c.sentinel) {
+			return c.category
+		}
+	}
+	msg := err.Error()
+	switch {
+	case cueSyntaxErrorRegex.MatchString(msg):
+		return CategorySyntax
+	case cueTypeErrorRegex.MatchString(msg):
+		return CategoryType
+	default:
+		return CategoryUnknown
+	}
+}
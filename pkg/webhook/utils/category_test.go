@@ -0,0 +1,105 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+)
+
+func TestErrorCategory(t *testing.T) {
+	cases := map[string]struct {
+		err  error
+		want string
+	}{
+		"nil": {
+			err:  nil,
+			want: "",
+		},
+		"unclosedStruct": {
+			err:  ValidateCueTemplate(`parameter: {`),
+			want: CategorySyntax,
+		},
+		"unterminatedString": {
+			err:  ValidateCueTemplate(`parameter: name: "abc`),
+			want: CategorySyntax,
+		},
+		"illegalCharacter": {
+			err:  ValidateCueTemplate(`output: %`),
+			want: CategorySyntax,
+		},
+		"conflictingValues": {
+			err:  ValidateCueTemplate("parameter: port: int\nparameter: port: \"80\""),
+			want: CategoryType,
+		},
+		"referenceNotFound": {
+			err:  ValidateCueTemplate(`output: hello: world`),
+			want: CategoryType,
+		},
+		"invalidDefault": {
+			err:  ValidateCueTemplate(`parameter: replicas: *1.5 | int`),
+			want: CategoryType,
+		},
+		"revisionHashMismatch": {
+			err:  fmt.Errorf("%w:\n%s", ErrRevisionHashMismatch, "diff"),
+			want: CategorySchemaDrift,
+		},
+		"revisionSpecDrift": {
+			err:  ErrRevisionSpecDrift,
+			want: CategorySchemaDrift,
+		},
+		"invalidRevisionName": {
+			err:  fmt.Errorf("%w %s", ErrInvalidRevisionName, "My_Def-v1"),
+			want: CategoryNaming,
+		},
+		"invalidVersion": {
+			err:  ValidateSemanticVersion("1.x"),
+			want: CategoryVersion,
+		},
+		"invalidVersionConstraint": {
+			err:  ValidateSemanticVersionConstraint("abc"),
+			want: CategoryVersion,
+		},
+		"conflictingVersions": {
+			err:  ValidateVersionConsistency("1.0.0", "1.1.0"),
+			want: CategoryVersion,
+		},
+		"multipleVersions": {
+			err:  ValidateMultipleDefVersionsNotPresent("1.0.0", "v1", "TraitDefinition"),
+			want: CategoryVersion,
+		},
+		"aggregate": {
+			err: utilerrors.NewAggregate([]error{
+				fmt.Errorf("unsupported definition type"),
+				ValidateCueTemplate(`output: hello: world`),
+			}),
+			want: CategoryType,
+		},
+		"unknown": {
+			err:  ErrTemplateTooComplex,
+			want: CategoryUnknown,
+		},
+	}
+	for caseName, cs := range cases {
+		t.Run(caseName, func(t *testing.T) {
+			assert.Equal(t, cs.want, ErrorCategory(cs.err))
+		})
+	}
+}
@@ -0,0 +1,104 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"cuelang.org/go/cue/ast"
+	"cuelang.org/go/cue/parser"
+	"cuelang.org/go/cue/token"
+)
+
+var (
+	// CueTemplateMaxBytes is the max size in bytes of a cue template to be validated, the larger templates are
+	// rejected before they are compiled. 0 disables the limit.
+	CueTemplateMaxBytes = 8 << 20
+	// CueTemplateMaxNodes is the max number of syntax nodes of a cue template validated with CueX
+	CueTemplateMaxNodes = 200000
+	// CueTemplateMaxDisjunctions is the max number of disjunctions of a cue template validated with CueX
+	CueTemplateMaxDisjunctions = 5000
+	// CueTemplateValidationTimeout is the hard ceiling of the evaluation time of a cue template validated with CueX,
+	// which applies even if the context passed in has a later deadline
+	CueTemplateValidationTimeout = 30 * time.Second
+)
+
+// checkTemplateSize checks the size of the cue template against CueTemplateMaxBytes
+func checkTemplateSize(cueTemplate string) error {
+	if CueTemplateMaxBytes > 0 && len(cueTemplate) > CueTemplateMaxBytes {
+		return fmt.Errorf("%w: %d bytes exceed the limit %d", ErrTemplateTooLarge, len(cueTemplate), CueTemplateMaxBytes)
+	}
+	return nil
+}
+
+// checkTemplateComplexity checks the size of the cue template against the node and disjunction budget
+func checkTemplateComplexity(cueTemplate string) error {
+	f, err := parser.ParseFile("-", cueTemplate)
+	if err != nil {
+		// leave the syntax error to the compiler
+		return nil
+	}
+	nodes, disjunctions := 0, 0
+	ast.Walk(f, func(node ast.Node) bool {
+		nodes++
+		if x, ok := node.(*ast.BinaryExpr); ok && x.Op == token.OR {
+			disjunctions++
+		}
+		return true
+	}, nil)
+	if nodes > CueTemplateMaxNodes {
+		return fmt.Errorf("%w: %d syntax nodes exceed the budget %d", ErrTemplateTooComplex, nodes, CueTemplateMaxNodes)
+	}
+	if disjunctions > CueTemplateMaxDisjunctions {
+		return fmt.Errorf("%w: %d disjunctions exceed the budget %d", ErrTemplateTooComplex, disjunctions, CueTemplateMaxDisjunctions)
+	}
+	return nil
+}
+
+// validateWithTimeout runs the validation until it finishes, the context is done or CueTemplateValidationTimeout
+// passes. The cue evaluation cannot be interrupted, so the validation keeps running in the background after timeout,
+// but the admission is not blocked by it. ErrTemplateTooComplex is returned if the deadline passes, either
+// CueTemplateValidationTimeout or the one of the context, and context.Canceled if the context is cancelled, e.g.
+// the admission request is aborted.
+func validateWithTimeout(ctx context.Context, validate func(ctx context.Context) ([]CueValidationError, error)) ([]CueValidationError, error) {
+	timeoutCtx, cancel := context.WithTimeout(ctx, CueTemplateValidationTimeout)
+	defer cancel()
+	type result struct {
+		errs []CueValidationError
+		err  error
+	}
+	ch := make(chan result, 1)
+	go func() {
+		var r result
+		// the panic of the goroutine can't be recovered by the caller
+		defer func() { ch <- r }()
+		defer recoverValidation(validatorCuexTemplate, &r.err)
+		r.errs, r.err = validate(timeoutCtx)
+	}()
+	select {
+	case r := <-ch:
+		return r.errs, r.err
+	case <-timeoutCtx.Done():
+		if errors.Is(timeoutCtx.Err(), context.Canceled) {
+			return nil, timeoutCtx.Err()
+		}
+		return nil, fmt.Errorf("%w: the evaluation is not finished: %w", ErrTemplateTooComplex, timeoutCtx.Err())
+	}
+}
@@ -0,0 +1,104 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckTemplateSize(t *testing.T) {
+	defer func(maxBytes int) { CueTemplateMaxBytes = maxBytes }(CueTemplateMaxBytes)
+	CueTemplateMaxBytes = 64
+	small := `parameter: name: string`
+	large := "parameter: name: *\"" + strings.Repeat("x", 64) + "\" | string"
+
+	assert.NoError(t, ValidateCueTemplate(small))
+	err := ValidateCueTemplate(large)
+	assert.ErrorIs(t, err, ErrTemplateTooLarge)
+	assert.EqualError(t, err, fmt.Sprintf("template too large: %d bytes exceed the limit 64", len(large)))
+	_, err = ValidateCuexTemplateDetailed(context.Background(), large)
+	assert.ErrorIs(t, err, ErrTemplateTooLarge)
+
+	CueTemplateMaxBytes = 0
+	assert.NoError(t, checkTemplateSize(large))
+}
+
+func TestCheckTemplateComplexity(t *testing.T) {
+	disjunctions := make([]string, CueTemplateMaxDisjunctions+2)
+	for i := range disjunctions {
+		disjunctions[i] = fmt.Sprintf("%q", fmt.Sprint(i))
+	}
+	cases := map[string]struct {
+		cueTemplate string
+		wantErr     string
+	}{
+		"simpleTemplate": {
+			cueTemplate: `parameter: type: *"ClusterIP" | "NodePort" | "LoadBalancer"`,
+		},
+		"tooManyDisjunctions": {
+			cueTemplate: "parameter: type: " + strings.Join(disjunctions, " | "),
+			wantErr:     fmt.Sprintf("template exceeds complexity limit: %d disjunctions exceed the budget %d", CueTemplateMaxDisjunctions+1, CueTemplateMaxDisjunctions),
+		},
+	}
+	for caseName, cs := range cases {
+		t.Run(caseName, func(t *testing.T) {
+			err := checkTemplateComplexity(cs.cueTemplate)
+			if cs.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorIs(t, err, ErrTemplateTooComplex)
+			assert.EqualError(t, err, cs.wantErr)
+		})
+	}
+}
+
+func TestValidateWithTimeout(t *testing.T) {
+	defer func(timeout time.Duration) { CueTemplateValidationTimeout = timeout }(CueTemplateValidationTimeout)
+	CueTemplateValidationTimeout = 50 * time.Millisecond
+	blocking := func(ctx context.Context) ([]CueValidationError, error) {
+		time.Sleep(time.Second)
+		return nil, nil
+	}
+
+	_, err := validateWithTimeout(context.Background(), func(ctx context.Context) ([]CueValidationError, error) {
+		return []CueValidationError{{Message: "foo"}}, fmt.Errorf("foo")
+	})
+	assert.EqualError(t, err, "foo")
+
+	_, err = validateWithTimeout(context.Background(), blocking)
+	assert.ErrorIs(t, err, ErrTemplateTooComplex)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = validateWithTimeout(ctx, blocking)
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.NotErrorIs(t, err, ErrTemplateTooComplex)
+
+	ctx, cancel = context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err = validateWithTimeout(ctx, blocking)
+	assert.ErrorIs(t, err, ErrTemplateTooComplex)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
@@ -0,0 +1,247 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"cuelang.org/go/cue"
+	"cuelang.org/go/cue/ast"
+	"cuelang.org/go/cue/cuecontext"
+	"cuelang.org/go/cue/parser"
+	"cuelang.org/go/cue/token"
+	"github.com/kubevela/pkg/cue/cuex"
+	"github.com/kubevela/workflow/pkg/cue/model"
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/common"
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
+	"github.com/oam-dev/kubevela/apis/types"
+	"github.com/oam-dev/kubevela/pkg/cue/definition"
+)
+
+// statusStub is appended to the status snippets, the context and parameter are injected at runtime
+const statusStub = "\ncontext: _\nparameter: _\n"
+
+// outputObjectSchema is the minimal schema of the output of the component, which is rendered to the workload
+const outputObjectSchema = `{apiVersion: string, kind: string, ...}`
+
+// ValidateComponentDefinition validates the component's cue template and the status snippets, the output of the
+// template must be a Kubernetes object with concrete apiVersion and kind, the healthPolicy must evaluate isHealth to
+// a bool and the customStatus must evaluate message to a string. The kind of the workload must be served by the
+// cluster, see ValidateWorkloadKind.
+// The unknown keys of the attributes in the header of the template in the form of a definition file, e.g. a
+// misspelled woorkload, are returned as warnings.
+func ValidateComponentDefinition(ctx context.Context, cli client.Client, cd *v1beta1.ComponentDefinition) ([]string, error) {
+	var warnings []string
+	if cd.Spec.Schematic != nil && cd.Spec.Schematic.CUE != nil {
+		if !isCueTemplateValidated(ctx) {
+			if err := ValidateCuexTemplate(WithDefinitionKind(ctx, v1beta1.ComponentDefinitionKind), cd.Spec.Schematic.CUE.Template); err != nil {
+				return nil, err
+			}
+		}
+		if err := validateOutputObject(ctx, cd.Spec.Schematic.CUE.Template); err != nil {
+			return nil, err
+		}
+		for _, w := range unknownAttributesOf(cd.Spec.Schematic.CUE.Template) {
+			warnings = append(warnings, w.Error())
+		}
+	}
+	if err := ValidateWorkloadKind(ctx, cli, cd); err != nil {
+		return warnings, err
+	}
+	return warnings, validateStatus(cd.Spec.Status)
+}
+
+// validateOutputObject unifies the output of the template evaluated with the context filled by concreteContextStub
+// against outputObjectSchema, and returns ErrOutputNotObject with the fields of apiVersion and kind that are not concrete strings.
+// The template without an output, e.g. the one only outputting under a condition on the parameter, and the output
+// not declared by struct literals, e.g. `output: parameter`, which is only known at runtime, are not checked.
+func validateOutputObject(ctx context.Context, cueTemplate string) error {
+	output, ok := evaluatedOutputOf(ctx, cueTemplate)
+	if !ok {
+		return nil
+	}
+	output = output.Unify(output.Context().CompileString(outputObjectSchema))
+	var missing []string
+	for _, field := range []string{"apiVersion", "kind"} {
+		if s, err := output.LookupPath(cue.ParsePath(field)).String(); err != nil || s == "" {
+			missing = append(missing, field)
+		}
+	}
+	if len(missing) != 0 {
+		return fmt.Errorf("%w: %s", ErrOutputNotObject, strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+// evaluatedOutputOf returns the output of the template evaluated with the context filled by concreteContextStub,
+// false if the template has no output, or the output is not declared by struct literals or can't be evaluated
+func evaluatedOutputOf(ctx context.Context, cueTemplate string) (cue.Value, bool) {
+	if !isOutputDeclaredByLiterals(cueTemplate) {
+		return cue.Value{}, false
+	}
+	val, err := cuex.DefaultCompiler.Get().CompileStringWithOptions(ctx, cueTemplate+contextStub, cuex.DisableResolveProviderFunctions{})
+	if err != nil || val.Err() != nil {
+		// the template has been validated, the error only comes from the context stub
+		return cue.Value{}, false
+	}
+	val = val.FillPath(cue.ParsePath(model.ContextFieldName), val.Context().CompileString(concreteContextStub))
+	output := val.LookupPath(cue.ParsePath(model.OutputFieldName))
+	return output, output.Exists() && output.Err() == nil
+}
+
+// ValidateWorkloadKind checks with the RESTMapper of the client that the kind of the workload is served by the
+// cluster, otherwise the component can never be applied. The workload is taken from spec.workload.definition, or
+// spec.workload.type if it names a resource like deployments.apps, or else the concrete apiVersion and kind of the
+// output of the template. The check is skipped without a client or a RESTMapper, e.g. offline, and when the mapper
+// fails for any other reason than the kind not being found, e.g. the discovery being unavailable.
+func ValidateWorkloadKind(ctx context.Context, cli client.Client, cd *v1beta1.ComponentDefinition) error {
+	if cli == nil || cli.RESTMapper() == nil {
+		return nil
+	}
+	mapper := cli.RESTMapper()
+	workload := cd.Spec.Workload
+	var err error
+	var kind string
+	switch {
+	case workload.Definition.APIVersion != "" && workload.Definition.Kind != "":
+		gvk := schema.FromAPIVersionAndKind(workload.Definition.APIVersion, workload.Definition.Kind)
+		kind = fmt.Sprintf("%s of %s", gvk.Kind, workload.Definition.APIVersion)
+		_, err = mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+	case strings.Contains(workload.Type, ".") && workload.Type != types.AutoDetectWorkloadDefinition:
+		kind = workload.Type
+		_, err = mapper.KindFor(schema.ParseGroupResource(workload.Type).WithVersion(""))
+	case cd.Spec.Schematic != nil && cd.Spec.Schematic.CUE != nil:
+		output, ok := evaluatedOutputOf(ctx, cd.Spec.Schematic.CUE.Template)
+		if !ok {
+			return nil
+		}
+		apiVersion, avErr := output.LookupPath(cue.ParsePath("apiVersion")).String()
+		outputKind, kErr := output.LookupPath(cue.ParsePath("kind")).String()
+		if avErr != nil || kErr != nil || apiVersion == "" || outputKind == "" {
+			return nil
+		}
+		gvk := schema.FromAPIVersionAndKind(apiVersion, outputKind)
+		kind = fmt.Sprintf("%s of %s", outputKind, apiVersion)
+		_, err = mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+	default:
+		return nil
+	}
+	if meta.IsNoMatchError(err) {
+		return errors.Errorf("workload kind %s not found in cluster", kind)
+	}
+	return nil
+}
+
+// validateStatus validates the healthPolicy and customStatus snippets of the definition
+func validateStatus(status *common.Status) error {
+	if status == nil {
+		return nil
+	}
+	if status.HealthPolicy != "" {
+		if err := validateStatusField(status.HealthPolicy, definition.HealthCheckPolicy, cue.BoolKind); err != nil {
+			return errors.WithMessage(err, "invalid healthPolicy")
+		}
+	}
+	if status.CustomStatus != "" {
+		if err := validateStatusField(status.CustomStatus, definition.CustomMessage, cue.StringKind); err != nil {
+			return errors.WithMessage(err, "invalid customStatus")
+		}
+	}
+	return nil
+}
+
+// validateStatusField compiles the status snippet and checks that the field evaluates to the kind. As the context
+// is only available at runtime, the incomplete values are allowed, and the field defined in the comprehensions
+// depending on the context is not checked.
+func validateStatusField(snippet, field string, kind cue.Kind) error {
+	val := cuecontext.New().CompileString(snippet + statusStub)
+	if err := val.Validate(); err != nil {
+		return checkError(err)
+	}
+	v := val.LookupPath(cue.ParsePath(field))
+	if !v.Exists() {
+		if !declaresField(snippet, field) {
+			return errors.Errorf("%s is not defined", field)
+		}
+		return nil
+	}
+	if v.Err() != nil {
+		// the field depends on the runtime context
+		return nil
+	}
+	if k := v.IncompleteKind(); k&kind == 0 {
+		return errors.Errorf("%s must be %s %s, got %s", field, indefiniteArticle(kind.String()), kind, k)
+	}
+	return nil
+}
+
+// isOutputDeclaredByLiterals checks whether the output fields at the top level of the template are the struct
+// literals or their unifications
+func isOutputDeclaredByLiterals(cueTemplate string) bool {
+	f, err := parser.ParseFile("-", cueTemplate)
+	if err != nil {
+		return false
+	}
+	for _, decl := range f.Decls {
+		field, ok := decl.(*ast.Field)
+		if !ok {
+			continue
+		}
+		if name, _, err := ast.LabelName(field.Label); err == nil && name == model.OutputFieldName && !isStructLiteral(field.Value) {
+			return false
+		}
+	}
+	return true
+}
+
+// isStructLiteral checks whether the expression is a struct literal or the unification of them
+func isStructLiteral(expr ast.Expr) bool {
+	switch x := expr.(type) {
+	case *ast.StructLit:
+		return true
+	case *ast.ParenExpr:
+		return isStructLiteral(x.X)
+	case *ast.BinaryExpr:
+		return x.Op == token.AND && isStructLiteral(x.X) && isStructLiteral(x.Y)
+	}
+	return false
+}
+
+// declaresField checks whether the field is declared anywhere in the cue snippet
+func declaresField(snippet, field string) bool {
+	f, err := parser.ParseFile("-", snippet)
+	if err != nil {
+		return false
+	}
+	declared := false
+	ast.Walk(f, func(node ast.Node) bool {
+		if x, ok := node.(*ast.Field); ok {
+			if name, _, err := ast.LabelName(x.Label); err == nil && name == field {
+				declared = true
+			}
+		}
+		return !declared
+	}, nil)
+	return declared
+}
@@ -0,0 +1,264 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	apicommon "github.com/oam-dev/kubevela/apis/core.oam.dev/common"
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
+	"github.com/oam-dev/kubevela/apis/types"
+	"github.com/oam-dev/kubevela/pkg/utils/common"
+)
+
+func TestValidateComponentDefinition(t *testing.T) {
+	defer setFakeCuexCompiler()()
+
+	cases := map[string]struct {
+		template string
+		status   *apicommon.Status
+		wantErr  string
+	}{
+		"noStatus": {
+			template: `output: {apiVersion: "v1", kind: "ConfigMap", metadata: name: context.name}`,
+		},
+		"validStatus": {
+			template: `output: {apiVersion: "v1", kind: "ConfigMap", metadata: name: context.name}`,
+			status: &apicommon.Status{
+				HealthPolicy: `isHealth: (context.output.status.readyReplicas > 0) && (context.output.status.readyReplicas == context.output.spec.replicas)`,
+				CustomStatus: `
+ready: {
+	readyReplicas: *0 | int
+} & {
+	if context.output.status.readyReplicas != _|_ {
+		readyReplicas: context.output.status.readyReplicas
+	}
+}
+message: "Ready:\(ready.readyReplicas)/\(context.output.spec.replicas)"`,
+			},
+		},
+		"messageInComprehension": {
+			status: &apicommon.Status{
+				CustomStatus: `
+let igs = context.outputs.ingress.status.loadBalancer.ingress
+if igs == _|_ {
+	message: "No loadBalancer found"
+}`,
+			},
+		},
+		"healthPolicyNotBool": {
+			status:  &apicommon.Status{HealthPolicy: `isHealth: "yes"`},
+			wantErr: "invalid healthPolicy: isHealth must be a bool, got string",
+		},
+		"healthPolicyMissingIsHealth": {
+			status:  &apicommon.Status{HealthPolicy: `healthy: context.output.status.ready`},
+			wantErr: "invalid healthPolicy: isHealth is not defined",
+		},
+		"customStatusNotString": {
+			status:  &apicommon.Status{CustomStatus: `message: 1`},
+			wantErr: "invalid customStatus: message must be a string, got int",
+		},
+		"customStatusInvalidReference": {
+			status:  &apicommon.Status{CustomStatus: `message: status.phase`},
+			wantErr: "invalid customStatus: message: reference \"status\" not found",
+		},
+		"outputWithoutKind": {
+			template: `output: {apiVersion: "v1", metadata: name: context.name}`,
+			wantErr:  "output is missing concrete apiVersion/kind: kind",
+		},
+		"outputWithoutApiVersionAndKind": {
+			template: `output: metadata: name: context.name`,
+			wantErr:  "output is missing concrete apiVersion/kind: apiVersion, kind",
+		},
+		"outputKindNotConcrete": {
+			template: `
+parameter: kind: string
+output: {apiVersion: "v1", kind: parameter.kind}`,
+			wantErr: "output is missing concrete apiVersion/kind: kind",
+		},
+		"outputFromContext": {
+			template: `
+output: {
+	if context.clusterVersion.minor < 19 {
+		apiVersion: "networking.k8s.io/v1beta1"
+	}
+	if context.clusterVersion.minor >= 19 {
+		apiVersion: "networking.k8s.io/v1"
+	}
+	kind: "Ingress"
+}`,
+		},
+		"outputFromParameter": {
+			template: `
+output: parameter
+parameter: {...}`,
+		},
+		"noOutput": {
+			template: `outputs: service: {apiVersion: "v1", kind: "Service"}`,
+		},
+		"invalidTemplate": {
+			template: `output: hello: world`,
+			status:   &apicommon.Status{HealthPolicy: `isHealth: true`},
+			wantErr:  "output.hello: reference \"world\" not found",
+		},
+	}
+
+	for caseName, cs := range cases {
+		t.Run(caseName, func(t *testing.T) {
+			cd := &v1beta1.ComponentDefinition{
+				ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "default"},
+				Spec:       v1beta1.ComponentDefinitionSpec{Status: cs.status},
+			}
+			if cs.template != "" {
+				cd.Spec.Schematic = &apicommon.Schematic{CUE: &apicommon.CUE{Template: cs.template}}
+			}
+			_, err := ValidateComponentDefinition(context.Background(), nil, cd)
+			if cs.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.EqualError(t, err, cs.wantErr)
+		})
+	}
+}
+
+// newWorkloadRESTMapper returns the RESTMapper serving the Deployments and the ConfigMaps, the RESTMapper of the
+// fake client serves nothing by default
+func newWorkloadRESTMapper() meta.RESTMapper {
+	mapper := meta.NewDefaultRESTMapper(nil)
+	mapper.Add(schema.GroupVersionKind{Group: "apps", Version: "v1", Kind: "Deployment"}, meta.RESTScopeNamespace)
+	mapper.Add(schema.GroupVersionKind{Version: "v1", Kind: "ConfigMap"}, meta.RESTScopeNamespace)
+	return mapper
+}
+
+func TestValidateWorkloadKind(t *testing.T) {
+	defer setFakeCuexCompiler()()
+	cli := fake.NewClientBuilder().WithScheme(common.Scheme).WithRESTMapper(newWorkloadRESTMapper()).Build()
+
+	cases := map[string]struct {
+		workload apicommon.WorkloadTypeDescriptor
+		template string
+		wantErr  string
+	}{
+		"servedType": {
+			workload: apicommon.WorkloadTypeDescriptor{Type: "deployments.apps"},
+		},
+		"unservedType": {
+			workload: apicommon.WorkloadTypeDescriptor{Type: "cronjobs.batch"},
+			wantErr:  "workload kind cronjobs.batch not found in cluster",
+		},
+		"workloadDefinitionName": {
+			workload: apicommon.WorkloadTypeDescriptor{Type: "worker"},
+		},
+		"autodetect": {
+			workload: apicommon.WorkloadTypeDescriptor{Type: types.AutoDetectWorkloadDefinition},
+			template: `output: {apiVersion: "v1", kind: "ConfigMap"}`,
+		},
+		"unservedDefinition": {
+			workload: apicommon.WorkloadTypeDescriptor{Definition: apicommon.WorkloadGVK{APIVersion: "apps/v1beta1", Kind: "Deployment"}},
+			wantErr:  "workload kind Deployment of apps/v1beta1 not found in cluster",
+		},
+		"unservedOutput": {
+			template: `output: {apiVersion: "example.com/v1", kind: "Widget"}`,
+			wantErr:  "workload kind Widget of example.com/v1 not found in cluster",
+		},
+		"outputFromParameter": {
+			template: `
+output: parameter
+parameter: {...}`,
+		},
+	}
+
+	for caseName, cs := range cases {
+		t.Run(caseName, func(t *testing.T) {
+			cd := &v1beta1.ComponentDefinition{
+				ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "default"},
+				Spec:       v1beta1.ComponentDefinitionSpec{Workload: cs.workload},
+			}
+			if cs.template != "" {
+				cd.Spec.Schematic = &apicommon.Schematic{CUE: &apicommon.CUE{Template: cs.template}}
+			}
+			_, err := ValidateComponentDefinition(context.Background(), cli, cd)
+			if cs.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.EqualError(t, err, cs.wantErr)
+		})
+	}
+}
+
+func TestValidateComponentDefinitionAttributes(t *testing.T) {
+	defer setFakeCuexCompiler()()
+
+	cases := map[string]struct {
+		template     string
+		wantWarnings []string
+	}{
+		"knownAttributes": {
+			template: `
+configmap: {
+	type: "component"
+	attributes: {
+		workload: type: "autodetects.core.oam.dev"
+		status: healthPolicy: "isHealth: true"
+	}
+}
+template: output: {apiVersion: "v1", kind: "ConfigMap"}`,
+		},
+		"misspelledAttributes": {
+			template: `
+configmap: {
+	type: "component"
+	attributes: {
+		woorkload: type: "autodetects.core.oam.dev"
+		podSpecPath: "spec.template.spec"
+		unrelated: true
+	}
+}
+template: output: {apiVersion: "v1", kind: "ConfigMap"}`,
+			wantWarnings: []string{
+				"line 5: attribute woorkload of component definition configmap is unknown and ignored, did you mean workload?",
+				"line 7: attribute unrelated of component definition configmap is unknown and ignored",
+			},
+		},
+		"inlineTemplate": {
+			template: `output: {apiVersion: "v1", kind: "ConfigMap"}`,
+		},
+	}
+
+	for caseName, cs := range cases {
+		t.Run(caseName, func(t *testing.T) {
+			cd := &v1beta1.ComponentDefinition{
+				ObjectMeta: metav1.ObjectMeta{Name: "configmap", Namespace: "default"},
+				Spec: v1beta1.ComponentDefinitionSpec{
+					Schematic: &apicommon.Schematic{CUE: &apicommon.CUE{Template: cs.template}},
+				},
+			}
+			warnings, err := ValidateComponentDefinition(context.Background(), nil, cd)
+			assert.NoError(t, err)
+			assert.Equal(t, cs.wantWarnings, warnings)
+		})
+	}
+}
@@ -0,0 +1,84 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"fmt"
+	"regexp"
+
+	"cuelang.org/go/cue"
+	cueErrors "cuelang.org/go/cue/errors"
+	"github.com/kubevela/workflow/pkg/cue/model"
+)
+
+// concreteFields are the fields of the template rendered to the resources, which must be concrete at runtime
+var concreteFields = []string{model.OutputFieldName, model.OutputsFieldName}
+
+// concreteContextStub fills the context fields known before the rendering with sample values, so that the
+// expressions using them are evaluated concretely. The output and outputs of the context are left incomplete.
+const concreteContextStub = `{
+	name:           "validation"
+	namespace:      "default"
+	appName:        "validation"
+	appRevision:    "validation-v1"
+	appRevisionNum: 1
+	revision:       "validation-v1"
+	appLabels:      {}
+	appAnnotations: {}
+	cluster:        "local"
+	clusterVersion: {major: "1", minor: 30, gitVersion: "v1.30.0", platform: ""}
+	publishVersion: "1"
+}`
+
+// incompleteErrorRegex matches the errors of the values left incomplete by the parameter and the context without
+// values before the rendering, e.g. the parameters without defaults, the optional parameters in the guards, the
+// open lists and the results of the providers, and the summaries of the disjunction errors reported one by one
+var incompleteErrorRegex = regexp.MustCompile(`incomplete value|non-concrete value|is incomplete\)|undefined field|cannot reference optional field|index out of range|errors in empty disjunction`)
+
+// validateConcreteOutputsOf evaluates the output and outputs of the template concretely with the context filled by
+// concreteContextStub, as the rendering does, and returns the errors that only appear under the concrete evaluation,
+// e.g. the arithmetic on context.name. The errors are marked as Concrete and the returned error wraps
+// ErrConcreteEvaluation, which distinguishes them from the structural errors of the template.
+// The values depending on what is only known at runtime are incomplete rather than invalid, so they're not reported.
+func validateConcreteOutputsOf(val cue.Value) ([]CueValidationError, error) {
+	if val.Err() != nil {
+		// the template has been validated, the error only comes from the context stub
+		return nil, nil
+	}
+	val = val.FillPath(cue.ParsePath(model.ContextFieldName), val.Context().CompileString(concreteContextStub))
+	var errs []CueValidationError
+	for _, field := range concreteFields {
+		v := val.LookupPath(cue.ParsePath(field))
+		if !v.Exists() {
+			continue
+		}
+		for _, e := range cueErrors.Errors(v.Validate(cue.Concrete(true))) {
+			if !incompleteErrorRegex.MatchString(e.Error()) {
+				errs = append(errs, collectCueValidationErrors(e)...)
+			}
+		}
+	}
+	if len(errs) == 0 {
+		return nil, nil
+	}
+	msgs := make([]error, 0, len(errs))
+	for i := range errs {
+		errs[i].Concrete = true
+		msgs = append(msgs, cueErrors.New(errs[i].Message))
+	}
+	return errs, fmt.Errorf("%w: %v", ErrConcreteEvaluation, aggregateErrors(msgs))
+}
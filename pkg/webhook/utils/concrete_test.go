@@ -0,0 +1,93 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateConcreteOutputs(t *testing.T) {
+	cases := map[string]struct {
+		cueTemplate string
+		want        []CueValidationError
+		wantErr     string
+	}{
+		"concrete": {
+			cueTemplate: `
+parameter: replicas: *1 | int
+output: {
+	metadata: name: context.name + "-worker"
+	spec: replicas: parameter.replicas
+	if context.clusterVersion.minor < 25 {
+		apiVersion: "batch/v1beta1"
+	}
+}`,
+		},
+		"incompleteParameters": {
+			cueTemplate: `
+parameter: {
+	image: string
+	port?: int
+	env: [...{name: string}]
+}
+output: spec: {
+	image: parameter.image
+	if parameter.port != _|_ {
+		port: parameter.port
+	}
+	first: parameter.env[0].name
+	owner: context.output.metadata.name
+}`,
+		},
+		"contextTypeMismatch": {
+			cueTemplate: `
+parameter: {}
+output: metadata: name: context.name + 1`,
+			want: []CueValidationError{{
+				Message:  "output.metadata.name: invalid operands \"validation\" and 1 to '+' (type string and int)",
+				Line:     3,
+				Column:   25,
+				Concrete: true,
+			}},
+			wantErr: "the output fails under concrete evaluation: output.metadata.name: invalid operands \"validation\" and 1 to '+' (type string and int)",
+		},
+		"contextInOutputs": {
+			cueTemplate: `
+parameter: {}
+outputs: service: spec: ports: [{port: context.appRevisionNum & string}]`,
+			wantErr: "the output fails under concrete evaluation: outputs.service.spec.ports.0.port: conflicting values 1 and string (mismatched types int and string)",
+		},
+	}
+	for caseName, cs := range cases {
+		t.Run(caseName, func(t *testing.T) {
+			errs, err := ValidateCueTemplateDetailed(cs.cueTemplate)
+			if cs.wantErr == "" {
+				assert.NoError(t, err)
+				assert.Empty(t, errs)
+				return
+			}
+			assert.ErrorIs(t, err, ErrConcreteEvaluation)
+			assert.EqualError(t, err, cs.wantErr)
+			assert.Equal(t, CategoryType, ErrorCategory(err))
+			if cs.want != nil {
+				assert.Equal(t, cs.want, errs)
+			}
+		})
+	}
+}
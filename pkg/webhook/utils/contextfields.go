@@ -0,0 +1,164 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+
+	"cuelang.org/go/cue/ast"
+	"cuelang.org/go/cue/parser"
+	"cuelang.org/go/cue/token"
+	"github.com/kubevela/workflow/pkg/cue/model"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
+	"github.com/oam-dev/kubevela/pkg/cue/process"
+)
+
+// ContextSchema is the context fields injected into the cue templates of a definition kind, and who injects them
+type ContextSchema struct {
+	// Fields are the names of the context fields, e.g. appName
+	Fields []string
+	// Provider describes who injects the fields in the messages of the unknown ones, e.g. the workflow runtime
+	Provider string
+}
+
+// renderContextFields are the context fields provided by the application rendering to the templates of the
+// components, the traits and the policies
+var renderContextFields = []string{
+	process.ContextName,
+	process.ContextNamespace,
+	process.ContextAppName,
+	process.ContextAppRevision,
+	process.ContextAppRevisionNum,
+	process.ContextAppLabels,
+	process.ContextAppAnnotations,
+	process.ContextCompRevisionName,
+	process.ContextComponents,
+	process.ContextReplicaKey,
+	process.ContextCluster,
+	process.ContextClusterVersion,
+	process.ContextPublishVersion,
+	process.ContextWorkflowName,
+	process.ParameterFieldName,
+	process.OutputFieldName,
+	process.OutputsFieldName,
+	process.OutputSecretName,
+}
+
+// DefinitionContextSchemas are the context schemas of the definition kinds, keyed by the kind, e.g.
+// ComponentDefinition. The components get their output and outputs, the traits get the type of the component they
+// are attached to as well, and the workflow steps get the step session rather than the rendered resources. The
+// definitions of the kinds missing here are not checked.
+var DefinitionContextSchemas = map[string]ContextSchema{
+	v1beta1.ComponentDefinitionKind: {
+		Fields:   renderContextFields,
+		Provider: "the component rendering",
+	},
+	v1beta1.TraitDefinitionKind: {
+		Fields:   append(append([]string{}, renderContextFields...), process.ContextComponentType),
+		Provider: "the trait rendering",
+	},
+	v1beta1.PolicyDefinitionKind: {
+		Fields:   append(append([]string{}, renderContextFields...), process.ContextDataArtifacts),
+		Provider: "the policy rendering",
+	},
+	v1beta1.WorkflowStepDefinitionKind: {
+		Fields:   WorkflowStepContextFields,
+		Provider: "the workflow runtime",
+	},
+}
+
+// ValidateContextFields reports the context fields referenced by the cue template of the definition but missing in
+// the context schema of its kind, chosen by the GVK of the definition from DefinitionContextSchemas, which are likely
+// typos or fields only injected into the other kinds, e.g. context.stepSessionID in a ComponentDefinition. The
+// fields looked up by index, e.g. context["config"], are guarded by the template and not reported.
+// The findings are returned as warnings, CheckUnknownContextFields sets their severity in ValidateDefinition.
+func ValidateContextFields(def runtime.Object) (*ValidationResult, error) {
+	result := &ValidationResult{}
+	_, schematic, err := definitionSchematicOf(def)
+	if err != nil {
+		return result, err
+	}
+	if schematic == nil || schematic.CUE == nil {
+		return result, nil
+	}
+	kind := definitionKind(def)
+	schema, ok := DefinitionContextSchemas[kind]
+	if !ok {
+		return result, nil
+	}
+	var name string
+	if obj, ok := def.(metav1.Object); ok {
+		name = obj.GetName()
+	}
+	for _, field := range unknownContextFields(schematic.CUE.Template, schema.Fields) {
+		result.Warnings = append(result.Warnings, CueValidationError{
+			Message: fmt.Sprintf("%s %s references context.%s which is not provided by %s", kind, name, field, schema.Provider),
+		})
+	}
+	return result, nil
+}
+
+// unknownContextFields returns the sorted context fields referenced by the cue template but neither in the known
+// fields nor looked up by index
+func unknownContextFields(cueTemplate string, knownFields []string) []string {
+	f, err := parser.ParseFile("-", cueTemplate)
+	if err != nil {
+		return nil
+	}
+	known := map[string]bool{}
+	for _, field := range knownFields {
+		known[field] = true
+	}
+	unknown := map[string]bool{}
+	ast.Walk(f, func(node ast.Node) bool {
+		switch n := node.(type) {
+		case *ast.SelectorExpr:
+			if isContextIdent(n.X) {
+				if name, _, err := ast.LabelName(n.Sel); err == nil && !known[name] {
+					unknown[name] = true
+				}
+			}
+		case *ast.IndexExpr:
+			// context["config"] != _|_ guards the optional field
+			if lit, ok := n.Index.(*ast.BasicLit); ok && lit.Kind == token.STRING && isContextIdent(n.X) {
+				if name, err := strconv.Unquote(lit.Value); err == nil {
+					known[name] = true
+				}
+			}
+		}
+		return true
+	}, nil)
+	fields := make([]string, 0, len(unknown))
+	for field := range unknown {
+		if !known[field] {
+			fields = append(fields, field)
+		}
+	}
+	sort.Strings(fields)
+	return fields
+}
+
+// isContextIdent checks whether the expression is the context identifier
+func isContextIdent(expr ast.Expr) bool {
+	x, ok := expr.(*ast.Ident)
+	return ok && x.Name == model.ContextFieldName
+}
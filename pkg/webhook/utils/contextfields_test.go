@@ -0,0 +1,140 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	apicommon "github.com/oam-dev/kubevela/apis/core.oam.dev/common"
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
+)
+
+func TestValidateContextFields(t *testing.T) {
+	schematic := func(template string) *apicommon.Schematic {
+		return &apicommon.Schematic{CUE: &apicommon.CUE{Template: template}}
+	}
+	componentDef := func(template string) *v1beta1.ComponentDefinition {
+		return &v1beta1.ComponentDefinition{
+			ObjectMeta: metav1.ObjectMeta{Name: "worker"},
+			Spec:       v1beta1.ComponentDefinitionSpec{Schematic: schematic(template)},
+		}
+	}
+	policyDef := &v1beta1.PolicyDefinition{
+		ObjectMeta: metav1.ObjectMeta{Name: "topology"},
+		Spec: v1beta1.PolicyDefinitionSpec{Schematic: schematic(`
+output: {
+	clusters: [context.cluster]
+	resources: context.artifacts
+}`)},
+	}
+	stepDef := &v1beta1.WorkflowStepDefinition{
+		ObjectMeta: metav1.ObjectMeta{Name: "notify"},
+		Spec: v1beta1.WorkflowStepDefinitionSpec{Schematic: schematic(`
+data: {
+	id:   context.stepSessionID
+	spec: context.output.spec
+}`)},
+	}
+	unstructuredDef := &unstructured.Unstructured{}
+	unstructuredDef.SetAPIVersion(v1beta1.SchemeGroupVersion.String())
+	unstructuredDef.SetKind(v1beta1.ComponentDefinitionKind)
+
+	cases := map[string]struct {
+		def          runtime.Object
+		wantWarnings []string
+		wantErr      string
+	}{
+		"componentFields": {
+			def: componentDef(`
+output: {
+	metadata: name: context.name
+	metadata: labels: "app.oam.dev/revision": context.revision
+	spec: replicas: context.output.spec.replicas
+}`),
+		},
+		"stepFieldsInComponent": {
+			def: componentDef(`
+output: metadata: annotations: {
+	session: context.stepSessionID
+	step:    context.stepName
+}`),
+			wantWarnings: []string{
+				"ComponentDefinition worker references context.stepName which is not provided by the component rendering",
+				"ComponentDefinition worker references context.stepSessionID which is not provided by the component rendering",
+			},
+		},
+		"guardedField": {
+			def: componentDef(`
+output: spec: {
+	if context["config"] != _|_ {
+		env: context.config
+	}
+}`),
+		},
+		"componentTypeInTrait": {
+			def: newTraitDefinition("labels", "", `
+patch: metadata: labels: {
+	type:   context.componentType
+	output: context.outputs.service.metadata.name
+}`),
+		},
+		"componentTypeInComponent": {
+			def: componentDef(`output: metadata: labels: type: context.componentType`),
+			wantWarnings: []string{
+				"ComponentDefinition worker references context.componentType which is not provided by the component rendering",
+			},
+		},
+		"artifactsInPolicy": {
+			def: policyDef,
+		},
+		"outputInStep": {
+			def: stepDef,
+			wantWarnings: []string{
+				"WorkflowStepDefinition notify references context.output which is not provided by the workflow runtime",
+			},
+		},
+		"noSchematic": {
+			def: &v1beta1.TraitDefinition{ObjectMeta: metav1.ObjectMeta{Name: "labels"}},
+		},
+		"unsupportedDefinition": {
+			def:     unstructuredDef,
+			wantErr: "unsupported definition type *unstructured.Unstructured",
+		},
+	}
+
+	for caseName, cs := range cases {
+		t.Run(caseName, func(t *testing.T) {
+			result, err := ValidateContextFields(cs.def)
+			if cs.wantErr != "" {
+				assert.EqualError(t, err, cs.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Empty(t, result.Errors)
+			var warnings []string
+			for _, w := range result.Warnings {
+				warnings = append(warnings, w.Error())
+			}
+			assert.Equal(t, cs.wantWarnings, warnings)
+		})
+	}
+}
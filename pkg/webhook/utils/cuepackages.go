@@ -0,0 +1,155 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strings"
+
+	"cuelang.org/go/cue/parser"
+	"github.com/Masterminds/semver/v3"
+	"github.com/kubevela/pkg/cue/cuex"
+	cuexruntime "github.com/kubevela/pkg/cue/cuex/runtime"
+
+	"github.com/oam-dev/kubevela/version"
+)
+
+// The sources of the CueX packages
+const (
+	// CuePackageSourceInternal is the package built in KubeVela, e.g. vela/kube
+	CuePackageSourceInternal = "internal"
+	// CuePackageSourceExternal is the package loaded from a Package of cue.oam.dev
+	CuePackageSourceExternal = "external"
+)
+
+// CuePackageMinVersions are the minimum versions of the CueX packages by their import paths, e.g. vela/kube: v1.9.0,
+// the templates importing an older package are rejected by ValidateCuexTemplate. The packages not listed are not
+// constrained.
+var CuePackageMinVersions = map[string]string{}
+
+// ImportedCuePackage is the CueX package resolved for an import of the cue template
+type ImportedCuePackage struct {
+	// Path is the import path, e.g. vela/kube
+	Path string `json:"path"`
+	// Name is the name of the package, i.e. the name of the Package for the external packages
+	Name string `json:"name"`
+	// Source is CuePackageSourceInternal or CuePackageSourceExternal
+	Source string `json:"source"`
+	// Version is the version of KubeVela for the internal packages, and empty for the external packages which carry
+	// no version
+	Version string `json:"version,omitempty"`
+	// Digest identifies the templates of the package, so that the changes of the external packages are detected
+	Digest string `json:"digest"`
+}
+
+// ResolveCuexImports returns the CueX packages loaded by the default compiler for the imports of the cue template in
+// the order of the imports, the imports of the CUE standard library are skipped. An error is returned for the import
+// not provided by any package, or the package older than its minimum in CuePackageMinVersions.
+func ResolveCuexImports(cueTemplate string) ([]ImportedCuePackage, error) {
+	packages, missing := resolveCuexImports(cueTemplate, cuex.DefaultCompiler.Get())
+	var errs []error
+	for _, path := range missing {
+		errs = append(errs, fmt.Errorf("imported package %s is not loaded by the CueX compiler", path))
+	}
+	errs = append(errs, checkCuePackageMinVersions(packages)...)
+	return packages, aggregateErrors(errs)
+}
+
+// resolveCuexImports returns the packages of the compiler for the imports of the cue template, and the import paths
+// provided by none of them
+func resolveCuexImports(cueTemplate string, compiler *cuex.Compiler) ([]ImportedCuePackage, []string) {
+	f, err := parser.ParseFile("-", cueTemplate, parser.ImportsOnly)
+	if err != nil {
+		// leave the syntax error to the compiler
+		return nil, nil
+	}
+	var packages []ImportedCuePackage
+	var missing []string
+	for _, path := range importPathsOf(f) {
+		if root, _, _ := strings.Cut(path, "/"); cueStdlibRoots[root] {
+			continue
+		}
+		if pkg, ok := cuePackageOf(compiler, path); ok {
+			packages = append(packages, pkg)
+			continue
+		}
+		missing = append(missing, path)
+	}
+	return packages, missing
+}
+
+// cuePackageOf returns the package of the import path in the compiler. An external package is preferred over the
+// internal one of the same path, and the one with the smallest name among the packages of the same source.
+func cuePackageOf(compiler *cuex.Compiler, path string) (ImportedCuePackage, bool) {
+	var found ImportedCuePackage
+	ok := false
+	match := func(source string) func(_ string, pkg cuexruntime.Package) {
+		return func(_ string, pkg cuexruntime.Package) {
+			if pkg.GetPath() != path || ok && found.Source == source && found.Name < pkg.GetName() {
+				return
+			}
+			found, ok = ImportedCuePackage{Path: path, Name: pkg.GetName(), Source: source, Digest: cuePackageDigestOf(pkg)}, true
+			if source == CuePackageSourceInternal {
+				found.Version = version.VelaVersion
+			}
+		}
+	}
+	compiler.Internals.Range(match(CuePackageSourceInternal))
+	compiler.Externals.Range(match(CuePackageSourceExternal))
+	return found, ok
+}
+
+// cuePackageDigestOf returns the sha256 of the sorted templates of the package
+func cuePackageDigestOf(pkg cuexruntime.Package) string {
+	templates := append([]string{}, pkg.GetTemplates()...)
+	sort.Strings(templates)
+	h := sha256.New()
+	for _, template := range templates {
+		h.Write([]byte(template))
+		h.Write([]byte{0})
+	}
+	return "sha256:" + hex.EncodeToString(h.Sum(nil))
+}
+
+// checkCuePackageMinVersions returns ErrPackageTooOld for each package older than its minimum in
+// CuePackageMinVersions, the package without a valid version, e.g. an external package, never satisfies a minimum
+func checkCuePackageMinVersions(packages []ImportedCuePackage) []error {
+	var errs []error
+	for _, pkg := range packages {
+		minVersion, ok := CuePackageMinVersions[pkg.Path]
+		if !ok {
+			continue
+		}
+		required, err := semver.NewVersion(minVersion)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("invalid minimum version %q of package %s: %w", minVersion, pkg.Path, err))
+			continue
+		}
+		if current, err := semver.NewVersion(pkg.Version); err == nil && !current.LessThan(required) {
+			continue
+		}
+		resolved := pkg.Version
+		if resolved == "" {
+			resolved = "unknown"
+		}
+		errs = append(errs, fmt.Errorf("imported %s %w %s, the resolved version is %s", pkg.Path, ErrPackageTooOld, minVersion, resolved))
+	}
+	return errs
+}
@@ -0,0 +1,119 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/oam-dev/kubevela/version"
+)
+
+func TestResolveCuexImports(t *testing.T) {
+	defer setFakeCuexCompiler(newTestPackage("ext", "test/ext", "package ext\nx: 1"))()
+	defer func(v string) { version.VelaVersion = v }(version.VelaVersion)
+	version.VelaVersion = "v1.10.1"
+
+	cases := map[string]struct {
+		cueTemplate string
+		minVersions map[string]string
+		wantPaths   []string
+		wantSources []string
+		wantErr     string
+	}{
+		"internalAndExternal": {
+			cueTemplate: "import (\n\t\"strings\"\n\t\"vela/base64\"\n\t\"test/ext\"\n)\noutput: {}",
+			wantPaths:   []string{"vela/base64", "test/ext"},
+			wantSources: []string{CuePackageSourceInternal, CuePackageSourceExternal},
+		},
+		"satisfied": {
+			cueTemplate: "import \"vela/base64\"\noutput: {}",
+			minVersions: map[string]string{"vela/base64": "v1.10.0"},
+			wantPaths:   []string{"vela/base64"},
+			wantSources: []string{CuePackageSourceInternal},
+		},
+		"tooOld": {
+			cueTemplate: "import \"vela/base64\"\noutput: {}",
+			minVersions: map[string]string{"vela/base64": "v1.11.0"},
+			wantPaths:   []string{"vela/base64"},
+			wantSources: []string{CuePackageSourceInternal},
+			wantErr:     "imported vela/base64 older than required v1.11.0, the resolved version is v1.10.1",
+		},
+		"externalWithoutVersion": {
+			cueTemplate: "import \"test/ext\"\noutput: {}",
+			minVersions: map[string]string{"test/ext": "v1.0.0"},
+			wantPaths:   []string{"test/ext"},
+			wantSources: []string{CuePackageSourceExternal},
+			wantErr:     "imported test/ext older than required v1.0.0, the resolved version is unknown",
+		},
+		"invalidMinVersion": {
+			cueTemplate: "import \"vela/base64\"\noutput: {}",
+			minVersions: map[string]string{"vela/base64": "latest"},
+			wantPaths:   []string{"vela/base64"},
+			wantSources: []string{CuePackageSourceInternal},
+			wantErr:     "invalid minimum version \"latest\" of package vela/base64",
+		},
+		"missing": {
+			cueTemplate: "import \"test/missing\"\noutput: {}",
+			wantErr:     "imported package test/missing is not loaded by the CueX compiler",
+		},
+	}
+
+	for caseName, cs := range cases {
+		t.Run(caseName, func(t *testing.T) {
+			defer func(m map[string]string) { CuePackageMinVersions = m }(CuePackageMinVersions)
+			CuePackageMinVersions = cs.minVersions
+			packages, err := ResolveCuexImports(cs.cueTemplate)
+			var paths, sources []string
+			for _, pkg := range packages {
+				paths = append(paths, pkg.Path)
+				sources = append(sources, pkg.Source)
+				assert.Contains(t, pkg.Digest, "sha256:")
+				if pkg.Source == CuePackageSourceInternal {
+					assert.Equal(t, "v1.10.1", pkg.Version)
+				}
+			}
+			assert.Equal(t, cs.wantPaths, paths)
+			assert.Equal(t, cs.wantSources, sources)
+			if cs.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorContains(t, err, cs.wantErr)
+		})
+	}
+}
+
+func TestValidateCuexTemplatePackageMinVersions(t *testing.T) {
+	defer setFakeCuexCompiler()()
+	defer func(v string) { version.VelaVersion = v }(version.VelaVersion)
+	defer func(m map[string]string) { CuePackageMinVersions = m }(CuePackageMinVersions)
+	version.VelaVersion = "v1.10.1"
+	cueTemplate := "import \"vela/base64\"\noutput: {}"
+
+	CuePackageMinVersions = map[string]string{"vela/base64": "v1.11.0"}
+	err := ValidateCuexTemplate(context.Background(), cueTemplate)
+	require.Error(t, err)
+	assert.ErrorIs(t, err, ErrPackageTooOld)
+	assert.EqualError(t, err, "imported vela/base64 older than required v1.11.0, the resolved version is v1.10.1")
+
+	CuePackageMinVersions = map[string]string{"vela/base64": "v1.9.0"}
+	assert.NoError(t, ValidateCuexTemplate(context.Background(), cueTemplate))
+}
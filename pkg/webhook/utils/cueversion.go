@@ -0,0 +1,99 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"strings"
+
+	"cuelang.org/go/cue/ast"
+	cueErrors "cuelang.org/go/cue/errors"
+	"cuelang.org/go/cue/parser"
+	"cuelang.org/go/cue/token"
+	"github.com/Masterminds/semver/v3"
+	"github.com/pkg/errors"
+)
+
+// cueLanguageFeature is a construct of the CUE language that is only supported since a CUE version
+type cueLanguageFeature struct {
+	name  string
+	since string
+	used  func(node ast.Node) bool
+}
+
+// cueLanguageFeatures are the constructs introduced after the first CUE version supported by KubeVela
+var cueLanguageFeatures = []cueLanguageFeature{{
+	name:  "required field constraint `!`",
+	since: "v0.6.0",
+	used: func(node ast.Node) bool {
+		field, ok := node.(*ast.Field)
+		return ok && field.Constraint == token.NOT
+	},
+}, {
+	name:  "@embed attribute",
+	since: "v0.10.0",
+	used: func(node ast.Node) bool {
+		attr, ok := node.(*ast.Attribute)
+		return ok && strings.HasPrefix(attr.Text, "@embed(")
+	},
+}, {
+	name:  "builtin matchN",
+	since: "v0.11.0",
+	used:  isBuiltinCall("matchN"),
+}, {
+	name:  "builtin matchIf",
+	since: "v0.11.0",
+	used:  isBuiltinCall("matchIf"),
+}}
+
+func isBuiltinCall(name string) func(node ast.Node) bool {
+	return func(node ast.Node) bool {
+		call, ok := node.(*ast.CallExpr)
+		if !ok {
+			return false
+		}
+		fn, ok := call.Fun.(*ast.Ident)
+		return ok && fn.Name == name
+	}
+}
+
+// ValidateCueTemplateForVersion validates the cueTemplate as ValidateCueTemplate does, and rejects the constructs
+// that are not supported by the target CUE version, e.g. required fields `name!: string` for CUE v0.5.0.
+// Templates are always evaluated by the CUE version embedded in KubeVela, the target version only restricts the syntax.
+func ValidateCueTemplateForVersion(cueTemplate, cueVersion string) error {
+	target, err := semver.NewVersion(cueVersion)
+	if err != nil {
+		return errors.Wrapf(err, "invalid CUE version %q", cueVersion)
+	}
+	f, err := parser.ParseFile("-", cueTemplate, parser.ParseComments)
+	if err != nil {
+		return checkError(err)
+	}
+	var errs []error
+	ast.Walk(f, func(node ast.Node) bool {
+		for _, feature := range cueLanguageFeatures {
+			if feature.used(node) && target.LessThan(semver.MustParse(feature.since)) {
+				errs = append(errs, cueErrors.Newf(node.Pos(), "%s is not supported by CUE %s, it requires CUE %s or later",
+					feature.name, cueVersion, feature.since))
+			}
+		}
+		return true
+	}, nil)
+	if len(errs) != 0 {
+		return aggregateErrors(errs)
+	}
+	return ValidateCueTemplate(cueTemplate)
+}
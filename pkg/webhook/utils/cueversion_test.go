@@ -0,0 +1,84 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateCueTemplateForVersion(t *testing.T) {
+	cases := map[string]struct {
+		cueTemplate string
+		cueVersion  string
+		wantErr     string
+	}{
+		"plainTemplate": {
+			cueTemplate: `
+parameter: image: string
+output: {
+	metadata: name: context.name
+	spec: image: parameter.image
+}`,
+			cueVersion: "v0.4.3",
+		},
+		"requiredFieldSupported": {
+			cueTemplate: `
+parameter: image!: string
+output: spec: image: parameter.image`,
+			cueVersion: "v0.6.0",
+		},
+		"requiredFieldUnsupported": {
+			cueTemplate: `
+parameter: image!: string
+output: spec: image: parameter.image`,
+			cueVersion: "v0.5.0",
+			wantErr:    "required field constraint `!` is not supported by CUE v0.5.0, it requires CUE v0.6.0 or later",
+		},
+		"multipleUnsupportedConstructs": {
+			cueTemplate: `
+parameter: {
+	image!: string
+	port:   matchN(1, [int, string])
+}`,
+			cueVersion: "0.5.0",
+			wantErr: "[required field constraint `!` is not supported by CUE 0.5.0, it requires CUE v0.6.0 or later, " +
+				"builtin matchN is not supported by CUE 0.5.0, it requires CUE v0.11.0 or later]",
+		},
+		"invalidTemplate": {
+			cueTemplate: `output: hello: world`,
+			cueVersion:  "v0.9.2",
+			wantErr:     "output.hello: reference \"world\" not found",
+		},
+		"invalidVersion": {
+			cueTemplate: `output: hello: "world"`,
+			cueVersion:  "latest",
+			wantErr:     "invalid CUE version \"latest\": Invalid Semantic Version",
+		},
+	}
+	for caseName, cs := range cases {
+		t.Run(caseName, func(t *testing.T) {
+			err := ValidateCueTemplateForVersion(cs.cueTemplate, cs.cueVersion)
+			if cs.wantErr == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, cs.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,210 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"cuelang.org/go/cue"
+	"cuelang.org/go/cue/ast"
+	"cuelang.org/go/cue/build"
+	"cuelang.org/go/cue/parser"
+	cuexruntime "github.com/kubevela/pkg/cue/cuex/runtime"
+	cueutil "github.com/kubevela/pkg/cue/util"
+)
+
+const (
+	// providerFnKey and providerKey are the fields of a provider function call resolved by CueX
+	providerFnKey = "#do"
+	providerKey   = "#provider"
+)
+
+// CueImportMaxDepth is the max depth of the imports followed from a cue template through the CueX packages, the
+// deeper imports are rejected with ErrImportTooDeep so that the validation never recurses without bound.
+// The limit is disabled if it's not positive.
+var CueImportMaxDepth = 32
+
+// CycleError is returned when the imports of a cue template form a cycle
+type CycleError struct {
+	// Cycle is the import paths in the cycle, the first one and the last one are the same
+	Cycle []string
+}
+
+// Error implements error interface
+func (e *CycleError) Error() string {
+	return "import cycle detected: " + strings.Join(e.Cycle, " imports ")
+}
+
+// SelfReferenceError is returned when the cue template of a definition imports the package named by the definition
+type SelfReferenceError struct {
+	// Name is the name of the definition
+	Name string
+}
+
+// Error implements error interface
+func (e *SelfReferenceError) Error() string {
+	return fmt.Sprintf("definition %s references itself", e.Name)
+}
+
+type definitionNameCtxKey struct{}
+
+// WithDefinitionName returns the context carrying the name of the definition whose cue template is validated, so
+// that the template importing the definition itself is detected by ValidateCuexTemplate
+func WithDefinitionName(ctx context.Context, name string) context.Context {
+	return context.WithValue(ctx, definitionNameCtxKey{}, name)
+}
+
+// definitionNameOf returns the name of the definition carried by the context, or empty if it's not set
+func definitionNameOf(ctx context.Context) string {
+	name, _ := ctx.Value(definitionNameCtxKey{}).(string)
+	return name
+}
+
+type definitionKindCtxKey struct{}
+
+// WithDefinitionKind returns the context carrying the kind of the definition whose cue template is validated, e.g.
+// TraitDefinition, which labels the metrics of the template validation
+func WithDefinitionKind(ctx context.Context, kind string) context.Context {
+	return context.WithValue(ctx, definitionKindCtxKey{}, kind)
+}
+
+// definitionKindOf returns the kind of the definition carried by the context, or empty if it's not set
+func definitionKindOf(ctx context.Context) string {
+	kind, _ := ctx.Value(definitionKindCtxKey{}).(string)
+	return kind
+}
+
+// checkImportCycles walks the import graph from the imports of the cue template through the packages
+// provided by the compiler, and returns a CycleError if any import cycle is found. If the name of the definition
+// of the template is set and it's not the path of a package of the CUE standard library or the compiler, the
+// template is the package of that import path in the graph, and a SelfReferenceError is returned if the template
+// imports it directly. The imports deeper than CueImportMaxDepth are rejected.
+func checkImportCycles(name, cueTemplate string, packages []*build.Instance) error {
+	f, err := parser.ParseFile("-", cueTemplate, parser.ImportsOnly)
+	if err != nil {
+		// leave the syntax error to the compiler
+		return nil
+	}
+	graph := map[string][]string{}
+	for _, pkg := range packages {
+		for _, file := range pkg.Files {
+			graph[pkg.ImportPath] = append(graph[pkg.ImportPath], importPathsOf(file)...)
+		}
+	}
+	roots, depth := importPathsOf(f), 0
+	if _, provided := graph[name]; name != "" && !provided && !isCueStdlibImport(name) {
+		for _, path := range roots {
+			if path == name {
+				return &SelfReferenceError{Name: name}
+			}
+		}
+		// the template takes the place of the package of the same name
+		graph[name] = roots
+		roots, depth = []string{name}, -1
+	}
+
+	const (
+		visiting = 1
+		visited  = 2
+	)
+	state := map[string]int{}
+	var stack []string
+	var visit func(path string) error
+	visit = func(path string) error {
+		switch state[path] {
+		case visited:
+			return nil
+		case visiting:
+			for i, p := range stack {
+				if p == path {
+					return &CycleError{Cycle: append(append([]string{}, stack[i:]...), path)}
+				}
+			}
+		}
+		if CueImportMaxDepth > 0 && len(stack)+depth >= CueImportMaxDepth {
+			return fmt.Errorf("%w: %s imports %s, the max import depth is %d", ErrImportTooDeep,
+				strings.Join(stack, " imports "), path, CueImportMaxDepth)
+		}
+		state[path] = visiting
+		stack = append(stack, path)
+		for _, next := range graph[path] {
+			if err := visit(next); err != nil {
+				return err
+			}
+		}
+		stack = stack[:len(stack)-1]
+		state[path] = visited
+		return nil
+	}
+	for _, path := range roots {
+		if err := visit(path); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func importPathsOf(f *ast.File) []string {
+	var paths []string
+	for _, spec := range f.Imports {
+		if path, err := strconv.Unquote(spec.Path.Value); err == nil {
+			paths = append(paths, path)
+		}
+	}
+	return paths
+}
+
+// importsCuexPackages reports whether the cue template imports a package out of the CUE standard library, which is
+// only resolved by the CueX compiler. The template that can't be parsed imports nothing.
+func importsCuexPackages(cueTemplate string) bool {
+	f, err := parser.ParseFile("-", cueTemplate, parser.ImportsOnly)
+	if err != nil {
+		return false
+	}
+	for _, path := range importPathsOf(f) {
+		if root, _, _ := strings.Cut(path, "/"); !cueStdlibRoots[root] {
+			return true
+		}
+	}
+	return false
+}
+
+// checkProviderFunctions finds the provider function calls in the value the same way as CueX resolves them,
+// and reports the calls whose provider or function is not registered, so that they fail upfront instead of
+// at runtime
+func checkProviderFunctions(val cue.Value, providers map[string]cuexruntime.Provider) error {
+	var errs []error
+	cueutil.Iterate(val, func(v cue.Value) (stop bool) {
+		fn, _ := v.LookupPath(cue.ParsePath(providerFnKey)).String()
+		if fn == "" {
+			return false
+		}
+		name, _ := v.LookupPath(cue.ParsePath(providerKey)).String()
+		provider, found := providers[name]
+		switch {
+		case !found:
+			errs = append(errs, fmt.Errorf("%s: unknown provider %s", v.Path(), name))
+		case provider.GetProviderFn(fn) == nil:
+			errs = append(errs, fmt.Errorf("%s: unknown function %s of provider %s", v.Path(), fn, name))
+		}
+		return false
+	})
+	return aggregateErrors(errs)
+}
@@ -0,0 +1,242 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"cuelang.org/go/cue/build"
+	"github.com/kubevela/pkg/cue/cuex"
+	"github.com/kubevela/pkg/cue/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func newTestPackage(name, path, template string) *unstructured.Unstructured {
+	return &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"apiVersion": "cue.oam.dev/v1alpha1",
+			"kind":       "Package",
+			"metadata": map[string]interface{}{
+				"name":      name,
+				"namespace": "vela-system",
+			},
+			"spec": map[string]interface{}{
+				"path":      path,
+				"templates": map[string]interface{}{path: template},
+			},
+		},
+	}
+}
+
+func TestCheckImportCycles(t *testing.T) {
+	buildImport := func(path, template string) *build.Instance {
+		bi, err := util.BuildImport(path, map[string]string{path: template})
+		require.NoError(t, err)
+		return bi
+	}
+	packages := []*build.Instance{
+		buildImport("test/a", "package a\nimport \"test/b\"\nx: b.y"),
+		buildImport("test/b", "package b\nimport \"test/c\"\ny: c.z"),
+		buildImport("test/c", "package c\nimport \"test/a\"\nz: a.x"),
+		buildImport("test/d", "package d\nimport \"test/e\"\nw: e.v"),
+		buildImport("test/e", "package e\nv: 1"),
+		buildImport("test/self", "package self\nimport \"test/self\"\nu: self.u"),
+		buildImport("loop", "package loop\nimport \"mydef\"\nt: mydef.output"),
+	}
+	cases := map[string]struct {
+		name        string
+		cueTemplate string
+		want        error
+	}{
+		"noImports": {
+			cueTemplate: `output: name: "test"`,
+		},
+		"acyclicImports": {
+			cueTemplate: "import \"test/d\"\noutput: value: d.w",
+		},
+		"unknownImports": {
+			cueTemplate: "import \"strings\"\noutput: value: strings.ToLower(\"A\")",
+		},
+		"cyclicImports": {
+			cueTemplate: "import \"test/a\"\noutput: value: a.x",
+			want:        &CycleError{Cycle: []string{"test/a", "test/b", "test/c", "test/a"}},
+		},
+		"selfImport": {
+			cueTemplate: "import \"test/self\"\noutput: value: self.u",
+			want:        &CycleError{Cycle: []string{"test/self", "test/self"}},
+		},
+		"namedAcyclicImports": {
+			name:        "mydef",
+			cueTemplate: "import \"test/d\"\noutput: value: d.w",
+		},
+		"namedCyclicImports": {
+			name:        "mydef",
+			cueTemplate: "import \"test/a\"\noutput: value: a.x",
+			want:        &CycleError{Cycle: []string{"test/a", "test/b", "test/c", "test/a"}},
+		},
+		"definitionImportsItself": {
+			name:        "mydef",
+			cueTemplate: "import \"mydef\"\noutput: value: mydef.output",
+			want:        &SelfReferenceError{Name: "mydef"},
+		},
+		"definitionImportedByItsImport": {
+			name:        "mydef",
+			cueTemplate: "import \"loop\"\noutput: value: loop.t",
+			want:        &CycleError{Cycle: []string{"mydef", "loop", "mydef"}},
+		},
+		"unnamedImportOfLoop": {
+			cueTemplate: "import \"loop\"\noutput: value: loop.t",
+		},
+		"definitionNamedAsStdlib": {
+			name:        "strings",
+			cueTemplate: "import \"strings\"\noutput: value: strings.ToLower(\"A\")",
+		},
+		"definitionNamedAsPackage": {
+			name:        "test/e",
+			cueTemplate: "import \"test/e\"\noutput: value: e.v",
+		},
+		"definitionNamedAsCyclicPackage": {
+			name:        "test/a",
+			cueTemplate: "import \"test/a\"\noutput: value: a.x",
+			want:        &CycleError{Cycle: []string{"test/a", "test/b", "test/c", "test/a"}},
+		},
+	}
+	for caseName, cs := range cases {
+		t.Run(caseName, func(t *testing.T) {
+			err := checkImportCycles(cs.name, cs.cueTemplate, packages)
+			assert.Equal(t, cs.want, err)
+		})
+	}
+}
+
+func TestCheckImportDepth(t *testing.T) {
+	defer func(depth int) { CueImportMaxDepth = depth }(CueImportMaxDepth)
+	var packages []*build.Instance
+	for i := 0; i < 5; i++ {
+		template := fmt.Sprintf("package p%d\nx: 1", i)
+		if i < 4 {
+			template = fmt.Sprintf("package p%d\nimport \"test/p%d\"\nx: p%d.x", i, i+1, i+1)
+		}
+		bi, err := util.BuildImport(fmt.Sprintf("test/p%d", i), map[string]string{"p.cue": template})
+		require.NoError(t, err)
+		packages = append(packages, bi)
+	}
+	cueTemplate := "import \"test/p0\"\noutput: value: p0.x"
+
+	CueImportMaxDepth = 5
+	assert.NoError(t, checkImportCycles("", cueTemplate, packages))
+	assert.NoError(t, checkImportCycles("mydef", cueTemplate, packages))
+
+	CueImportMaxDepth = 3
+	err := checkImportCycles("", cueTemplate, packages)
+	assert.ErrorIs(t, err, ErrImportTooDeep)
+	assert.EqualError(t, err, "imports of the template are nested too deep: test/p0 imports test/p1 imports test/p2 imports test/p3, the max import depth is 3")
+	err = checkImportCycles("mydef", cueTemplate, packages)
+	assert.EqualError(t, err, "imports of the template are nested too deep: mydef imports test/p0 imports test/p1 imports test/p2 imports test/p3, the max import depth is 3")
+
+	CueImportMaxDepth = 0
+	assert.NoError(t, checkImportCycles("", cueTemplate, packages))
+}
+
+func TestValidateCuexTemplateSelfReference(t *testing.T) {
+	defer setFakeCuexCompiler()()
+
+	ctx := WithDefinitionName(context.Background(), "mydef")
+	err := ValidateCuexTemplate(ctx, "import \"mydef\"\noutput: value: mydef.output")
+	assert.EqualError(t, err, "definition mydef references itself")
+	var selfErr *SelfReferenceError
+	assert.ErrorAs(t, err, &selfErr)
+}
+
+func TestValidateCuexTemplateImportCycle(t *testing.T) {
+	a := newTestPackage("a", "test/a", "package a\nimport \"test/b\"\nx: b.y")
+	b := newTestPackage("b", "test/b", "package b\nimport \"test/a\"\ny: a.x")
+	defer setFakeCuexCompiler(a, b)()
+
+	err := ValidateCuexTemplate(context.Background(), "import \"test/a\"\noutput: value: a.x")
+	assert.EqualError(t, err, "import cycle detected: test/a imports test/b imports test/a")
+	var cycleErr *CycleError
+	assert.ErrorAs(t, err, &cycleErr)
+}
+
+func TestCheckProviderFunctions(t *testing.T) {
+	cases := map[string]struct {
+		cueTemplate string
+		wantErrMsg  string
+	}{
+		"registered": {
+			cueTemplate: `
+import "vela/base64"
+
+encoded: base64.#Encode & {$params: "hello"}
+`,
+		},
+		"unknownProvider": {
+			cueTemplate: `
+#FooBar: {
+	#do:       "bar"
+	#provider: "foo"
+}
+output: #FooBar
+`,
+			wantErrMsg: "output: unknown provider foo",
+		},
+		"unknownFunction": {
+			cueTemplate: `
+encrypted: {
+	#do:       "encrypt"
+	#provider: "base64"
+	$params:   "hello"
+}
+`,
+			wantErrMsg: "encrypted: unknown function encrypt of provider base64",
+		},
+		"notConcrete": {
+			cueTemplate: `
+parameter: fn: string
+call: {
+	#do:       parameter.fn
+	#provider: "base64"
+}
+`,
+		},
+	}
+	compiler := cuex.NewCompilerWithDefaultInternalPackages()
+	for caseName, cs := range cases {
+		t.Run(caseName, func(t *testing.T) {
+			val, err := compiler.CompileStringWithOptions(context.Background(), cs.cueTemplate, cuex.DisableResolveProviderFunctions{})
+			assert.NoError(t, err)
+			err = checkProviderFunctions(val, compiler.GetProviders())
+			if cs.wantErrMsg != "" {
+				assert.EqualError(t, err, cs.wantErrMsg)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestValidateCuexTemplateUnknownProvider(t *testing.T) {
+	defer setFakeCuexCompiler()()
+
+	err := ValidateCuexTemplate(context.Background(), "output: {\n\t#do: \"get\"\n\t#provider: \"unknown\"\n}")
+	assert.EqualError(t, err, "output: unknown provider unknown")
+}
@@ -0,0 +1,165 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"strings"
+
+	"cuelang.org/go/cue/ast"
+	cueErrors "cuelang.org/go/cue/errors"
+	"cuelang.org/go/cue/parser"
+	"cuelang.org/go/cue/token"
+	"github.com/kubevela/workflow/pkg/cue/model"
+)
+
+// ValidateNoDanglingReferences checks that the references to the parameter in the template, e.g. parameter.foo,
+// select the fields declared in the parameter schema. CUE leaves such a reference as an incomplete value instead
+// of an error, which only fails when the template is rendered.
+// The check is conservative: the references to context are not checked, and a struct of the parameter accepts
+// any field once it's not a plain struct literal, i.e. it has a pattern constraint, an ellipsis, an embedding or a
+// comprehension, or it refers to a definition or a disjunction. The references compared with _|_ are existence
+// checks and are not reported either.
+// It's not part of ValidateCueTemplate, the callers opt in to it.
+func ValidateNoDanglingReferences(cueTemplate string) error {
+	f, err := parser.ParseFile("-", cueTemplate)
+	if err != nil {
+		// the syntax error is reported by the validation
+		return nil
+	}
+	var values []ast.Expr
+	for _, decl := range f.Decls {
+		if field, ok := decl.(*ast.Field); ok {
+			if name, _, err := ast.LabelName(field.Label); err == nil && name == model.ParameterFieldName {
+				values = append(values, field.Value)
+			}
+		}
+	}
+	if len(values) == 0 {
+		return nil
+	}
+	declared := declaredFieldsOf(values)
+
+	var errs []error
+	reported := map[string]bool{}
+	ast.Walk(f, func(n ast.Node) bool {
+		switch x := n.(type) {
+		case *ast.BinaryExpr:
+			if (x.Op == token.EQL || x.Op == token.NEQ) && (isBottomLit(x.X) || isBottomLit(x.Y)) {
+				return false
+			}
+		case *ast.SelectorExpr:
+			root, selectors := selectorChainOf(x)
+			id, ok := root.(*ast.Ident)
+			if !ok {
+				return true
+			}
+			if _, topLevel := id.Scope.(*ast.File); !topLevel || id.Name != model.ParameterFieldName {
+				return false
+			}
+			if pos, path, found := declared.lookup(selectors); !found && !reported[path] {
+				reported[path] = true
+				errs = append(errs, cueErrors.Newf(pos, "reference %s.%s is not declared in the parameter",
+					model.ParameterFieldName, path))
+			}
+			return false
+		}
+		return true
+	}, nil)
+	return aggregateErrors(errs)
+}
+
+// declaredFields are the fields of a struct in the parameter schema, the struct is open if any field is accepted
+type declaredFields struct {
+	open   bool
+	values map[string][]ast.Expr
+}
+
+// declaredFieldsOf collects the fields of the struct unified from the values
+func declaredFieldsOf(values []ast.Expr) *declaredFields {
+	d := &declaredFields{values: map[string][]ast.Expr{}}
+	for len(values) != 0 {
+		value := values[0]
+		values = values[1:]
+		switch v := value.(type) {
+		case *ast.ParenExpr:
+			values = append(values, v.X)
+		case *ast.BinaryExpr:
+			if v.Op != token.AND {
+				d.open = true
+				continue
+			}
+			values = append(values, v.X, v.Y)
+		case *ast.StructLit:
+			for _, elt := range v.Elts {
+				switch e := elt.(type) {
+				case *ast.Field:
+					name, _, err := ast.LabelName(e.Label)
+					if err != nil {
+						// pattern constraints and dynamic fields
+						d.open = true
+						continue
+					}
+					d.values[name] = append(d.values[name], e.Value)
+				case *ast.LetClause, *ast.Attribute, *ast.CommentGroup:
+				default:
+					d.open = true
+				}
+			}
+		default:
+			d.open = true
+		}
+	}
+	return d
+}
+
+// lookup returns the position and the path of the first selector not declared, or found if all of them are
+func (d *declaredFields) lookup(selectors []*ast.SelectorExpr) (token.Pos, string, bool) {
+	var path []string
+	for _, sel := range selectors {
+		name, _, err := ast.LabelName(sel.Sel)
+		if err != nil || d.open {
+			return token.NoPos, "", true
+		}
+		path = append(path, name)
+		values, ok := d.values[name]
+		if !ok {
+			return sel.Sel.Pos(), strings.Join(path, "."), false
+		}
+		d = declaredFieldsOf(values)
+	}
+	return token.NoPos, "", true
+}
+
+// selectorChainOf splits a.b.c into the root a and the selectors from the innermost one
+func selectorChainOf(sel *ast.SelectorExpr) (ast.Expr, []*ast.SelectorExpr) {
+	var selectors []*ast.SelectorExpr
+	var expr ast.Expr = sel
+	for {
+		s, ok := expr.(*ast.SelectorExpr)
+		if !ok {
+			break
+		}
+		selectors = append([]*ast.SelectorExpr{s}, selectors...)
+		expr = s.X
+	}
+	return expr, selectors
+}
+
+func isBottomLit(expr ast.Expr) bool {
+	_, ok := expr.(*ast.BottomLit)
+	return ok
+}
@@ -0,0 +1,137 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateNoDanglingReferences(t *testing.T) {
+	cases := map[string]struct {
+		template   string
+		wantErrMsg string
+	}{
+		"declared": {
+			template: `
+parameter: {
+	image: string
+	port?: int
+	resources: cpu: string
+}
+output: spec: {
+	image: parameter.image
+	port:  parameter.port
+	cpu:   parameter.resources.cpu
+	name:  context.name
+}
+`,
+		},
+		"undeclared": {
+			template: `
+parameter: image: string
+output: spec: {
+	image:    parameter.image
+	replicas: parameter.replicas
+}
+`,
+			wantErrMsg: "reference parameter.replicas is not declared in the parameter",
+		},
+		"undeclaredNested": {
+			template: `
+parameter: resources: cpu: string
+output: spec: memory: parameter.resources.memory
+`,
+			wantErrMsg: "reference parameter.resources.memory is not declared in the parameter",
+		},
+		"reportedOnce": {
+			template: `
+parameter: {}
+output: spec: {
+	a: parameter.foo
+	b: parameter.foo
+	c: parameter.bar
+}
+`,
+			wantErrMsg: "[reference parameter.foo is not declared in the parameter, reference parameter.bar is not declared in the parameter]",
+		},
+		"unifiedDeclarations": {
+			template: `
+parameter: {image: string} & {port: int}
+parameter: cmd?: [...string]
+output: spec: {
+	image: parameter.image
+	port:  parameter.port
+	cmd:   parameter.cmd
+}
+`,
+		},
+		"openStructs": {
+			template: `
+#Resources: cpu: string
+parameter: {
+	labels: [string]: string
+	extra: {...}
+	resources: #Resources
+	volume: {emptyDir: {}} | {hostPath: string}
+}
+output: spec: {
+	app:      parameter.labels.app
+	extra:    parameter.extra.foo
+	memory:   parameter.resources.memory
+	hostPath: parameter.volume.hostPath
+}
+`,
+		},
+		"existenceCheck": {
+			template: `
+parameter: image: string
+output: spec: {
+	if parameter.replicas != _|_ {
+		replicas: 1
+	}
+}
+`,
+		},
+		"shadowedParameter": {
+			template: `
+parameter: image: string
+output: spec: {
+	parameter: foo: 1
+	value: parameter.foo
+}
+`,
+		},
+		"noParameter": {
+			template: `output: spec: name: context.name`,
+		},
+		"syntaxError": {
+			template: `parameter: {`,
+		},
+	}
+	for caseName, cs := range cases {
+		t.Run(caseName, func(t *testing.T) {
+			err := ValidateNoDanglingReferences(cs.template)
+			if cs.wantErrMsg != "" {
+				assert.EqualError(t, err, cs.wantErrMsg)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
@@ -0,0 +1,212 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"cuelang.org/go/cue/ast"
+	"cuelang.org/go/cue/parser"
+	"cuelang.org/go/cue/token"
+)
+
+// parameterField is a field under the parameter with the references made by its default values
+type parameterField struct {
+	pos token.Pos
+	// defaultRefs are the paths of the fields referenced by the default values of the field
+	defaultRefs []string
+}
+
+// validateParameterDefaultCycles reports the fields of the parameter whose default values reference each other in a
+// cycle, e.g. `a: *b | int, b: *a | int`, which cue resolves silently to the types without the defaults.
+// Each cycle is reported once with ErrDefaultCycle and the paths of the fields involved.
+func validateParameterDefaultCycles(cueTemplate string) ([]CueValidationError, error) {
+	f, err := parser.ParseFile("-", cueTemplate)
+	if err != nil {
+		// the syntax error is reported by the validation
+		return nil, nil
+	}
+	// paths are the paths of the fields by their values, which are the nodes the references are resolved to
+	paths := map[ast.Node]string{}
+	var values []ast.Expr
+	for _, decl := range f.Decls {
+		field, ok := decl.(*ast.Field)
+		if !ok {
+			continue
+		}
+		if name, _, err := ast.LabelName(field.Label); err == nil && name == parameterFieldName {
+			paths[field.Value] = parameterFieldName
+			values = append(values, field.Value)
+		}
+	}
+	fields := map[string]*parameterField{}
+	var order []string
+	var collect func(value ast.Expr, prefix string)
+	collect = func(value ast.Expr, prefix string) {
+		for _, lit := range structLitsOf(value) {
+			for _, elt := range lit.Elts {
+				field, ok := elt.(*ast.Field)
+				if !ok {
+					continue
+				}
+				name, _, err := ast.LabelName(field.Label)
+				if err != nil {
+					continue
+				}
+				path := prefix + "." + name
+				paths[field.Value] = path
+				if _, ok := fields[path]; !ok {
+					fields[path] = &parameterField{pos: field.Pos()}
+					order = append(order, path)
+				}
+				collect(field.Value, path)
+			}
+		}
+	}
+	for _, value := range values {
+		collect(value, parameterFieldName)
+	}
+	for _, value := range values {
+		ast.Walk(value, func(node ast.Node) bool {
+			field, ok := node.(*ast.Field)
+			if !ok {
+				return true
+			}
+			path, ok := paths[field.Value]
+			if !ok {
+				return true
+			}
+			for _, def := range defaultsOf(field.Value) {
+				fields[path].defaultRefs = append(fields[path].defaultRefs, fieldReferencesOf(def, paths)...)
+			}
+			return true
+		}, nil)
+	}
+
+	var errs []CueValidationError
+	var cycleErrs []error
+	for _, cycle := range defaultCyclesOf(order, fields) {
+		err := fmt.Errorf("%w: %s", ErrDefaultCycle, strings.Join(cycle, " -> "))
+		pos := fields[cycle[0]].pos
+		errs = append(errs, CueValidationError{Message: err.Error(), Filename: pos.Filename(), Line: pos.Line(), Column: pos.Column()})
+		cycleErrs = append(cycleErrs, err)
+	}
+	return errs, aggregateErrors(cycleErrs)
+}
+
+// defaultsOf returns the expressions marked as the default by * in the value
+func defaultsOf(expr ast.Expr) []ast.Expr {
+	switch x := expr.(type) {
+	case *ast.UnaryExpr:
+		if x.Op == token.MUL {
+			return []ast.Expr{x.X}
+		}
+	case *ast.ParenExpr:
+		return defaultsOf(x.X)
+	case *ast.BinaryExpr:
+		if x.Op == token.OR || x.Op == token.AND {
+			return append(defaultsOf(x.X), defaultsOf(x.Y)...)
+		}
+	}
+	return nil
+}
+
+// fieldReferencesOf returns the paths of the fields of the parameter referenced in the expression, either by the
+// name of a field in the enclosing structs, e.g. b, or through the parameter, e.g. parameter.b
+func fieldReferencesOf(expr ast.Expr, paths map[ast.Node]string) []string {
+	var refs []string
+	ast.Walk(expr, func(node ast.Node) bool {
+		switch x := node.(type) {
+		case *ast.SelectorExpr:
+			root, selectors := selectorChainOf(x)
+			id, ok := root.(*ast.Ident)
+			if !ok {
+				return true
+			}
+			path, ok := paths[id.Node]
+			if !ok {
+				return false
+			}
+			for _, sel := range selectors {
+				name, _, err := ast.LabelName(sel.Sel)
+				if err != nil {
+					break
+				}
+				path += "." + name
+			}
+			refs = append(refs, path)
+			return false
+		case *ast.Ident:
+			if path, ok := paths[x.Node]; ok {
+				refs = append(refs, path)
+			}
+		}
+		return true
+	}, nil)
+	return refs
+}
+
+// defaultCyclesOf walks the references of the defaults from the fields in order, and returns the distinct cycles,
+// each starting and ending with the same field
+func defaultCyclesOf(order []string, fields map[string]*parameterField) [][]string {
+	const (
+		visiting = 1
+		visited  = 2
+	)
+	state := map[string]int{}
+	seen := map[string]bool{}
+	var cycles [][]string
+	var stack []string
+	var visit func(path string)
+	visit = func(path string) {
+		switch state[path] {
+		case visited:
+			return
+		case visiting:
+			start := 0
+			for i, p := range stack {
+				if p == path {
+					start = i
+					break
+				}
+			}
+			cycle := append(append([]string{}, stack[start:]...), path)
+			members := append([]string{}, stack[start:]...)
+			sort.Strings(members)
+			if key := strings.Join(members, ","); !seen[key] {
+				seen[key] = true
+				cycles = append(cycles, cycle)
+			}
+			return
+		}
+		state[path] = visiting
+		stack = append(stack, path)
+		if field, ok := fields[path]; ok {
+			for _, ref := range field.defaultRefs {
+				visit(ref)
+			}
+		}
+		stack = stack[:len(stack)-1]
+		state[path] = visited
+	}
+	for _, path := range order {
+		visit(path)
+	}
+	return cycles
+}
@@ -0,0 +1,100 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateParameterDefaultCycles(t *testing.T) {
+	cases := map[string]struct {
+		cueTemplate string
+		wantErr     string
+		wantLine    int
+	}{
+		"noCycle": {
+			cueTemplate: `
+parameter: {
+	port: *8080 | int
+	targetPort: *port | int
+	name: *"svc" | string
+}`,
+		},
+		"siblings": {
+			cueTemplate: `
+parameter: {
+	a: *b | int
+	b: *a | int
+}`,
+			wantErr:  "default values of the parameter form a cycle: parameter.a -> parameter.b -> parameter.a",
+			wantLine: 3,
+		},
+		"throughParameter": {
+			cueTemplate: `
+parameter: {
+	a: *(parameter.nested.b + 1) | int
+	nested: b: *(parameter.c * 2) | int
+}
+parameter: c: *parameter.a | int
+output: replicas: parameter.a`,
+			wantErr:  "default values of the parameter form a cycle: parameter.a -> parameter.nested.b -> parameter.c -> parameter.a",
+			wantLine: 3,
+		},
+		"selfReference": {
+			cueTemplate: `
+parameter: {
+	name: *"\(name)-svc" | string
+}`,
+			wantErr:  "default values of the parameter form a cycle: parameter.name -> parameter.name",
+			wantLine: 3,
+		},
+		"multipleCycles": {
+			cueTemplate: `
+parameter: {
+	a: *b | int
+	b: *a | int
+	c: *d | string
+	d: *c | string
+}`,
+			wantErr:  "[default values of the parameter form a cycle: parameter.a -> parameter.b -> parameter.a, default values of the parameter form a cycle: parameter.c -> parameter.d -> parameter.c]",
+			wantLine: 3,
+		},
+		"notDefaultReference": {
+			cueTemplate: `
+parameter: {
+	a: *b | int
+	b: int & a
+}`,
+		},
+	}
+	for caseName, cs := range cases {
+		t.Run(caseName, func(t *testing.T) {
+			errs, err := validateParameterDefaultCycles(cs.cueTemplate)
+			if cs.wantErr == "" {
+				assert.NoError(t, err)
+				assert.Empty(t, errs)
+				return
+			}
+			assert.ErrorIs(t, err, ErrDefaultCycle)
+			assert.EqualError(t, err, cs.wantErr)
+			assert.Equal(t, cs.wantLine, errs[0].Line)
+			assert.EqualError(t, ValidateCueTemplate(cs.cueTemplate), cs.wantErr)
+		})
+	}
+}
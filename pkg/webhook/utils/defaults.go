@@ -0,0 +1,127 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/common"
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
+	"github.com/oam-dev/kubevela/apis/types"
+)
+
+// extensionTemplateFieldName is the field of spec.extension carrying the cue template of the legacy definitions
+const extensionTemplateFieldName = "template"
+
+// DefaultDefinition fills the fields omitted in the definition with the defaults the controller interprets them by,
+// so that the validation sees the same definition as the runtime:
+//   - the template in spec.extension is the cue schematic of the definition without a schematic
+//   - the terraform configuration is in HCL if its type is not set
+//   - the workload of the ComponentDefinition is autodetected if neither its type nor its definition is set
+//
+// The definition is modified in place, both the typed definitions and the unstructured ones are supported.
+func DefaultDefinition(obj runtime.Object) error {
+	u, isUnstructured := obj.(*unstructured.Unstructured)
+	obj, err := typedDefinitionOf(obj)
+	if err != nil {
+		return err
+	}
+	switch def := obj.(type) {
+	case *v1beta1.ComponentDefinition:
+		schematic, err := defaultSchematic(def.Spec.Schematic, def.Spec.Extension)
+		if err != nil {
+			return err
+		}
+		def.Spec.Schematic = schematic
+		if def.Spec.Workload.Type == "" && def.Spec.Workload.Definition == (common.WorkloadGVK{}) {
+			def.Spec.Workload.Type = types.AutoDetectWorkloadDefinition
+		}
+	case *v1beta1.TraitDefinition:
+		schematic, err := defaultSchematic(def.Spec.Schematic, def.Spec.Extension)
+		if err != nil {
+			return err
+		}
+		def.Spec.Schematic = schematic
+	case *v1beta1.WorkloadDefinition:
+		schematic, err := defaultSchematic(def.Spec.Schematic, def.Spec.Extension)
+		if err != nil {
+			return err
+		}
+		def.Spec.Schematic = schematic
+	case *v1beta1.PolicyDefinition:
+		def.Spec.Schematic, _ = defaultSchematic(def.Spec.Schematic, nil)
+	case *v1beta1.WorkflowStepDefinition:
+		def.Spec.Schematic, _ = defaultSchematic(def.Spec.Schematic, nil)
+	}
+	if !isUnstructured {
+		return nil
+	}
+	return setDefaultsInUnstructured(u, obj)
+}
+
+// setDefaultsInUnstructured sets the defaulted fields of the typed definition in the unstructured one, the other
+// fields are kept as is
+func setDefaultsInUnstructured(u *unstructured.Unstructured, def runtime.Object) error {
+	_, schematic, err := definitionSchematicOf(def)
+	if err != nil || schematic == nil {
+		return err
+	}
+	content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(schematic)
+	if err != nil {
+		return fmt.Errorf("failed to convert the schematic of %s %s: %w", u.GetKind(), u.GetName(), err)
+	}
+	if err := unstructured.SetNestedMap(u.Object, content, "spec", "schematic"); err != nil {
+		return err
+	}
+	if cd, ok := def.(*v1beta1.ComponentDefinition); ok {
+		return unstructured.SetNestedField(u.Object, cd.Spec.Workload.Type, "spec", "workload", "type")
+	}
+	return nil
+}
+
+// defaultSchematic returns the schematic with the defaults filled, the cue template in the extension is used if
+// neither a cue nor a terraform schematic is set, as the controller loads the template
+func defaultSchematic(schematic *common.Schematic, extension *runtime.RawExtension) (*common.Schematic, error) {
+	if schematic == nil || (schematic.CUE == nil && schematic.Terraform == nil) {
+		template, err := extensionTemplateOf(extension)
+		if err != nil || template == "" {
+			return schematic, err
+		}
+		return &common.Schematic{CUE: &common.CUE{Template: template}}, nil
+	}
+	if schematic.Terraform != nil && schematic.Terraform.Type == "" {
+		schematic.Terraform.Type = terraformTypeHCL
+	}
+	return schematic, nil
+}
+
+// extensionTemplateOf returns the cue template in the extension, empty if there is none
+func extensionTemplateOf(extension *runtime.RawExtension) (string, error) {
+	if extension == nil || len(extension.Raw) == 0 {
+		return "", nil
+	}
+	fields := map[string]interface{}{}
+	if err := json.Unmarshal(extension.Raw, &fields); err != nil {
+		return "", fmt.Errorf("cannot parse the extension of the definition: %w", err)
+	}
+	template, _ := fields[extensionTemplateFieldName].(string)
+	return template, nil
+}
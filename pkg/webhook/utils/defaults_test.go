@@ -0,0 +1,118 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/common"
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
+	"github.com/oam-dev/kubevela/apis/types"
+)
+
+func TestDefaultDefinition(t *testing.T) {
+	extension := func(raw string) *runtime.RawExtension {
+		return &runtime.RawExtension{Raw: []byte(raw)}
+	}
+	cases := map[string]struct {
+		def     runtime.Object
+		want    runtime.Object
+		wantErr string
+	}{
+		"componentExtensionTemplate": {
+			def: &v1beta1.ComponentDefinition{Spec: v1beta1.ComponentDefinitionSpec{
+				Extension: extension(`{"template":"output: {}"}`),
+			}},
+			want: &v1beta1.ComponentDefinition{Spec: v1beta1.ComponentDefinitionSpec{
+				Workload:  common.WorkloadTypeDescriptor{Type: types.AutoDetectWorkloadDefinition},
+				Schematic: &common.Schematic{CUE: &common.CUE{Template: "output: {}"}},
+				Extension: extension(`{"template":"output: {}"}`),
+			}},
+		},
+		"traitSchematicKept": {
+			def: &v1beta1.TraitDefinition{Spec: v1beta1.TraitDefinitionSpec{
+				Schematic: &common.Schematic{CUE: &common.CUE{Template: "patch: {}"}},
+				Extension: extension(`{"template":"outputs: {}"}`),
+			}},
+			want: &v1beta1.TraitDefinition{Spec: v1beta1.TraitDefinitionSpec{
+				Schematic: &common.Schematic{CUE: &common.CUE{Template: "patch: {}"}},
+				Extension: extension(`{"template":"outputs: {}"}`),
+			}},
+		},
+		"terraformType": {
+			def: &v1beta1.ComponentDefinition{Spec: v1beta1.ComponentDefinitionSpec{
+				Workload:  common.WorkloadTypeDescriptor{Definition: common.WorkloadGVK{APIVersion: "apps/v1", Kind: "Deployment"}},
+				Schematic: &common.Schematic{Terraform: &common.Terraform{Configuration: "variable \"name\" {}"}},
+			}},
+			want: &v1beta1.ComponentDefinition{Spec: v1beta1.ComponentDefinitionSpec{
+				Workload:  common.WorkloadTypeDescriptor{Definition: common.WorkloadGVK{APIVersion: "apps/v1", Kind: "Deployment"}},
+				Schematic: &common.Schematic{Terraform: &common.Terraform{Configuration: "variable \"name\" {}", Type: terraformTypeHCL}},
+			}},
+		},
+		"unstructured": {
+			def: &unstructured.Unstructured{Object: map[string]interface{}{
+				"apiVersion": "core.oam.dev/v1beta1",
+				"kind":       "TraitDefinition",
+				"metadata":   map[string]interface{}{"name": "scaler"},
+				"spec":       map[string]interface{}{"extension": map[string]interface{}{"template": "patch: {}"}},
+			}},
+			want: &unstructured.Unstructured{Object: map[string]interface{}{
+				"apiVersion": "core.oam.dev/v1beta1",
+				"kind":       "TraitDefinition",
+				"metadata":   map[string]interface{}{"name": "scaler"},
+				"spec": map[string]interface{}{
+					"extension": map[string]interface{}{"template": "patch: {}"},
+					"schematic": map[string]interface{}{"cue": map[string]interface{}{"template": "patch: {}"}},
+				},
+			}},
+		},
+		"invalidExtension": {
+			def: &v1beta1.TraitDefinition{Spec: v1beta1.TraitDefinitionSpec{
+				Extension: extension(`["template"]`),
+			}},
+			wantErr: "cannot parse the extension of the definition: json: cannot unmarshal array into Go value of type map[string]interface {}",
+		},
+	}
+
+	for caseName, cs := range cases {
+		t.Run(caseName, func(t *testing.T) {
+			err := DefaultDefinition(cs.def)
+			if cs.wantErr != "" {
+				assert.EqualError(t, err, cs.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, cs.want, cs.def)
+		})
+	}
+}
+
+func TestValidateDefinitionDefaulted(t *testing.T) {
+	defer setFakeCuexCompiler()()
+	def := &v1beta1.TraitDefinition{Spec: v1beta1.TraitDefinitionSpec{
+		Extension: &runtime.RawExtension{Raw: []byte(`{"template":"patch: spec: replicas: parameter.replicas\nparameter: replicas: int\nparameter: replicas: \"1\""}`)},
+	}}
+	def.SetName("scaler")
+	err := ValidateDefinition(context.Background(), nil, def)
+	assert.EqualError(t, err, "patch.spec.replicas: conflicting values int and \"1\" (mismatched types int and string)")
+	assert.Nil(t, def.Spec.Schematic)
+}
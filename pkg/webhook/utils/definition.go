@@ -0,0 +1,109 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"context"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
+)
+
+// ValidateDefinition checks the size of the definition by ValidateDefinitionSize and validates it with the validators
+// of DefaultValidatorRegistry, which are by default the kind-specific validator, e.g. ValidateComponentDefinition, and
+// then the validation of the version and the definitionRevision of it, the optional checks at the severities of
+// the SeverityConfig set by WithSeverityConfig, the labels and the annotations of DefinitionRequiredMetadata, and the
+// DeprecatedDefinitionFields, which are warned.
+// Both the typed definitions and the unstructured ones, e.g. read from a file, are supported.
+// The warnings of the validators, e.g. of ValidateWorkflowStepDefinition, are dropped. The admission webhook runs it
+// on the definitions after validating their cue templates, see WithCueTemplateValidated.
+func ValidateDefinition(ctx context.Context, cli client.Client, obj runtime.Object) error {
+	_, err := ValidateDefinitionWithWarnings(ctx, cli, obj)
+	return err
+}
+
+// ValidateDefinitionWithWarnings validates the definition as ValidateDefinition does, and also returns the warnings
+// of the validators. The validators get a copy of the definition with the defaults filled by DefaultDefinition, and
+// their panic is returned as ErrValidationPanic.
+func ValidateDefinitionWithWarnings(ctx context.Context, cli client.Client, obj runtime.Object) (warnings []string, err error) {
+	defer recoverValidation(validatorDefinition, &err)
+	def, err := typedDefinitionOf(obj)
+	if err != nil {
+		return nil, err
+	}
+	if err := ValidateDefinitionSize(def); err != nil {
+		return nil, err
+	}
+	def = def.DeepCopyObject()
+	if err := DefaultDefinition(def); err != nil {
+		return nil, err
+	}
+	// the registered validators only get the supported definitions
+	if _, _, err := definitionVersionOf(def); err != nil {
+		return nil, err
+	}
+	return DefaultValidatorRegistry.Validate(ctx, cli, def)
+}
+
+type cueTemplateValidatedCtxKey struct{}
+
+// WithCueTemplateValidated returns the context telling the kind-specific validators of ValidateDefinition that the
+// cue template of the definition is validated by the caller, e.g. the admission webhook rendering the errors with
+// their positions, or unchanged since it was validated, so that it's not validated again
+func WithCueTemplateValidated(ctx context.Context) context.Context {
+	return context.WithValue(ctx, cueTemplateValidatedCtxKey{}, true)
+}
+
+// isCueTemplateValidated checks whether the context is returned by WithCueTemplateValidated
+func isCueTemplateValidated(ctx context.Context) bool {
+	validated, _ := ctx.Value(cueTemplateValidatedCtxKey{}).(bool)
+	return validated
+}
+
+// typedDefinitionOf converts the unstructured definition to the typed one of its kind, the typed definitions are
+// returned as is. The group of the definition must be core.oam.dev if it is set.
+func typedDefinitionOf(obj runtime.Object) (runtime.Object, error) {
+	gvk := obj.GetObjectKind().GroupVersionKind()
+	if gvk.Group != "" && gvk.Group != v1beta1.Group {
+		return nil, fmt.Errorf("unsupported definition group %s", gvk.Group)
+	}
+	u, ok := obj.(*unstructured.Unstructured)
+	if !ok {
+		return obj, nil
+	}
+	var def runtime.Object
+	switch gvk.Kind {
+	case v1beta1.ComponentDefinitionKind:
+		def = &v1beta1.ComponentDefinition{}
+	case v1beta1.TraitDefinitionKind:
+		def = &v1beta1.TraitDefinition{}
+	case v1beta1.PolicyDefinitionKind:
+		def = &v1beta1.PolicyDefinition{}
+	case v1beta1.WorkflowStepDefinitionKind:
+		def = &v1beta1.WorkflowStepDefinition{}
+	default:
+		return nil, fmt.Errorf("unsupported definition kind %q", gvk.Kind)
+	}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(u.Object, def); err != nil {
+		return nil, fmt.Errorf("failed to convert %s %s: %w", gvk.Kind, u.GetName(), err)
+	}
+	return def, nil
+}
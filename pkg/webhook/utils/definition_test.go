@@ -0,0 +1,115 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	apicommon "github.com/oam-dev/kubevela/apis/core.oam.dev/common"
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
+	"github.com/oam-dev/kubevela/pkg/oam"
+	"github.com/oam-dev/kubevela/pkg/utils/common"
+)
+
+func TestValidateDefinition(t *testing.T) {
+	defer setFakeCuexCompiler()()
+	cli := fake.NewClientBuilder().WithScheme(common.Scheme).WithRESTMapper(newWorkloadRESTMapper()).Build()
+	unstructuredDef := func(kind, template string) *unstructured.Unstructured {
+		return &unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": v1beta1.SchemeGroupVersion.String(),
+			"kind":       kind,
+			"metadata":   map[string]interface{}{"name": "test", "namespace": "default"},
+			"spec": map[string]interface{}{
+				"schematic": map[string]interface{}{"cue": map[string]interface{}{"template": template}},
+			},
+		}}
+	}
+
+	cases := map[string]struct {
+		def     runtime.Object
+		wantErr string
+	}{
+		"component": {
+			def: &v1beta1.ComponentDefinition{
+				ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "default"},
+				Spec: v1beta1.ComponentDefinitionSpec{
+					Schematic: &apicommon.Schematic{CUE: &apicommon.CUE{Template: `output: {apiVersion: "v1", kind: "ConfigMap", metadata: name: context.name}`}},
+					Status:    &apicommon.Status{HealthPolicy: `isHealth: "yes"`},
+				},
+			},
+			wantErr: "invalid healthPolicy: isHealth must be a bool, got string",
+		},
+		"componentVersion": {
+			def: &v1beta1.ComponentDefinition{
+				ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "default"},
+				Spec:       v1beta1.ComponentDefinitionSpec{Version: "1.a"},
+			},
+			wantErr: "Not a valid version",
+		},
+		"unstructuredTrait": {
+			def:     unstructuredDef(v1beta1.TraitDefinitionKind, `patch: hello: world`),
+			wantErr: "patch.hello: reference \"world\" not found",
+		},
+		"unstructuredPolicy": {
+			def: unstructuredDef(v1beta1.PolicyDefinitionKind, `output: hello: "world"`),
+		},
+		"workflowStep": {
+			def: &v1beta1.WorkflowStepDefinition{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        "test",
+					Namespace:   "default",
+					Annotations: map[string]string{oam.AnnotationDefinitionRevisionName: "1.0.0"},
+				},
+				Spec: v1beta1.WorkflowStepDefinitionSpec{Version: "1.0.0"},
+			},
+			wantErr: "WorkflowStepDefinition has both spec.version and revision name annotation. Only one can be present",
+		},
+		"unsupportedKind": {
+			def:     unstructuredDef(v1beta1.WorkloadDefinitionKind, ""),
+			wantErr: "unsupported definition kind \"WorkloadDefinition\"",
+		},
+		"unsupportedGroup": {
+			def: &unstructured.Unstructured{Object: map[string]interface{}{
+				"apiVersion": "apps/v1",
+				"kind":       "Deployment",
+			}},
+			wantErr: "unsupported definition group apps",
+		},
+		"unsupportedType": {
+			def:     &v1beta1.Application{},
+			wantErr: "unsupported definition type *v1beta1.Application",
+		},
+	}
+
+	for caseName, cs := range cases {
+		t.Run(caseName, func(t *testing.T) {
+			err := ValidateDefinition(context.Background(), cli, cs.def)
+			if cs.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorContains(t, err, cs.wantErr)
+		})
+	}
+}
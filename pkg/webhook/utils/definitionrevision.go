@@ -0,0 +1,204 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/aryann/difflib"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/yaml"
+
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/common"
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
+	controllerutils "github.com/oam-dev/kubevela/pkg/controller/utils"
+)
+
+// diffContextLines is the number of unchanged lines kept around each change in the diff
+const diffContextLines = 2
+
+// DiffDefinitionRevision returns a human-readable unified diff of the definition spec stored in
+// the old and new definitionRevision. Lines only in old are prefixed with "-", lines only in new
+// are prefixed with "+". An empty string is returned if the specs are the same.
+func DiffDefinitionRevision(old, new *v1beta1.DefinitionRevision) (string, error) {
+	oldSpec, err := definitionRevisionSpecYAML(old)
+	if err != nil {
+		return "", err
+	}
+	newSpec, err := definitionRevisionSpecYAML(new)
+	if err != nil {
+		return "", err
+	}
+	if oldSpec == newSpec {
+		return "", nil
+	}
+	records := difflib.Diff(strings.Split(oldSpec, "\n"), strings.Split(newSpec, "\n"))
+	return renderUnifiedDiff(records), nil
+}
+
+// definitionRevisionSpecYAML marshals the spec of the definition held by the definitionRevision
+func definitionRevisionSpecYAML(defRev *v1beta1.DefinitionRevision) (string, error) {
+	spec, err := definitionRevisionSpecOf(defRev)
+	if err != nil {
+		return "", err
+	}
+	bs, err := yaml.Marshal(spec)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSuffix(string(bs), "\n"), nil
+}
+
+// definitionRevisionSpecOf returns the spec of the definition held by the definitionRevision
+func definitionRevisionSpecOf(defRev *v1beta1.DefinitionRevision) (interface{}, error) {
+	switch defRev.Spec.DefinitionType {
+	case common.ComponentType:
+		return defRev.Spec.ComponentDefinition.Spec, nil
+	case common.TraitType:
+		return defRev.Spec.TraitDefinition.Spec, nil
+	case common.PolicyType:
+		return defRev.Spec.PolicyDefinition.Spec, nil
+	case common.WorkflowStepType:
+		return defRev.Spec.WorkflowStepDefinition.Spec, nil
+	default:
+		return nil, fmt.Errorf("unsupported definition type %q", defRev.Spec.DefinitionType)
+	}
+}
+
+// DefinitionRevisionValidationOptions configures how ValidateDefinitionRevisionWithOptions decides whether the
+// definition changes its existing definitionRevision
+type DefinitionRevisionValidationOptions struct {
+	// IgnoredFields are the dot separated paths in the definition spec whose changes don't count, e.g.
+	// "status.customStatus" of a ComponentDefinition. The metadata and the status of the definition are never
+	// compared. It's empty by default, so that every change of the definition spec counts.
+	IgnoredFields []string
+	// ReportNotFound makes the validation return ErrRevisionNotFound if the definitionRevision doesn't exist, so that
+	// the caller can create it. The validation passes in that case by default.
+	ReportNotFound bool
+	// CompareFunctionalContent makes the definitions compared by the hashes of their functional content, i.e. the
+	// definition spec without NonFunctionalDefinitionFields and with the cue template normalized, see
+	// NormalizeCueTemplate, so that only the changes of the behavior of the definition count
+	CompareFunctionalContent bool
+}
+
+// NonFunctionalDefinitionFields are the dot separated paths in the definition spec that don't change the behavior of
+// the definition, which are ignored by the comparison of CompareFunctionalContent. The extension is functional as it
+// may carry the legacy template of the definition.
+var NonFunctionalDefinitionFields = []string{"version"}
+
+// AllowDefinitionRevisionMutation honours the annotation definitionrevision.oam.dev/allow-mutation of the definitions
+// to modify their existing definitionRevisions, which is meant for the development clusters only
+var AllowDefinitionRevisionMutation = false
+
+// equalIgnoringFields compares the definition specs of the definitionRevisions without the IgnoredFields, or their
+// functional content if CompareFunctionalContent is set, false is returned if neither is set as the strict comparison
+// has been done
+func (o DefinitionRevisionValidationOptions) equalIgnoringFields(old, new *v1beta1.DefinitionRevision) (bool, error) {
+	if o.CompareFunctionalContent {
+		oldHash, err := o.functionalContentHashOf(old)
+		if err != nil {
+			return false, err
+		}
+		newHash, err := o.functionalContentHashOf(new)
+		if err != nil {
+			return false, err
+		}
+		return oldHash == newHash, nil
+	}
+	if len(o.IgnoredFields) == 0 {
+		return false, nil
+	}
+	oldSpec, err := o.prunedSpecOf(old)
+	if err != nil {
+		return false, err
+	}
+	newSpec, err := o.prunedSpecOf(new)
+	if err != nil {
+		return false, err
+	}
+	return reflect.DeepEqual(oldSpec, newSpec), nil
+}
+
+func (o DefinitionRevisionValidationOptions) prunedSpecOf(defRev *v1beta1.DefinitionRevision) (map[string]interface{}, error) {
+	spec, err := definitionRevisionSpecOf(defRev)
+	if err != nil {
+		return nil, err
+	}
+	bs, err := json.Marshal(spec)
+	if err != nil {
+		return nil, err
+	}
+	pruned := map[string]interface{}{}
+	if err := json.Unmarshal(bs, &pruned); err != nil {
+		return nil, err
+	}
+	for _, path := range o.IgnoredFields {
+		unstructured.RemoveNestedField(pruned, strings.Split(path, ".")...)
+	}
+	return pruned, nil
+}
+
+// functionalContentHashOf hashes the definition spec held by the definitionRevision without the IgnoredFields and
+// NonFunctionalDefinitionFields, with the cue template normalized. The template failing to be normalized is hashed
+// as is.
+func (o DefinitionRevisionValidationOptions) functionalContentHashOf(defRev *v1beta1.DefinitionRevision) (string, error) {
+	defRev = defRev.DeepCopy()
+	for _, schematic := range schematicsOf(defRev) {
+		if schematic == nil || schematic.CUE == nil {
+			continue
+		}
+		if normalized, err := NormalizeCueTemplate(schematic.CUE.Template); err == nil {
+			schematic.CUE.Template = normalized
+		}
+	}
+	o.IgnoredFields = append(append([]string{}, o.IgnoredFields...), NonFunctionalDefinitionFields...)
+	spec, err := o.prunedSpecOf(defRev)
+	if err != nil {
+		return "", err
+	}
+	return controllerutils.ComputeSpecHash(spec)
+}
+
+// renderUnifiedDiff renders the changed records with diffContextLines unchanged lines around them,
+// hunks far from each other are separated by "...".
+func renderUnifiedDiff(records []difflib.DiffRecord) string {
+	keep := make([]bool, len(records))
+	for i, r := range records {
+		if r.Delta == difflib.Common {
+			continue
+		}
+		for j := i - diffContextLines; j <= i+diffContextLines; j++ {
+			if j >= 0 && j < len(records) {
+				keep[j] = true
+			}
+		}
+	}
+	var lines []string
+	for i, r := range records {
+		if !keep[i] {
+			if i > 0 && keep[i-1] {
+				lines = append(lines, "...")
+			}
+			continue
+		}
+		lines = append(lines, r.String())
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,566 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/common"
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
+	"github.com/oam-dev/kubevela/pkg/controller/core.oam.dev/v1beta1/core"
+	"github.com/oam-dev/kubevela/pkg/oam"
+	pkgcommon "github.com/oam-dev/kubevela/pkg/utils/common"
+)
+
+func traitDefRevision(template string) *v1beta1.DefinitionRevision {
+	return &v1beta1.DefinitionRevision{
+		Spec: v1beta1.DefinitionRevisionSpec{
+			DefinitionType: common.TraitType,
+			TraitDefinition: v1beta1.TraitDefinition{
+				Spec: v1beta1.TraitDefinitionSpec{
+					AppliesToWorkloads: []string{"webservice"},
+					Schematic:          &common.Schematic{CUE: &common.CUE{Template: template}},
+				},
+			},
+		},
+	}
+}
+
+// newTraitDefinition returns the TraitDefinition with the cue template, its schematic is unset if the template is empty
+func newTraitDefinition(name, namespace, template string) *v1beta1.TraitDefinition {
+	td := &v1beta1.TraitDefinition{
+		TypeMeta:   metav1.TypeMeta{APIVersion: v1beta1.SchemeGroupVersion.String(), Kind: v1beta1.TraitDefinitionKind},
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+	}
+	if template != "" {
+		td.Spec.Schematic = &common.Schematic{CUE: &common.CUE{Template: template}}
+	}
+	return td
+}
+
+func TestDeepEqualDefRevisionIgnoresRevisionNumber(t *testing.T) {
+	oldRev := traitDefRevision("patch: replicas: 1")
+	oldRev.Name = "scaler-v1"
+	oldRev.Spec.Revision = 1
+	oldRev.Spec.RevisionHash = "hash"
+	oldRev.Spec.TraitDefinition.ObjectMeta = metav1.ObjectMeta{Name: "scaler", ResourceVersion: "1", Generation: 1}
+
+	newRev := oldRev.DeepCopy()
+	newRev.Name = "scaler-v2"
+	newRev.ResourceVersion = "2"
+	newRev.Spec.Revision = 2
+	newRev.Spec.TraitDefinition.ResourceVersion = "2"
+	newRev.Spec.TraitDefinition.Generation = 2
+	newRev.Spec.TraitDefinition.Status.LatestRevision = &common.Revision{Name: "scaler-v2", Revision: 2}
+	assert.True(t, core.DeepEqualDefRevision(oldRev, newRev))
+	assert.True(t, isCosmeticRevisionChange(oldRev, newRev))
+	diff, err := DiffDefinitionRevision(oldRev, newRev)
+	assert.NoError(t, err)
+	assert.Empty(t, diff)
+
+	newRev.Spec.TraitDefinition.Spec.Schematic.CUE.Template = "patch: replicas: 2"
+	assert.False(t, core.DeepEqualDefRevision(oldRev, newRev))
+}
+
+func TestDiffDefinitionRevision(t *testing.T) {
+	cases := map[string]struct {
+		old     *v1beta1.DefinitionRevision
+		new     *v1beta1.DefinitionRevision
+		want    string
+		wantErr bool
+	}{
+		"sameSpec": {
+			old:  traitDefRevision("patch: replicas: 1\n"),
+			new:  traitDefRevision("patch: replicas: 1\n"),
+			want: "",
+		},
+		"templateChanged": {
+			old: traitDefRevision("patch: replicas: 1\n"),
+			new: traitDefRevision("patch: replicas: 2\n"),
+			want: `    cue:
+      template: |
+-       patch: replicas: 1
++       patch: replicas: 2`,
+		},
+		"unsupportedType": {
+			old:     &v1beta1.DefinitionRevision{},
+			new:     &v1beta1.DefinitionRevision{},
+			wantErr: true,
+		},
+	}
+	for caseName, cs := range cases {
+		t.Run(caseName, func(t *testing.T) {
+			diff, err := DiffDefinitionRevision(cs.old, cs.new)
+			if cs.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, cs.want, diff)
+		})
+	}
+}
+
+func TestValidateDefinitionRevisionWithResult(t *testing.T) {
+	existingRev, _, err := core.GatherRevisionInfo(newTraitDefinition("scaler", "default", "patch: replicas: 1"))
+	assert.NoError(t, err)
+	existingRev.Name = "scaler-v1"
+	existingRev.Namespace = "default"
+	// driftedRev has the same hash as the definition with replicas 3 but a different spec
+	driftedRev, _, err := core.GatherRevisionInfo(newTraitDefinition("scaler", "default", "patch: replicas: 3"))
+	assert.NoError(t, err)
+	driftedRev.Name = "scaler-v3"
+	driftedRev.Namespace = "default"
+	driftedRev.Spec.TraitDefinition.Spec.Schematic.CUE.Template = "patch: replicas: 4"
+	cli := fake.NewClientBuilder().WithScheme(pkgcommon.Scheme).WithObjects(existingRev, driftedRev).Build()
+	revKey := types.NamespacedName{Namespace: "default", Name: "scaler-v1"}
+
+	cases := map[string]struct {
+		def           *v1beta1.TraitDefinition
+		revKey        types.NamespacedName
+		allowMutation bool
+		want          *DefinitionRevisionValidationResult
+		wantErr       error
+		wantErrMsg    string
+	}{
+		"unchanged": {
+			def:    newTraitDefinition("scaler", "default", "patch: replicas: 1"),
+			revKey: revKey,
+			want:   &DefinitionRevisionValidationResult{},
+		},
+		"revisionNotExist": {
+			def:    newTraitDefinition("scaler", "default", "patch: replicas: 2"),
+			revKey: types.NamespacedName{Namespace: "default", Name: "scaler-v2"},
+			want:   &DefinitionRevisionValidationResult{},
+		},
+		"changed": {
+			def:        newTraitDefinition("scaler", "default", "patch: replicas: 2"),
+			revKey:     revKey,
+			want:       &DefinitionRevisionValidationResult{},
+			wantErr:    ErrRevisionHashMismatch,
+			wantErrMsg: "-     template: 'patch: replicas: 1'\n+     template: 'patch: replicas: 2'",
+		},
+		"cosmeticChange": {
+			def:    newTraitDefinition("scaler", "default", "// scale the workload\npatch: {\n\treplicas: 1\n}"),
+			revKey: revKey,
+			want:   &DefinitionRevisionValidationResult{},
+		},
+		"specDrifted": {
+			def:        newTraitDefinition("scaler", "default", "patch: replicas: 3"),
+			revKey:     types.NamespacedName{Namespace: "default", Name: "scaler-v3"},
+			want:       &DefinitionRevisionValidationResult{},
+			wantErr:    ErrRevisionSpecDrift,
+			wantErrMsg: "-     template: 'patch: replicas: 4'\n+     template: 'patch: replicas: 3'",
+		},
+		"invalidRevisionName": {
+			def:        newTraitDefinition("scaler", "default", "patch: replicas: 1"),
+			revKey:     types.NamespacedName{Namespace: "default", Name: "scaler-v1.0_"},
+			want:       &DefinitionRevisionValidationResult{},
+			wantErr:    ErrInvalidRevisionName,
+			wantErrMsg: "; did you mean 'scaler-v1.0'?",
+		},
+		"changedWithMutationDisallowed": {
+			def: func() *v1beta1.TraitDefinition {
+				td := newTraitDefinition("scaler", "default", "patch: replicas: 2")
+				td.Annotations = map[string]string{oam.AnnotationAllowDefinitionRevisionMutation: "false"}
+				return td
+			}(),
+			revKey:        revKey,
+			allowMutation: true,
+			want:          &DefinitionRevisionValidationResult{},
+			wantErr:       ErrRevisionHashMismatch,
+		},
+		"changedWithMutationNotEnabled": {
+			def: func() *v1beta1.TraitDefinition {
+				td := newTraitDefinition("scaler", "default", "patch: replicas: 2")
+				td.Annotations = map[string]string{oam.AnnotationAllowDefinitionRevisionMutation: "true"}
+				return td
+			}(),
+			revKey:  revKey,
+			want:    &DefinitionRevisionValidationResult{},
+			wantErr: ErrRevisionHashMismatch,
+		},
+		"changedWithMutationAllowed": {
+			def: func() *v1beta1.TraitDefinition {
+				td := newTraitDefinition("scaler", "default", "patch: replicas: 2")
+				td.Annotations = map[string]string{oam.AnnotationAllowDefinitionRevisionMutation: "true"}
+				return td
+			}(),
+			revKey:        revKey,
+			allowMutation: true,
+			want: &DefinitionRevisionValidationResult{
+				MutationAllowed: true,
+				Warnings: []string{"definitionRevision scaler-v1 is allowed to be modified by annotation " +
+					"definitionrevision.oam.dev/allow-mutation, the immutability check is skipped"},
+			},
+		},
+	}
+	defer func(allow bool) { AllowDefinitionRevisionMutation = allow }(AllowDefinitionRevisionMutation)
+	for caseName, cs := range cases {
+		t.Run(caseName, func(t *testing.T) {
+			AllowDefinitionRevisionMutation = cs.allowMutation
+			result, err := ValidateDefinitionRevisionWithResult(context.Background(), cli, cs.def, cs.revKey)
+			if cs.wantErr != nil {
+				assert.ErrorIs(t, err, cs.wantErr)
+				assert.Contains(t, err.Error(), cs.wantErrMsg)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, cs.want, result)
+		})
+	}
+}
+
+func TestValidateDefinitionRevisionWithOptions(t *testing.T) {
+	traitDef := func(template string, appliesTo ...string) *v1beta1.TraitDefinition {
+		td := newTraitDefinition("scaler", "default", template)
+		td.Spec.AppliesToWorkloads = appliesTo
+		return td
+	}
+	withExtension := func(td *v1beta1.TraitDefinition) *v1beta1.TraitDefinition {
+		td.Spec.Extension = &runtime.RawExtension{Raw: []byte(`{"docs":"https://kubevela.io"}`)}
+		return td
+	}
+	existingRev, _, err := core.GatherRevisionInfo(traitDef("patch: replicas: 1", "deployments.apps"))
+	assert.NoError(t, err)
+	existingRev.Name = "scaler-v1"
+	existingRev.Namespace = "default"
+	cli := fake.NewClientBuilder().WithScheme(pkgcommon.Scheme).WithObjects(existingRev).Build()
+	revKey := types.NamespacedName{Namespace: "default", Name: "scaler-v1"}
+
+	cases := map[string]struct {
+		def     *v1beta1.TraitDefinition
+		revName string
+		opts    DefinitionRevisionValidationOptions
+		wantErr error
+	}{
+		"strictByDefault": {
+			def:     traitDef("patch: replicas: 1", "deployments.apps", "statefulsets.apps"),
+			wantErr: ErrRevisionHashMismatch,
+		},
+		"ignoredFieldChanged": {
+			def:  traitDef("patch: replicas: 1", "deployments.apps", "statefulsets.apps"),
+			opts: DefinitionRevisionValidationOptions{IgnoredFields: []string{"appliesToWorkloads"}},
+		},
+		"otherFieldChanged": {
+			def:     traitDef("patch: replicas: 2", "deployments.apps", "statefulsets.apps"),
+			opts:    DefinitionRevisionValidationOptions{IgnoredFields: []string{"appliesToWorkloads"}},
+			wantErr: ErrRevisionHashMismatch,
+		},
+		"nestedIgnoredField": {
+			def:  traitDef("patch: replicas: 2", "deployments.apps"),
+			opts: DefinitionRevisionValidationOptions{IgnoredFields: []string{"schematic.cue.template"}},
+		},
+		"ignoredFieldNotExist": {
+			def:  traitDef("patch: replicas: 1", "deployments.apps"),
+			opts: DefinitionRevisionValidationOptions{IgnoredFields: []string{"status.customStatus"}},
+		},
+		"extensionChanged": {
+			def:     withExtension(traitDef("patch: replicas: 1", "deployments.apps")),
+			wantErr: ErrRevisionHashMismatch,
+		},
+		"nonFunctionalFieldChangedComparingFunctionalContent": {
+			def: func() *v1beta1.TraitDefinition {
+				td := traitDef("// the replicas\npatch: replicas: 1", "deployments.apps")
+				td.Spec.Version = "1.0.1"
+				return td
+			}(),
+			opts: DefinitionRevisionValidationOptions{CompareFunctionalContent: true},
+		},
+		"functionalContentChanged": {
+			def:     traitDef("patch: replicas: 2", "deployments.apps"),
+			opts:    DefinitionRevisionValidationOptions{CompareFunctionalContent: true},
+			wantErr: ErrRevisionHashMismatch,
+		},
+		// the extension may carry the legacy template of the definition, which changes its behavior
+		"extensionChangedComparingFunctionalContent": {
+			def:     withExtension(traitDef("patch: replicas: 1", "deployments.apps")),
+			opts:    DefinitionRevisionValidationOptions{CompareFunctionalContent: true},
+			wantErr: ErrRevisionHashMismatch,
+		},
+		"ignoredFieldChangedComparingFunctionalContent": {
+			def:  traitDef("patch: replicas: 1", "deployments.apps", "statefulsets.apps"),
+			opts: DefinitionRevisionValidationOptions{CompareFunctionalContent: true, IgnoredFields: []string{"appliesToWorkloads"}},
+		},
+		"notFoundIgnoredByDefault": {
+			def:     traitDef("patch: replicas: 1", "deployments.apps"),
+			revName: "scaler-v2",
+		},
+		"notFoundReported": {
+			def:     traitDef("patch: replicas: 1", "deployments.apps"),
+			revName: "scaler-v2",
+			opts:    DefinitionRevisionValidationOptions{ReportNotFound: true},
+			wantErr: ErrRevisionNotFound,
+		},
+		"existingNotReported": {
+			def:  traitDef("patch: replicas: 1", "deployments.apps"),
+			opts: DefinitionRevisionValidationOptions{ReportNotFound: true},
+		},
+	}
+	for caseName, cs := range cases {
+		t.Run(caseName, func(t *testing.T) {
+			key := revKey
+			if cs.revName != "" {
+				key.Name = cs.revName
+			}
+			_, err := ValidateDefinitionRevisionWithOptions(context.Background(), cli, cs.def, key, cs.opts)
+			if cs.wantErr != nil {
+				assert.ErrorIs(t, err, cs.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestValidateDefinitionRevisionRetry(t *testing.T) {
+	defer func(retries int, backoff wait.Backoff) {
+		DefinitionRevisionGetRetries, definitionRevisionGetBackoff = retries, backoff
+	}(DefinitionRevisionGetRetries, definitionRevisionGetBackoff)
+	definitionRevisionGetBackoff = wait.Backoff{Duration: time.Millisecond, Factor: 1}
+	def := &v1beta1.TraitDefinition{
+		TypeMeta:   metav1.TypeMeta{APIVersion: v1beta1.SchemeGroupVersion.String(), Kind: v1beta1.TraitDefinitionKind},
+		ObjectMeta: metav1.ObjectMeta{Name: "scaler", Namespace: "default"},
+		Spec: v1beta1.TraitDefinitionSpec{
+			Schematic: &common.Schematic{CUE: &common.CUE{Template: "patch: replicas: 1"}},
+		},
+	}
+	rev, _, err := core.GatherRevisionInfo(def)
+	assert.NoError(t, err)
+	rev.Name = "scaler-v1"
+	rev.Namespace = "default"
+	revKey := types.NamespacedName{Namespace: "default", Name: "scaler-v1"}
+	gr := v1beta1.SchemeGroupVersion.WithResource("definitionrevisions").GroupResource()
+
+	cases := map[string]struct {
+		retries   int
+		failures  []error
+		wantCalls int
+		wantErr   string
+	}{
+		"noFailure": {
+			retries:   3,
+			wantCalls: 1,
+		},
+		"serverTimeoutRetried": {
+			retries:   3,
+			failures:  []error{apierrors.NewServerTimeout(gr, "get", 1), apierrors.NewServerTimeout(gr, "get", 1)},
+			wantCalls: 3,
+		},
+		"tooManyRequestsRetried": {
+			retries:   3,
+			failures:  []error{apierrors.NewTooManyRequests("throttled", 1)},
+			wantCalls: 2,
+		},
+		"retriesExhausted": {
+			retries: 2,
+			failures: []error{apierrors.NewTooManyRequests("throttled", 1), apierrors.NewTooManyRequests("throttled", 1),
+				apierrors.NewTooManyRequests("throttled", 1)},
+			wantCalls: 3,
+			wantErr:   "throttled",
+		},
+		"retryDisabled": {
+			retries:   0,
+			failures:  []error{apierrors.NewServerTimeout(gr, "get", 1)},
+			wantCalls: 1,
+			wantErr:   "The get operation against definitionrevisions.core.oam.dev could not be completed at this time",
+		},
+		"notTransient": {
+			retries:   3,
+			failures:  []error{apierrors.NewInternalError(errors.New("boom"))},
+			wantCalls: 1,
+			wantErr:   "Internal error occurred: boom",
+		},
+		"notFoundNotRetried": {
+			retries:   3,
+			failures:  []error{apierrors.NewNotFound(gr, "scaler-v1")},
+			wantCalls: 1,
+		},
+	}
+	for caseName, cs := range cases {
+		t.Run(caseName, func(t *testing.T) {
+			DefinitionRevisionGetRetries = cs.retries
+			calls := 0
+			cli := fake.NewClientBuilder().WithScheme(pkgcommon.Scheme).WithObjects(rev.DeepCopy()).WithInterceptorFuncs(interceptor.Funcs{
+				Get: func(ctx context.Context, cli client.WithWatch, key client.ObjectKey, obj client.Object, opts ...client.GetOption) error {
+					calls++
+					if calls <= len(cs.failures) {
+						return cs.failures[calls-1]
+					}
+					return cli.Get(ctx, key, obj, opts...)
+				},
+			}).Build()
+			_, err := ValidateDefinitionRevisionWithOptions(context.Background(), cli, def, revKey, DefinitionRevisionValidationOptions{})
+			assert.Equal(t, cs.wantCalls, calls)
+			if cs.wantErr != "" {
+				assert.ErrorContains(t, err, cs.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestValidateDefinitionRevisionHashAlgorithm(t *testing.T) {
+	// legacyRev is created before the hash algorithm is recorded
+	legacyRev, _, err := core.GatherRevisionInfo(newTraitDefinition("scaler", "default", "patch: replicas: 1"))
+	assert.NoError(t, err)
+	legacyRev.Name = "scaler-v1"
+	legacyRev.Namespace = "default"
+	legacyRev.Spec.RevisionHashAlgorithm = ""
+	// futureRev is created by a newer version with an unknown hash algorithm
+	futureRev := legacyRev.DeepCopy()
+	futureRev.Name = "scaler-v2"
+	futureRev.Spec.RevisionHash = "unknown-hash"
+	futureRev.Spec.RevisionHashAlgorithm = "v99"
+	cli := fake.NewClientBuilder().WithScheme(pkgcommon.Scheme).WithObjects(legacyRev, futureRev).Build()
+
+	cases := map[string]struct {
+		def     *v1beta1.TraitDefinition
+		revName string
+		wantErr error
+	}{
+		"legacyUnchanged": {
+			def:     newTraitDefinition("scaler", "default", "patch: replicas: 1"),
+			revName: "scaler-v1",
+		},
+		"legacyChanged": {
+			def:     newTraitDefinition("scaler", "default", "patch: replicas: 2"),
+			revName: "scaler-v1",
+			wantErr: ErrRevisionHashMismatch,
+		},
+		"unknownAlgorithmUnchanged": {
+			def:     newTraitDefinition("scaler", "default", "patch: replicas: 1"),
+			revName: "scaler-v2",
+		},
+		"unknownAlgorithmCosmeticChange": {
+			def:     newTraitDefinition("scaler", "default", "patch: {\n\treplicas: 1\n}"),
+			revName: "scaler-v2",
+		},
+		"unknownAlgorithmChanged": {
+			def:     newTraitDefinition("scaler", "default", "patch: replicas: 2"),
+			revName: "scaler-v2",
+			wantErr: ErrRevisionHashMismatch,
+		},
+	}
+	for caseName, cs := range cases {
+		t.Run(caseName, func(t *testing.T) {
+			err := ValidateDefinitionRevision(context.Background(), cli, cs.def, types.NamespacedName{Namespace: "default", Name: cs.revName})
+			if cs.wantErr != nil {
+				assert.ErrorIs(t, err, cs.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestIsDefinitionSpecUnchanged(t *testing.T) {
+	stored := newTraitDefinition("scaler", "default", "patch: replicas: 1")
+
+	cases := map[string]struct {
+		def    *v1beta1.TraitDefinition
+		stored runtime.Object
+		want   bool
+	}{
+		"unchanged": {
+			def:    newTraitDefinition("scaler", "default", "patch: replicas: 1"),
+			stored: stored,
+			want:   true,
+		},
+		"metadataChanged": {
+			def: func() *v1beta1.TraitDefinition {
+				td := newTraitDefinition("scaler", "default", "patch: replicas: 1")
+				td.Labels = map[string]string{"team": "platform"}
+				return td
+			}(),
+			stored: stored,
+			want:   true,
+		},
+		"templateChanged": {
+			def:    newTraitDefinition("scaler", "default", "patch: replicas: 2"),
+			stored: stored,
+		},
+		"noStoredVersion": {
+			def: newTraitDefinition("scaler", "default", "patch: replicas: 1"),
+		},
+		"unsupportedStoredVersion": {
+			def:    newTraitDefinition("scaler", "default", "patch: replicas: 1"),
+			stored: &v1beta1.WorkloadDefinition{},
+		},
+	}
+	for caseName, cs := range cases {
+		t.Run(caseName, func(t *testing.T) {
+			assert.Equal(t, cs.want, IsDefinitionSpecUnchanged(cs.def, cs.stored))
+		})
+	}
+}
+
+func TestValidateDefinitionRevisionWithCache(t *testing.T) {
+	existingRev, _, err := core.GatherRevisionInfo(newTraitDefinition("scaler", "default", "patch: replicas: 1"))
+	assert.NoError(t, err)
+	revKey := types.NamespacedName{Namespace: "default", Name: "scaler-v1"}
+	revIndex := map[types.NamespacedName]*v1beta1.DefinitionRevision{revKey: existingRev}
+
+	cases := map[string]struct {
+		def     *v1beta1.TraitDefinition
+		revKey  types.NamespacedName
+		wantErr error
+	}{
+		"unchanged": {
+			def:    newTraitDefinition("scaler", "default", "patch: replicas: 1"),
+			revKey: revKey,
+		},
+		"changed": {
+			def:     newTraitDefinition("scaler", "default", "patch: replicas: 2"),
+			revKey:  revKey,
+			wantErr: ErrRevisionHashMismatch,
+		},
+		"notInIndex": {
+			def:    newTraitDefinition("scaler", "default", "patch: replicas: 2"),
+			revKey: types.NamespacedName{Namespace: "default", Name: "scaler-v2"},
+		},
+		"invalidRevisionName": {
+			def:     newTraitDefinition("scaler", "default", "patch: replicas: 1"),
+			revKey:  types.NamespacedName{Namespace: "default", Name: "scaler-v1.0_"},
+			wantErr: ErrInvalidRevisionName,
+		},
+	}
+	for caseName, cs := range cases {
+		t.Run(caseName, func(t *testing.T) {
+			err := ValidateDefinitionRevisionWithCache(context.Background(), revIndex, cs.def, cs.revKey)
+			if cs.wantErr != nil {
+				assert.ErrorIs(t, err, cs.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
@@ -0,0 +1,59 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	apimachineryvalidation "k8s.io/apimachinery/pkg/api/validation"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// DefinitionMaxBytes is the max size in bytes of a definition serialized for the storage, the larger definitions are
+// rejected as etcd refuses the objects over its request size limit, 1.5MiB by default. 0 disables the limit.
+var DefinitionMaxBytes = 1 << 20
+
+// ValidateDefinitionSize estimates the size of the definition when it's stored by its JSON serialization, which
+// includes the template and the annotations, e.g. the last-applied-configuration of kubectl duplicating the whole
+// definition, and checks it against DefinitionMaxBytes. The annotations are also checked against the limit of the
+// API server on their total size.
+// ErrDefinitionTooLarge is returned if either limit is exceeded.
+func ValidateDefinitionSize(obj runtime.Object) error {
+	if accessor, err := meta.Accessor(obj); err == nil {
+		annotationsSize := 0
+		for k, v := range accessor.GetAnnotations() {
+			annotationsSize += len(k) + len(v)
+		}
+		if annotationsSize > apimachineryvalidation.TotalAnnotationSizeLimitB {
+			return fmt.Errorf("%w: annotations of %d bytes exceed the limit %d", ErrDefinitionTooLarge,
+				annotationsSize, apimachineryvalidation.TotalAnnotationSizeLimitB)
+		}
+	}
+	if DefinitionMaxBytes <= 0 {
+		return nil
+	}
+	data, err := json.Marshal(obj)
+	if err != nil {
+		return fmt.Errorf("failed to serialize the definition: %w", err)
+	}
+	if len(data) > DefinitionMaxBytes {
+		return fmt.Errorf("%w: %d bytes exceed the limit %d", ErrDefinitionTooLarge, len(data), DefinitionMaxBytes)
+	}
+	return nil
+}
@@ -0,0 +1,80 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/common"
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
+)
+
+func TestValidateDefinitionSize(t *testing.T) {
+	originMaxBytes := DefinitionMaxBytes
+	defer func() { DefinitionMaxBytes = originMaxBytes }()
+	def := func(template string, annotations map[string]string) *v1beta1.TraitDefinition {
+		return &v1beta1.TraitDefinition{
+			ObjectMeta: metav1.ObjectMeta{Name: "scaler", Annotations: annotations},
+			Spec: v1beta1.TraitDefinitionSpec{
+				Schematic: &common.Schematic{CUE: &common.CUE{Template: template}},
+			},
+		}
+	}
+
+	cases := map[string]struct {
+		def      *v1beta1.TraitDefinition
+		maxBytes int
+		wantErr  string
+	}{
+		"small": {
+			def:      def("patch: spec: replicas: 1", nil),
+			maxBytes: 1024,
+		},
+		"templateTooLarge": {
+			def:      def(strings.Repeat("a", 1024), nil),
+			maxBytes: 1024,
+			wantErr:  "definition too large for storage: 1164 bytes exceed the limit 1024",
+		},
+		"limitDisabled": {
+			def:      def(strings.Repeat("a", 1024), nil),
+			maxBytes: 0,
+		},
+		"annotationsTooLarge": {
+			def:      def("patch: spec: replicas: 1", map[string]string{"kubectl.kubernetes.io/last-applied-configuration": strings.Repeat("a", 256<<10)}),
+			maxBytes: 0,
+			wantErr:  "definition too large for storage: annotations of 262192 bytes exceed the limit 262144",
+		},
+	}
+
+	for caseName, cs := range cases {
+		t.Run(caseName, func(t *testing.T) {
+			DefinitionMaxBytes = cs.maxBytes
+			err := ValidateDefinitionSize(cs.def)
+			if cs.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.EqualError(t, err, cs.wantErr)
+			assert.True(t, errors.Is(err, ErrDefinitionTooLarge))
+		})
+	}
+}
@@ -0,0 +1,69 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// DeprecatedDefinitionFields are the deprecated fields of the definitions by their paths, e.g. spec.extension, and
+// the messages telling the replacements, e.g. "use spec.schematic". The definitions setting them are warned by
+// ValidateDefinition and the admission webhook rather than denied, so that the authors are nudged off them without
+// breaking the existing ones.
+var DeprecatedDefinitionFields = map[string]string{}
+
+// ValidateDeprecatedFields returns a warning for each field of deprecated set by the definition, in the order of the
+// paths. A field set to null is not set.
+func ValidateDeprecatedFields(def runtime.Object, deprecated map[string]string) ([]string, error) {
+	if len(deprecated) == 0 {
+		return nil, nil
+	}
+	obj, err := runtime.DefaultUnstructuredConverter.ToUnstructured(def)
+	if err != nil {
+		return nil, err
+	}
+	paths := make([]string, 0, len(deprecated))
+	for path := range deprecated {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	var warnings []string
+	for _, path := range paths {
+		val, found, err := unstructured.NestedFieldNoCopy(obj, strings.Split(path, ".")...)
+		if err != nil || !found || val == nil {
+			continue
+		}
+		if replacement := deprecated[path]; replacement != "" {
+			warnings = append(warnings, fmt.Sprintf("%s is deprecated; %s", path, replacement))
+		} else {
+			warnings = append(warnings, fmt.Sprintf("%s is deprecated", path))
+		}
+	}
+	return warnings, nil
+}
+
+// validateDeprecatedFields warns the fields of DeprecatedDefinitionFields set by the definition
+func validateDeprecatedFields(_ context.Context, _ client.Client, def runtime.Object) ([]string, error) {
+	return ValidateDeprecatedFields(def, DeprecatedDefinitionFields)
+}
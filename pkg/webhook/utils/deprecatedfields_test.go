@@ -0,0 +1,96 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	apicommon "github.com/oam-dev/kubevela/apis/core.oam.dev/common"
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
+	"github.com/oam-dev/kubevela/pkg/utils/common"
+)
+
+func TestValidateDeprecatedFields(t *testing.T) {
+	deprecated := map[string]string{
+		"spec.extension":     "use spec.schematic",
+		"spec.podDisruptive": "",
+		"spec.workload.type": "use spec.workload.definition",
+	}
+	traitDef := &v1beta1.TraitDefinition{
+		ObjectMeta: metav1.ObjectMeta{Name: "scaler", Namespace: "default"},
+		Spec: v1beta1.TraitDefinitionSpec{
+			PodDisruptive: true,
+			Extension:     &runtime.RawExtension{Raw: []byte(`{"template":"patch: {}"}`)},
+		},
+	}
+	componentDef := &v1beta1.ComponentDefinition{
+		ObjectMeta: metav1.ObjectMeta{Name: "worker", Namespace: "default"},
+		Spec: v1beta1.ComponentDefinitionSpec{
+			Schematic: &apicommon.Schematic{CUE: &apicommon.CUE{Template: "output: {}"}},
+		},
+	}
+
+	cases := map[string]struct {
+		def          runtime.Object
+		deprecated   map[string]string
+		wantWarnings []string
+	}{
+		"deprecatedFieldsSet": {
+			def:        traitDef,
+			deprecated: deprecated,
+			wantWarnings: []string{
+				"spec.extension is deprecated; use spec.schematic",
+				"spec.podDisruptive is deprecated",
+			},
+		},
+		"deprecatedFieldsNotSet": {
+			def:        componentDef,
+			deprecated: deprecated,
+		},
+		"noPolicy": {
+			def: traitDef,
+		},
+	}
+
+	for caseName, cs := range cases {
+		t.Run(caseName, func(t *testing.T) {
+			warnings, err := ValidateDeprecatedFields(cs.def, cs.deprecated)
+			assert.NoError(t, err)
+			assert.Equal(t, cs.wantWarnings, warnings)
+		})
+	}
+}
+
+func TestValidateDefinitionDeprecatedFields(t *testing.T) {
+	defer func(deprecated map[string]string) { DeprecatedDefinitionFields = deprecated }(DeprecatedDefinitionFields)
+	DeprecatedDefinitionFields = map[string]string{"spec.podDisruptive": "set the pod disruption by the patch instead"}
+	cli := fake.NewClientBuilder().WithScheme(common.Scheme).Build()
+	def := &v1beta1.TraitDefinition{
+		ObjectMeta: metav1.ObjectMeta{Name: "scaler", Namespace: "default"},
+		Spec:       v1beta1.TraitDefinitionSpec{PodDisruptive: true},
+	}
+
+	warnings, err := ValidateDefinitionWithWarnings(context.Background(), cli, def)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"spec.podDisruptive is deprecated; set the pod disruption by the patch instead"}, warnings)
+}
@@ -0,0 +1,125 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"fmt"
+	"strings"
+
+	cueErrors "cuelang.org/go/cue/errors"
+	"github.com/pkg/errors"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+)
+
+// FormatCueError renders every error of err followed by the lines of cueTemplate where it occurs, with a caret
+// pointing at the column, similar to the output of the cue CLI, e.g.
+//
+//	parameter.replicas: conflicting values int and "a" (mismatched types int and string):
+//	    2 | 	replicas: int & "a"
+//	      | 	          ^
+//
+// The positions are taken from the cue errors or CueValidationError, the errors without a position inside the
+// template are rendered with the message only.
+func FormatCueError(cueTemplate string, err error) string {
+	if err == nil {
+		return ""
+	}
+	lines := strings.Split(cueTemplate, "\n")
+	var sb strings.Builder
+	for i, e := range flattenErrors(err) {
+		if i > 0 {
+			sb.WriteString("\n")
+		}
+		msg, positions := messageAndPositionsOf(e)
+		sb.WriteString(msg)
+		var snippets []string
+		for _, pos := range positions {
+			if pos.line < 1 || pos.line > len(lines) {
+				continue
+			}
+			snippets = append(snippets, formatSourceSnippet(lines[pos.line-1], pos.line, pos.column))
+		}
+		if len(snippets) != 0 {
+			sb.WriteString(":\n")
+			sb.WriteString(strings.Join(snippets, "\n"))
+		}
+	}
+	return sb.String()
+}
+
+// sourcePosition is the 1-based line and column of an error in the template
+type sourcePosition struct {
+	line   int
+	column int
+}
+
+// flattenErrors splits the aggregated and the cue list errors into single ones
+func flattenErrors(err error) []error {
+	var agg utilerrors.Aggregate
+	if errors.As(err, &agg) {
+		var errs []error
+		for _, e := range agg.Errors() {
+			errs = append(errs, flattenErrors(e)...)
+		}
+		return errs
+	}
+	var cueErr cueErrors.Error
+	if errors.As(err, &cueErr) {
+		var errs []error
+		for _, e := range cueErrors.Errors(cueErr) {
+			errs = append(errs, e)
+		}
+		return errs
+	}
+	return []error{err}
+}
+
+func messageAndPositionsOf(err error) (string, []sourcePosition) {
+	var ve CueValidationError
+	if errors.As(err, &ve) {
+		if ve.Line == 0 {
+			return ve.Message, nil
+		}
+		return ve.Message, []sourcePosition{{line: ve.Line, column: ve.Column}}
+	}
+	var positions []sourcePosition
+	seen := map[sourcePosition]bool{}
+	for _, pos := range cueErrors.Positions(err) {
+		sp := sourcePosition{line: pos.Line(), column: pos.Column()}
+		if !pos.IsValid() || seen[sp] {
+			continue
+		}
+		seen[sp] = true
+		positions = append(positions, sp)
+	}
+	return err.Error(), positions
+}
+
+// formatSourceSnippet renders the source line with its number and a caret under the column. The tabs before the
+// column are kept so that the caret is aligned with the source however the tabs are displayed.
+func formatSourceSnippet(line string, lineNumber, column int) string {
+	gutter := fmt.Sprintf("%5d | ", lineNumber)
+	var indent strings.Builder
+	for i := 0; i < column-1 && i < len(line); i++ {
+		if line[i] == '\t' {
+			indent.WriteByte('\t')
+		} else {
+			indent.WriteByte(' ')
+		}
+	}
+	return gutter + line + "\n" + strings.Repeat(" ", len(gutter)-2) + "| " + indent.String() + "^"
+}
@@ -0,0 +1,91 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"testing"
+
+	"cuelang.org/go/cue/cuecontext"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+)
+
+func TestFormatCueError(t *testing.T) {
+	template := "parameter: {\n\treplicas: int\n}\noutput: {\n\tspec: replicas: parameter.replica\n}\n"
+	cueErr := cuecontext.New().CompileString("a: 1\nb: a.c\n").Validate()
+
+	cases := map[string]struct {
+		template string
+		err      error
+		want     string
+	}{
+		"nil": {
+			template: template,
+		},
+		"validationError": {
+			template: template,
+			err:      CueValidationError{Message: "output.spec.replicas: undefined field: replica", Line: 5, Column: 27},
+			want: "output.spec.replicas: undefined field: replica:\n" +
+				"    5 | \tspec: replicas: parameter.replica\n" +
+				"      | \t                         ^",
+		},
+		"aggregatedErrors": {
+			template: template,
+			err: utilerrors.NewAggregate([]error{
+				CueValidationError{Message: "first", Line: 1, Column: 1},
+				CueValidationError{Message: "second", Line: 2, Column: 2},
+			}),
+			want: "first:\n" +
+				"    1 | parameter: {\n" +
+				"      | ^\n" +
+				"second:\n" +
+				"    2 | \treplicas: int\n" +
+				"      | \t^",
+		},
+		"cueError": {
+			template: "a: 1\nb: a.c\n",
+			err:      cueErr,
+			want: "b: invalid operand a (found int, want list or struct):\n" +
+				"    2 | b: a.c\n" +
+				"      |    ^",
+		},
+		"positionOutsideTemplate": {
+			template: template,
+			err:      CueValidationError{Message: "context.name: reference not found", Line: 20, Column: 1},
+			want:     "context.name: reference not found",
+		},
+		"withoutPosition": {
+			template: template,
+			err:      errors.New("invalid template"),
+			want:     "invalid template",
+		},
+	}
+	for caseName, cs := range cases {
+		t.Run(caseName, func(t *testing.T) {
+			assert.Equal(t, cs.want, FormatCueError(cs.template, cs.err))
+		})
+	}
+}
+
+func TestValidationResultErr(t *testing.T) {
+	assert.NoError(t, (&ValidationResult{}).Err())
+	first := CueValidationError{Message: "first", Line: 1, Column: 1}
+	second := CueValidationError{Message: "second", Line: 2, Column: 2}
+	assert.Equal(t, first, (&ValidationResult{Errors: []CueValidationError{first}}).Err())
+	assert.EqualError(t, (&ValidationResult{Errors: []CueValidationError{first, second}}).Err(), "[line 1: first, line 2: second]")
+}
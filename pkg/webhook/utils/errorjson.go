@@ -0,0 +1,128 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"encoding/json"
+	"regexp"
+	"strings"
+
+	cueErrors "cuelang.org/go/cue/errors"
+	"github.com/pkg/errors"
+)
+
+// The kinds of the validation errors in ValidationErrorDetail
+const (
+	// ValidationErrorKindCue is the error of the cue template, e.g. a conflict or an invalid default
+	ValidationErrorKindCue = "CueError"
+	// ValidationErrorKindImportCycle is the CycleError of the imports of the cue template
+	ValidationErrorKindImportCycle = "ImportCycle"
+	// ValidationErrorKindSelfReference is the SelfReferenceError of the definition importing itself
+	ValidationErrorKindSelfReference = "SelfReference"
+	// ValidationErrorKindGeneric is the other errors raised by the validation
+	ValidationErrorKindGeneric = "Validation"
+)
+
+// ValidationErrorDetail is the machine-readable form of a single validation error
+type ValidationErrorDetail struct {
+	// Code is the code of the message of the error, empty if the error has no code, see ErrorMessageCode
+	Code MessageCode `json:"code,omitempty"`
+	// Kind is the kind of the error, one of the ValidationErrorKind constants
+	Kind string `json:"kind"`
+	// Field is the dot separated path of the field in the cue template where the error occurs
+	Field string `json:"field,omitempty"`
+	// Message is the message of the error in English
+	Message string `json:"message"`
+	// Suggestion is the fix proposed by the message, e.g. the nearest definition name
+	Suggestion string `json:"suggestion,omitempty"`
+	// Line and Column are the 1-based position of the error in the cue template, zero if it's unknown
+	Line   int `json:"line,omitempty"`
+	Column int `json:"column,omitempty"`
+}
+
+// validationErrorDocument is the JSON document produced by MarshalValidationError
+type validationErrorDocument struct {
+	Errors []ValidationErrorDetail `json:"errors"`
+}
+
+// cueFieldRegex matches the path of the field prefixing the messages of the cue errors, e.g. "parameter.image: "
+var cueFieldRegex = regexp.MustCompile(`^([A-Za-z_#$][\w#$-]*(?:\.[A-Za-z_#$"][\w#$"-]*)*): `)
+
+// suggestionRegex matches the suggestion appended to the messages, e.g. ", did you mean webservice?"
+var suggestionRegex = regexp.MustCompile(`did you mean '?([^'?\s]+)'?\?`)
+
+// ValidationErrorDetailsOf splits the error returned by the validation into the single errors and describes each of
+// them, the aggregated errors and the lists of cue errors are flattened. It returns nil for a nil error.
+func ValidationErrorDetailsOf(err error) []ValidationErrorDetail {
+	if err == nil {
+		return nil
+	}
+	var details []ValidationErrorDetail
+	for _, e := range flattenErrors(err) {
+		details = append(details, validationErrorDetailOf(e))
+	}
+	return details
+}
+
+// MarshalValidationError serializes the error returned by the validation into a stable JSON document like
+// {"errors": [{"code": ..., "kind": ..., "field": ..., "message": ..., "suggestion": ...}]}, so that the rejection
+// of the webhook can be consumed by machines along with its plaintext message. A nil error has no errors.
+func MarshalValidationError(err error) ([]byte, error) {
+	details := ValidationErrorDetailsOf(err)
+	if details == nil {
+		details = []ValidationErrorDetail{}
+	}
+	return json.Marshal(validationErrorDocument{Errors: details})
+}
+
+func validationErrorDetailOf(err error) ValidationErrorDetail {
+	detail := ValidationErrorDetail{
+		Code:    ErrorMessageCode(err),
+		Kind:    ValidationErrorKindGeneric,
+		Message: err.Error(),
+	}
+	var cycleErr *CycleError
+	var selfRefErr *SelfReferenceError
+	var ve CueValidationError
+	var cueErr cueErrors.Error
+	switch {
+	case errors.As(err, &cycleErr):
+		detail.Kind = ValidationErrorKindImportCycle
+	case errors.As(err, &selfRefErr):
+		detail.Kind = ValidationErrorKindSelfReference
+	case errors.As(err, &ve):
+		detail.Kind = ValidationErrorKindCue
+		detail.Message, detail.Line, detail.Column = ve.Message, ve.Line, ve.Column
+	case errors.As(err, &cueErr):
+		detail.Kind = ValidationErrorKindCue
+		detail.Field = strings.Join(cueErr.Path(), ".")
+		if _, positions := messageAndPositionsOf(cueErr); len(positions) != 0 {
+			detail.Line, detail.Column = positions[0].line, positions[0].column
+		}
+	}
+	if m := cueFieldRegex.FindStringSubmatch(detail.Message); m != nil && detail.Field == "" {
+		detail.Field = m[1]
+		// the cue errors collected by the validation keep the message only
+		if detail.Kind == ValidationErrorKindGeneric && detail.Code == "" {
+			detail.Kind = ValidationErrorKindCue
+		}
+	}
+	if m := suggestionRegex.FindStringSubmatch(detail.Message); m != nil {
+		detail.Suggestion = m[1]
+	}
+	return detail
+}
@@ -0,0 +1,86 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"fmt"
+	"testing"
+
+	"cuelang.org/go/cue/cuecontext"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+)
+
+func TestMarshalValidationError(t *testing.T) {
+	cueErr := ValidateCueTemplate("parameter: {\n\treplicas: int & \"a\"\n}")
+	require.Error(t, cueErr)
+	rawCueErr := cuecontext.New().CompileString("parameter: {\n\treplicas: int & \"a\"\n}").Validate()
+	require.Error(t, rawCueErr)
+
+	cases := map[string]struct {
+		err  error
+		want string
+	}{
+		"nil": {
+			err:  nil,
+			want: `{"errors":[]}`,
+		},
+		"sentinel": {
+			err:  fmt.Errorf("%w: 1.0.0 and 2.0.0", ErrVersionConflict),
+			want: `{"errors":[{"code":"VersionConflict","kind":"Validation","message":"conflicting versions of the definition: 1.0.0 and 2.0.0"}]}`,
+		},
+		"suggestion": {
+			err:  fmt.Errorf("component api references ComponentDefinition webservic that is not found, did you mean webservice?"),
+			want: `{"errors":[{"kind":"Validation","message":"component api references ComponentDefinition webservic that is not found, did you mean webservice?","suggestion":"webservice"}]}`,
+		},
+		"quotedSuggestion": {
+			err:  fmt.Errorf("%w My_Def:a lowercase name; did you mean 'my-def'?", ErrInvalidRevisionName),
+			want: `{"errors":[{"code":"InvalidRevisionName","kind":"Validation","message":"invalid definitionRevision name My_Def:a lowercase name; did you mean 'my-def'?","suggestion":"my-def"}]}`,
+		},
+		"cue": {
+			err:  cueErr,
+			want: `{"errors":[{"kind":"CueError","field":"parameter.replicas","message":"parameter.replicas: conflicting values int and \"a\" (mismatched types int and string)"}]}`,
+		},
+		"rawCue": {
+			err:  rawCueErr,
+			want: `{"errors":[{"kind":"CueError","field":"parameter.replicas","message":"parameter.replicas: conflicting values int and \"a\" (mismatched types int and string)","line":2,"column":12}]}`,
+		},
+		"cueValidationError": {
+			err:  CueValidationError{Message: "parameter.image: invalid default", Line: 3, Column: 9},
+			want: `{"errors":[{"kind":"CueError","field":"parameter.image","message":"parameter.image: invalid default","line":3,"column":9}]}`,
+		},
+		"forbiddenFunction": {
+			err:  fmt.Errorf("apply: %w: kube.apply", ErrForbiddenFunction),
+			want: `{"errors":[{"code":"ForbiddenFunction","kind":"Validation","field":"apply","message":"apply: forbidden function: kube.apply"}]}`,
+		},
+		"aggregated": {
+			err: utilerrors.NewAggregate([]error{
+				&CycleError{Cycle: []string{"a", "b", "a"}},
+				&SelfReferenceError{Name: "a"},
+			}),
+			want: `{"errors":[{"kind":"ImportCycle","message":"import cycle detected: a imports b imports a"},{"kind":"SelfReference","message":"definition a references itself"}]}`,
+		},
+	}
+	for caseName, cs := range cases {
+		t.Run(caseName, func(t *testing.T) {
+			bs, err := MarshalValidationError(cs.err)
+			assert.NoError(t, err)
+			assert.JSONEq(t, cs.want, string(bs))
+		})
+	}
+}
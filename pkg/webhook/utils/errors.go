@@ -0,0 +1,128 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"github.com/pkg/errors"
+)
+
+var (
+	// ErrInvalidRevisionName means the name of the definitionRevision is not a qualified name
+	ErrInvalidRevisionName = errors.New("invalid definitionRevision name")
+
+	// ErrInvalidRevisionNameAnnotation means the revision name annotation of the definition is not of the expected format
+	ErrInvalidRevisionNameAnnotation = errors.New("invalid revision name annotation")
+
+	// ErrRevisionNotFound means the definitionRevision to validate against doesn't exist
+	ErrRevisionNotFound = errors.New("definitionRevision not found")
+
+	// ErrRevisionSpecDrift means the definition's spec is different with the existing definitionRevision's spec
+	ErrRevisionSpecDrift = errors.New("the definition's spec is different with existing definitionRevision's spec")
+
+	// ErrRevisionHashMismatch means the revision hash of the definition is different with the existing definitionRevision's,
+	// it keeps the message of the spec comparison and is told apart from ErrRevisionSpecDrift by errors.Is
+	ErrRevisionHashMismatch = errors.New("the definition's spec is different with existing definitionRevision's spec")
+
+	// ErrTemplateTooComplex means the cue template exceeds the complexity budget or its evaluation times out
+	ErrTemplateTooComplex = errors.New("template exceeds complexity limit")
+
+	// ErrTemplateTooLarge means the size of the cue template exceeds CueTemplateMaxBytes
+	ErrTemplateTooLarge = errors.New("template too large")
+
+	// ErrDefinitionTooLarge means the serialized definition or its annotations exceed the size limits of the storage
+	ErrDefinitionTooLarge = errors.New("definition too large for storage")
+
+	// ErrImportTooDeep means the imports of the cue template are nested deeper than CueImportMaxDepth
+	ErrImportTooDeep = errors.New("imports of the template are nested too deep")
+
+	// ErrForbiddenFunction means the cue template calls a function in the denylist of its namespace
+	ErrForbiddenFunction = errors.New("forbidden function")
+
+	// ErrUnrecognizedTemplate means the cue template is neither an inline template nor a definition file wrapping it
+	// under the template field
+	ErrUnrecognizedTemplate = errors.New("unrecognized template form")
+
+	// ErrValidationPanic means the validation panicked, e.g. the cue compiler on a malformed template
+	ErrValidationPanic = errors.New("the validation panicked")
+
+	// ErrUnsupportedSchematic means none of the schematic types supported by the validation is set
+	ErrUnsupportedSchematic = errors.New("unsupported schematic type")
+
+	// ErrConcreteEvaluation means the output of the cue template fails when it's evaluated concretely as the rendering
+	// does, although the template itself is valid
+	ErrConcreteEvaluation = errors.New("the output fails under concrete evaluation")
+
+	// ErrOutputNotObject means the output of the component template is not a Kubernetes object with apiVersion and kind
+	ErrOutputNotObject = errors.New("output is missing concrete apiVersion/kind")
+
+	// ErrDefaultCycle means the default values of the fields of the parameter reference each other in a cycle
+	ErrDefaultCycle = errors.New("default values of the parameter form a cycle")
+
+	// ErrInvalidVersion means the version of the definition is not a valid SemVer 2.0.0 version
+	ErrInvalidVersion = errors.New("Not a valid version")
+
+	// ErrInvalidVersionConstraint means the version range expression is not valid
+	ErrInvalidVersionConstraint = errors.New("Not a valid version constraint")
+
+	// ErrVersionConflict means the versions declared by the definition conflict with each other
+	ErrVersionConflict = errors.New("conflicting versions of the definition")
+
+	// ErrVersionNotIncreasing means the version of the definition is not greater than its latest published version
+	ErrVersionNotIncreasing = errors.New("version of the definition is not increasing")
+
+	// ErrInvalidStepCondition means the if condition of the workflow step is not a boolean cue expression
+	ErrInvalidStepCondition = errors.New("invalid if condition")
+
+	// ErrPackageTooOld means the version of the CueX package imported by the template is older than its minimum
+	ErrPackageTooOld = errors.New("older than required")
+
+	// ErrDefinitionNameCollision means the definition has both the versioned DefinitionRevisions and the ones without
+	// a version, so that the revisions referenced by name@vN are ambiguous
+	ErrDefinitionNameCollision = errors.New("definition is both versioned and unversioned")
+
+	// ErrMissingRequiredMetadata means the definition lacks some of the labels or the annotations required by
+	// DefinitionRequiredMetadata
+	ErrMissingRequiredMetadata = errors.New("missing required metadata")
+
+	// ErrTooManyTraits means a component of the application has more traits than ComponentMaxTraits
+	ErrTooManyTraits = errors.New("too many traits")
+
+	// ErrMissingChangelog means the definition bumps its version without the changelog annotation required by
+	// RequireVersionChangelog
+	ErrMissingChangelog = errors.New("missing changelog")
+)
+
+// sentinelError keeps the message of the error while making it match the sentinel by errors.Is
+type sentinelError struct {
+	error
+	sentinel error
+}
+
+// Is reports whether the target is the sentinel of the error
+func (e sentinelError) Is(target error) bool {
+	return target == e.sentinel
+}
+
+// Unwrap returns the error marked
+func (e sentinelError) Unwrap() error {
+	return e.error
+}
+
+// withSentinel marks the error with the sentinel without changing its message
+func withSentinel(err, sentinel error) error {
+	return sentinelError{error: err, sentinel: sentinel}
+}
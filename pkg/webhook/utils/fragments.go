@@ -0,0 +1,103 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"cuelang.org/go/cue/ast"
+	"cuelang.org/go/cue/build"
+	"cuelang.org/go/cue/cuecontext"
+	cueErrors "cuelang.org/go/cue/errors"
+	"cuelang.org/go/cue/parser"
+)
+
+const (
+	fragmentFilenamePrefix = "fragment-"
+	fragmentPackageName    = "fragment"
+)
+
+// ValidateCueTemplateMerged validates the template merged from the fragments, e.g. a base template and the overlays
+// applied on it. The fragments are unified in order as the files of one package, so they can reference the fields of
+// each other. Each error is prefixed by the 1-based index of the fragment introducing it, which is the last fragment
+// involved in the error. The references to context are resolved by a stub, so they never fail the validation.
+func ValidateCueTemplateMerged(fragments ...string) error {
+	bi := build.NewContext().NewInstance("", nil)
+	for i, fragment := range fragments {
+		f, err := parser.ParseFile(fragmentFilename(i), fragment, parser.ParseComments)
+		if err != nil {
+			return fmt.Errorf("fragment %d: %w", i+1, checkError(err))
+		}
+		if f.PackageName() == "" {
+			// the files are only unified in the same package
+			f.Decls = append([]ast.Decl{&ast.Package{Name: ast.NewIdent(fragmentPackageName)}}, f.Decls...)
+		}
+		if err = bi.AddSyntax(f); err != nil {
+			return fmt.Errorf("fragment %d: %w", i+1, err)
+		}
+	}
+	stub, err := parser.ParseFile("context-stub", "package "+fragmentPackageName+contextStub)
+	if err != nil {
+		return err
+	}
+	if err = bi.AddSyntax(stub); err != nil {
+		return err
+	}
+	val := cuecontext.New().BuildInstance(bi)
+	err = val.Err()
+	if err == nil {
+		err = val.Validate()
+	}
+	return fragmentErrors(err)
+}
+
+func fragmentFilename(i int) string {
+	return fragmentFilenamePrefix + strconv.Itoa(i)
+}
+
+// fragmentErrors prefixes the cue errors by the fragment introducing them
+func fragmentErrors(err error) error {
+	if err == nil {
+		return nil
+	}
+	var errs []error
+	for _, e := range cueErrors.Errors(err) {
+		if i := lastFragmentOf(e); i >= 0 {
+			errs = append(errs, fmt.Errorf("fragment %d: %s", i+1, e.Error()))
+			continue
+		}
+		errs = append(errs, cueErrors.New(e.Error()))
+	}
+	return aggregateErrors(errs)
+}
+
+// lastFragmentOf returns the index of the last fragment in the positions of the error, or -1 if there is none
+func lastFragmentOf(e cueErrors.Error) int {
+	last := -1
+	for _, pos := range cueErrors.Positions(e) {
+		name, ok := strings.CutPrefix(pos.Filename(), fragmentFilenamePrefix)
+		if !ok {
+			continue
+		}
+		if i, err := strconv.Atoi(name); err == nil && i > last {
+			last = i
+		}
+	}
+	return last
+}
@@ -0,0 +1,72 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateCueTemplateMerged(t *testing.T) {
+	base := `
+output: {
+	apiVersion: "apps/v1"
+	kind:       "Deployment"
+	metadata: name: context.name
+	spec: replicas: parameter.replicas
+}
+parameter: replicas: *1 | int`
+
+	cases := map[string]struct {
+		fragments []string
+		wantErr   string
+	}{
+		"noFragment": {},
+		"compatibleOverlay": {
+			fragments: []string{base, `
+output: spec: template: metadata: labels: app: context.name
+parameter: replicas: >0`},
+		},
+		"crossFragmentReference": {
+			fragments: []string{base, `output: metadata: labels: version: parameter.version`, `parameter: version: *"v1" | string`},
+		},
+		"conflictingOverlay": {
+			fragments: []string{base, `output: kind: "StatefulSet"`},
+			wantErr:   "fragment 2: output.kind: conflicting values \"StatefulSet\" and \"Deployment\"",
+		},
+		"invalidOverlay": {
+			fragments: []string{base, `output: metadata: labels: app: appName`},
+			wantErr:   "fragment 2: output.metadata.labels.app: reference \"appName\" not found",
+		},
+		"syntaxError": {
+			fragments: []string{base, `output: {`},
+			wantErr:   "fragment 2: expected '}', found 'EOF'",
+		},
+	}
+
+	for caseName, cs := range cases {
+		t.Run(caseName, func(t *testing.T) {
+			err := ValidateCueTemplateMerged(cs.fragments...)
+			if cs.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.EqualError(t, err, cs.wantErr)
+		})
+	}
+}
@@ -0,0 +1,147 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"fmt"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+
+	"cuelang.org/go/cue"
+	"cuelang.org/go/cue/ast"
+	"cuelang.org/go/cue/parser"
+	cueutil "github.com/kubevela/pkg/cue/util"
+
+	"github.com/oam-dev/kubevela/pkg/oam"
+)
+
+// DefaultCueFunctionDenylist is the functions forbidden for the namespaces without a denylist, nothing is forbidden
+// by default
+var DefaultCueFunctionDenylist []string
+
+var (
+	cueFunctionDenylists     = map[string][]string{}
+	cueFunctionDenylistsLock sync.RWMutex
+)
+
+// SetCueFunctionDenylist sets the functions forbidden in the cue templates of the definitions in the namespace,
+// a nil denylist restores the default one. An entry is either a CueX provider function like "kube.apply", or the
+// member of an imported package like "tool/exec.Run", and an entry without the function, e.g. "kube" or
+// "tool/exec", forbids all the functions of the provider or the package.
+func SetCueFunctionDenylist(namespace string, functions []string) {
+	cueFunctionDenylistsLock.Lock()
+	defer cueFunctionDenylistsLock.Unlock()
+	if functions == nil {
+		delete(cueFunctionDenylists, namespace)
+		return
+	}
+	cueFunctionDenylists[namespace] = functions
+}
+
+// CueFunctionDenylistOf returns the functions forbidden in the namespace, DefaultCueFunctionDenylist is used if
+// the namespace has no denylist. The system definition namespace is not restricted unless a denylist is set for it.
+func CueFunctionDenylistOf(namespace string) []string {
+	cueFunctionDenylistsLock.RLock()
+	defer cueFunctionDenylistsLock.RUnlock()
+	if functions, ok := cueFunctionDenylists[namespace]; ok {
+		return functions
+	}
+	if namespace == oam.SystemDefinitionNamespace {
+		return nil
+	}
+	return DefaultCueFunctionDenylist
+}
+
+// checkImportedFunctionDenylist returns an error for each reference of the cue template to a forbidden member of an
+// imported package, e.g. exec.Run of "tool/exec"
+func checkImportedFunctionDenylist(cueTemplate string, denylist []string) error {
+	if len(denylist) == 0 {
+		return nil
+	}
+	f, err := parser.ParseFile("-", cueTemplate)
+	if err != nil {
+		// leave the syntax error to the compiler
+		return nil
+	}
+	packages := map[string]string{}
+	for _, spec := range f.Imports {
+		importPath, err := strconv.Unquote(spec.Path.Value)
+		if err != nil {
+			continue
+		}
+		name := path.Base(importPath)
+		if spec.Name != nil {
+			name = spec.Name.Name
+		}
+		packages[name] = importPath
+	}
+	var errs []error
+	ast.Walk(f, func(node ast.Node) bool {
+		sel, ok := node.(*ast.SelectorExpr)
+		if !ok {
+			return true
+		}
+		pkg, ok := sel.X.(*ast.Ident)
+		if !ok {
+			return true
+		}
+		importPath, ok := packages[pkg.Name]
+		if !ok {
+			return true
+		}
+		member, _, _ := ast.LabelName(sel.Sel)
+		if fn := importPath + "." + member; isFunctionDenied(fn, denylist) {
+			errs = append(errs, fmt.Errorf("%w: %s at %d:%d", ErrForbiddenFunction, fn, sel.Pos().Line(), sel.Pos().Column()))
+		}
+		return true
+	}, nil)
+	return aggregateErrors(errs)
+}
+
+// checkProviderFunctionDenylist finds the provider function calls in the value the same way as CueX resolves them,
+// and returns an error for each call to a forbidden function
+func checkProviderFunctionDenylist(val cue.Value, denylist []string) error {
+	if len(denylist) == 0 {
+		return nil
+	}
+	var errs []error
+	cueutil.Iterate(val, func(v cue.Value) (stop bool) {
+		fn, _ := v.LookupPath(cue.ParsePath(providerFnKey)).String()
+		if fn == "" {
+			return false
+		}
+		name, _ := v.LookupPath(cue.ParsePath(providerKey)).String()
+		if call := name + "." + fn; isFunctionDenied(call, denylist) {
+			errs = append(errs, fmt.Errorf("%s: %w: %s", v.Path(), ErrForbiddenFunction, call))
+		}
+		return false
+	})
+	return aggregateErrors(errs)
+}
+
+// isFunctionDenied reports whether the function, qualified by its provider or package, is in the denylist by itself
+// or by its provider or package
+func isFunctionDenied(fn string, denylist []string) bool {
+	for _, denied := range denylist {
+		if fn == denied || strings.HasPrefix(fn, denied+".") {
+			return true
+		}
+	}
+	return false
+}
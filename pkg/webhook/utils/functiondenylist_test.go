@@ -0,0 +1,120 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/oam-dev/kubevela/pkg/oam"
+	"github.com/oam-dev/kubevela/pkg/oam/util"
+)
+
+func TestValidateCuexTemplateFunctionDenylist(t *testing.T) {
+	defer setFakeCuexCompiler()()
+	SetCueFunctionDenylist("tenant-a", []string{"kube.apply", "base64.decode", "http", "tool/exec", "tool/file.Create"})
+	defer SetCueFunctionDenylist("tenant-a", nil)
+
+	cases := map[string]struct {
+		namespace   string
+		cueTemplate string
+		wantErr     string
+	}{
+		"notDenied": {
+			namespace: "tenant-a",
+			cueTemplate: `
+import "vela/base64"
+
+encoded: base64.#Encode & {$params: "hello"}`,
+		},
+		"deniedProviderFunction": {
+			namespace: "tenant-a",
+			cueTemplate: `
+import "vela/kube"
+
+apply: kube.#Apply & {
+	$params: resource: {
+		apiVersion: "v1"
+		kind:       "ConfigMap"
+		metadata: name: "foo"
+	}
+}`,
+			wantErr: "apply: forbidden function: kube.apply",
+		},
+		"deniedProvider": {
+			namespace: "tenant-a",
+			cueTemplate: `
+req: {
+	#do:       "do"
+	#provider: "http"
+}`,
+			wantErr: "req: forbidden function: http.do",
+		},
+		"deniedPackage": {
+			namespace: "tenant-a",
+			cueTemplate: `
+import "tool/exec"
+
+run: exec.Run & {cmd: "ls"}`,
+			wantErr: "forbidden function: tool/exec.Run at 4:6",
+		},
+		"deniedPackageMemberWithAlias": {
+			namespace: "tenant-a",
+			cueTemplate: `
+import f "tool/file"
+
+read: f.Read & {filename: "foo"}
+write: f.Create & {filename: "foo", contents: "bar"}`,
+			wantErr: "forbidden function: tool/file.Create at 5:8",
+		},
+		"otherNamespace": {
+			namespace: "tenant-b",
+			cueTemplate: `
+import "tool/exec"
+
+run: exec.Run & {cmd: "ls"}`,
+		},
+	}
+
+	for caseName, cs := range cases {
+		t.Run(caseName, func(t *testing.T) {
+			err := ValidateCuexTemplate(util.SetNamespaceInCtx(context.Background(), cs.namespace), cs.cueTemplate)
+			if cs.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorIs(t, err, ErrForbiddenFunction)
+			assert.EqualError(t, err, cs.wantErr)
+		})
+	}
+}
+
+func TestCueFunctionDenylistOf(t *testing.T) {
+	defer func(denylist []string) { DefaultCueFunctionDenylist = denylist }(DefaultCueFunctionDenylist)
+	DefaultCueFunctionDenylist = []string{"tool/exec"}
+	assert.Equal(t, []string{"tool/exec"}, CueFunctionDenylistOf("default"))
+	assert.Nil(t, CueFunctionDenylistOf(oam.SystemDefinitionNamespace))
+
+	SetCueFunctionDenylist(oam.SystemDefinitionNamespace, []string{"kube"})
+	defer SetCueFunctionDenylist(oam.SystemDefinitionNamespace, nil)
+	assert.Equal(t, []string{"kube"}, CueFunctionDenylistOf(oam.SystemDefinitionNamespace))
+	assert.True(t, isFunctionDenied("kube.apply", CueFunctionDenylistOf(oam.SystemDefinitionNamespace)))
+	assert.False(t, isFunctionDenied("kubernetes.apply", []string{"kube"}))
+	assert.False(t, isFunctionDenied("kube.applyAll", []string{"kube.apply"}))
+}
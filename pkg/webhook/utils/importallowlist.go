@@ -0,0 +1,109 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"cuelang.org/go/cue/parser"
+
+	"github.com/oam-dev/kubevela/pkg/oam"
+)
+
+// DefaultCueImportAllowlist is the import prefixes permitted for the namespaces without an allowlist,
+// which are the public providers built in CueX
+var DefaultCueImportAllowlist = []string{"vela/base64", "vela/cue", "vela/http", "vela/kube"}
+
+// cueStdlibRoots are the root directories of the CUE standard library, which can always be imported
+var cueStdlibRoots = map[string]bool{
+	"crypto": true, "encoding": true, "html": true, "list": true, "math": true, "net": true, "path": true,
+	"regexp": true, "strconv": true, "strings": true, "struct": true, "text": true, "time": true, "tool": true,
+	"uuid": true,
+}
+
+var (
+	cueImportAllowlists     = map[string][]string{}
+	cueImportAllowlistsLock sync.RWMutex
+)
+
+// SetCueImportAllowlist sets the import prefixes permitted for the cue templates of the definitions in the namespace,
+// a nil allowlist restores the default one
+func SetCueImportAllowlist(namespace string, prefixes []string) {
+	cueImportAllowlistsLock.Lock()
+	defer cueImportAllowlistsLock.Unlock()
+	if prefixes == nil {
+		delete(cueImportAllowlists, namespace)
+		return
+	}
+	cueImportAllowlists[namespace] = prefixes
+}
+
+// CueImportAllowlistOf returns the import prefixes permitted in the namespace, DefaultCueImportAllowlist is used if
+// the namespace has no allowlist. The system definition namespace is not restricted unless an allowlist is set for it,
+// and a nil allowlist is returned in that case.
+func CueImportAllowlistOf(namespace string) []string {
+	cueImportAllowlistsLock.RLock()
+	defer cueImportAllowlistsLock.RUnlock()
+	if prefixes, ok := cueImportAllowlists[namespace]; ok {
+		return prefixes
+	}
+	if namespace == oam.SystemDefinitionNamespace {
+		return nil
+	}
+	return DefaultCueImportAllowlist
+}
+
+// checkImportAllowlist returns an error for each import of the cue template that is neither in the CUE standard
+// library nor under one of the permitted prefixes. A nil allowlist permits every import.
+func checkImportAllowlist(cueTemplate string, allowlist []string) error {
+	if allowlist == nil {
+		return nil
+	}
+	f, err := parser.ParseFile("-", cueTemplate, parser.ImportsOnly)
+	if err != nil {
+		// leave the syntax error to the compiler
+		return nil
+	}
+	var errs []error
+	for _, path := range importPathsOf(f) {
+		if !isImportPermitted(path, allowlist) {
+			errs = append(errs, fmt.Errorf("import %s is not permitted", path))
+		}
+	}
+	return aggregateErrors(errs)
+}
+
+func isImportPermitted(path string, allowlist []string) bool {
+	if isCueStdlibImport(path) {
+		return true
+	}
+	for _, prefix := range allowlist {
+		prefix = strings.TrimSuffix(prefix, "/")
+		if path == prefix || strings.HasPrefix(path, prefix+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// isCueStdlibImport checks whether the import path is a package of the CUE standard library
+func isCueStdlibImport(path string) bool {
+	root, _, _ := strings.Cut(path, "/")
+	return cueStdlibRoots[root]
+}
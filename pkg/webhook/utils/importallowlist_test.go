@@ -0,0 +1,124 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/oam-dev/kubevela/pkg/oam"
+	"github.com/oam-dev/kubevela/pkg/oam/util"
+)
+
+func TestValidateCuexTemplateImportAllowlist(t *testing.T) {
+	defer setFakeCuexCompiler()()
+	SetCueImportAllowlist("tenant-b", []string{"vela/kube", "ext/"})
+	defer SetCueImportAllowlist("tenant-b", nil)
+
+	const kubeTemplate = `
+import (
+	"strings"
+	"encoding/json"
+	"vela/kube"
+)
+
+get: kube.#Get & {
+	$params: resource: {
+		apiVersion: "v1"
+		kind:       "ConfigMap"
+		metadata: name: strings.ToLower(json.Marshal(context.name))
+	}
+}`
+	const secretsTemplate = `
+import (
+	"vela/internal/secrets"
+	"vela/kubernetes"
+)
+
+output: secrets.#Read & kubernetes.#Apply`
+
+	cases := map[string]struct {
+		namespace        string
+		defaultAllowlist []string
+		cueTemplate      string
+		wantErr          string
+	}{
+		"defaultAllowlist": {
+			namespace:   "tenant-a",
+			cueTemplate: kubeTemplate,
+		},
+		"notPermittedByDefaultAllowlist": {
+			namespace:   "tenant-a",
+			cueTemplate: secretsTemplate,
+			wantErr:     "[import vela/internal/secrets is not permitted, import vela/kubernetes is not permitted]",
+		},
+		"overriddenDefaultAllowlist": {
+			namespace:        "tenant-a",
+			defaultAllowlist: []string{"vela/kube"},
+			cueTemplate: `
+import "vela/http"
+
+req: http.#Do`,
+			wantErr: "import vela/http is not permitted",
+		},
+		"namespaceAllowlist": {
+			namespace: "tenant-b",
+			cueTemplate: `
+import "vela/http"
+
+req: http.#Do`,
+			wantErr: "import vela/http is not permitted",
+		},
+		"systemNamespaceNotRestricted": {
+			namespace: oam.SystemDefinitionNamespace,
+			cueTemplate: `
+import "vela/internal/secrets"
+
+output: secrets.#Read`,
+			wantErr: "builtin package \"vela/internal/secrets\" undefined",
+		},
+	}
+
+	for caseName, cs := range cases {
+		t.Run(caseName, func(t *testing.T) {
+			defer func(allowlist []string) { DefaultCueImportAllowlist = allowlist }(DefaultCueImportAllowlist)
+			if cs.defaultAllowlist != nil {
+				DefaultCueImportAllowlist = cs.defaultAllowlist
+			}
+			err := ValidateCuexTemplate(util.SetNamespaceInCtx(context.Background(), cs.namespace), cs.cueTemplate)
+			if cs.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorContains(t, err, cs.wantErr)
+		})
+	}
+}
+
+func TestCueImportAllowlistOf(t *testing.T) {
+	assert.Equal(t, []string{"vela/base64", "vela/cue", "vela/http", "vela/kube"}, CueImportAllowlistOf("default"))
+	assert.Nil(t, CueImportAllowlistOf(oam.SystemDefinitionNamespace))
+
+	SetCueImportAllowlist(oam.SystemDefinitionNamespace, []string{"vela/"})
+	defer SetCueImportAllowlist(oam.SystemDefinitionNamespace, nil)
+	assert.Equal(t, []string{"vela/"}, CueImportAllowlistOf(oam.SystemDefinitionNamespace))
+	assert.True(t, isImportPermitted("vela/kube", CueImportAllowlistOf(oam.SystemDefinitionNamespace)))
+	assert.False(t, isImportPermitted("ext/kube", CueImportAllowlistOf(oam.SystemDefinitionNamespace)))
+	assert.False(t, isImportPermitted("vela/kubernetes", []string{"vela/kube"}))
+}
@@ -0,0 +1,172 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"context"
+	"strconv"
+	"strings"
+
+	"cuelang.org/go/cue/ast"
+	cueErrors "cuelang.org/go/cue/errors"
+	"cuelang.org/go/cue/parser"
+)
+
+// ValidationResult is the result of the cue template validation, the warnings are non-fatal issues that
+// should be reported to the user without denying the request
+type ValidationResult struct {
+	Errors   []CueValidationError `json:"errors,omitempty"`
+	Warnings []CueValidationError `json:"warnings,omitempty"`
+}
+
+// WarningMessages returns the messages of the warnings, e.g. for the warnings of the admission response
+func (r *ValidationResult) WarningMessages() []string {
+	var msgs []string
+	for _, w := range r.Warnings {
+		msgs = append(msgs, w.Error())
+	}
+	return msgs
+}
+
+// Err returns the errors of the result as a single error carrying their positions, nil if there is none,
+// e.g. for FormatCueError
+func (r *ValidationResult) Err() error {
+	var errs []error
+	for _, e := range r.Errors {
+		errs = append(errs, e)
+	}
+	return aggregateErrors(errs)
+}
+
+// ValidateCueTemplateWithResult validates the cueTemplate as ValidateCueTemplateDetailed does,
+// and also returns the warnings found in the template
+func ValidateCueTemplateWithResult(cueTemplate string) (*ValidationResult, error) {
+	return ValidateCueTemplateWithResultWithContext(context.Background(), cueTemplate)
+}
+
+// ValidateCueTemplateWithResultWithContext validates the cueTemplate as ValidateCueTemplateDetailedWithContext
+// does, and also returns the warnings found in the template
+func ValidateCueTemplateWithResultWithContext(ctx context.Context, cueTemplate string) (*ValidationResult, error) {
+	errs, err := ValidateCueTemplateDetailedWithContext(ctx, cueTemplate)
+	return &ValidationResult{Errors: errs, Warnings: collectCueValidationErrors(lintCueTemplate(cueTemplate))}, err
+}
+
+// ValidateCuexTemplateWithResult validates the cueTemplate as ValidateCuexTemplate does,
+// and also returns the warnings found in the template
+func ValidateCuexTemplateWithResult(ctx context.Context, cueTemplate string) (*ValidationResult, error) {
+	errs, err := ValidateCuexTemplateDetailed(ctx, cueTemplate)
+	return &ValidationResult{Errors: errs, Warnings: collectCueValidationErrors(lintCueTemplate(cueTemplate))}, err
+}
+
+// legacyPackages are the CueX packages kept for compatibility, which have replacements in the new providers
+var legacyPackages = map[string]bool{
+	"vela/op": true,
+	"vela/ql": true,
+}
+
+// lintCueTemplate finds the non-fatal issues of the template, i.e. the usage of the legacy packages, the imported
+// standard library packages shadowed by the template, and the definitions and the parameters that are declared but
+// never referenced
+func lintCueTemplate(cueTemplate string) error {
+	f, err := parser.ParseFile("-", cueTemplate)
+	if err != nil {
+		// the syntax error is reported by the validation
+		return nil
+	}
+	var warnings cueErrors.Error
+	for _, spec := range f.Imports {
+		if path, err := strconv.Unquote(spec.Path.Value); err == nil && legacyPackages[path] {
+			warnings = cueErrors.Append(warnings, cueErrors.Newf(spec.Pos(), "package %q is legacy, consider using the new providers instead", path))
+		}
+	}
+
+	for _, err := range shadowedImportsOf(f) {
+		warnings = cueErrors.Append(warnings, err)
+	}
+
+	referenced := map[string]bool{}
+	var collect func(node ast.Node) bool
+	collect = func(node ast.Node) bool {
+		switch x := node.(type) {
+		case *ast.Field:
+			// skip the label of the field, which declares rather than references the name
+			if _, ok := x.Label.(*ast.Ident); ok {
+				ast.Walk(x.Value, collect, nil)
+				return false
+			}
+		case *ast.Ident:
+			referenced[x.Name] = true
+		}
+		return true
+	}
+	ast.Walk(f, collect, nil)
+	for _, decl := range f.Decls {
+		field, ok := decl.(*ast.Field)
+		if !ok {
+			continue
+		}
+		name, _, err := ast.LabelName(field.Label)
+		if err != nil || !strings.HasPrefix(name, "#") || referenced[name] {
+			continue
+		}
+		warnings = cueErrors.Append(warnings, cueErrors.Newf(field.Pos(), "definition %s is declared but never referenced", name))
+	}
+	for _, err := range unusedParametersOf(f) {
+		warnings = cueErrors.Append(warnings, err)
+	}
+	if warnings == nil {
+		return nil
+	}
+	return warnings
+}
+
+// shadowedImportsOf returns a warning for each top-level field named after a package of the standard
+// library imported by the template, e.g. `strings: ...` with `import "strings"`, as the references to the package,
+// e.g. strings.ToUpper, resolve to the field instead and fail confusingly. Only the exact name of the imported package
+// is checked, the packages not imported and the ones outside the standard library are left alone.
+func shadowedImportsOf(f *ast.File) []cueErrors.Error {
+	imported := map[string]string{}
+	for _, spec := range f.Imports {
+		path, err := strconv.Unquote(spec.Path.Value)
+		if err != nil || !cueStdlibRoots[strings.Split(path, "/")[0]] {
+			continue
+		}
+		name := path[strings.LastIndex(path, "/")+1:]
+		if spec.Name != nil {
+			name = spec.Name.Name
+		}
+		imported[name] = path
+	}
+	if len(imported) == 0 {
+		return nil
+	}
+	var errs []cueErrors.Error
+	for _, decl := range f.Decls {
+		field, ok := decl.(*ast.Field)
+		if !ok {
+			continue
+		}
+		ident, ok := field.Label.(*ast.Ident)
+		if !ok {
+			continue
+		}
+		if path, ok := imported[ident.Name]; ok {
+			errs = append(errs, cueErrors.Newf(field.Pos(), "field %s shadows the imported package %q, rename the field or import the package with an alias", ident.Name, path))
+		}
+	}
+	return errs
+}
@@ -0,0 +1,116 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateCueTemplateWithResult(t *testing.T) {
+	cases := map[string]struct {
+		cueTemplate  string
+		wantWarnings []string
+		wantErr      string
+	}{
+		"noWarnings": {
+			cueTemplate: `
+#Port: {
+	port: int
+}
+parameter: ports: [...#Port]
+output: spec: ports: parameter.ports`,
+		},
+		"unreferencedDefinition": {
+			cueTemplate: `
+#Port: {
+	port: int
+}
+#Unused: string
+parameter: port: int
+output: spec: port: parameter.port`,
+			wantWarnings: []string{
+				"line 2: definition #Port is declared but never referenced",
+				"line 5: definition #Unused is declared but never referenced",
+			},
+		},
+		"definitionReferencedByDefinition": {
+			cueTemplate: `
+#Port: port: int
+#Service: ports: [...#Port]
+parameter: service: #Service
+output: spec: parameter.service`,
+		},
+		"unusedParameter": {
+			cueTemplate: `
+parameter: {
+	image: string
+	cmd?: [...string]
+}
+output: spec: image: parameter.image`,
+			wantWarnings: []string{"line 4: parameter.cmd is declared but never referenced"},
+		},
+		"legacyPackage": {
+			cueTemplate: `
+import "vela/op"
+
+output: op.#Steps`,
+			wantWarnings: []string{
+				"line 2: package \"vela/op\" is legacy, consider using the new providers instead",
+			},
+			wantErr: "builtin package \"vela/op\" undefined",
+		},
+		"shadowedImport": {
+			cueTemplate: `
+import (
+	"strings"
+	"encoding/json"
+	l "list"
+)
+
+strings: "a"
+json: "b"
+list: [1]
+output: spec: {a: strings, b: json, c: list, d: l.Concat([[1]])}`,
+			wantWarnings: []string{
+				"line 8: field strings shadows the imported package \"strings\", rename the field or import the package with an alias",
+				"line 9: field json shadows the imported package \"encoding/json\", rename the field or import the package with an alias",
+			},
+		},
+		"errorsWithWarnings": {
+			cueTemplate: `
+#Unused: string
+output: hello: world`,
+			wantWarnings: []string{"line 2: definition #Unused is declared but never referenced"},
+			wantErr:      "output.hello: reference \"world\" not found",
+		},
+	}
+	for caseName, cs := range cases {
+		t.Run(caseName, func(t *testing.T) {
+			result, err := ValidateCueTemplateWithResult(cs.cueTemplate)
+			if cs.wantErr == "" {
+				assert.NoError(t, err)
+				assert.Empty(t, result.Errors)
+			} else {
+				assert.EqualError(t, err, cs.wantErr)
+				assert.NotEmpty(t, result.Errors)
+			}
+			assert.Equal(t, cs.wantWarnings, result.WarningMessages())
+		})
+	}
+}
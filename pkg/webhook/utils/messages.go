@@ -0,0 +1,205 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/oam-dev/kubevela/pkg/oam"
+)
+
+// MessageCode identifies the message of a validation error raised by this package in the message catalogs
+type MessageCode string
+
+// The codes of the messages of the validation errors, which are stable so that the catalogs can be kept
+// outside the package
+const (
+	CodeInvalidRevisionName           MessageCode = "InvalidRevisionName"
+	CodeInvalidRevisionNameAnnotation MessageCode = "InvalidRevisionNameAnnotation"
+	CodeRevisionNotFound              MessageCode = "RevisionNotFound"
+	CodeRevisionSpecDrift             MessageCode = "RevisionSpecDrift"
+	CodeRevisionHashMismatch          MessageCode = "RevisionHashMismatch"
+	CodeTemplateTooComplex            MessageCode = "TemplateTooComplex"
+	CodeTemplateTooLarge              MessageCode = "TemplateTooLarge"
+	CodeDefinitionTooLarge            MessageCode = "DefinitionTooLarge"
+	CodeImportTooDeep                 MessageCode = "ImportTooDeep"
+	CodeForbiddenFunction             MessageCode = "ForbiddenFunction"
+	CodeUnrecognizedTemplate          MessageCode = "UnrecognizedTemplate"
+	CodeValidationPanic               MessageCode = "ValidationPanic"
+	CodeUnsupportedSchematic          MessageCode = "UnsupportedSchematic"
+	CodeConcreteEvaluation            MessageCode = "ConcreteEvaluation"
+	CodeOutputNotObject               MessageCode = "OutputNotObject"
+	CodeDefaultCycle                  MessageCode = "DefaultCycle"
+	CodeInvalidVersion                MessageCode = "InvalidVersion"
+	CodeInvalidVersionConstraint      MessageCode = "InvalidVersionConstraint"
+	CodeVersionConflict               MessageCode = "VersionConflict"
+	CodeVersionNotIncreasing          MessageCode = "VersionNotIncreasing"
+	CodeInvalidStepCondition          MessageCode = "InvalidStepCondition"
+	CodePackageTooOld                 MessageCode = "PackageTooOld"
+	CodeDefinitionNameCollision       MessageCode = "DefinitionNameCollision"
+	CodeMissingRequiredMetadata       MessageCode = "MissingRequiredMetadata"
+	CodeTooManyTraits                 MessageCode = "TooManyTraits"
+	CodeMissingChangelog              MessageCode = "MissingChangelog"
+)
+
+// DefaultLanguage is the language of the messages of the errors returned by this package
+const DefaultLanguage = "en"
+
+// sentinelCodes maps the sentinel errors to the codes of their messages, the messages of the sentinel errors are
+// the English defaults
+var sentinelCodes = []struct {
+	sentinel error
+	code     MessageCode
+}{
+	{ErrInvalidRevisionName, CodeInvalidRevisionName},
+	{ErrInvalidRevisionNameAnnotation, CodeInvalidRevisionNameAnnotation},
+	{ErrRevisionNotFound, CodeRevisionNotFound},
+	{ErrRevisionSpecDrift, CodeRevisionSpecDrift},
+	{ErrRevisionHashMismatch, CodeRevisionHashMismatch},
+	{ErrTemplateTooComplex, CodeTemplateTooComplex},
+	{ErrTemplateTooLarge, CodeTemplateTooLarge},
+	{ErrDefinitionTooLarge, CodeDefinitionTooLarge},
+	{ErrImportTooDeep, CodeImportTooDeep},
+	{ErrForbiddenFunction, CodeForbiddenFunction},
+	{ErrUnrecognizedTemplate, CodeUnrecognizedTemplate},
+	{ErrValidationPanic, CodeValidationPanic},
+	{ErrUnsupportedSchematic, CodeUnsupportedSchematic},
+	{ErrConcreteEvaluation, CodeConcreteEvaluation},
+	{ErrOutputNotObject, CodeOutputNotObject},
+	{ErrDefaultCycle, CodeDefaultCycle},
+	{ErrInvalidVersion, CodeInvalidVersion},
+	{ErrInvalidVersionConstraint, CodeInvalidVersionConstraint},
+	{ErrVersionConflict, CodeVersionConflict},
+	{ErrVersionNotIncreasing, CodeVersionNotIncreasing},
+	{ErrInvalidStepCondition, CodeInvalidStepCondition},
+	{ErrPackageTooOld, CodePackageTooOld},
+	{ErrDefinitionNameCollision, CodeDefinitionNameCollision},
+	{ErrMissingRequiredMetadata, CodeMissingRequiredMetadata},
+	{ErrTooManyTraits, CodeTooManyTraits},
+	{ErrMissingChangelog, CodeMissingChangelog},
+}
+
+var (
+	catalogsMu sync.RWMutex
+	// messageCatalogs are the messages of the codes by language
+	messageCatalogs = map[string]map[MessageCode]string{DefaultLanguage: defaultMessages()}
+)
+
+func defaultMessages() map[MessageCode]string {
+	messages := make(map[MessageCode]string, len(sentinelCodes))
+	for _, c := range sentinelCodes {
+		messages[c.code] = c.sentinel.Error()
+	}
+	return messages
+}
+
+// RegisterMessageCatalog adds the messages of the codes in the language, on top of the ones registered before.
+// The codes missing in the catalog of a language fall back to the English defaults.
+func RegisterMessageCatalog(lang string, messages map[MessageCode]string) {
+	catalogsMu.Lock()
+	defer catalogsMu.Unlock()
+	lang = normalizeLanguage(lang)
+	catalog, ok := messageCatalogs[lang]
+	if !ok {
+		catalog = map[MessageCode]string{}
+		messageCatalogs[lang] = catalog
+	}
+	for code, msg := range messages {
+		catalog[code] = msg
+	}
+}
+
+// Message returns the message of the code in the language, falling back to English and then to the code itself
+func Message(code MessageCode, lang string) string {
+	catalogsMu.RLock()
+	defer catalogsMu.RUnlock()
+	if msg, ok := messageCatalogs[normalizeLanguage(lang)][code]; ok {
+		return msg
+	}
+	if msg, ok := messageCatalogs[DefaultLanguage][code]; ok {
+		return msg
+	}
+	return string(code)
+}
+
+// ErrorMessageCode returns the code of the message of the error raised by this package, or empty if the error,
+// e.g. a cue error, has no code
+func ErrorMessageCode(err error) MessageCode {
+	if err == nil {
+		return ""
+	}
+	for _, c := range sentinelCodes {
+		if errors.Is(err, c.sentinel) {
+			return c.code
+		}
+	}
+	return ""
+}
+
+// LocalizeError returns the message of the error in the language. Only the messages generated by this package are
+// translated, the details wrapped in them, e.g. the cue errors, are kept as they are. The errors in an aggregate
+// are localized one by one.
+func LocalizeError(err error, lang string) string {
+	if err == nil {
+		return ""
+	}
+	var agg utilerrors.Aggregate
+	if errors.As(err, &agg) {
+		errs := make([]error, 0, len(agg.Errors()))
+		for _, e := range agg.Errors() {
+			errs = append(errs, errors.New(LocalizeError(e, lang)))
+		}
+		return utilerrors.NewAggregate(errs).Error()
+	}
+	msg := err.Error()
+	code := ErrorMessageCode(err)
+	if code == "" || normalizeLanguage(lang) == DefaultLanguage {
+		return msg
+	}
+	defaultMsg, localized := Message(code, DefaultLanguage), Message(code, lang)
+	if strings.Contains(msg, defaultMsg) {
+		return strings.Replace(msg, defaultMsg, localized, 1)
+	}
+	// the message of the error marked with the sentinel doesn't contain the one of the sentinel
+	return localized + ": " + msg
+}
+
+// PreferredLanguage returns the language of the validation messages requested by the AnnotationValidationLanguage
+// annotation of the object, English by default
+func PreferredLanguage(obj client.Object) string {
+	if lang := obj.GetAnnotations()[oam.AnnotationValidationLanguage]; lang != "" {
+		return normalizeLanguage(lang)
+	}
+	return DefaultLanguage
+}
+
+// normalizeLanguage keeps the primary language of the tag, e.g. zh-CN to zh
+func normalizeLanguage(lang string) string {
+	lang = strings.ToLower(strings.TrimSpace(lang))
+	if i := strings.IndexAny(lang, "-_"); i >= 0 {
+		lang = lang[:i]
+	}
+	if lang == "" {
+		return DefaultLanguage
+	}
+	return lang
+}
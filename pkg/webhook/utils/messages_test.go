@@ -0,0 +1,129 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
+	"github.com/oam-dev/kubevela/pkg/oam"
+)
+
+func TestDefaultMessages(t *testing.T) {
+	for _, c := range sentinelCodes {
+		assert.Equal(t, c.sentinel.Error(), Message(c.code, DefaultLanguage), c.code)
+	}
+}
+
+func TestLocalizeError(t *testing.T) {
+	RegisterMessageCatalog("x-test", map[MessageCode]string{
+		CodeVersionConflict:    "versions conflict (test)",
+		CodeInvalidVersion:     "invalid version (test)",
+		CodeConcreteEvaluation: "concrete evaluation fails (test)",
+	})
+	cases := map[string]struct {
+		err  error
+		lang string
+		want string
+	}{
+		"nil": {
+			err:  nil,
+			lang: "x-test",
+			want: "",
+		},
+		"default": {
+			err:  fmt.Errorf("%w: 1.0.0 and 2.0.0", ErrVersionConflict),
+			lang: DefaultLanguage,
+			want: "conflicting versions of the definition: 1.0.0 and 2.0.0",
+		},
+		"wrapped": {
+			err:  fmt.Errorf("%w: 1.0.0 and 2.0.0", ErrVersionConflict),
+			lang: "x-test",
+			want: "versions conflict (test): 1.0.0 and 2.0.0",
+		},
+		"withSentinel": {
+			err:  withSentinel(fmt.Errorf("v1.x is invalid"), ErrInvalidVersion),
+			lang: "x-test",
+			want: "invalid version (test): v1.x is invalid",
+		},
+		"fallbackToEnglish": {
+			err:  ErrVersionNotIncreasing,
+			lang: "x-test",
+			want: "version of the definition is not increasing",
+		},
+		"unknownLanguage": {
+			err:  ErrVersionConflict,
+			lang: "fr",
+			want: "conflicting versions of the definition",
+		},
+		"noCode": {
+			err:  fmt.Errorf("parameter.image: reference \"foo\" not found"),
+			lang: "x-test",
+			want: "parameter.image: reference \"foo\" not found",
+		},
+		"aggregate": {
+			err: utilerrors.NewAggregate([]error{
+				ErrVersionConflict,
+				fmt.Errorf("%w: output: incomplete value", ErrConcreteEvaluation),
+			}),
+			lang: "X-Test",
+			want: "[versions conflict (test), concrete evaluation fails (test): output: incomplete value]",
+		},
+	}
+	for caseName, cs := range cases {
+		t.Run(caseName, func(t *testing.T) {
+			assert.Equal(t, cs.want, LocalizeError(cs.err, cs.lang))
+		})
+	}
+}
+
+func TestErrorMessageCode(t *testing.T) {
+	assert.Equal(t, CodeRevisionSpecDrift, ErrorMessageCode(fmt.Errorf("%w: spec.schematic", ErrRevisionSpecDrift)))
+	assert.Equal(t, CodeInvalidVersionConstraint, ErrorMessageCode(ValidateSemanticVersionConstraint("not a constraint")))
+	assert.Equal(t, MessageCode(""), ErrorMessageCode(ValidateCueTemplate(`parameter: {`)))
+	assert.Equal(t, MessageCode(""), ErrorMessageCode(nil))
+}
+
+func TestPreferredLanguage(t *testing.T) {
+	cases := map[string]struct {
+		annotations map[string]string
+		want        string
+	}{
+		"noAnnotation": {
+			want: DefaultLanguage,
+		},
+		"language": {
+			annotations: map[string]string{oam.AnnotationValidationLanguage: "zh"},
+			want:        "zh",
+		},
+		"region": {
+			annotations: map[string]string{oam.AnnotationValidationLanguage: "zh-CN"},
+			want:        "zh",
+		},
+	}
+	for caseName, cs := range cases {
+		t.Run(caseName, func(t *testing.T) {
+			def := &v1beta1.ComponentDefinition{ObjectMeta: metav1.ObjectMeta{Annotations: cs.annotations}}
+			assert.Equal(t, cs.want, PreferredLanguage(def))
+		})
+	}
+}
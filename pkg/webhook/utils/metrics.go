@@ -0,0 +1,46 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"time"
+
+	"github.com/oam-dev/kubevela/pkg/monitor/metrics"
+)
+
+const (
+	validatorCueTemplate        = "cue_template"
+	validatorCuexTemplate       = "cuex_template"
+	validatorDefinitionRevision = "definition_revision"
+	validatorDefinition         = "definition"
+
+	validationOutcomeSuccess = "success"
+	validationOutcomeFailure = "failure"
+)
+
+// observeValidation starts timing a validation, the returned func records the outcome and the duration of it
+func observeValidation(validator, kind string) func(err error) {
+	begin := time.Now()
+	return func(err error) {
+		outcome := validationOutcomeSuccess
+		if err != nil {
+			outcome = validationOutcomeFailure
+		}
+		metrics.DefinitionValidationCounter.WithLabelValues(validator, kind, outcome).Inc()
+		metrics.DefinitionValidationDurationHistogram.WithLabelValues(validator, kind).Observe(time.Since(begin).Seconds())
+	}
+}
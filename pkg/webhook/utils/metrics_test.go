@@ -0,0 +1,56 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"context"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
+	"github.com/oam-dev/kubevela/pkg/monitor/metrics"
+	pkgcommon "github.com/oam-dev/kubevela/pkg/utils/common"
+)
+
+func TestValidationMetrics(t *testing.T) {
+	count := func(validator, kind, outcome string) float64 {
+		return testutil.ToFloat64(metrics.DefinitionValidationCounter.WithLabelValues(validator, kind, outcome))
+	}
+
+	success, failure := count(validatorCueTemplate, "", validationOutcomeSuccess), count(validatorCueTemplate, "", validationOutcomeFailure)
+	assert.NoError(t, ValidateCueTemplate(`output: hello: "metrics"`))
+	assert.Error(t, ValidateCueTemplate(`output: hello: metrics`))
+	assert.Equal(t, success+1, count(validatorCueTemplate, "", validationOutcomeSuccess))
+	assert.Equal(t, failure+1, count(validatorCueTemplate, "", validationOutcomeFailure))
+
+	success = count(validatorCuexTemplate, "TraitDefinition", validationOutcomeSuccess)
+	ctx := WithDefinitionKind(context.Background(), v1beta1.TraitDefinitionKind)
+	assert.NoError(t, ValidateCuexTemplate(ctx, `patch: metadata: labels: hello: "metrics"`))
+	assert.Equal(t, success+1, count(validatorCuexTemplate, "TraitDefinition", validationOutcomeSuccess))
+
+	success = count(validatorDefinitionRevision, "TraitDefinition", validationOutcomeSuccess)
+	td := &v1beta1.TraitDefinition{ObjectMeta: metav1.ObjectMeta{Name: "scaler", Namespace: "default"}}
+	cli := fake.NewClientBuilder().WithScheme(pkgcommon.Scheme).Build()
+	assert.NoError(t, ValidateDefinitionRevision(context.Background(), cli, td, types.NamespacedName{Namespace: "default", Name: "scaler-v1"}))
+	assert.Equal(t, success+1, count(validatorDefinitionRevision, "TraitDefinition", validationOutcomeSuccess))
+	assert.Less(t, 0, testutil.CollectAndCount(metrics.DefinitionValidationDurationHistogram))
+}
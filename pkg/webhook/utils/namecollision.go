@@ -0,0 +1,74 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
+	"github.com/oam-dev/kubevela/pkg/oam/util"
+)
+
+// ValidateDefinitionNameCollision rejects the definition whose name is used in the other form by its
+// DefinitionRevisions, i.e. the definition with spec.version while there are DefinitionRevisions without a version,
+// or the definition without spec.version while there are versioned DefinitionRevisions. Both forms name the
+// revisions <name>-v<...>, so the type name@v1 of an Application may resolve to the legacy revision 1 instead of
+// the version 1.x.y. The error is ErrDefinitionNameCollision with the guidance to finish the migration.
+func ValidateDefinitionNameCollision(ctx context.Context, cli client.Client, def runtime.Object) error {
+	defType, _, err := definitionSchematicOf(def)
+	if err != nil {
+		return err
+	}
+	obj, version, err := definitionVersionOf(def)
+	if err != nil {
+		return err
+	}
+	revs := &v1beta1.DefinitionRevisionList{}
+	if err := cli.List(ctx, revs, client.InNamespace(obj.GetNamespace()), client.MatchingLabels{util.DefinitionKindToNameLabel[defType]: obj.GetName()}); err != nil {
+		return errors.Wrapf(err, "failed to list DefinitionRevisions of definition %s", obj.GetName())
+	}
+	var colliding []string
+	for i := range revs.Items {
+		rev := &revs.Items[i]
+		if rev.Spec.DefinitionType != defType {
+			continue
+		}
+		if (version == "") != (revisionVersionOf(rev) == "") {
+			colliding = append(colliding, rev.Name)
+		}
+	}
+	if len(colliding) == 0 {
+		return nil
+	}
+	sort.Strings(colliding)
+	kind, name := definitionKind(def), obj.GetName()
+	if version != "" {
+		return fmt.Errorf("%w: %s %s sets spec.version %s but has DefinitionRevisions without a version: %s. "+
+			"To migrate, move the Applications referencing %s@v<revision> to the versions, then delete these DefinitionRevisions",
+			ErrDefinitionNameCollision, kind, name, version, strings.Join(colliding, ", "), name)
+	}
+	return fmt.Errorf("%w: %s %s has no spec.version but has versioned DefinitionRevisions: %s. "+
+		"Set spec.version to publish a new version instead",
+		ErrDefinitionNameCollision, kind, name, strings.Join(colliding, ", "))
+}
@@ -0,0 +1,98 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	apicommon "github.com/oam-dev/kubevela/apis/core.oam.dev/common"
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
+	"github.com/oam-dev/kubevela/pkg/oam"
+	"github.com/oam-dev/kubevela/pkg/utils/common"
+)
+
+func TestValidateDefinitionNameCollision(t *testing.T) {
+	componentDef := func(name, version string) *v1beta1.ComponentDefinition {
+		return &v1beta1.ComponentDefinition{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+			Spec:       v1beta1.ComponentDefinitionSpec{Version: version},
+		}
+	}
+	revision := func(name, revName string, revision int64, version string) *v1beta1.DefinitionRevision {
+		return &v1beta1.DefinitionRevision{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      revName,
+				Namespace: "default",
+				Labels:    map[string]string{oam.LabelComponentDefinitionName: name},
+			},
+			Spec: v1beta1.DefinitionRevisionSpec{
+				Revision:            revision,
+				DefinitionType:      apicommon.ComponentType,
+				ComponentDefinition: *componentDef(name, version),
+			},
+		}
+	}
+	cli := fake.NewClientBuilder().WithScheme(common.Scheme).WithObjects(
+		revision("worker", "worker-v1", 1, ""),
+		revision("worker", "worker-v2", 2, ""),
+		revision("task", "task-v1.0.0", 1, "1.0.0"),
+		revision("task", "task-v1.1.0", 2, "1.1.0"),
+	).Build()
+
+	cases := map[string]struct {
+		def     *v1beta1.ComponentDefinition
+		wantErr string
+	}{
+		"legacyStaysLegacy": {
+			def: componentDef("worker", ""),
+		},
+		"versionedStaysVersioned": {
+			def: componentDef("task", "1.2.0"),
+		},
+		"noRevision": {
+			def: componentDef("new", "1.0.0"),
+		},
+		"versionedOverLegacy": {
+			def: componentDef("worker", "1.0.0"),
+			wantErr: "definition is both versioned and unversioned: ComponentDefinition worker sets spec.version 1.0.0 " +
+				"but has DefinitionRevisions without a version: worker-v1, worker-v2. To migrate, move the Applications " +
+				"referencing worker@v<revision> to the versions, then delete these DefinitionRevisions",
+		},
+		"legacyOverVersioned": {
+			def: componentDef("task", ""),
+			wantErr: "definition is both versioned and unversioned: ComponentDefinition task has no spec.version " +
+				"but has versioned DefinitionRevisions: task-v1.0.0, task-v1.1.0. Set spec.version to publish a new version instead",
+		},
+	}
+
+	for caseName, cs := range cases {
+		t.Run(caseName, func(t *testing.T) {
+			err := ValidateDefinitionNameCollision(context.Background(), cli, cs.def)
+			if cs.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.EqualError(t, err, cs.wantErr)
+			assert.ErrorIs(t, err, ErrDefinitionNameCollision)
+		})
+	}
+}
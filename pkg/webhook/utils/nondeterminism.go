@@ -0,0 +1,149 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"cuelang.org/go/cue"
+	"github.com/kubevela/pkg/cue/cuex"
+	cueutil "github.com/kubevela/pkg/cue/util"
+	"github.com/kubevela/workflow/pkg/cue/model"
+)
+
+// NonDeterministicProviderFunctions are the CueX provider functions, qualified by their provider, returning values
+// that change from a rendering to another, e.g. the responses of the remote services or the live state of the
+// cluster, which must not be rendered into the outputs of the definitions
+var NonDeterministicProviderFunctions = []string{"http.do", "kube.get", "kube.list"}
+
+// outputsMaxDepth bounds the walk of the output and the outputs, whose references may be recursive
+const outputsMaxDepth = 32
+
+// ValidateDeterministicOutputs finds the calls to NonDeterministicProviderFunctions in the template, and reports the
+// fields of the output and the outputs calling them or referencing their results, as the resources rendered from
+// them change on every rendering and break the drift detection. They are returned as warnings, so that the authors
+// move the calls to a side-effect phase, e.g. a workflow step.
+// Nothing is reported if the template can't be compiled, which is reported by the validation.
+func ValidateDeterministicOutputs(ctx context.Context, cueTemplate string) (*ValidationResult, error) {
+	result := &ValidationResult{}
+	val, err := cuex.DefaultCompiler.Get().CompileStringWithOptions(ctx, cueTemplate+contextStub, cuex.DisableResolveProviderFunctions{})
+	if err != nil || val.Err() != nil {
+		return result, nil
+	}
+	calls := nonDeterministicCallsOf(val)
+	if len(calls) == 0 {
+		return result, nil
+	}
+	var walk func(v cue.Value, depth int)
+	walk = func(v cue.Value, depth int) {
+		if depth > outputsMaxDepth {
+			return
+		}
+		if msg := nonDeterministicUseOf(v, calls); msg != "" {
+			result.Warnings = append(result.Warnings, CueValidationError{
+				Message: fmt.Sprintf("%s %s, move it out of the rendered resources", v.Path(), msg),
+				Line:    v.Pos().Line(),
+				Column:  v.Pos().Column(),
+			})
+			return
+		}
+		if iter, err := v.Fields(); err == nil {
+			for iter.Next() {
+				walk(iter.Value(), depth+1)
+			}
+		}
+		if iter, err := v.List(); err == nil {
+			for iter.Next() {
+				walk(iter.Value(), depth+1)
+			}
+		}
+	}
+	for _, field := range []string{model.OutputFieldName, model.OutputsFieldName} {
+		if v := val.LookupPath(cue.ParsePath(field)); v.Exists() {
+			walk(v, 0)
+		}
+	}
+	return result, nil
+}
+
+// nonDeterministicCallsOf returns the paths of the calls to NonDeterministicProviderFunctions in the value, with
+// the functions they call
+func nonDeterministicCallsOf(val cue.Value) map[string]string {
+	calls := map[string]string{}
+	cueutil.Iterate(val, func(v cue.Value) (stop bool) {
+		fn, _ := v.LookupPath(cue.ParsePath(providerFnKey)).String()
+		if fn == "" {
+			return false
+		}
+		name, _ := v.LookupPath(cue.ParsePath(providerKey)).String()
+		for _, call := range NonDeterministicProviderFunctions {
+			if call == name+"."+fn {
+				calls[v.Path().String()] = call
+			}
+		}
+		return false
+	})
+	return calls
+}
+
+// nonDeterministicUseOf describes how the value uses one of the calls, i.e. it's inside the call or references the
+// result of the call directly or in its expression, empty if it doesn't
+func nonDeterministicUseOf(v cue.Value, calls map[string]string) string {
+	if path, fn := callOfPath(v.Path().String(), calls); fn != "" {
+		if path != v.Path().String() {
+			return fmt.Sprintf("is inside the call to the non-deterministic provider function %s at %s", fn, path)
+		}
+		return fmt.Sprintf("calls the non-deterministic provider function %s", fn)
+	}
+	for _, ref := range referencePathsOf(v, 0) {
+		if path, fn := callOfPath(ref, calls); fn != "" {
+			return fmt.Sprintf("references %s, the result of the non-deterministic provider function %s at %s", ref, fn, path)
+		}
+	}
+	return ""
+}
+
+// callOfPath returns the call containing the path and its function, empty if there is none
+func callOfPath(path string, calls map[string]string) (string, string) {
+	for call, fn := range calls {
+		if path == call || strings.HasPrefix(path, call+".") || strings.HasPrefix(path, call+"[") {
+			return call, fn
+		}
+	}
+	return "", ""
+}
+
+// referencePathsOf returns the paths referenced by the value and the operands of its expression
+func referencePathsOf(v cue.Value, depth int) []string {
+	if depth > outputsMaxDepth {
+		return nil
+	}
+	if _, path := v.ReferencePath(); len(path.Selectors()) != 0 {
+		return []string{path.String()}
+	}
+	op, args := v.Expr()
+	if op == cue.NoOp {
+		return nil
+	}
+	var paths []string
+	for _, arg := range args {
+		paths = append(paths, referencePathsOf(arg, depth+1)...)
+	}
+	return paths
+}
@@ -0,0 +1,89 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateDeterministicOutputs(t *testing.T) {
+	defer setFakeCuexCompiler()()
+
+	cases := map[string]struct {
+		cueTemplate  string
+		wantWarnings []string
+	}{
+		"deterministic": {
+			cueTemplate: `
+import "vela/kube"
+
+apply: kube.#Apply & {
+	$params: value: output
+}
+output: {
+	apiVersion: "v1"
+	kind:       "ConfigMap"
+	metadata: name: context.name
+}`,
+		},
+		"referencesResults": {
+			cueTemplate: `
+import (
+	"vela/http"
+	"vela/kube"
+)
+
+_secret: kube.#Get & {
+	$params: resource: {apiVersion: "v1", kind: "Secret", metadata: name: "token"}
+}
+resp: http.#Get & {$params: url: "https://example.com"}
+output: {
+	apiVersion: "v1"
+	kind:       "ConfigMap"
+	metadata: name: context.name
+	metadata: annotations: body: "body: \(resp.$returns.body)"
+	data: _secret.$returns.data
+}`,
+			wantWarnings: []string{
+				"line 15: output.metadata.annotations.body references resp.$returns.body, the result of the non-deterministic provider function http.do at resp, move it out of the rendered resources",
+				"line 16: output.data references _secret.$returns.data, the result of the non-deterministic provider function kube.get at _secret, move it out of the rendered resources",
+			},
+		},
+		"callsInOutputs": {
+			cueTemplate: `
+import "vela/kube"
+
+outputs: secret: kube.#Get & {
+	$params: resource: {apiVersion: "v1", kind: "Secret", metadata: name: "token"}
+}`,
+			wantWarnings: []string{"line 4: outputs.secret calls the non-deterministic provider function kube.get, move it out of the rendered resources"},
+		},
+		"syntaxError": {
+			cueTemplate: `output: {`,
+		},
+	}
+	for caseName, cs := range cases {
+		t.Run(caseName, func(t *testing.T) {
+			result, err := ValidateDeterministicOutputs(context.Background(), cs.cueTemplate)
+			assert.NoError(t, err)
+			assert.Equal(t, cs.wantWarnings, result.WarningMessages())
+		})
+	}
+}
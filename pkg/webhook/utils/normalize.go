@@ -0,0 +1,107 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"sort"
+
+	"cuelang.org/go/cue/ast"
+	"cuelang.org/go/cue/format"
+	"cuelang.org/go/cue/parser"
+	"cuelang.org/go/cue/token"
+
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/common"
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
+	"github.com/oam-dev/kubevela/pkg/controller/core.oam.dev/v1beta1/core"
+)
+
+// NormalizeCueTemplate returns the canonical form of the cue template, in which the comments are stripped,
+// the fields of every struct are sorted by label and the template is formatted, so that the templates only
+// different in whitespaces, comments or field ordering have the same normalized form.
+// The fields are only reordered among the adjacent fields, embeddings, comprehensions and other declarations
+// are kept in place.
+func NormalizeCueTemplate(cueTemplate string) (string, error) {
+	f, err := parser.ParseFile("-", cueTemplate)
+	if err != nil {
+		return "", err
+	}
+	ast.Walk(f, func(node ast.Node) bool {
+		switch x := node.(type) {
+		case *ast.File:
+			sortFieldDecls(x.Decls)
+		case *ast.StructLit:
+			sortFieldDecls(x.Elts)
+		}
+		return true
+	}, nil)
+	bs, err := format.Node(f, format.Simplify())
+	if err != nil {
+		return "", err
+	}
+	return string(bs), nil
+}
+
+// sortFieldDecls sorts every run of adjacent fields by label and puts each declaration on its own line
+func sortFieldDecls(decls []ast.Decl) {
+	start := 0
+	for i := 0; i <= len(decls); i++ {
+		if i < len(decls) {
+			if _, ok := decls[i].(*ast.Field); ok {
+				continue
+			}
+		}
+		fields := decls[start:i]
+		sort.SliceStable(fields, func(a, b int) bool {
+			return fieldLabelOf(fields[a]) < fieldLabelOf(fields[b])
+		})
+		start = i + 1
+	}
+	for _, decl := range decls {
+		ast.SetRelPos(decl, token.Newline)
+	}
+}
+
+func fieldLabelOf(decl ast.Decl) string {
+	return labelString(decl.(*ast.Field).Label)
+}
+
+// isCosmeticRevisionChange checks whether the definitionRevisions are the same after the cue templates are normalized
+func isCosmeticRevisionChange(old, new *v1beta1.DefinitionRevision) bool {
+	normalizedOld, normalizedNew := old.DeepCopy(), new.DeepCopy()
+	for _, defRev := range []*v1beta1.DefinitionRevision{normalizedOld, normalizedNew} {
+		for _, schematic := range schematicsOf(defRev) {
+			if schematic == nil || schematic.CUE == nil {
+				continue
+			}
+			normalized, err := NormalizeCueTemplate(schematic.CUE.Template)
+			if err != nil {
+				return false
+			}
+			schematic.CUE.Template = normalized
+		}
+	}
+	return core.DeepEqualDefRevision(normalizedOld, normalizedNew)
+}
+
+func schematicsOf(defRev *v1beta1.DefinitionRevision) []*common.Schematic {
+	return []*common.Schematic{
+		defRev.Spec.ComponentDefinition.Spec.Schematic,
+		defRev.Spec.TraitDefinition.Spec.Schematic,
+		defRev.Spec.PolicyDefinition.Spec.Schematic,
+		defRev.Spec.WorkflowStepDefinition.Spec.Schematic,
+	}
+}
@@ -0,0 +1,99 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalizeCueTemplate(t *testing.T) {
+	cases := map[string]struct {
+		cueTemplate string
+		want        string
+		wantErr     bool
+	}{
+		"sortFieldsAndStripComments": {
+			cueTemplate: `
+import "strings"
+
+// the output
+output: {
+	spec: image: strings.ToLower(parameter.image)
+
+
+	metadata: name: context.name
+}
+parameter: {
+	// +usage=the image
+	image: string
+	cmd?: [...string]
+}`,
+			want: `import "strings"
+
+output: {
+	metadata: name: context.name
+	spec: image:    strings.ToLower(parameter.image)
+}
+parameter: {
+	cmd?: [...string]
+	image: string
+}
+`,
+		},
+		"keepComprehensionsInPlace": {
+			cueTemplate: `b: 1
+if b > 0 {z: 1, a: 2}
+a: 1`,
+			want: `b: 1
+if b > 0 {
+	a: 2
+	z: 1
+}
+a: 1
+`,
+		},
+		"listsAreNotSorted": {
+			cueTemplate: `args: ["b", "a"]`,
+			want:        "args: [\"b\", \"a\"]\n",
+		},
+		"invalidSyntax": {
+			cueTemplate: `a: {`,
+			wantErr:     true,
+		},
+	}
+	for caseName, cs := range cases {
+		t.Run(caseName, func(t *testing.T) {
+			got, err := NormalizeCueTemplate(cs.cueTemplate)
+			if cs.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, cs.want, got)
+		})
+	}
+}
+
+func TestNormalizeCueTemplateIsStable(t *testing.T) {
+	a, err := NormalizeCueTemplate("parameter: {\n\treplicas: *1 | int\n\timage: string\n}\n")
+	assert.NoError(t, err)
+	b, err := NormalizeCueTemplate("// the parameter\nparameter: {image: string, replicas: *1 | int}")
+	assert.NoError(t, err)
+	assert.Equal(t, a, b)
+}
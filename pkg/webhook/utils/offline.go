@@ -0,0 +1,114 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"context"
+	"fmt"
+
+	"cuelang.org/go/cue"
+	"github.com/kubevela/pkg/cue/cuex"
+	"github.com/kubevela/pkg/util/singleton"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/common"
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
+	"github.com/oam-dev/kubevela/pkg/oam"
+)
+
+// offlineCompiler only has the internal packages, so it never connects to the cluster for the external packages
+var offlineCompiler = singleton.NewSingleton[*cuex.Compiler](cuex.NewCompilerWithDefaultInternalPackages)
+
+// ValidateDefinitionOffline performs every validation of the definition that doesn't need a cluster, e.g. in CI
+// or a pre-commit hook. The checks that need existing objects, like the immutability of the definitionRevision and
+// the references of the TraitDefinition, are skipped.
+// The cue templates are compiled with the internal CueX packages only and the provider functions are not executed.
+// The templates of WorkflowStepDefinition rely on the workflow providers and are not compiled.
+func ValidateDefinitionOffline(def runtime.Object) error {
+	_, err := ValidateDefinitionOfflineWithResult(def)
+	return err
+}
+
+// ValidateDefinitionOfflineWithResult validates the definition as ValidateDefinitionOffline does, and also returns
+// the errors of the cue template with their positions and the warnings found in the template
+func ValidateDefinitionOfflineWithResult(def runtime.Object) (*ValidationResult, error) {
+	result := &ValidationResult{}
+	var err error
+	switch d := def.(type) {
+	case *v1beta1.ComponentDefinition:
+		result.Warnings = lintSchematic(d.Spec.Schematic)
+		if result.Errors, err = validateCuexSchematicOffline(d.Name, d.Spec.Schematic); err == nil {
+			err = validateDefinitionVersionsOffline(d, d.Spec.Version)
+		}
+	case *v1beta1.TraitDefinition:
+		result.Warnings = lintSchematic(d.Spec.Schematic)
+		if result.Errors, err = validateCuexSchematicOffline(d.Name, d.Spec.Schematic); err == nil {
+			err = validateDefinitionVersionsOffline(d, d.Spec.Version)
+		}
+	case *v1beta1.PolicyDefinition:
+		result.Warnings = lintSchematic(d.Spec.Schematic)
+		if result.Errors, err = validateCuexSchematicOffline(d.Name, d.Spec.Schematic); err == nil {
+			err = validatePolicyShadowing(context.Background(), offlineCompiler.Get(), d)
+		}
+		if err == nil {
+			err = validateDefinitionVersionsOffline(d, d.Spec.Version)
+		}
+	case *v1beta1.WorkflowStepDefinition:
+		err = validateDefinitionVersionsOffline(d, d.Spec.Version)
+	default:
+		err = fmt.Errorf("unsupported definition type %T", def)
+	}
+	return result, err
+}
+
+func validateCuexSchematicOffline(name string, schematic *common.Schematic) ([]CueValidationError, error) {
+	if schematic == nil || schematic.CUE == nil {
+		return nil, nil
+	}
+	compiler := offlineCompiler.Get()
+	if err := checkImportCycles(name, schematic.CUE.Template, compiler.GetImports()); err != nil {
+		return nil, err
+	}
+	compile := func(src string) (cue.Value, error) {
+		return compiler.CompileStringWithOptions(context.Background(), src, cuex.DisableResolveProviderFunctions{})
+	}
+	return validateCueTemplateWith(schematic.CUE.Template, compile, compile)
+}
+
+// lintSchematic returns the warnings of the cue template of the schematic
+func lintSchematic(schematic *common.Schematic) []CueValidationError {
+	if schematic == nil || schematic.CUE == nil {
+		return nil
+	}
+	return collectCueValidationErrors(lintCueTemplate(schematic.CUE.Template))
+}
+
+// validateDefinitionVersionsOffline validates the version and the revision name annotation of the definition, and that
+// they are not set together
+func validateDefinitionVersionsOffline(def client.Object, version string) error {
+	if version != "" {
+		if err := ValidateSemanticVersion(version); err != nil {
+			return err
+		}
+	}
+	revisionName := def.GetAnnotations()[oam.AnnotationDefinitionRevisionName]
+	if err := ValidateRevisionNameAnnotation(revisionName); err != nil {
+		return err
+	}
+	return ValidateMultipleDefVersionsNotPresent(version, revisionName, definitionKind(def))
+}
@@ -0,0 +1,147 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	apicommon "github.com/oam-dev/kubevela/apis/core.oam.dev/common"
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
+	"github.com/oam-dev/kubevela/pkg/oam"
+)
+
+func TestValidateDefinitionOffline(t *testing.T) {
+	schematic := func(template string) *apicommon.Schematic {
+		return &apicommon.Schematic{CUE: &apicommon.CUE{Template: template}}
+	}
+	meta := metav1.ObjectMeta{Name: "test", Namespace: "default"}
+
+	cases := map[string]struct {
+		def     runtime.Object
+		wantErr string
+	}{
+		"validComponentDefinition": {
+			def: &v1beta1.ComponentDefinition{ObjectMeta: meta, Spec: v1beta1.ComponentDefinitionSpec{
+				Version: "1.2.0",
+				Schematic: schematic(`
+import "encoding/base64"
+
+parameter: data: string
+output: {
+	metadata: name: context.name
+	data: value: base64.Encode(null, parameter.data)
+}`),
+			}},
+		},
+		"invalidComponentTemplate": {
+			def: &v1beta1.ComponentDefinition{ObjectMeta: meta, Spec: v1beta1.ComponentDefinitionSpec{
+				Schematic: schematic(`output: hello: world`),
+			}},
+			wantErr: "output.hello: reference \"world\" not found",
+		},
+		"invalidTraitVersion": {
+			def: &v1beta1.TraitDefinition{ObjectMeta: meta, Spec: v1beta1.TraitDefinitionSpec{
+				Version:   "1.2",
+				Schematic: schematic(`patch: replicas: 1`),
+			}},
+			wantErr: "Not a valid version",
+		},
+		"traitWithVersionAndRevisionName": {
+			def: &v1beta1.TraitDefinition{
+				ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "default", Annotations: map[string]string{
+					oam.AnnotationDefinitionRevisionName: "1",
+				}},
+				Spec: v1beta1.TraitDefinitionSpec{Version: "1.2.0"},
+			},
+			wantErr: "TraitDefinition has both spec.version and revision name annotation. Only one can be present",
+		},
+		"policyShadowingBuiltin": {
+			def:     &v1beta1.PolicyDefinition{ObjectMeta: metav1.ObjectMeta{Name: "override", Namespace: "default"}},
+			wantErr: "PolicyDefinition override is invalid: the name conflicts with the built-in policy type override, set the annotation policydefinition.oam.dev/allow-reserved-name to \"true\" to override it",
+		},
+		"workflowStepDefinition": {
+			def: &v1beta1.WorkflowStepDefinition{ObjectMeta: meta, Spec: v1beta1.WorkflowStepDefinitionSpec{
+				Version:   "1.0.0",
+				Schematic: schematic(`import "vela/op"`),
+			}},
+		},
+		"unsupportedType": {
+			def:     &v1beta1.Application{ObjectMeta: meta},
+			wantErr: "unsupported definition type *v1beta1.Application",
+		},
+	}
+
+	for caseName, cs := range cases {
+		t.Run(caseName, func(t *testing.T) {
+			err := ValidateDefinitionOffline(cs.def)
+			if cs.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.EqualError(t, err, cs.wantErr)
+		})
+	}
+}
+
+func TestValidateDefinitionOfflineWithResult(t *testing.T) {
+	meta := metav1.ObjectMeta{Name: "test", Namespace: "default"}
+	cases := map[string]struct {
+		def     runtime.Object
+		want    *ValidationResult
+		wantErr string
+	}{
+		"warnings": {
+			def: &v1beta1.TraitDefinition{ObjectMeta: meta, Spec: v1beta1.TraitDefinitionSpec{
+				Schematic: &apicommon.Schematic{CUE: &apicommon.CUE{Template: "import \"vela/kube\"\n\n#Unused: int\npatch: {}\n"}},
+			}},
+			want: &ValidationResult{Warnings: []CueValidationError{
+				{Message: "definition #Unused is declared but never referenced", Filename: "-", Line: 3, Column: 1},
+			}},
+		},
+		"templateErrors": {
+			def: &v1beta1.PolicyDefinition{ObjectMeta: meta, Spec: v1beta1.PolicyDefinitionSpec{
+				Schematic: &apicommon.Schematic{CUE: &apicommon.CUE{Template: "parameter: {}\nproperties: hello: world\n"}},
+			}},
+			want: &ValidationResult{Errors: []CueValidationError{
+				{Message: "properties.hello: reference \"world\" not found", Filename: "-", Line: 2, Column: 20},
+			}},
+			wantErr: "properties.hello: reference \"world\" not found",
+		},
+		"versionError": {
+			def: &v1beta1.ComponentDefinition{ObjectMeta: meta, Spec: v1beta1.ComponentDefinitionSpec{
+				Version: "1.x",
+			}},
+			want:    &ValidationResult{},
+			wantErr: "Not a valid version",
+		},
+	}
+	for caseName, cs := range cases {
+		t.Run(caseName, func(t *testing.T) {
+			result, err := ValidateDefinitionOfflineWithResult(cs.def)
+			if cs.wantErr == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, cs.wantErr)
+			}
+			assert.Equal(t, cs.want, result)
+		})
+	}
+}
@@ -0,0 +1,88 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"cuelang.org/go/cue"
+	cueErrors "cuelang.org/go/cue/errors"
+	"github.com/kubevela/workflow/pkg/cue/model"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+// ValidateOutputKeys validates that the keys of the outputs of the cueTemplate are valid names of the resources
+// rendered. The key names the resource in the component by the trait.oam.dev/resource label, so it must be a valid
+// label value, e.g. the camelCase keys are allowed.
+func ValidateOutputKeys(cueTemplate string) error {
+	val, err := compileAndValidate(context.Background(), cueTemplate)
+	if err != nil {
+		return err
+	}
+	_, err = validateOutputKeysOf(val)
+	return err
+}
+
+// validateOutputKeysOf checks the keys of the outputs of the template evaluated with the context stub. The keys
+// are evaluated with the context filled by concreteContextStub, the keys depending on the parameter are only
+// known at runtime and not checked.
+func validateOutputKeysOf(val cue.Value) ([]CueValidationError, error) {
+	if val.Err() != nil {
+		// the template has been validated, the error only comes from the context stub
+		return nil, nil
+	}
+	val = val.FillPath(cue.ParsePath(model.ContextFieldName), val.Context().CompileString(concreteContextStub))
+	outputs := val.LookupPath(cue.ParsePath(model.OutputsFieldName))
+	if !outputs.Exists() {
+		return nil, nil
+	}
+	iter, err := outputs.Fields()
+	if err != nil {
+		// the outputs is not a struct, which is reported by the rendering
+		return nil, nil
+	}
+	var errs []CueValidationError
+	for iter.Next() {
+		sel := iter.Selector()
+		if sel.LabelType() != cue.StringLabel {
+			continue
+		}
+		key := sel.Unquoted()
+		msgs := validation.IsValidLabelValue(key)
+		if key == "" {
+			msgs = []string{"must not be empty"}
+		}
+		if len(msgs) == 0 {
+			continue
+		}
+		ve := CueValidationError{Message: fmt.Sprintf("outputs.%s: invalid output key %q: %s", sel.String(), key, strings.Join(msgs, ", "))}
+		if pos := iter.Value().Pos(); pos.IsValid() {
+			ve.Filename, ve.Line, ve.Column = pos.Filename(), pos.Line(), pos.Column()
+		}
+		errs = append(errs, ve)
+	}
+	if len(errs) == 0 {
+		return nil, nil
+	}
+	msgs := make([]error, 0, len(errs))
+	for _, e := range errs {
+		msgs = append(msgs, cueErrors.New(e.Message))
+	}
+	return errs, aggregateErrors(msgs)
+}
@@ -0,0 +1,123 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/common"
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
+)
+
+func TestValidateOutputKeys(t *testing.T) {
+	cases := map[string]struct {
+		cueTemplate string
+		wantErrMsgs []string
+	}{
+		"valid": {
+			cueTemplate: `
+outputs: {
+	service: kind: "Service"
+	"web.ingress": kind: "Ingress"
+	hpa_v2: kind: "HorizontalPodAutoscaler"
+	nocalhostService: kind: "Service"
+}
+`,
+		},
+		"noOutputs": {
+			cueTemplate: `output: kind: "Deployment"`,
+		},
+		"invalidCharacter": {
+			cueTemplate: `outputs: "my svc": kind: "Service"`,
+			wantErrMsgs: []string{`outputs."my svc": invalid output key "my svc"`},
+		},
+		"tooLong": {
+			cueTemplate: `outputs: "service-with-a-very-long-name-exceeding-the-limit-of-the-label-value": kind: "Service"`,
+			wantErrMsgs: []string{"must be no more than 63 characters"},
+		},
+		"invalidStart": {
+			cueTemplate: `outputs: "-svc": kind: "Service"`,
+			wantErrMsgs: []string{`outputs."-svc": invalid output key "-svc"`},
+		},
+		"empty": {
+			cueTemplate: `outputs: "": kind: "Service"`,
+			wantErrMsgs: []string{`outputs."": invalid output key "": must not be empty`},
+		},
+		"interpolatedWithContext": {
+			cueTemplate: `outputs: "\(context.name)/svc": kind: "Service"`,
+			wantErrMsgs: []string{`invalid output key "validation/svc"`},
+		},
+		"interpolatedWithParameter": {
+			cueTemplate: `
+parameter: name: string
+outputs: "\(parameter.name) svc": kind: "Service"
+`,
+		},
+		"multipleKeys": {
+			cueTemplate: `
+outputs: {
+	"my svc": kind:    "Service"
+	ok: kind:          "Service"
+	"my ingress": kind: "Ingress"
+}
+`,
+			wantErrMsgs: []string{`invalid output key "my svc"`, `invalid output key "my ingress"`},
+		},
+	}
+	for caseName, cs := range cases {
+		t.Run(caseName, func(t *testing.T) {
+			err := ValidateOutputKeys(cs.cueTemplate)
+			if len(cs.wantErrMsgs) == 0 {
+				assert.NoError(t, err)
+			} else {
+				for _, msg := range cs.wantErrMsgs {
+					assert.ErrorContains(t, err, msg)
+				}
+			}
+			// the keys are checked by the validation of the template as well
+			assert.Equal(t, err == nil, ValidateCueTemplate(cs.cueTemplate) == nil)
+		})
+	}
+}
+
+func TestValidateOutputKeysDetailed(t *testing.T) {
+	errs, err := ValidateCueTemplateDetailed(`
+outputs: {
+	ok: kind: "Service"
+	"my svc": kind: "Service"
+}
+`)
+	assert.Error(t, err)
+	if assert.Len(t, errs, 1) {
+		assert.Equal(t, 4, errs[0].Line)
+		assert.Contains(t, errs[0].Message, `invalid output key "my svc"`)
+	}
+}
+
+func TestValidateOutputKeysOfWorkflowStep(t *testing.T) {
+	defer setFakeCuexCompiler()()
+	wd := &v1beta1.WorkflowStepDefinition{}
+	wd.Spec.Schematic = &common.Schematic{CUE: &common.CUE{Template: `
+parameter: {}
+outputs: "step result": "done"
+`}}
+	_, err := ValidateWorkflowStepDefinition(context.Background(), nil, wd)
+	assert.NoError(t, err)
+}
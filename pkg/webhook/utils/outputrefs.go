@@ -0,0 +1,170 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"cuelang.org/go/cue/ast"
+	cueErrors "cuelang.org/go/cue/errors"
+	"cuelang.org/go/cue/parser"
+	"cuelang.org/go/cue/token"
+	"github.com/kubevela/workflow/pkg/cue/model"
+)
+
+// ValidateOutputsReferences checks that the references to the outputs in the template, e.g. outputs.service from
+// the output or the patch of a trait, select the keys declared in the outputs struct, as the reference to a key not
+// declared renders nil. The dangling references are returned as errors. The declared keys never referenced are
+// returned as warnings if the template references the outputs at all, as the outputs are rendered anyway.
+// The check is conservative as ValidateNoDanglingReferences is: the outputs accept any key once a key is generated,
+// e.g. by a for comprehension or an interpolated label, and the references compared with _|_ are existence checks
+// which are not reported. The outputs of the component referenced by context.outputs are not checked.
+// It's not part of ValidateCueTemplate, the callers opt in to it.
+func ValidateOutputsReferences(cueTemplate string) (*ValidationResult, error) {
+	result := &ValidationResult{}
+	f, err := parser.ParseFile("-", cueTemplate)
+	if err != nil {
+		// the syntax error is reported by the validation
+		return result, nil
+	}
+	declared := map[string]token.Pos{}
+	var keys []string
+	open := false
+	for _, decl := range f.Decls {
+		field, ok := decl.(*ast.Field)
+		if !ok {
+			continue
+		}
+		if name, _, err := ast.LabelName(field.Label); err != nil || name != model.OutputsFieldName {
+			continue
+		}
+		for _, key := range outputKeysOf(field.Value, &open) {
+			if _, ok := declared[key.name]; !ok {
+				declared[key.name] = key.pos
+				keys = append(keys, key.name)
+			}
+		}
+	}
+
+	var dangling, unused cueErrors.Error
+	referenced := map[string]bool{}
+	ast.Walk(f, func(n ast.Node) bool {
+		switch x := n.(type) {
+		case *ast.BinaryExpr:
+			if (x.Op == token.EQL || x.Op == token.NEQ) && (isBottomLit(x.X) || isBottomLit(x.Y)) {
+				// the existence check references the key without requiring it
+				for _, operand := range []ast.Expr{x.X, x.Y} {
+					if sel, ok := operand.(*ast.SelectorExpr); ok {
+						if key, _, ok := outputsReferenceOf(sel); ok {
+							referenced[key] = true
+						}
+					}
+				}
+				return false
+			}
+		case *ast.SelectorExpr:
+			key, pos, ok := outputsReferenceOf(x)
+			if !ok {
+				return true
+			}
+			if _, declaredKey := declared[key]; !declaredKey && !open && !referenced[key] {
+				dangling = cueErrors.Append(dangling, cueErrors.Newf(pos,
+					"reference %s.%s is not declared in %s", model.OutputsFieldName, key, model.OutputsFieldName))
+			}
+			referenced[key] = true
+			return false
+		}
+		return true
+	}, nil)
+	result.Errors = collectCueValidationErrors(dangling)
+	if len(referenced) == 0 {
+		return result, result.Err()
+	}
+	for _, key := range keys {
+		if !referenced[key] {
+			unused = cueErrors.Append(unused, cueErrors.Newf(declared[key],
+				"%s.%s is declared but never referenced", model.OutputsFieldName, key))
+		}
+	}
+	result.Warnings = collectCueValidationErrors(unused)
+	return result, result.Err()
+}
+
+// outputsReferenceOf returns the key of the outputs selected by the reference and its position, false if the
+// reference is not to a key of the top-level outputs
+func outputsReferenceOf(sel *ast.SelectorExpr) (string, token.Pos, bool) {
+	root, selectors := selectorChainOf(sel)
+	id, ok := root.(*ast.Ident)
+	if !ok || id.Name != model.OutputsFieldName {
+		return "", token.NoPos, false
+	}
+	if _, topLevel := id.Scope.(*ast.File); !topLevel {
+		return "", token.NoPos, false
+	}
+	key, _, err := ast.LabelName(selectors[0].Sel)
+	if err != nil {
+		return "", token.NoPos, false
+	}
+	return key, selectors[0].Sel.Pos(), true
+}
+
+// outputKey is a key declared in the outputs
+type outputKey struct {
+	name string
+	pos  token.Pos
+}
+
+// outputKeysOf returns the keys of the outputs struct, including the ones declared under if comprehensions, open is
+// set if the keys can't be all known statically
+func outputKeysOf(value ast.Expr, open *bool) []outputKey {
+	lits := structLitsOf(value)
+	if len(lits) == 0 {
+		*open = true
+	}
+	var keys []outputKey
+	for _, lit := range lits {
+		for _, elt := range lit.Elts {
+			switch e := elt.(type) {
+			case *ast.Field:
+				name, _, err := ast.LabelName(e.Label)
+				if err != nil {
+					*open = true
+					continue
+				}
+				keys = append(keys, outputKey{name: name, pos: e.Pos()})
+			case *ast.Comprehension:
+				if !isIfComprehension(e) {
+					*open = true
+					continue
+				}
+				keys = append(keys, outputKeysOf(e.Value, open)...)
+			case *ast.LetClause, *ast.Attribute, *ast.CommentGroup:
+			default:
+				*open = true
+			}
+		}
+	}
+	return keys
+}
+
+// isIfComprehension checks whether the clauses of the comprehension are all if clauses
+func isIfComprehension(c *ast.Comprehension) bool {
+	for _, clause := range c.Clauses {
+		if _, ok := clause.(*ast.IfClause); !ok {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,106 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateOutputsReferences(t *testing.T) {
+	cases := map[string]struct {
+		cueTemplate  string
+		wantErrors   []CueValidationError
+		wantWarnings []CueValidationError
+	}{
+		"matched": {
+			cueTemplate: `
+output: spec: serviceName: outputs.service.metadata.name
+outputs: service: metadata: name: "api"
+`,
+		},
+		"notReferenced": {
+			cueTemplate: `
+output: kind: "Deployment"
+outputs: service: kind: "Service"
+`,
+		},
+		"danglingAndUnused": {
+			cueTemplate: `
+patch: metadata: annotations: {
+	service: outputs.service.metadata.name
+	ingress: outputs.ingres.metadata.name
+}
+outputs: {
+	service: metadata: name: "api"
+	if true {
+		ingress: metadata: name: "api"
+	}
+}
+`,
+			wantErrors: []CueValidationError{{
+				Message:  "reference outputs.ingres is not declared in outputs",
+				Filename: "-",
+				Line:     4,
+				Column:   19,
+			}},
+			wantWarnings: []CueValidationError{{
+				Message:  "outputs.ingress is declared but never referenced",
+				Filename: "-",
+				Line:     9,
+				Column:   3,
+			}},
+		},
+		"existenceCheck": {
+			cueTemplate: `
+output: {
+	if outputs.ingress != _|_ {
+		spec: host: "api"
+	}
+}
+outputs: service: kind: "Service"
+`,
+			wantWarnings: []CueValidationError{{
+				Message:  "outputs.service is declared but never referenced",
+				Filename: "-",
+				Line:     7,
+				Column:   10,
+			}},
+		},
+		"generatedKeys": {
+			cueTemplate: `
+parameter: names: [...string]
+output: spec: serviceName: outputs.service.metadata.name
+outputs: {
+	for name in parameter.names {
+		"\(name)": metadata: "name": name
+	}
+}
+`,
+		},
+	}
+
+	for caseName, cs := range cases {
+		t.Run(caseName, func(t *testing.T) {
+			result, err := ValidateOutputsReferences(cs.cueTemplate)
+			assert.Equal(t, cs.wantErrors, result.Errors)
+			assert.Equal(t, cs.wantWarnings, result.Warnings)
+			assert.Equal(t, result.Err(), err)
+		})
+	}
+}
@@ -0,0 +1,232 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"context"
+	"fmt"
+
+	"cuelang.org/go/cue"
+	"github.com/kubevela/pkg/cue/cuex"
+	"github.com/kubevela/workflow/pkg/cue/model"
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/common"
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
+	"github.com/oam-dev/kubevela/pkg/oam/util"
+)
+
+// ParameterCompatibilityOptions configures ValidateParameterCompatibilityWithOptions
+type ParameterCompatibilityOptions struct {
+	// AsErrors reports the breaking changes as errors instead of warnings
+	AsErrors bool
+}
+
+// ValidateParameterCompatibility loads the latest DefinitionRevision of the definition and compares the parameter of
+// its cue template with the one of the definition, so that the changes breaking the existing Applications are
+// reported before the definition is applied. A field of the parameter is broken if it's removed while the parameter
+// doesn't accept any field by ... or a pattern, if it becomes required, i.e. it was optional or had a default, or if
+// its type is narrowed, e.g. from int | string to int. The breaking changes are returned as warnings.
+// Nothing is reported if the definition has no DefinitionRevision yet or either template can't be compiled, which is
+// reported by the validation of the template.
+func ValidateParameterCompatibility(ctx context.Context, cli client.Client, def runtime.Object) (*ValidationResult, error) {
+	return ValidateParameterCompatibilityWithOptions(ctx, cli, def, ParameterCompatibilityOptions{})
+}
+
+// ValidateParameterCompatibilityWithOptions reports the breaking changes of the parameter as
+// ValidateParameterCompatibility does, as errors instead of warnings if opts.AsErrors is set.
+func ValidateParameterCompatibilityWithOptions(ctx context.Context, cli client.Client, def runtime.Object, opts ParameterCompatibilityOptions) (*ValidationResult, error) {
+	result := &ValidationResult{}
+	def, err := typedDefinitionOf(def)
+	if err != nil {
+		return result, err
+	}
+	obj, ok := def.(client.Object)
+	if !ok {
+		return result, fmt.Errorf("unsupported definition type %T", def)
+	}
+	defType, schematic, err := definitionSchematicOf(def)
+	if err != nil {
+		return result, err
+	}
+	if schematic == nil || schematic.CUE == nil {
+		return result, nil
+	}
+	rev, err := latestDefinitionRevision(ctx, cli, defType, obj.GetName(), obj.GetNamespace())
+	if err != nil || rev == nil {
+		return result, err
+	}
+	_, oldSchematic, err := definitionSchematicOf(revisionDefinitionOf(rev))
+	if err != nil || oldSchematic == nil || oldSchematic.CUE == nil {
+		return result, err
+	}
+	oldParam, ok := compileParameter(ctx, oldSchematic.CUE.Template)
+	if !ok {
+		return result, nil
+	}
+	newParam, ok := compileParameter(ctx, schematic.CUE.Template)
+	if !ok {
+		return result, nil
+	}
+	var changes []CueValidationError
+	for _, msg := range breakingParameterChangesOf(oldParam, newParam, model.ParameterFieldName) {
+		changes = append(changes, CueValidationError{Message: fmt.Sprintf("breaking change since DefinitionRevision %s: %s", rev.Name, msg)})
+	}
+	if !opts.AsErrors {
+		result.Warnings = changes
+		return result, nil
+	}
+	result.Errors = changes
+	return result, result.Err()
+}
+
+// breakingParameterChangesOf compares the fields of the old parameter with the new one at the path, and returns the
+// messages of the fields removed, becoming required or narrowed
+func breakingParameterChangesOf(oldParam, newParam cue.Value, path string) []string {
+	iter, err := oldParam.Fields(cue.Optional(true))
+	if err != nil {
+		return nil
+	}
+	var msgs []string
+	for iter.Next() {
+		if iter.Selector().LabelType() != cue.StringLabel {
+			continue
+		}
+		sel := cue.Str(iter.Selector().Unquoted())
+		fieldPath := path + "." + sel.String()
+		newField := newParam.LookupPath(cue.MakePath(sel))
+		if !newField.Exists() {
+			newField = newParam.LookupPath(cue.MakePath(sel.Optional()))
+		}
+		if !newField.Exists() {
+			// the removed field is still accepted by the ellipsis or the pattern constraints of the parameter
+			if !newParam.LookupPath(cue.MakePath(cue.AnyString)).Exists() {
+				msgs = append(msgs, fmt.Sprintf("%s is removed", fieldPath))
+			}
+			continue
+		}
+		oldField := iter.Value()
+		if !isRequiredValue(oldField, iter.IsOptional()) && isRequiredValue(newField, isOptionalIn(newParam, sel)) {
+			msgs = append(msgs, fmt.Sprintf("%s becomes required", fieldPath))
+		}
+		oldKind, newKind := oldField.IncompleteKind(), newField.IncompleteKind()
+		if oldKind&^newKind != 0 {
+			msgs = append(msgs, fmt.Sprintf("%s is narrowed from %s to %s", fieldPath, oldKind, newKind))
+			continue
+		}
+		if oldKind == cue.StructKind && newKind == cue.StructKind {
+			msgs = append(msgs, breakingParameterChangesOf(oldField, newField, fieldPath)...)
+		}
+	}
+	return msgs
+}
+
+// isRequiredValue checks whether the value of the field must be set by the user, i.e. the field is not optional and
+// its value has no default and is not concrete
+func isRequiredValue(v cue.Value, optional bool) bool {
+	if optional {
+		return false
+	}
+	if _, ok := v.Default(); ok {
+		return false
+	}
+	return !v.IsConcrete() && v.IncompleteKind() != cue.StructKind
+}
+
+// isOptionalIn checks whether the field of the selector is optional in the struct
+func isOptionalIn(v cue.Value, sel cue.Selector) bool {
+	iter, err := v.Fields(cue.Optional(true))
+	if err != nil {
+		return false
+	}
+	for iter.Next() {
+		if iter.Selector().Unquoted() == sel.Unquoted() {
+			return iter.IsOptional()
+		}
+	}
+	return false
+}
+
+// compileParameter compiles the cue template with CueX and returns its parameter, false if the template can't be
+// compiled or has no parameter
+func compileParameter(ctx context.Context, cueTemplate string) (cue.Value, bool) {
+	return compileParameterWith(ctx, cuex.DefaultCompiler.Get(), cueTemplate)
+}
+
+// compileParameterWith compiles the parameter of the cue template as compileParameter does with the compiler
+func compileParameterWith(ctx context.Context, compiler *cuex.Compiler, cueTemplate string) (cue.Value, bool) {
+	val, err := compiler.CompileStringWithOptions(ctx, cueTemplate+contextStub, cuex.DisableResolveProviderFunctions{})
+	if err != nil || val.Err() != nil {
+		return cue.Value{}, false
+	}
+	param := val.LookupPath(cue.ParsePath(model.ParameterFieldName))
+	return param, param.Exists() && param.Err() == nil
+}
+
+// latestDefinitionRevision returns the DefinitionRevision of the definition with the max revision number, nil if
+// there is none
+func latestDefinitionRevision(ctx context.Context, cli client.Client, defType common.DefinitionType, defName, namespace string) (*v1beta1.DefinitionRevision, error) {
+	revs := &v1beta1.DefinitionRevisionList{}
+	if err := cli.List(ctx, revs, client.InNamespace(namespace), client.MatchingLabels{util.DefinitionKindToNameLabel[defType]: defName}); err != nil {
+		return nil, errors.Wrapf(err, "failed to list DefinitionRevisions of definition %s", defName)
+	}
+	var latest *v1beta1.DefinitionRevision
+	for i := range revs.Items {
+		rev := &revs.Items[i]
+		if rev.Spec.DefinitionType != defType {
+			continue
+		}
+		if latest == nil || rev.Spec.Revision > latest.Spec.Revision {
+			latest = rev
+		}
+	}
+	return latest, nil
+}
+
+// revisionDefinitionOf returns the definition embedded in the DefinitionRevision
+func revisionDefinitionOf(rev *v1beta1.DefinitionRevision) runtime.Object {
+	switch rev.Spec.DefinitionType {
+	case common.ComponentType:
+		return &rev.Spec.ComponentDefinition
+	case common.TraitType:
+		return &rev.Spec.TraitDefinition
+	case common.PolicyType:
+		return &rev.Spec.PolicyDefinition
+	case common.WorkflowStepType:
+		return &rev.Spec.WorkflowStepDefinition
+	default:
+		return nil
+	}
+}
+
+// definitionSchematicOf returns the type and the schematic of the typed definition
+func definitionSchematicOf(def runtime.Object) (common.DefinitionType, *common.Schematic, error) {
+	switch d := def.(type) {
+	case *v1beta1.ComponentDefinition:
+		return common.ComponentType, d.Spec.Schematic, nil
+	case *v1beta1.TraitDefinition:
+		return common.TraitType, d.Spec.Schematic, nil
+	case *v1beta1.PolicyDefinition:
+		return common.PolicyType, d.Spec.Schematic, nil
+	case *v1beta1.WorkflowStepDefinition:
+		return common.WorkflowStepType, d.Spec.Schematic, nil
+	default:
+		return "", nil, fmt.Errorf("unsupported definition type %T", def)
+	}
+}
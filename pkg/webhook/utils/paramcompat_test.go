@@ -0,0 +1,142 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	apicommon "github.com/oam-dev/kubevela/apis/core.oam.dev/common"
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
+	"github.com/oam-dev/kubevela/pkg/oam"
+	"github.com/oam-dev/kubevela/pkg/utils/common"
+)
+
+func TestValidateParameterCompatibility(t *testing.T) {
+	defer setFakeCuexCompiler()()
+	componentDef := func(name, template string) *v1beta1.ComponentDefinition {
+		return &v1beta1.ComponentDefinition{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+			Spec: v1beta1.ComponentDefinitionSpec{
+				Schematic: &apicommon.Schematic{CUE: &apicommon.CUE{Template: template}},
+			},
+		}
+	}
+	revision := func(name string, revision int64, template string) *v1beta1.DefinitionRevision {
+		return &v1beta1.DefinitionRevision{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      fmt.Sprintf("%s-v%d", name, revision),
+				Namespace: "default",
+				Labels:    map[string]string{oam.LabelComponentDefinitionName: name},
+			},
+			Spec: v1beta1.DefinitionRevisionSpec{
+				Revision:            revision,
+				DefinitionType:      apicommon.ComponentType,
+				ComponentDefinition: *componentDef(name, template),
+			},
+		}
+	}
+	cli := fake.NewClientBuilder().WithScheme(common.Scheme).WithObjects(
+		revision("worker", 1, `parameter: {image: string}`),
+		revision("worker", 2, `
+parameter: {
+	image:    string
+	cmd?:     string
+	port:     *80 | int
+	replicas: int | string
+	env: {
+		name:   string
+		value?: string
+	}
+}`),
+		revision("task", 1, `parameter: {image: string}`),
+	).Build()
+
+	cases := map[string]struct {
+		def          *v1beta1.ComponentDefinition
+		asErrors     bool
+		wantWarnings []string
+		wantErr      string
+	}{
+		"compatible": {
+			def: componentDef("worker", `
+parameter: {
+	image:    string
+	cmd?:     string
+	port:     *8080 | int
+	replicas: int | string
+	env: {
+		name:   string
+		value?: string
+	}
+	labels?: [string]: string
+}`),
+		},
+		"breaking": {
+			def: componentDef("worker", `
+parameter: {
+	image:    string
+	cmd:      string
+	port:     int
+	replicas: int
+	env: {
+		name: string
+	}
+}`),
+			wantWarnings: []string{
+				"breaking change since DefinitionRevision worker-v2: parameter.cmd becomes required",
+				"breaking change since DefinitionRevision worker-v2: parameter.port becomes required",
+				"breaking change since DefinitionRevision worker-v2: parameter.replicas is narrowed from (int|string) to int",
+				"breaking change since DefinitionRevision worker-v2: parameter.env.value is removed",
+			},
+		},
+		"removedButOpen": {
+			def: componentDef("task", `parameter: {...}`),
+		},
+		"asErrors": {
+			def:      componentDef("task", `parameter: {name: string}`),
+			asErrors: true,
+			wantErr:  "breaking change since DefinitionRevision task-v1: parameter.image is removed",
+		},
+		"noRevision": {
+			def: componentDef("new", `parameter: {name: string}`),
+		},
+		"invalidTemplate": {
+			def: componentDef("worker", `parameter: {image: }`),
+		},
+	}
+
+	for caseName, cs := range cases {
+		t.Run(caseName, func(t *testing.T) {
+			result, err := ValidateParameterCompatibility(context.Background(), cli, cs.def)
+			if cs.asErrors {
+				result, err = ValidateParameterCompatibilityWithOptions(context.Background(), cli, cs.def, ParameterCompatibilityOptions{AsErrors: true})
+			}
+			assert.Equal(t, cs.wantWarnings, result.WarningMessages())
+			if cs.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.EqualError(t, err, cs.wantErr)
+		})
+	}
+}
@@ -0,0 +1,115 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"context"
+	"fmt"
+
+	"cuelang.org/go/cue"
+	"cuelang.org/go/cue/token"
+	"github.com/kubevela/workflow/pkg/cue/model"
+)
+
+// ParameterMaxDisjunctions is the max number of the branches of the disjunction of a field of the parameter, e.g. an
+// enum of the regions, as the large disjunctions slow down the evaluation and the UI generated from the schema.
+// The limit is disabled if it's not positive.
+var ParameterMaxDisjunctions = 64
+
+// parameterSchemaMaxDepth bounds the walk of the parameter, whose schema may be recursive through the definitions
+const parameterSchemaMaxDepth = 32
+
+// ParameterDisjunctionOptions configures ValidateParameterDisjunctions
+type ParameterDisjunctionOptions struct {
+	// MaxBranches overrides ParameterMaxDisjunctions if it's positive
+	MaxBranches int
+	// AsErrors reports the large disjunctions as errors instead of warnings
+	AsErrors bool
+}
+
+// ValidateParameterDisjunctions counts the branches of the disjunction of every field of the parameter, including
+// the elements of the lists, the pattern constraints and the disjunctions behind the references to the definitions,
+// and reports the fields with more branches than the max along with their paths and counts, so that the authors can
+// refactor them into a constrained string. The large disjunctions are returned as warnings, or as errors if
+// opts.AsErrors is set.
+// Nothing is reported if the template can't be compiled or has no parameter, which is reported by the validation.
+func ValidateParameterDisjunctions(ctx context.Context, cueTemplate string, opts ParameterDisjunctionOptions) (*ValidationResult, error) {
+	result := &ValidationResult{}
+	maxBranches := ParameterMaxDisjunctions
+	if opts.MaxBranches > 0 {
+		maxBranches = opts.MaxBranches
+	}
+	if maxBranches <= 0 {
+		return result, nil
+	}
+	param, ok := compileParameter(ctx, cueTemplate)
+	if !ok {
+		return result, nil
+	}
+	var findings []CueValidationError
+	// the disjunctions reached again through a recursive definition are reported once
+	reported := map[token.Pos]bool{}
+	var walk func(v cue.Value, path string, depth int)
+	walk = func(v cue.Value, path string, depth int) {
+		if depth > parameterSchemaMaxDepth {
+			return
+		}
+		if n := disjunctionBranchesOf(v); n > maxBranches && !reported[v.Pos()] {
+			reported[v.Pos()] = true
+			findings = append(findings, CueValidationError{
+				Message: fmt.Sprintf("%s has %d disjunction branches, more than the max %d, consider a constrained string instead", path, n, maxBranches),
+				Line:    v.Pos().Line(),
+				Column:  v.Pos().Column(),
+			})
+		}
+		if iter, err := v.Fields(cue.Optional(true)); err == nil {
+			for iter.Next() {
+				if iter.Selector().LabelType() == cue.StringLabel {
+					walk(iter.Value(), path+"."+iter.Selector().Unquoted(), depth+1)
+				}
+			}
+		}
+		if elem := v.LookupPath(cue.MakePath(cue.AnyIndex)); elem.Exists() {
+			walk(elem, path+"[_]", depth+1)
+		}
+		if pattern := v.LookupPath(cue.MakePath(cue.AnyString)); pattern.Exists() {
+			walk(pattern, path+"[string]", depth+1)
+		}
+	}
+	walk(param, model.ParameterFieldName, 0)
+	if !opts.AsErrors {
+		result.Warnings = findings
+		return result, nil
+	}
+	result.Errors = findings
+	return result, result.Err()
+}
+
+// disjunctionBranchesOf returns the number of the branches of the disjunction of the value, the nested disjunctions
+// and the ones of the referenced values are flattened, e.g. 4 for `1 | 2 | #Enum` with `#Enum: 3 | 4`. It's 1 if the
+// value is not a disjunction.
+func disjunctionBranchesOf(v cue.Value) int {
+	op, args := cue.Dereference(v).Expr()
+	if op != cue.OrOp {
+		return 1
+	}
+	n := 0
+	for _, arg := range args {
+		n += disjunctionBranchesOf(arg)
+	}
+	return n
+}
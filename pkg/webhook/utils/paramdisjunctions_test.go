@@ -0,0 +1,93 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateParameterDisjunctions(t *testing.T) {
+	defer setFakeCuexCompiler()()
+	const cueTemplate = `
+#Zone: "a" | "b" | "c" | "d"
+parameter: {
+	region: *"us" | "eu" | "ap"
+	zone:   #Zone
+	ports?: [...(80 | 443 | 8080 | 8443)]
+	labels: [string]: "x" | "y"
+	nested: tier: 1 | 2 | (3 | 4)
+	image:  string
+}
+output: {}
+`
+
+	cases := map[string]struct {
+		cueTemplate  string
+		opts         ParameterDisjunctionOptions
+		wantWarnings []string
+		wantErr      string
+	}{
+		"underLimit": {
+			cueTemplate: cueTemplate,
+		},
+		"overLimit": {
+			cueTemplate: cueTemplate,
+			opts:        ParameterDisjunctionOptions{MaxBranches: 3},
+			wantWarnings: []string{
+				"line 5: parameter.zone has 4 disjunction branches, more than the max 3, consider a constrained string instead",
+				"line 6: parameter.ports[_] has 4 disjunction branches, more than the max 3, consider a constrained string instead",
+				"line 8: parameter.nested.tier has 4 disjunction branches, more than the max 3, consider a constrained string instead",
+			},
+		},
+		"asErrors": {
+			cueTemplate: `parameter: size: "s" | "m" | "l"`,
+			opts:        ParameterDisjunctionOptions{MaxBranches: 2, AsErrors: true},
+			wantErr:     "line 1: parameter.size has 3 disjunction branches, more than the max 2, consider a constrained string instead",
+		},
+		"recursiveSchema": {
+			cueTemplate: `
+#Node: {
+	kind: "a" | "b" | "c"
+	children?: [...#Node]
+}
+parameter: root: #Node`,
+			opts: ParameterDisjunctionOptions{MaxBranches: 2},
+			wantWarnings: []string{
+				"line 3: parameter.root.kind has 3 disjunction branches, more than the max 2, consider a constrained string instead",
+			},
+		},
+		"invalidTemplate": {
+			cueTemplate: `parameter: size: `,
+			opts:        ParameterDisjunctionOptions{MaxBranches: 1},
+		},
+	}
+
+	for caseName, cs := range cases {
+		t.Run(caseName, func(t *testing.T) {
+			result, err := ValidateParameterDisjunctions(context.Background(), cs.cueTemplate, cs.opts)
+			assert.Equal(t, cs.wantWarnings, result.WarningMessages())
+			if cs.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.EqualError(t, err, cs.wantErr)
+		})
+	}
+}
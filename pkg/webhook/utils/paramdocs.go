@@ -0,0 +1,150 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"strings"
+
+	"cuelang.org/go/cue/ast"
+	cueErrors "cuelang.org/go/cue/errors"
+	"cuelang.org/go/cue/parser"
+	"cuelang.org/go/cue/token"
+	"github.com/kubevela/workflow/pkg/cue/model"
+)
+
+// usageMarker is the marker of the comment describing the parameter for the generated docs, e.g. // +usage=...
+const usageMarker = "+usage="
+
+// ParameterDocsOptions configures ValidateParameterDocs
+type ParameterDocsOptions struct {
+	// AsErrors reports the undocumented parameters as errors instead of warnings
+	AsErrors bool
+}
+
+// ValidateParameterDocs checks that every required field of the parameter, i.e. the one neither optional nor with
+// a default, carries a description, which is either a // comment or a +usage marker, so that the reference docs
+// and the UI generated from the schema are complete. The comments consisting of the other markers only, e.g.
+// +short, are not descriptions. The undocumented parameters are returned as warnings, or as errors if
+// opts.AsErrors is set.
+func ValidateParameterDocs(cueTemplate string, opts ParameterDocsOptions) (*ValidationResult, error) {
+	result := &ValidationResult{}
+	f, err := parser.ParseFile("-", cueTemplate, parser.ParseComments)
+	if err != nil {
+		// the syntax error is reported by the validation
+		return result, nil
+	}
+	var missing cueErrors.Error
+	for _, decl := range f.Decls {
+		field, ok := decl.(*ast.Field)
+		if !ok {
+			continue
+		}
+		if name, _, err := ast.LabelName(field.Label); err == nil && name == model.ParameterFieldName {
+			for _, err := range undocumentedParametersOf(field.Value, model.ParameterFieldName) {
+				missing = cueErrors.Append(missing, err)
+			}
+		}
+	}
+	if missing == nil {
+		return result, nil
+	}
+	if !opts.AsErrors {
+		result.Warnings = collectCueValidationErrors(missing)
+		return result, nil
+	}
+	result.Errors = collectCueValidationErrors(missing)
+	return result, result.Err()
+}
+
+// undocumentedParametersOf returns the errors of the required fields without a description in the struct literals
+// unified in the value, and in their subfields
+func undocumentedParametersOf(value ast.Expr, prefix string) []cueErrors.Error {
+	var errs []cueErrors.Error
+	for _, lit := range structLitsOf(value) {
+		for _, elt := range lit.Elts {
+			field, ok := elt.(*ast.Field)
+			if !ok {
+				continue
+			}
+			name, _, err := ast.LabelName(field.Label)
+			if err != nil {
+				continue
+			}
+			path := prefix + "." + name
+			if isRequiredParameter(field) && !hasDescription(field) {
+				errs = append(errs, cueErrors.Newf(field.Pos(), "%s is required but has no description, add a // comment or a %s marker", path, usageMarker))
+			}
+			errs = append(errs, undocumentedParametersOf(field.Value, path)...)
+		}
+	}
+	return errs
+}
+
+// structLitsOf returns the struct literals unified in the expression
+func structLitsOf(expr ast.Expr) []*ast.StructLit {
+	switch x := expr.(type) {
+	case *ast.StructLit:
+		return []*ast.StructLit{x}
+	case *ast.ParenExpr:
+		return structLitsOf(x.X)
+	case *ast.BinaryExpr:
+		if x.Op == token.AND {
+			return append(structLitsOf(x.X), structLitsOf(x.Y)...)
+		}
+	}
+	return nil
+}
+
+// isRequiredParameter checks whether the field must be set by the user, i.e. it's not optional and has no default
+func isRequiredParameter(field *ast.Field) bool {
+	if field.Constraint == token.OPTION {
+		return false
+	}
+	return !hasDefault(field.Value)
+}
+
+// hasDefault checks whether the expression is or contains a default marked by *
+func hasDefault(expr ast.Expr) bool {
+	switch x := expr.(type) {
+	case *ast.UnaryExpr:
+		return x.Op == token.MUL
+	case *ast.ParenExpr:
+		return hasDefault(x.X)
+	case *ast.BinaryExpr:
+		if x.Op == token.OR || x.Op == token.AND {
+			return hasDefault(x.X) || hasDefault(x.Y)
+		}
+	}
+	return false
+}
+
+// hasDescription checks whether one of the comments of the field is a description, i.e. a +usage marker or a
+// comment that is not a marker
+func hasDescription(field *ast.Field) bool {
+	for _, group := range ast.Comments(field) {
+		for _, c := range group.List {
+			text := strings.TrimSpace(strings.TrimPrefix(c.Text, "//"))
+			if strings.HasPrefix(text, usageMarker) && strings.TrimSpace(strings.TrimPrefix(text, usageMarker)) != "" {
+				return true
+			}
+			if text != "" && !strings.HasPrefix(text, "+") {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,77 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateParameterDocs(t *testing.T) {
+	cueTemplate := `
+output: {
+	image: parameter.image
+}
+parameter: {
+	// +usage=Which image would you like to use for your service
+	// +short=i
+	image: string
+	// the port exposed by the service
+	port: int
+	// +short=r
+	replicas: int
+	cpu?: string
+	memory: *"1Gi" | string
+	env: [...{
+		name: string
+	}]
+	resources: {
+		// +usage=
+		limits: string
+	}
+}
+parameter: {
+	tag: string // the tag of the image
+}
+`
+	wantMessages := []string{
+		"parameter.replicas is required but has no description, add a // comment or a +usage= marker",
+		"parameter.env is required but has no description, add a // comment or a +usage= marker",
+		"parameter.resources is required but has no description, add a // comment or a +usage= marker",
+		"parameter.resources.limits is required but has no description, add a // comment or a +usage= marker",
+	}
+
+	result, err := ValidateParameterDocs(cueTemplate, ParameterDocsOptions{})
+	assert.NoError(t, err)
+	assert.Empty(t, result.Errors)
+	var msgs []string
+	for _, w := range result.Warnings {
+		msgs = append(msgs, w.Message)
+	}
+	assert.Equal(t, wantMessages, msgs)
+	assert.Equal(t, 12, result.Warnings[0].Line)
+
+	result, err = ValidateParameterDocs(cueTemplate, ParameterDocsOptions{AsErrors: true})
+	assert.Error(t, err)
+	assert.Empty(t, result.Warnings)
+	assert.Len(t, result.Errors, len(wantMessages))
+
+	result, err = ValidateParameterDocs("parameter: {\n\t// the name\n\tname: string\n}", ParameterDocsOptions{AsErrors: true})
+	assert.NoError(t, err)
+	assert.Empty(t, result.Errors)
+}
@@ -0,0 +1,268 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"cuelang.org/go/cue"
+	"cuelang.org/go/cue/ast"
+	cueErrors "cuelang.org/go/cue/errors"
+	"cuelang.org/go/cue/format"
+	"cuelang.org/go/cue/parser"
+)
+
+const (
+	// parameterFieldName is the field of the template that declares the parameter schema
+	parameterFieldName = "parameter"
+	// contextStub is appended to the template so that the references to context can be resolved
+	// without the runtime context, which allows evaluating the rest of the template.
+	contextStub = "\ncontext: _\n"
+)
+
+// ValidateParameterDefaults validates that the default values declared in the parameter of the cueTemplate
+// are valid instances of their declared types, e.g. `replicas: *1.5 | int` is rejected as 1.5 is not an int.
+// The defaults referencing each other in a cycle, e.g. `a: *b | int, b: *a | int`, are rejected with ErrDefaultCycle.
+// The defaults of the enums dropped by the other declarations of the field, e.g. `mode: *"fooo" | "bar"` unified with
+// `mode: "foo" | "bar"`, are rejected as well.
+func ValidateParameterDefaults(cueTemplate string) error {
+	val, err := compileAndValidate(context.Background(), cueTemplate)
+	if err != nil {
+		return err
+	}
+	_, err = validateParameterDefaultsOf(val)
+	return err
+}
+
+// checkParameterDefaults checks the defaults of every field under the parameter of the evaluated template
+func checkParameterDefaults(val cue.Value) []CueValidationError {
+	parameter := val.LookupPath(cue.ParsePath(parameterFieldName))
+	if !parameter.Exists() {
+		return nil
+	}
+	return checkStructDefaults(parameter, parameterFieldName)
+}
+
+func checkStructDefaults(val cue.Value, path string) []CueValidationError {
+	if val.IncompleteKind() != cue.StructKind {
+		return nil
+	}
+	iter, err := val.Fields(cue.Optional(true))
+	if err != nil {
+		return nil
+	}
+	var errs []CueValidationError
+	for iter.Next() {
+		fieldPath := path + "." + strings.TrimSuffix(iter.Selector().String(), "?")
+		field := iter.Value()
+		msg := ""
+		if m := checkFieldDefault(field); m != "" {
+			msg = fmt.Sprintf("%s default %s", fieldPath, m)
+		} else if def, ok := droppedDefaultOf(field); ok {
+			msg = fmt.Sprintf("default '%s' is not a valid option for %s", optionString(def), fieldPath)
+		}
+		if msg != "" {
+			pos := field.Pos()
+			errs = append(errs, CueValidationError{
+				Message:  msg,
+				Filename: pos.Filename(),
+				Line:     pos.Line(),
+				Column:   pos.Column(),
+			})
+		}
+		errs = append(errs, checkStructDefaults(field, fieldPath)...)
+	}
+	return errs
+}
+
+// checkFieldDefault checks whether the default value of the field is an instance of the other disjuncts,
+// and returns the reason if it's not. Enums with concrete values only, e.g. `*"a" | "b"`, and null defaults
+// are always valid as the default is one of the options.
+func checkFieldDefault(field cue.Value) string {
+	def, ok := field.Default()
+	if !ok || def.IsNull() {
+		return ""
+	}
+	_, disjuncts := field.Expr()
+	var types []cue.Value
+	for _, d := range disjuncts {
+		if d.IsConcrete() && def.Equals(d) {
+			continue
+		}
+		if !d.IsConcrete() {
+			types = append(types, d)
+		}
+	}
+	if len(types) == 0 {
+		return ""
+	}
+	var descs []string
+	for _, t := range types {
+		if def.Unify(t).Validate() == nil {
+			return ""
+		}
+		descs = append(descs, fmt.Sprint(t))
+	}
+	if len(descs) == 1 && isBuiltinKindName(descs[0]) {
+		return fmt.Sprintf("'%v' is not %s %s", def, indefiniteArticle(descs[0]), descs[0])
+	}
+	return fmt.Sprintf("'%v' does not match %s", def, strings.Join(descs, " | "))
+}
+
+// droppedDefaultOf returns the default marked by a conjunct of the field which is not one of the options left by the
+// other conjuncts, e.g. 'fooo' of `*"fooo" | "bar"` unified with the enum `"foo" | "bar"`. CUE silently drops such a
+// default, so that the field ends up without one.
+func droppedDefaultOf(field cue.Value) (cue.Value, bool) {
+	if _, ok := field.Default(); ok {
+		return cue.Value{}, false
+	}
+	op, conjuncts := field.Expr()
+	if op != cue.AndOp {
+		return cue.Value{}, false
+	}
+	for _, conjunct := range conjuncts {
+		def, ok := conjunct.Default()
+		if !ok || !def.IsConcrete() || def.IsNull() {
+			continue
+		}
+		if field.Unify(def).Validate() != nil {
+			return def, true
+		}
+	}
+	return cue.Value{}, false
+}
+
+// optionString formats the option of an enum, the strings are not quoted
+func optionString(v cue.Value) string {
+	if s, err := v.String(); err == nil {
+		return s
+	}
+	return fmt.Sprint(v)
+}
+
+func isBuiltinKindName(name string) bool {
+	switch name {
+	case "int", "float", "number", "string", "bool", "bytes":
+		return true
+	}
+	return false
+}
+
+func indefiniteArticle(word string) string {
+	if strings.ContainsAny(word[:1], "aeiou") {
+		return "an"
+	}
+	return "a"
+}
+
+// ValidateCueTemplateStrict validates the cueTemplate as ValidateCueTemplate does, and also rejects the open
+// structs, i.e. structs with `...`, in the parameter, so that the users cannot pass fields that are not declared.
+// The definitions referenced by the parameter are checked as well, pattern constraints like `[string]: string`
+// and lists like `[...string]` are allowed.
+func ValidateCueTemplateStrict(cueTemplate string) error {
+	if err := ValidateCueTemplate(cueTemplate); err != nil {
+		return err
+	}
+	f, err := parser.ParseFile("-", cueTemplate)
+	if err != nil {
+		return err
+	}
+	return aggregateErrors(checkOpenParameterStructs(f))
+}
+
+// openStructChecker walks the parameter AST and records every open struct with its field path
+type openStructChecker struct {
+	definitions map[string][]ast.Expr
+	visiting    map[string]bool
+	errs        []error
+}
+
+func checkOpenParameterStructs(f *ast.File) []error {
+	c := &openStructChecker{definitions: map[string][]ast.Expr{}, visiting: map[string]bool{}}
+	var parameters []ast.Expr
+	for _, decl := range f.Decls {
+		field, ok := decl.(*ast.Field)
+		if !ok {
+			continue
+		}
+		name, _, err := ast.LabelName(field.Label)
+		if err != nil {
+			continue
+		}
+		switch {
+		case name == parameterFieldName:
+			parameters = append(parameters, field.Value)
+		case strings.HasPrefix(name, "#"):
+			c.definitions[name] = append(c.definitions[name], field.Value)
+		}
+	}
+	for _, expr := range parameters {
+		c.check(expr, parameterFieldName)
+	}
+	return c.errs
+}
+
+func (c *openStructChecker) check(expr ast.Expr, path string) {
+	switch x := expr.(type) {
+	case *ast.StructLit:
+		for _, elt := range x.Elts {
+			switch e := elt.(type) {
+			case *ast.Ellipsis:
+				c.errs = append(c.errs, cueErrors.Newf(e.Pos(), "%s is an open struct, which is not allowed in strict mode", path))
+			case *ast.Field:
+				c.check(e.Value, path+"."+labelString(e.Label))
+			case *ast.EmbedDecl:
+				c.check(e.Expr, path)
+			}
+		}
+	case *ast.ListLit:
+		for _, elt := range x.Elts {
+			if e, ok := elt.(*ast.Ellipsis); ok {
+				elt = e.Type
+			}
+			c.check(elt, path+"[]")
+		}
+	case *ast.BinaryExpr:
+		c.check(x.X, path)
+		c.check(x.Y, path)
+	case *ast.UnaryExpr:
+		c.check(x.X, path)
+	case *ast.ParenExpr:
+		c.check(x.X, path)
+	case *ast.Ident:
+		if c.visiting[x.Name] {
+			return
+		}
+		c.visiting[x.Name] = true
+		for _, def := range c.definitions[x.Name] {
+			c.check(def, path)
+		}
+		c.visiting[x.Name] = false
+	}
+}
+
+func labelString(label ast.Label) string {
+	if name, _, err := ast.LabelName(label); err == nil {
+		return name
+	}
+	if bs, err := format.Node(label); err == nil {
+		return string(bs)
+	}
+	return "_"
+}
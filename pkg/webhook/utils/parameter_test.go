@@ -0,0 +1,191 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateParameterDefaults(t *testing.T) {
+	cases := map[string]struct {
+		cueTemplate string
+		wantErr     string
+	}{
+		"noParameter": {
+			cueTemplate: `output: name: context.name`,
+		},
+		"validDefaults": {
+			cueTemplate: `
+parameter: {
+	replicas: *1 | int
+	cpu: *0.5 | number
+	image: *"nginx" | string
+	exposeType: *"ClusterIP" | "NodePort" | "LoadBalancer"
+	labels?: [string]: string
+	ports: *[] | [...int]
+	nullable: *null | string
+	memory: *"512Mi" | =~"^[0-9]+Mi$"
+	nested: enabled: *true | bool
+}
+output: spec: replicas: parameter.replicas
+output: metadata: name: context.name`,
+		},
+		"floatDefaultForInt": {
+			cueTemplate: `
+parameter: replicas: *1.5 | int
+output: spec: replicas: parameter.replicas`,
+			wantErr: "parameter.replicas default '1.5' is not an int",
+		},
+		"stringDefaultForInt": {
+			cueTemplate: `
+parameter: port?: *"80" | int`,
+			wantErr: "parameter.port default '\"80\"' is not an int",
+		},
+		"nestedDefault": {
+			cueTemplate: `
+parameter: probe: enabled: *"yes" | bool`,
+			wantErr: "parameter.probe.enabled default '\"yes\"' is not a bool",
+		},
+		"defaultNotMatchingPattern": {
+			cueTemplate: `
+parameter: memory: *"4Gi" | =~"^[0-9]+Mi$"`,
+			wantErr: "parameter.memory default '\"4Gi\"' does not match =~\"^[0-9]+Mi$\"",
+		},
+		"enumDefaultDropped": {
+			cueTemplate: `
+parameter: mode: *"fooo" | "bar" | "baz"
+parameter: mode: "foo" | "bar" | "baz"`,
+			wantErr: "default 'fooo' is not a valid option for parameter.mode",
+		},
+		"enumDefaultDroppedByDefinition": {
+			cueTemplate: `
+#Mode: "foo" | "bar" | "baz"
+parameter: mode: #Mode & (*"fooo" | string)`,
+			wantErr: "default 'fooo' is not a valid option for parameter.mode",
+		},
+		"enumDefaultRefined": {
+			cueTemplate: `
+parameter: mode: *"foo" | "bar" | "baz"
+parameter: mode: "foo" | "bar"`,
+		},
+		"enumWithoutDefault": {
+			cueTemplate: `
+parameter: mode: "foo" | "bar"
+parameter: mode: string`,
+		},
+		"defaultCycle": {
+			cueTemplate: `
+parameter: {
+	a: *b | int
+	b: *a | int
+}`,
+			wantErr: "default values of the parameter form a cycle: parameter.a -> parameter.b -> parameter.a",
+		},
+	}
+	for caseName, cs := range cases {
+		t.Run(caseName, func(t *testing.T) {
+			err := ValidateParameterDefaults(cs.cueTemplate)
+			if cs.wantErr == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, cs.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateCueTemplateStrict(t *testing.T) {
+	cases := map[string]struct {
+		cueTemplate string
+		wantErr     string
+	}{
+		"closedParameter": {
+			cueTemplate: `
+parameter: {
+	image: string
+	ports: [...{port: int}]
+	labels?: [string]: string
+	args?: [...string]
+	probe?: close({path: string, ...})
+}
+output: {
+	metadata: name: context.name
+	spec: image: parameter.image
+}`,
+		},
+		"openParameter": {
+			cueTemplate: `
+parameter: {
+	image: string
+	...
+}`,
+			wantErr: "parameter is an open struct, which is not allowed in strict mode",
+		},
+		"nestedOpenStructs": {
+			cueTemplate: `
+parameter: {
+	env: {
+		name: string
+		...
+	}
+	volumes: [...{
+		name: string
+		...
+	}]
+	resources: *null | {cpu: string, ...}
+}`,
+			wantErr: "[parameter.env is an open struct, which is not allowed in strict mode, " +
+				"parameter.volumes[] is an open struct, which is not allowed in strict mode, " +
+				"parameter.resources is an open struct, which is not allowed in strict mode]",
+		},
+		"openDefinition": {
+			cueTemplate: `
+#Probe: {
+	path: string
+	...
+}
+parameter: livenessProbe?: #Probe`,
+			wantErr: "parameter.livenessProbe is an open struct, which is not allowed in strict mode",
+		},
+		"openStructOutsideParameter": {
+			cueTemplate: `
+parameter: image: string
+output: {
+	image: parameter.image
+	...
+}`,
+		},
+		"invalidCueTemplate": {
+			cueTemplate: `
+parameter: image: string
+output: hello: world`,
+			wantErr: "output.hello: reference \"world\" not found",
+		},
+	}
+	for caseName, cs := range cases {
+		t.Run(caseName, func(t *testing.T) {
+			err := ValidateCueTemplateStrict(cs.cueTemplate)
+			if cs.wantErr == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, cs.wantErr)
+			}
+		})
+	}
+}
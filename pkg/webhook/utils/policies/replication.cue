@@ -0,0 +1,9 @@
+// replication.cue is the template of the built-in replication policy, keep it in sync with
+// vela-templates/definitions/internal/policy/replication.cue
+
+parameter: {
+	// +usage=Spicify the keys of replication. Every key coresponds to a replication components
+	keys: [...string]
+	// +usage=Specify the components which will be replicated.
+	selector?: [...string]
+}
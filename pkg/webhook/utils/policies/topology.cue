@@ -0,0 +1,15 @@
+// topology.cue is the template of the built-in topology policy, keep it in sync with
+// vela-templates/definitions/internal/policy/topology.cue
+
+parameter: {
+	// +usage=Specify the names of the clusters to select.
+	clusters?: [...string]
+	// +usage=Specify the label selector for clusters
+	clusterLabelSelector?: [string]: string
+	// +usage=Ignore empty cluster error
+	allowEmpty?: bool
+	// +usage=Deprecated: Use clusterLabelSelector instead.
+	clusterSelector?: [string]: string
+	// +usage=Specify the target namespace to deploy in the selected clusters, default inherit the original namespace.
+	namespace?: string
+}
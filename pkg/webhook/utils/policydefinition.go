@@ -0,0 +1,133 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"context"
+
+	"cuelang.org/go/cue"
+	"cuelang.org/go/cue/ast"
+	"cuelang.org/go/cue/format"
+	"github.com/kubevela/pkg/cue/cuex"
+	"github.com/pkg/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1alpha1"
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
+	"github.com/oam-dev/kubevela/pkg/oam"
+)
+
+// ReservedPolicyNames are the policy types handled by the application controller itself.
+// A PolicyDefinition with one of these names outside the system namespace shadows the built-in policy
+// when the application is rendered, so it must be explicitly allowed by annotation.
+var ReservedPolicyNames = []string{
+	v1alpha1.TopologyPolicyType,
+	v1alpha1.OverridePolicyType,
+	v1alpha1.DebugPolicyType,
+	v1alpha1.ReplicationPolicyType,
+	v1alpha1.GarbageCollectPolicyType,
+	v1alpha1.ApplyOncePolicyType,
+	v1alpha1.SharedResourcePolicyType,
+	v1alpha1.TakeOverPolicyType,
+	v1alpha1.ReadOnlyPolicyType,
+	v1alpha1.ResourceUpdatePolicyType,
+	v1alpha1.EnvBindingPolicyType,
+}
+
+// ValidatePolicyDefinition validates the policy's cue template and rejects the definition if its name or the
+// fields of its parameter shadow a built-in policy type. The built-in definitions installed in the system namespace
+// are allowed, others need the annotation oam.AnnotationAllowReservedPolicyName set to "true".
+func ValidatePolicyDefinition(ctx context.Context, _ client.Client, pd *v1beta1.PolicyDefinition) error {
+	if pd.Spec.Schematic != nil && pd.Spec.Schematic.CUE != nil && !isCueTemplateValidated(ctx) {
+		if _, err := ValidateCueTemplateDetailedWithContext(WithDefinitionKind(WithDefinitionName(ctx, pd.Name), v1beta1.PolicyDefinitionKind), pd.Spec.Schematic.CUE.Template); err != nil {
+			return err
+		}
+	}
+	return validatePolicyShadowing(ctx, cuex.DefaultCompiler.Get(), pd)
+}
+
+// validatePolicyShadowing rejects the policy whose name or parameter shadows a built-in policy type, the parameter
+// is compiled by compiler
+func validatePolicyShadowing(ctx context.Context, compiler *cuex.Compiler, pd *v1beta1.PolicyDefinition) error {
+	if pd.Namespace == oam.SystemDefinitionNamespace || pd.GetAnnotations()[oam.AnnotationAllowReservedPolicyName] == "true" {
+		return nil
+	}
+	if isReservedPolicyName(pd.Name) {
+		return errors.Errorf("PolicyDefinition %s is invalid: the name conflicts with the built-in policy type %s, "+
+			"set the annotation %s to \"true\" to override it", pd.Name, pd.Name, oam.AnnotationAllowReservedPolicyName)
+	}
+	if pd.Spec.Schematic == nil || pd.Spec.Schematic.CUE == nil {
+		return nil
+	}
+	if policyType := builtinPolicyShadowedBy(ctx, compiler, pd.Spec.Schematic.CUE.Template); policyType != "" {
+		return errors.Errorf("PolicyDefinition %s is invalid: the parameter has the same shape as the built-in policy type %s, "+
+			"set the annotation %s to \"true\" to override it", pd.Name, policyType, oam.AnnotationAllowReservedPolicyName)
+	}
+	return nil
+}
+
+// builtinPolicyShadowedBy returns the built-in policy type whose parameter has the same shape as the parameter of the
+// cue template, empty if there's none or the template can't be compiled
+func builtinPolicyShadowedBy(ctx context.Context, compiler *cuex.Compiler, cueTemplate string) string {
+	param, ok := compileParameterWith(ctx, compiler, cueTemplate)
+	if !ok {
+		return ""
+	}
+	shape := parameterShapeOf(param)
+	if shape == "" {
+		return ""
+	}
+	for _, policyType := range ReservedPolicyNames {
+		schema := builtinPolicySchemaOf(policyType)
+		if schema == "" {
+			continue
+		}
+		builtin := param.Context().CompileString(schema + contextStub).LookupPath(cue.ParsePath(parameterFieldName))
+		if builtin.Exists() && builtin.Err() == nil && parameterShapeOf(builtin) == shape {
+			return policyType
+		}
+	}
+	return ""
+}
+
+// parameterShapeOf formats the parameter with the references resolved and the comments dropped, so that the copies
+// of a parameter with the definitions renamed or the docs reworded have the same shape. Empty is returned for the
+// parameter without fields.
+func parameterShapeOf(param cue.Value) string {
+	node := param.Syntax(cue.Optional(true), cue.ResolveReferences(true))
+	ast.Walk(node, func(n ast.Node) bool {
+		ast.SetComments(n, nil)
+		return true
+	}, nil)
+	if lit, ok := node.(*ast.StructLit); ok && len(lit.Elts) == 0 {
+		return ""
+	}
+	b, err := format.Node(node)
+	if err != nil {
+		return ""
+	}
+	return string(b)
+}
+
+func isReservedPolicyName(name string) bool {
+	for _, reserved := range ReservedPolicyNames {
+		if name == reserved {
+			return true
+		}
+	}
+	return false
+}
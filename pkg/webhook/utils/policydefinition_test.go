@@ -0,0 +1,108 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	apicommon "github.com/oam-dev/kubevela/apis/core.oam.dev/common"
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
+	"github.com/oam-dev/kubevela/pkg/oam"
+	"github.com/oam-dev/kubevela/pkg/utils/common"
+)
+
+func TestValidatePolicyDefinition(t *testing.T) {
+	cli := fake.NewClientBuilder().WithScheme(common.Scheme).Build()
+	// the parameter of the built-in topology policy with the docs dropped
+	const topologyParameter = `parameter: {
+	clusters?: [...string]
+	clusterLabelSelector?: [string]: string
+	allowEmpty?: bool
+	clusterSelector?: [string]: string
+	namespace?: string
+}`
+
+	cases := map[string]struct {
+		meta    metav1.ObjectMeta
+		spec    v1beta1.PolicyDefinitionSpec
+		wantErr string
+	}{
+		"customPolicy": {
+			meta: metav1.ObjectMeta{Name: "my-policy", Namespace: "default"},
+		},
+		"builtinPolicyInSystemNamespace": {
+			meta: metav1.ObjectMeta{Name: "override", Namespace: oam.SystemDefinitionNamespace},
+		},
+		"shadowBuiltinPolicy": {
+			meta:    metav1.ObjectMeta{Name: "topology", Namespace: "default"},
+			wantErr: "PolicyDefinition topology is invalid: the name conflicts with the built-in policy type topology, set the annotation policydefinition.oam.dev/allow-reserved-name to \"true\" to override it",
+		},
+		"shadowBuiltinPolicyAllowed": {
+			meta: metav1.ObjectMeta{Name: "topology", Namespace: "default", Annotations: map[string]string{
+				oam.AnnotationAllowReservedPolicyName: "true",
+			}},
+		},
+		"shadowBuiltinPolicyParameter": {
+			meta: metav1.ObjectMeta{Name: "my-topology", Namespace: "default"},
+			spec: v1beta1.PolicyDefinitionSpec{
+				Schematic: &apicommon.Schematic{CUE: &apicommon.CUE{Template: topologyParameter}},
+			},
+			wantErr: "PolicyDefinition my-topology is invalid: the parameter has the same shape as the built-in policy type topology, set the annotation policydefinition.oam.dev/allow-reserved-name to \"true\" to override it",
+		},
+		"shadowBuiltinPolicyParameterAllowed": {
+			meta: metav1.ObjectMeta{Name: "my-topology", Namespace: "default", Annotations: map[string]string{
+				oam.AnnotationAllowReservedPolicyName: "true",
+			}},
+			spec: v1beta1.PolicyDefinitionSpec{
+				Schematic: &apicommon.Schematic{CUE: &apicommon.CUE{Template: topologyParameter}},
+			},
+		},
+		"differentParameterShape": {
+			meta: metav1.ObjectMeta{Name: "my-topology", Namespace: "default"},
+			spec: v1beta1.PolicyDefinitionSpec{
+				Schematic: &apicommon.Schematic{CUE: &apicommon.CUE{Template: `parameter: {
+	clusters?: [...string]
+	namespace?: string
+}`}},
+			},
+		},
+		"invalidCueTemplate": {
+			meta: metav1.ObjectMeta{Name: "my-policy", Namespace: "default"},
+			spec: v1beta1.PolicyDefinitionSpec{
+				Schematic: &apicommon.Schematic{CUE: &apicommon.CUE{Template: "output: hello: world"}},
+			},
+			wantErr: "output.hello: reference \"world\" not found",
+		},
+	}
+
+	for caseName, cs := range cases {
+		t.Run(caseName, func(t *testing.T) {
+			pd := &v1beta1.PolicyDefinition{ObjectMeta: cs.meta, Spec: cs.spec}
+			err := ValidatePolicyDefinition(context.Background(), cli, pd)
+			if cs.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.EqualError(t, err, cs.wantErr)
+		})
+	}
+}
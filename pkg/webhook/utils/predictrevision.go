@@ -0,0 +1,85 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"context"
+
+	"github.com/Masterminds/semver"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
+	"github.com/oam-dev/kubevela/pkg/controller/core.oam.dev/v1beta1/core"
+	"github.com/oam-dev/kubevela/pkg/oam"
+)
+
+// PredictDefinitionRevision tells the revision number the definition would get once applied, and whether applying it
+// would create a new DefinitionRevision, without writing anything, e.g. for CI to report that a change creates
+// revision v4. It follows the controller: the revision named by spec.version or the definitionrevision.oam.dev/name
+// annotation is reused if it exists, otherwise the definition is compared with its latest DefinitionRevision, which
+// is reused if the spec is the same. A new revision is numbered after the latest one.
+func PredictDefinitionRevision(ctx context.Context, cli client.Client, def runtime.Object) (revisionNumber int64, isNew bool, err error) {
+	def, err = typedDefinitionOf(def)
+	if err != nil {
+		return 0, false, err
+	}
+	obj, version, err := definitionVersionOf(def)
+	if err != nil {
+		return 0, false, err
+	}
+	newRev, _, err := core.GatherRevisionInfo(def)
+	if err != nil {
+		return 0, false, err
+	}
+	latest, err := latestDefinitionRevision(ctx, cli, newRev.Spec.DefinitionType, obj.GetName(), obj.GetNamespace())
+	if err != nil {
+		return 0, false, err
+	}
+	nextRevision := int64(1)
+	if latest != nil {
+		nextRevision = latest.Spec.Revision + 1
+	}
+
+	revisionName := obj.GetAnnotations()[oam.AnnotationDefinitionRevisionName]
+	if version != "" {
+		semVersion, err := semver.NewVersion(version)
+		if err != nil {
+			return 0, false, err
+		}
+		revisionName = semVersion.String()
+	}
+	if revisionName != "" {
+		// the named DefinitionRevision is immutable, the definition applied with its name reuses it
+		named := &v1beta1.DefinitionRevision{}
+		key := types.NamespacedName{Name: core.ConstructDefinitionRevisionName(obj.GetName(), revisionName), Namespace: obj.GetNamespace()}
+		if err := cli.Get(ctx, key, named); err != nil {
+			if apierrors.IsNotFound(err) {
+				return nextRevision, true, nil
+			}
+			return 0, false, err
+		}
+		return named.Spec.Revision, false, nil
+	}
+
+	if latest != nil && core.DeepEqualDefRevision(latest, newRev) {
+		return latest.Spec.Revision, false, nil
+	}
+	return nextRevision, true, nil
+}
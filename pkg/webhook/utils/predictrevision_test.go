@@ -0,0 +1,111 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
+	"github.com/oam-dev/kubevela/pkg/controller/core.oam.dev/v1beta1/core"
+	"github.com/oam-dev/kubevela/pkg/oam"
+	pkgcommon "github.com/oam-dev/kubevela/pkg/utils/common"
+)
+
+func TestPredictDefinitionRevision(t *testing.T) {
+	traitDef := func(name, template, version string, annotations map[string]string) *v1beta1.TraitDefinition {
+		td := newTraitDefinition(name, "default", template)
+		td.Annotations = annotations
+		td.Spec.Version = version
+		return td
+	}
+	revision := func(name string, revision int64, def *v1beta1.TraitDefinition) *v1beta1.DefinitionRevision {
+		rev, _, err := core.GatherRevisionInfo(def)
+		assert.NoError(t, err)
+		rev.Name = name
+		rev.Namespace = "default"
+		rev.Labels = map[string]string{oam.LabelTraitDefinitionName: def.Name}
+		rev.Spec.Revision = revision
+		return rev
+	}
+	cli := fake.NewClientBuilder().WithScheme(pkgcommon.Scheme).WithObjects(
+		revision("scaler-v1", 1, traitDef("scaler", "patch: replicas: 1", "", nil)),
+		revision("scaler-v2", 2, traitDef("scaler", "patch: replicas: 2", "", nil)),
+		revision("gateway-v1.0.0", 1, traitDef("gateway", "patch: replicas: 1", "1.0.0", nil)),
+		revision("ingress-vstable", 3, traitDef("ingress", "patch: replicas: 1", "", map[string]string{oam.AnnotationDefinitionRevisionName: "stable"})),
+	).Build()
+
+	cases := map[string]struct {
+		def        *v1beta1.TraitDefinition
+		wantNumber int64
+		wantIsNew  bool
+		wantErrMsg string
+	}{
+		"sameAsLatest": {
+			def:        traitDef("scaler", "patch: replicas: 2", "", nil),
+			wantNumber: 2,
+		},
+		"sameAsOlder": {
+			def:        traitDef("scaler", "patch: replicas: 1", "", nil),
+			wantNumber: 3,
+			wantIsNew:  true,
+		},
+		"changed": {
+			def:        traitDef("scaler", "patch: replicas: 3", "", nil),
+			wantNumber: 3,
+			wantIsNew:  true,
+		},
+		"noRevision": {
+			def:        traitDef("new", "patch: replicas: 1", "", nil),
+			wantNumber: 1,
+			wantIsNew:  true,
+		},
+		"existingVersion": {
+			def:        traitDef("gateway", "patch: replicas: 2", "v1.0.0", nil),
+			wantNumber: 1,
+		},
+		"newVersion": {
+			def:        traitDef("gateway", "patch: replicas: 2", "1.1.0", nil),
+			wantNumber: 2,
+			wantIsNew:  true,
+		},
+		"existingRevisionName": {
+			def:        traitDef("ingress", "patch: replicas: 2", "", map[string]string{oam.AnnotationDefinitionRevisionName: "stable"}),
+			wantNumber: 3,
+		},
+		"invalidVersion": {
+			def:        traitDef("gateway", "patch: replicas: 2", "1.x", nil),
+			wantErrMsg: "Invalid Semantic Version",
+		},
+	}
+
+	for caseName, cs := range cases {
+		t.Run(caseName, func(t *testing.T) {
+			number, isNew, err := PredictDefinitionRevision(context.Background(), cli, cs.def)
+			if cs.wantErrMsg != "" {
+				assert.EqualError(t, err, cs.wantErrMsg)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, cs.wantNumber, number)
+			assert.Equal(t, cs.wantIsNew, isNew)
+		})
+	}
+}
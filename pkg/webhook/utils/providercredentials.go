@@ -0,0 +1,95 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"fmt"
+	"sync"
+
+	"cuelang.org/go/cue"
+	cueutil "github.com/kubevela/pkg/cue/util"
+)
+
+// paramsKey is the field of a provider function call holding its inputs
+const paramsKey = "$params"
+
+var (
+	providerCredentials     = map[string][]string{}
+	providerCredentialsLock sync.RWMutex
+)
+
+// RegisterProviderCredentials declares the credentials fields required by the CueX provider function, e.g.
+// "http.do", as the paths under the $params of the call, e.g. request.header.Authorization, so that the templates
+// calling the function without them are rejected by ValidateCuexTemplate instead of failing at runtime. Registering
+// no field removes the requirement of the function.
+func RegisterProviderCredentials(function string, fields ...string) {
+	providerCredentialsLock.Lock()
+	defer providerCredentialsLock.Unlock()
+	if len(fields) == 0 {
+		delete(providerCredentials, function)
+		return
+	}
+	providerCredentials[function] = fields
+}
+
+// ProviderCredentialsOf returns the credentials fields required by the provider function, see
+// RegisterProviderCredentials
+func ProviderCredentialsOf(function string) []string {
+	providerCredentialsLock.RLock()
+	defer providerCredentialsLock.RUnlock()
+	return providerCredentials[function]
+}
+
+// checkProviderCredentials finds the provider function calls in the value the same way as CueX resolves them, and
+// reports the credentials fields required by the function that are not declared in the $params of the call
+func checkProviderCredentials(val cue.Value) error {
+	providerCredentialsLock.RLock()
+	empty := len(providerCredentials) == 0
+	providerCredentialsLock.RUnlock()
+	if empty {
+		return nil
+	}
+	var errs []error
+	cueutil.Iterate(val, func(v cue.Value) (stop bool) {
+		fn, _ := v.LookupPath(cue.ParsePath(providerFnKey)).String()
+		if fn == "" {
+			return false
+		}
+		name, _ := v.LookupPath(cue.ParsePath(providerKey)).String()
+		for _, field := range ProviderCredentialsOf(name + "." + fn) {
+			if !v.LookupPath(cue.ParsePath(paramsKey + "." + field)).Exists() {
+				errs = append(errs, fmt.Errorf("%s: provider %s requires credentials field %s.%s which is not found",
+					v.Path(), providerCallNameOf(v, name+"."+fn), paramsKey, field))
+			}
+		}
+		return false
+	})
+	return aggregateErrors(errs)
+}
+
+// providerCallNameOf returns the definition the provider function call unifies, e.g. #Do of http.#Do & {...}, or
+// the fallback if the call doesn't reference a definition
+func providerCallNameOf(v cue.Value, fallback string) string {
+	_, args := v.Expr()
+	for _, arg := range args {
+		_, path := arg.ReferencePath()
+		if sels := path.Selectors(); len(sels) != 0 && sels[len(sels)-1].IsDefinition() {
+			return sels[len(sels)-1].String()
+		}
+	}
+	return fallback
+}
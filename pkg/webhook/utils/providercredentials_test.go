@@ -0,0 +1,82 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"context"
+	"testing"
+
+	"github.com/kubevela/pkg/cue/cuex"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckProviderCredentials(t *testing.T) {
+	defer setFakeCuexCompiler()()
+	RegisterProviderCredentials("http.do", "request.header.Authorization")
+	defer RegisterProviderCredentials("http.do")
+
+	cases := map[string]struct {
+		cueTemplate string
+		wantErr     string
+	}{
+		"credentialsDeclared": {
+			cueTemplate: `
+import "vela/http"
+
+parameter: token: string
+req: http.#Get & {
+	$params: {
+		url: "https://example.com"
+		request: header: Authorization: "Bearer " + parameter.token
+	}
+}`,
+		},
+		"credentialsMissing": {
+			cueTemplate: `
+import "vela/http"
+
+req: http.#Do & {
+	$params: url: "https://example.com"
+}`,
+			wantErr: "req: provider #Do requires credentials field $params.request.header.Authorization which is not found",
+		},
+		"otherProvider": {
+			cueTemplate: `
+import "vela/base64"
+
+out: base64.#Encode & {$params: "hello"}`,
+		},
+	}
+	for caseName, cs := range cases {
+		t.Run(caseName, func(t *testing.T) {
+			val, err := cuex.DefaultCompiler.Get().CompileStringWithOptions(context.Background(), cs.cueTemplate, cuex.DisableResolveProviderFunctions{})
+			assert.NoError(t, err)
+			err = checkProviderCredentials(val)
+			if cs.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.EqualError(t, err, cs.wantErr)
+		})
+	}
+	assert.Equal(t, []string{"request.header.Authorization"}, ProviderCredentialsOf("http.do"))
+	assert.Nil(t, ProviderCredentialsOf("kube.get"))
+
+	// the calls missing the credentials are rejected before the provider functions are executed
+	err := ValidateCuexTemplate(context.Background(), cases["credentialsMissing"].cueTemplate)
+	assert.EqualError(t, err, cases["credentialsMissing"].wantErr)
+}
@@ -0,0 +1,34 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"fmt"
+	"runtime/debug"
+
+	"k8s.io/klog/v2"
+)
+
+// recoverValidation converts the panic of the validation, e.g. of the cue compiler on a malformed template, into the
+// error wrapping ErrValidationPanic, so that a single definition can't crash the webhook. The stack is logged.
+// It must be deferred by the function whose error is set.
+func recoverValidation(validator string, err *error) {
+	if r := recover(); r != nil {
+		klog.ErrorS(nil, "Recovered from the panic of the validation", "validator", validator, "panic", r, "stack", string(debug.Stack()))
+		*err = fmt.Errorf("%w: %v", ErrValidationPanic, r)
+	}
+}
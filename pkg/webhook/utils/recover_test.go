@@ -0,0 +1,75 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/common"
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
+	pkgcommon "github.com/oam-dev/kubevela/pkg/utils/common"
+)
+
+func TestRecoverValidation(t *testing.T) {
+	t.Run("validator", func(t *testing.T) {
+		DefaultValidatorRegistry.Register("panic", func(context.Context, client.Client, runtime.Object) ([]string, error) {
+			panic("poisoned template")
+		})
+		defer DefaultValidatorRegistry.Unregister("panic")
+		def := &v1beta1.TraitDefinition{Spec: v1beta1.TraitDefinitionSpec{
+			Schematic: &common.Schematic{CUE: &common.CUE{Template: "patch: spec: replicas: 1"}},
+		}}
+		def.SetName("scaler")
+		cli := fake.NewClientBuilder().WithScheme(pkgcommon.Scheme).Build()
+		_, err := ValidateDefinitionWithWarnings(context.Background(), cli, def)
+		assert.EqualError(t, err, "the validation panicked: poisoned template")
+		assert.True(t, errors.Is(err, ErrValidationPanic))
+	})
+
+	t.Run("evaluation", func(t *testing.T) {
+		_, err := validateWithTimeout(context.Background(), func(context.Context) ([]CueValidationError, error) {
+			panic("poisoned template")
+		})
+		assert.EqualError(t, err, "the validation panicked: poisoned template")
+	})
+}
+
+func FuzzValidateCueTemplate(f *testing.F) {
+	for _, seed := range []string{
+		"output: {apiVersion: \"v1\", kind: \"ConfigMap\"}",
+		"parameter: {name: string}\noutput: metadata: name: parameter.name",
+		"patch: spec: replicas: *1 | int",
+		"a: b: [for x in [1, 2] {x}]",
+		"template: {output: {}}",
+		"output: {",
+	} {
+		f.Add(seed)
+	}
+	f.Fuzz(func(t *testing.T, cueTemplate string) {
+		_, err := ValidateCueTemplateDetailed(cueTemplate)
+		if errors.Is(err, ErrValidationPanic) {
+			t.Errorf("validating %q panicked: %v", cueTemplate, err)
+		}
+	})
+}
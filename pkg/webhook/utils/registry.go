@@ -0,0 +1,168 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
+)
+
+// The names of the built-in validators of DefaultValidatorRegistry
+const (
+	// DefinitionValidatorName is the validator running the kind-specific validation, e.g. ValidateComponentDefinition
+	DefinitionValidatorName = "definition"
+	// VersionValidatorName is the validator of the version, its monotonicity and the definitionRevision
+	VersionValidatorName = "version"
+	// ChecksValidatorName is the validator of the optional checks configured by the SeverityConfig
+	ChecksValidatorName = "checks"
+	// RequiredMetadataValidatorName is the validator of the labels and the annotations required by
+	// DefinitionRequiredMetadata
+	RequiredMetadataValidatorName = "required-metadata"
+	// DeprecatedFieldsValidatorName is the validator warning the fields of DeprecatedDefinitionFields
+	DeprecatedFieldsValidatorName = "deprecated-fields"
+)
+
+// Validator checks the typed definition, e.g. *v1beta1.ComponentDefinition, and returns the warnings to report to
+// the user and the error denying the definition
+type Validator func(ctx context.Context, cli client.Client, def runtime.Object) (warnings []string, err error)
+
+// ValidatorRegistry is the ordered set of the validators run by ValidateDefinition, it's safe for concurrent use
+type ValidatorRegistry struct {
+	lock       sync.RWMutex
+	names      []string
+	validators map[string]Validator
+}
+
+// NewValidatorRegistry returns an empty registry
+func NewValidatorRegistry() *ValidatorRegistry {
+	return &ValidatorRegistry{validators: map[string]Validator{}}
+}
+
+// DefaultValidatorRegistry is the registry of ValidateDefinition, with the built-in validators registered.
+// The org-specific checks, e.g. the naming conventions or the mandatory labels, can be registered on it.
+var DefaultValidatorRegistry = newDefaultValidatorRegistry()
+
+func newDefaultValidatorRegistry() *ValidatorRegistry {
+	r := NewValidatorRegistry()
+	r.Register(DefinitionValidatorName, validateDefinitionByKind)
+	r.Register(VersionValidatorName, validateDefinitionVersionsByKind)
+	r.Register(ChecksValidatorName, validateOptionalChecks)
+	r.Register(RequiredMetadataValidatorName, validateRequiredMetadata)
+	r.Register(DeprecatedFieldsValidatorName, validateDeprecatedFields)
+	return r
+}
+
+// Register adds the validator after the ones registered before. A validator registered with the name of an
+// existing one replaces it in place, e.g. to override a built-in validator.
+func (r *ValidatorRegistry) Register(name string, validator Validator) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	if _, ok := r.validators[name]; !ok {
+		r.names = append(r.names, name)
+	}
+	r.validators[name] = validator
+}
+
+// Unregister removes the validator of the name, it's a no-op if the name is not registered
+func (r *ValidatorRegistry) Unregister(name string) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	if _, ok := r.validators[name]; !ok {
+		return
+	}
+	delete(r.validators, name)
+	for i, n := range r.names {
+		if n == name {
+			r.names = append(r.names[:i:i], r.names[i+1:]...)
+			break
+		}
+	}
+}
+
+// Names returns the names of the validators in the order they run
+func (r *ValidatorRegistry) Names() []string {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+	return append([]string{}, r.names...)
+}
+
+// Validate runs the validators in order and stops at the first error, which is returned with the warnings reported
+// by the validators run so far
+func (r *ValidatorRegistry) Validate(ctx context.Context, cli client.Client, def runtime.Object) ([]string, error) {
+	r.lock.RLock()
+	validators := make([]Validator, 0, len(r.names))
+	for _, name := range r.names {
+		validators = append(validators, r.validators[name])
+	}
+	r.lock.RUnlock()
+	var warnings []string
+	for _, validate := range validators {
+		ws, err := validate(ctx, cli, def)
+		warnings = append(warnings, ws...)
+		if err != nil {
+			return warnings, err
+		}
+	}
+	return warnings, nil
+}
+
+// validateDefinitionByKind validates the definition with the kind-specific validator
+func validateDefinitionByKind(ctx context.Context, cli client.Client, def runtime.Object) ([]string, error) {
+	switch d := def.(type) {
+	case *v1beta1.ComponentDefinition:
+		return ValidateComponentDefinition(ctx, cli, d)
+	case *v1beta1.TraitDefinition:
+		return ValidateTraitDefinition(ctx, cli, d)
+	case *v1beta1.PolicyDefinition:
+		return nil, ValidatePolicyDefinition(ctx, cli, d)
+	case *v1beta1.WorkflowStepDefinition:
+		return ValidateWorkflowStepDefinition(ctx, cli, d)
+	default:
+		return nil, fmt.Errorf("unsupported definition type %T", def)
+	}
+}
+
+// validateDefinitionVersionsByKind validates the version and the definitionRevision of the definition
+func validateDefinitionVersionsByKind(ctx context.Context, cli client.Client, def runtime.Object) ([]string, error) {
+	d, version, err := definitionVersionOf(def)
+	if err != nil {
+		return nil, err
+	}
+	return validateDefinitionVersions(ctx, cli, definitionRevisionIndexOf(ctx), d, version)
+}
+
+// definitionVersionOf returns the definition as a client.Object along with its spec.version
+func definitionVersionOf(def runtime.Object) (client.Object, string, error) {
+	switch d := def.(type) {
+	case *v1beta1.ComponentDefinition:
+		return d, d.Spec.Version, nil
+	case *v1beta1.TraitDefinition:
+		return d, d.Spec.Version, nil
+	case *v1beta1.PolicyDefinition:
+		return d, d.Spec.Version, nil
+	case *v1beta1.WorkflowStepDefinition:
+		return d, d.Spec.Version, nil
+	default:
+		return nil, "", fmt.Errorf("unsupported definition type %T", def)
+	}
+}
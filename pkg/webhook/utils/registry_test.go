@@ -0,0 +1,168 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
+	"github.com/oam-dev/kubevela/pkg/utils/common"
+)
+
+func TestValidatorRegistry(t *testing.T) {
+	var called []string
+	validator := func(name string, warnings []string, err error) Validator {
+		return func(context.Context, client.Client, runtime.Object) ([]string, error) {
+			called = append(called, name)
+			return warnings, err
+		}
+	}
+
+	cases := map[string]struct {
+		register     []string
+		unregister   []string
+		errOf        map[string]error
+		wantNames    []string
+		wantCalled   []string
+		wantWarnings []string
+		wantErr      string
+	}{
+		"inOrder": {
+			register:     []string{"a", "b", "c"},
+			wantNames:    []string{"a", "b", "c"},
+			wantCalled:   []string{"a", "b", "c"},
+			wantWarnings: []string{"a", "b", "c"},
+		},
+		"replaceInPlace": {
+			register:     []string{"a", "b", "a"},
+			wantNames:    []string{"a", "b"},
+			wantCalled:   []string{"a", "b"},
+			wantWarnings: []string{"a", "b"},
+		},
+		"unregister": {
+			register:     []string{"a", "b", "c"},
+			unregister:   []string{"b", "unknown"},
+			wantNames:    []string{"a", "c"},
+			wantCalled:   []string{"a", "c"},
+			wantWarnings: []string{"a", "c"},
+		},
+		"stopAtFirstError": {
+			register:     []string{"a", "b", "c"},
+			errOf:        map[string]error{"b": fmt.Errorf("b failed")},
+			wantNames:    []string{"a", "b", "c"},
+			wantCalled:   []string{"a", "b"},
+			wantWarnings: []string{"a", "b"},
+			wantErr:      "b failed",
+		},
+	}
+
+	for caseName, cs := range cases {
+		t.Run(caseName, func(t *testing.T) {
+			called = nil
+			r := NewValidatorRegistry()
+			for _, name := range cs.register {
+				r.Register(name, validator(name, []string{name}, cs.errOf[name]))
+			}
+			for _, name := range cs.unregister {
+				r.Unregister(name)
+			}
+			assert.Equal(t, cs.wantNames, r.Names())
+			warnings, err := r.Validate(context.Background(), nil, nil)
+			assert.Equal(t, cs.wantCalled, called)
+			assert.Equal(t, cs.wantWarnings, warnings)
+			if cs.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.EqualError(t, err, cs.wantErr)
+		})
+	}
+}
+
+func TestValidateDefinitionWithCustomValidator(t *testing.T) {
+	defer setFakeCuexCompiler()()
+	assert.Equal(t, []string{DefinitionValidatorName, VersionValidatorName, ChecksValidatorName, RequiredMetadataValidatorName, DeprecatedFieldsValidatorName}, DefaultValidatorRegistry.Names())
+	cli := fake.NewClientBuilder().WithScheme(common.Scheme).Build()
+	const name = "naming-convention"
+	DefaultValidatorRegistry.Register(name, func(_ context.Context, _ client.Client, def runtime.Object) ([]string, error) {
+		obj := def.(client.Object)
+		if !strings.HasPrefix(obj.GetName(), "acme-") {
+			return nil, fmt.Errorf("the name of %s must start with acme-", obj.GetName())
+		}
+		if obj.GetLabels()["team"] == "" {
+			return []string{"the team label is missing"}, nil
+		}
+		return nil, nil
+	})
+	defer DefaultValidatorRegistry.Unregister(name)
+
+	cases := map[string]struct {
+		def          runtime.Object
+		wantWarnings []string
+		wantErr      string
+	}{
+		"pass": {
+			def: &v1beta1.TraitDefinition{
+				ObjectMeta: metav1.ObjectMeta{Name: "acme-trait", Namespace: "default", Labels: map[string]string{"team": "a"}},
+			},
+		},
+		"warning": {
+			def: &v1beta1.TraitDefinition{
+				ObjectMeta: metav1.ObjectMeta{Name: "acme-trait", Namespace: "default"},
+			},
+			wantWarnings: []string{"the team label is missing"},
+		},
+		"customError": {
+			def: &v1beta1.PolicyDefinition{
+				ObjectMeta: metav1.ObjectMeta{Name: "policy", Namespace: "default"},
+			},
+			wantErr: "the name of policy must start with acme-",
+		},
+		"builtinErrorFirst": {
+			def: &v1beta1.PolicyDefinition{
+				ObjectMeta: metav1.ObjectMeta{Name: "policy", Namespace: "default"},
+				Spec:       v1beta1.PolicyDefinitionSpec{Version: "1.a"},
+			},
+			wantErr: "Not a valid version",
+		},
+		"unsupportedType": {
+			def:     &v1beta1.Application{},
+			wantErr: "unsupported definition type *v1beta1.Application",
+		},
+	}
+
+	for caseName, cs := range cases {
+		t.Run(caseName, func(t *testing.T) {
+			warnings, err := ValidateDefinitionWithWarnings(context.Background(), cli, cs.def)
+			assert.Equal(t, cs.wantWarnings, warnings)
+			if cs.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorContains(t, err, cs.wantErr)
+		})
+	}
+}
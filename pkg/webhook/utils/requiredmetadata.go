@@ -0,0 +1,75 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// RequiredMetadata is the keys of the labels and the annotations every definition must carry, e.g. owner and team
+type RequiredMetadata struct {
+	Labels      []string
+	Annotations []string
+}
+
+// DefinitionRequiredMetadata is the labels and the annotations required on the definitions validated by
+// ValidateDefinition, nothing is required by default
+var DefinitionRequiredMetadata = RequiredMetadata{}
+
+// ValidateRequiredMetadata checks that the definition carries every label and annotation of required, the error
+// wraps ErrMissingRequiredMetadata and lists the missing keys. A key set to an empty value is missing as well.
+func ValidateRequiredMetadata(def runtime.Object, required RequiredMetadata) error {
+	accessor, err := meta.Accessor(def)
+	if err != nil {
+		return err
+	}
+	missingLabels := missingKeysOf(accessor.GetLabels(), required.Labels)
+	missingAnnotations := missingKeysOf(accessor.GetAnnotations(), required.Annotations)
+	if len(missingLabels) == 0 && len(missingAnnotations) == 0 {
+		return nil
+	}
+	var missing []string
+	if len(missingLabels) != 0 {
+		missing = append(missing, "labels "+strings.Join(missingLabels, ", "))
+	}
+	if len(missingAnnotations) != 0 {
+		missing = append(missing, "annotations "+strings.Join(missingAnnotations, ", "))
+	}
+	return fmt.Errorf("%w: %s %s lacks the %s", ErrMissingRequiredMetadata, definitionKind(def), accessor.GetName(), strings.Join(missing, " and the "))
+}
+
+// validateRequiredMetadata checks the definition against DefinitionRequiredMetadata
+func validateRequiredMetadata(_ context.Context, _ client.Client, def runtime.Object) ([]string, error) {
+	return nil, ValidateRequiredMetadata(def, DefinitionRequiredMetadata)
+}
+
+// missingKeysOf returns the keys missing or empty in m in the order of keys
+func missingKeysOf(m map[string]string, keys []string) []string {
+	var missing []string
+	for _, key := range keys {
+		if m[key] == "" {
+			missing = append(missing, key)
+		}
+	}
+	return missing
+}
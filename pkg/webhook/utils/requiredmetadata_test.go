@@ -0,0 +1,78 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
+	"github.com/oam-dev/kubevela/pkg/utils/common"
+)
+
+func TestValidateRequiredMetadata(t *testing.T) {
+	required := RequiredMetadata{Labels: []string{"owner", "team"}, Annotations: []string{"docs"}}
+	traitDef := func(labels, annotations map[string]string) *v1beta1.TraitDefinition {
+		td := newTraitDefinition("scaler", "default", "")
+		td.Labels, td.Annotations = labels, annotations
+		return td
+	}
+
+	cases := map[string]struct {
+		def     *v1beta1.TraitDefinition
+		wantErr string
+	}{
+		"allPresent": {
+			def: traitDef(map[string]string{"owner": "alice", "team": "infra"}, map[string]string{"docs": "https://docs"}),
+		},
+		"missingLabels": {
+			def:     traitDef(map[string]string{"team": "infra"}, map[string]string{"docs": "https://docs"}),
+			wantErr: "missing required metadata: TraitDefinition scaler lacks the labels owner",
+		},
+		"missingBoth": {
+			def:     traitDef(map[string]string{"owner": ""}, nil),
+			wantErr: "missing required metadata: TraitDefinition scaler lacks the labels owner, team and the annotations docs",
+		},
+	}
+
+	for caseName, cs := range cases {
+		t.Run(caseName, func(t *testing.T) {
+			err := ValidateRequiredMetadata(cs.def, required)
+			if cs.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.EqualError(t, err, cs.wantErr)
+			assert.True(t, errors.Is(err, ErrMissingRequiredMetadata))
+		})
+	}
+}
+
+func TestValidateDefinitionRequiredMetadata(t *testing.T) {
+	defer func(required RequiredMetadata) { DefinitionRequiredMetadata = required }(DefinitionRequiredMetadata)
+	cli := fake.NewClientBuilder().WithScheme(common.Scheme).Build()
+	def := &v1beta1.TraitDefinition{ObjectMeta: metav1.ObjectMeta{Name: "scaler", Namespace: "default"}}
+
+	assert.NoError(t, ValidateDefinition(context.Background(), cli, def))
+	DefinitionRequiredMetadata = RequiredMetadata{Labels: []string{"owner"}}
+	assert.EqualError(t, ValidateDefinition(context.Background(), cli, def), "missing required metadata: TraitDefinition scaler lacks the labels owner")
+}
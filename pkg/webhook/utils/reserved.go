@@ -0,0 +1,114 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"cuelang.org/go/cue/ast"
+	cueErrors "cuelang.org/go/cue/errors"
+	"cuelang.org/go/cue/parser"
+	"github.com/kubevela/workflow/pkg/cue/model"
+
+	"github.com/oam-dev/kubevela/pkg/cue/definition"
+)
+
+// reservedFieldNames are the top-level fields of the template with a meaning to the runtime, and whether the runtime
+// injects them into the template rather than reading them from it
+var reservedFieldNames = map[string]bool{
+	model.ContextFieldName:    true,
+	model.ParameterFieldName:  false,
+	model.OutputFieldName:     false,
+	model.OutputsFieldName:    false,
+	definition.PatchFieldName: false,
+}
+
+// reservedNameScopes are the top-level fields whose direct children named by the reserved names shadow the top-level
+// fields of the same name for the references inside the struct, e.g. a parameter named context turns context.name in
+// the defaults of the parameter into a reference to the parameter.
+var reservedNameScopes = []string{model.ParameterFieldName, model.OutputsFieldName}
+
+// ValidateReservedFieldNames checks that the template doesn't redefine the fields reserved by the runtime in a way
+// that shadows them, i.e. declare the injected context at the top level, or name a parameter or an output after a
+// reserved field referenced inside the parameter or the outputs. A parameter named context is allowed as long as
+// the references to context in the parameter don't resolve to it.
+func ValidateReservedFieldNames(cueTemplate string) error {
+	f, err := parser.ParseFile("-", cueTemplate)
+	if err != nil {
+		// the syntax error is reported by the validation
+		return nil
+	}
+	var errs []error
+	for _, decl := range f.Decls {
+		field, ok := decl.(*ast.Field)
+		if !ok {
+			continue
+		}
+		name, _, err := ast.LabelName(field.Label)
+		if err != nil {
+			continue
+		}
+		if reservedFieldNames[name] {
+			errs = append(errs, cueErrors.Newf(field.Pos(), "%s is injected by the runtime and must not be declared in the template", name))
+			continue
+		}
+		if st, ok := field.Value.(*ast.StructLit); ok && isReservedNameScope(name) {
+			errs = append(errs, shadowingFieldsOf(name, st)...)
+		}
+	}
+	return aggregateErrors(errs)
+}
+
+// shadowingFieldsOf returns the errors of the children of the scope named by the reserved names that are referenced
+// inside the scope
+func shadowingFieldsOf(scope string, st *ast.StructLit) []error {
+	children := map[string]*ast.Field{}
+	for _, elt := range st.Elts {
+		child, ok := elt.(*ast.Field)
+		if !ok {
+			continue
+		}
+		if name, _, err := ast.LabelName(child.Label); err == nil {
+			if _, reserved := reservedFieldNames[name]; reserved {
+				children[name] = child
+			}
+		}
+	}
+	if len(children) == 0 {
+		return nil
+	}
+	var errs []error
+	reported := map[string]bool{}
+	ast.Walk(st, func(n ast.Node) bool {
+		id, ok := n.(*ast.Ident)
+		if !ok || id.Scope != st || children[id.Name] == nil || reported[id.Name] {
+			return true
+		}
+		reported[id.Name] = true
+		errs = append(errs, cueErrors.Newf(children[id.Name].Pos(),
+			"%s.%s shadows the reserved field %s referenced in %s", scope, id.Name, id.Name, scope))
+		return true
+	}, nil)
+	return errs
+}
+
+func isReservedNameScope(name string) bool {
+	for _, scope := range reservedNameScopes {
+		if scope == name {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,142 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateReservedFieldNames(t *testing.T) {
+	cases := map[string]struct {
+		cueTemplate string
+		wantErrMsgs []string
+	}{
+		"valid": {
+			cueTemplate: `
+parameter: image: string
+output: metadata: name: context.name
+outputs: svc: metadata: name: context.name
+`,
+		},
+		"declaresContext": {
+			cueTemplate: `
+context: name: "fixed"
+output: metadata: name: context.name
+`,
+			wantErrMsgs: []string{"context is injected by the runtime and must not be declared in the template"},
+		},
+		"parameterShadowsContext": {
+			cueTemplate: `
+parameter: {
+	context: string
+	name:    *context | string
+}
+output: metadata: name: parameter.name
+`,
+			wantErrMsgs: []string{"parameter.context shadows the reserved field context referenced in parameter"},
+		},
+		"parameterShadowsParameter": {
+			cueTemplate: `
+parameter: {
+	parameter: string
+	image:     *parameter | string
+}
+`,
+			wantErrMsgs: []string{"parameter.parameter shadows the reserved field parameter referenced in parameter"},
+		},
+		"parameterShadowsPatch": {
+			cueTemplate: `
+parameter: {
+	patch: {...}
+	labels: patch.metadata.labels
+}
+patch: metadata: labels: parameter.labels
+`,
+			wantErrMsgs: []string{"parameter.patch shadows the reserved field patch referenced in parameter"},
+		},
+		"outputsShadowsOutput": {
+			cueTemplate: `
+output: metadata: name: context.name
+outputs: {
+	output: metadata: name: "svc"
+	ingress: metadata: name: output.metadata.name
+}
+`,
+			wantErrMsgs: []string{"outputs.output shadows the reserved field output referenced in outputs"},
+		},
+		"outputsShadowsOutputs": {
+			cueTemplate: `
+outputs: {
+	outputs: metadata: name: "svc"
+	ingress: metadata: name: outputs.metadata.name
+}
+`,
+			wantErrMsgs: []string{"outputs.outputs shadows the reserved field outputs referenced in outputs"},
+		},
+		"nestedReference": {
+			cueTemplate: `
+parameter: {
+	context: string
+	labels: app: *context | string
+}
+`,
+			wantErrMsgs: []string{"parameter.context shadows the reserved field context referenced in parameter"},
+		},
+		"reservedNameNotReferenced": {
+			cueTemplate: `
+parameter: context: string
+output: metadata: name: context.name
+output: spec: dir: parameter.context
+`,
+		},
+		"multipleCollisions": {
+			cueTemplate: `
+context: name: "fixed"
+parameter: {
+	context: string
+	name:    *context | string
+}
+outputs: {
+	outputs: metadata: name: "svc"
+	ingress: metadata: name: outputs.metadata.name
+}
+`,
+			wantErrMsgs: []string{
+				"context is injected by the runtime and must not be declared in the template",
+				"parameter.context shadows the reserved field context referenced in parameter",
+				"outputs.outputs shadows the reserved field outputs referenced in outputs",
+			},
+		},
+		"syntaxError": {
+			cueTemplate: `context: {`,
+		},
+	}
+	for caseName, cs := range cases {
+		t.Run(caseName, func(t *testing.T) {
+			err := ValidateReservedFieldNames(cs.cueTemplate)
+			if len(cs.wantErrMsgs) == 0 {
+				assert.NoError(t, err)
+				return
+			}
+			for _, msg := range cs.wantErrMsgs {
+				assert.ErrorContains(t, err, msg)
+			}
+		})
+	}
+}
@@ -0,0 +1,138 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"strings"
+
+	"cuelang.org/go/cue"
+	"cuelang.org/go/cue/ast"
+	"cuelang.org/go/cue/cuecontext"
+	"cuelang.org/go/cue/literal"
+	"cuelang.org/go/cue/parser"
+	"cuelang.org/go/encoding/jsonschema"
+	"cuelang.org/go/encoding/yaml"
+	"github.com/pkg/errors"
+)
+
+// SchemaRefAttr is the attribute referencing the OpenAPI schema of a field in the cue template, e.g.
+//
+//	parameter: _ @schemaRef("schemas/webservice.yaml")
+const SchemaRefAttr = "schemaRef"
+
+// SchemaResolver fetches the OpenAPI schema in JSON or YAML referenced by the cue template
+type SchemaResolver interface {
+	Resolve(ref string) ([]byte, error)
+}
+
+// schemaRef is a field of the cue template referencing an OpenAPI schema
+type schemaRef struct {
+	path cue.Path
+	ref  string
+}
+
+// ValidateCueTemplateWithSchemaRefs validates cueTemplate as ValidateCueTemplate does, with the OpenAPI schemas
+// referenced by the SchemaRefAttr attribute of the fields inlined, so that the template validates against them.
+// The schemas are fetched by resolver and converted to cue as JSON Schema, the references inside a schema are
+// not followed. Only the fields in the struct literals of the template can reference a schema.
+func ValidateCueTemplateWithSchemaRefs(cueTemplate string, resolver SchemaResolver) error {
+	f, err := parser.ParseFile("-", cueTemplate, parser.ParseComments)
+	if err != nil {
+		return err
+	}
+	refs, err := schemaRefsOf(f.Decls, nil)
+	if err != nil {
+		return err
+	}
+	cueCtx := cuecontext.New()
+	schemas := make([]cue.Value, len(refs))
+	for i, ref := range refs {
+		if schemas[i], err = compileSchemaRef(cueCtx, resolver, ref.ref); err != nil {
+			return err
+		}
+	}
+	compile := func(src string) (cue.Value, error) {
+		val := cueCtx.CompileString(src)
+		for i, ref := range refs {
+			val = val.FillPath(ref.path, schemas[i])
+		}
+		return val, nil
+	}
+	_, err = validateCueTemplateWith(cueTemplate, compile, compile)
+	return err
+}
+
+// schemaRefsOf collects the fields with the SchemaRefAttr attribute in the declarations under the path
+func schemaRefsOf(decls []ast.Decl, path []cue.Selector) ([]schemaRef, error) {
+	var refs []schemaRef
+	for _, decl := range decls {
+		field, ok := decl.(*ast.Field)
+		if !ok {
+			continue
+		}
+		name, _, err := ast.LabelName(field.Label)
+		if err != nil {
+			// the dynamic fields and the pattern constraints can't be filled
+			continue
+		}
+		fieldPath := append(append([]cue.Selector{}, path...), cue.Label(field.Label))
+		for _, attr := range field.Attrs {
+			key, body := attr.Split()
+			if key != SchemaRefAttr {
+				continue
+			}
+			ref, err := literal.Unquote(strings.TrimSpace(body))
+			if err != nil {
+				return nil, errors.Wrapf(err, "invalid attribute @%s of field %s", SchemaRefAttr, name)
+			}
+			refs = append(refs, schemaRef{path: cue.MakePath(fieldPath...), ref: ref})
+		}
+		if st, ok := field.Value.(*ast.StructLit); ok {
+			nested, err := schemaRefsOf(st.Elts, fieldPath)
+			if err != nil {
+				return nil, err
+			}
+			refs = append(refs, nested...)
+		}
+	}
+	return refs, nil
+}
+
+// compileSchemaRef fetches the schema referenced and converts it to a cue value in cueCtx
+func compileSchemaRef(cueCtx *cue.Context, resolver SchemaResolver, ref string) (cue.Value, error) {
+	data, err := resolver.Resolve(ref)
+	if err != nil {
+		return cue.Value{}, errors.Wrapf(err, "failed to resolve schema %s", ref)
+	}
+	doc, err := yaml.Extract(ref, data)
+	if err != nil {
+		return cue.Value{}, errors.Wrapf(err, "invalid schema %s", ref)
+	}
+	docVal := cueCtx.BuildFile(doc)
+	if docVal.Err() != nil {
+		return cue.Value{}, errors.Wrapf(docVal.Err(), "invalid schema %s", ref)
+	}
+	schema, err := jsonschema.Extract(docVal, &jsonschema.Config{})
+	if err != nil {
+		return cue.Value{}, errors.Wrapf(err, "invalid schema %s", ref)
+	}
+	val := cueCtx.BuildFile(schema)
+	if val.Err() != nil {
+		return cue.Value{}, errors.Wrapf(val.Err(), "invalid schema %s", ref)
+	}
+	return val, nil
+}
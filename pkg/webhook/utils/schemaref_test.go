@@ -0,0 +1,114 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+type mapSchemaResolver map[string]string
+
+func (r mapSchemaResolver) Resolve(ref string) ([]byte, error) {
+	schema, ok := r[ref]
+	if !ok {
+		return nil, errors.Errorf("schema %s is not found", ref)
+	}
+	return []byte(schema), nil
+}
+
+func TestValidateCueTemplateWithSchemaRefs(t *testing.T) {
+	resolver := mapSchemaResolver{
+		"webservice.yaml": `
+type: object
+required: [image]
+properties:
+  image:
+    type: string
+  port:
+    type: integer
+    minimum: 1
+`,
+		"labels.json":  `{"type": "object", "additionalProperties": {"type": "string"}}`,
+		"invalid.yaml": `type: [`,
+	}
+
+	cases := map[string]struct {
+		cueTemplate string
+		wantErrMsg  string
+	}{
+		"matchesSchema": {
+			cueTemplate: `
+parameter: _ @schemaRef("webservice.yaml")
+output: spec: {
+	image: parameter.image
+	name:  context.name
+}
+`,
+		},
+		"nestedRef": {
+			cueTemplate: `
+parameter: {
+	image:  string
+	labels: _ @schemaRef("labels.json")
+}
+output: metadata: labels: parameter.labels
+`,
+		},
+		"conflictsWithSchema": {
+			cueTemplate: `
+parameter: _ @schemaRef("webservice.yaml")
+parameter: port: "80"
+`,
+			wantErrMsg: "parameter.port: conflicting values \"80\" and int (mismatched types string and int)",
+		},
+		"valueViolatesSchema": {
+			cueTemplate: `
+parameter: _ @schemaRef("webservice.yaml")
+parameter: port: 0
+`,
+			wantErrMsg: "parameter.port: invalid value 0 (out of bound >=1)",
+		},
+		"noRef": {
+			cueTemplate: `parameter: image: string`,
+		},
+		"refNotFound": {
+			cueTemplate: `parameter: _ @schemaRef("worker.yaml")`,
+			wantErrMsg:  "failed to resolve schema worker.yaml: schema worker.yaml is not found",
+		},
+		"invalidSchema": {
+			cueTemplate: `parameter: _ @schemaRef("invalid.yaml")`,
+			wantErrMsg:  "invalid schema invalid.yaml",
+		},
+		"invalidAttribute": {
+			cueTemplate: `parameter: _ @schemaRef(webservice.yaml)`,
+			wantErrMsg:  "invalid attribute @schemaRef of field parameter",
+		},
+	}
+	for caseName, cs := range cases {
+		t.Run(caseName, func(t *testing.T) {
+			err := ValidateCueTemplateWithSchemaRefs(cs.cueTemplate, resolver)
+			if cs.wantErrMsg != "" {
+				assert.ErrorContains(t, err, cs.wantErrMsg)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
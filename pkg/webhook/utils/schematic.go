@@ -0,0 +1,93 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclparse"
+	"github.com/pkg/errors"
+
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/common"
+)
+
+const (
+	terraformTypeHCL    = "hcl"
+	terraformTypeJSON   = "json"
+	terraformTypeRemote = "remote"
+)
+
+// ValidateSchematic validates the schematic by the type set in it. The cue template is validated offline as
+// ValidateDefinitionOffline does, and the terraform configuration is parsed by its type.
+// ErrUnsupportedSchematic is returned if none of the supported types is set.
+func ValidateSchematic(schematic *common.Schematic) error {
+	if schematic == nil {
+		return nil
+	}
+	switch {
+	case schematic.CUE != nil:
+		_, err := validateCuexSchematicOffline("", schematic)
+		return err
+	case schematic.Terraform != nil:
+		return errors.WithMessage(validateTerraform(schematic.Terraform), "invalid terraform schematic")
+	default:
+		return ErrUnsupportedSchematic
+	}
+}
+
+// validateTerraform parses the terraform configuration in HCL or JSON syntax, or checks the remote configuration
+// is a valid git repository URL
+func validateTerraform(tf *common.Terraform) error {
+	if strings.TrimSpace(tf.Configuration) == "" {
+		return errors.New("configuration is empty")
+	}
+	var diags hcl.Diagnostics
+	switch tf.Type {
+	case "", terraformTypeHCL:
+		_, diags = hclparse.NewParser().ParseHCL([]byte(tf.Configuration), "main.tf")
+	case terraformTypeJSON:
+		_, diags = hclparse.NewParser().ParseJSON([]byte(tf.Configuration), "main.tf.json")
+	case terraformTypeRemote:
+		if !isGitRepositoryURL(tf.Configuration) {
+			return fmt.Errorf("invalid remote configuration %s, it must be the URL of a git repository", tf.Configuration)
+		}
+		return nil
+	default:
+		return fmt.Errorf("unknown terraform configuration type %s", tf.Type)
+	}
+	if diags.HasErrors() {
+		return errors.New(diags.Error())
+	}
+	return nil
+}
+
+// scpLikeGitURLRegex matches the scp-like git URLs, e.g. git@github.com:kubevela/terraform-modules.git
+var scpLikeGitURLRegex = regexp.MustCompile(`^[\w.-]+@[\w.-]+:.+$`)
+
+// isGitRepositoryURL checks whether the address is a URL like https://github.com/kubevela/terraform-modules.git
+// or a scp-like one used with ssh
+func isGitRepositoryURL(address string) bool {
+	if scpLikeGitURLRegex.MatchString(address) {
+		return true
+	}
+	u, err := url.Parse(address)
+	return err == nil && u.Scheme != "" && u.Host != ""
+}
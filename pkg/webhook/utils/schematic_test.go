@@ -0,0 +1,96 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/common"
+)
+
+func TestValidateSchematic(t *testing.T) {
+	cases := map[string]struct {
+		schematic *common.Schematic
+		wantErr   string
+	}{
+		"noSchematic": {},
+		"validCue": {
+			schematic: &common.Schematic{CUE: &common.CUE{Template: `output: metadata: name: context.name`}},
+		},
+		"invalidCue": {
+			schematic: &common.Schematic{CUE: &common.CUE{Template: `output: hello: world`}},
+			wantErr:   "output.hello: reference \"world\" not found",
+		},
+		"validHCL": {
+			schematic: &common.Schematic{Terraform: &common.Terraform{Configuration: `
+variable "bucket" {
+  type = string
+}
+
+resource "alicloud_oss_bucket" "bucket" {
+  bucket = var.bucket
+}`}},
+		},
+		"invalidHCL": {
+			schematic: &common.Schematic{Terraform: &common.Terraform{Configuration: `variable "bucket" {`}},
+			wantErr:   "invalid terraform schematic: main.tf:1,19-20: Unclosed configuration block; There is no closing brace for this block before the end of the file. This may be caused by incorrect brace nesting elsewhere in this file.",
+		},
+		"validJSON": {
+			schematic: &common.Schematic{Terraform: &common.Terraform{Type: "json", Configuration: `{"variable": {"bucket": {"type": "string"}}}`}},
+		},
+		"invalidJSON": {
+			schematic: &common.Schematic{Terraform: &common.Terraform{Type: "json", Configuration: `{"variable": `}},
+			wantErr:   "invalid terraform schematic: main.tf.json:1,14-14: Missing value; The JSON data ends prematurely., and 2 other diagnostic(s)",
+		},
+		"validRemote": {
+			schematic: &common.Schematic{Terraform: &common.Terraform{Type: "remote", Configuration: "https://github.com/kubevela-contrib/terraform-modules.git", Path: "alibaba/cs/dedicated-kubernetes"}},
+		},
+		"validScpLikeRemote": {
+			schematic: &common.Schematic{Terraform: &common.Terraform{Type: "remote", Configuration: "git@github.com:kubevela-contrib/terraform-modules.git"}},
+		},
+		"invalidRemote": {
+			schematic: &common.Schematic{Terraform: &common.Terraform{Type: "remote", Configuration: "terraform-modules"}},
+			wantErr:   "invalid terraform schematic: invalid remote configuration terraform-modules, it must be the URL of a git repository",
+		},
+		"emptyConfiguration": {
+			schematic: &common.Schematic{Terraform: &common.Terraform{}},
+			wantErr:   "invalid terraform schematic: configuration is empty",
+		},
+		"unknownTerraformType": {
+			schematic: &common.Schematic{Terraform: &common.Terraform{Type: "yaml", Configuration: "a: b"}},
+			wantErr:   "invalid terraform schematic: unknown terraform configuration type yaml",
+		},
+		"unsupportedSchematic": {
+			schematic: &common.Schematic{},
+			wantErr:   "unsupported schematic type",
+		},
+	}
+
+	for caseName, cs := range cases {
+		t.Run(caseName, func(t *testing.T) {
+			err := ValidateSchematic(cs.schematic)
+			if cs.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.EqualError(t, err, cs.wantErr)
+		})
+	}
+	assert.ErrorIs(t, ValidateSchematic(&common.Schematic{}), ErrUnsupportedSchematic)
+}
@@ -0,0 +1,231 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"cuelang.org/go/cue/parser"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
+)
+
+// Severity is the level at which the findings of an optional check are reported
+type Severity string
+
+const (
+	// SeverityOff skips the check
+	SeverityOff Severity = "off"
+	// SeverityWarn reports the findings of the check as warnings
+	SeverityWarn Severity = "warn"
+	// SeverityError reports the findings of the check as errors denying the definition
+	SeverityError Severity = "error"
+)
+
+// The names of the optional checks run by ValidateDefinition, the keys of SeverityConfig
+const (
+	// CheckOpenStructs reports the open structs in the parameter, see ValidateCueTemplateStrict
+	CheckOpenStructs = "open-structs"
+	// CheckParameterDocs reports the required parameters without a description, see ValidateParameterDocs
+	CheckParameterDocs = "parameter-docs"
+	// CheckParameterCompatibility reports the breaking changes of the parameter since the latest DefinitionRevision,
+	// see ValidateParameterCompatibility
+	CheckParameterCompatibility = "parameter-compatibility"
+	// CheckOutputsReferences reports the references to the undeclared outputs and the unused outputs, see
+	// ValidateOutputsReferences
+	CheckOutputsReferences = "outputs-references"
+	// CheckUnknownContextFields reports the context fields missing in the context schema of the definition kind,
+	// see ValidateContextFields
+	CheckUnknownContextFields = "unknown-context-fields"
+	// CheckBottomValues reports the fields of the template resolving to bottom, see ValidateBottomValues
+	CheckBottomValues = "bottom-values"
+	// CheckParameterDisjunctions reports the fields of the parameter with more disjunction branches than
+	// ParameterMaxDisjunctions, see ValidateParameterDisjunctions
+	CheckParameterDisjunctions = "parameter-disjunctions"
+	// CheckNonDeterministicOutputs reports the fields of the output and the outputs depending on
+	// NonDeterministicProviderFunctions, see ValidateDeterministicOutputs
+	CheckNonDeterministicOutputs = "non-deterministic-outputs"
+)
+
+// defaultSeverities are the levels of the checks missing in the SeverityConfig, i.e. the behavior before the
+// checks are configurable
+var defaultSeverities = map[string]Severity{
+	CheckOpenStructs:             SeverityOff,
+	CheckParameterDocs:           SeverityOff,
+	CheckParameterCompatibility:  SeverityOff,
+	CheckOutputsReferences:       SeverityOff,
+	CheckUnknownContextFields:    SeverityWarn,
+	CheckBottomValues:            SeverityOff,
+	CheckParameterDisjunctions:   SeverityOff,
+	CheckNonDeterministicOutputs: SeverityOff,
+}
+
+// SeverityConfig sets the severity of the optional checks by their names, e.g. CheckOpenStructs, so that the
+// strictness of the validation can differ between the teams. The checks missing in the config keep their default.
+type SeverityConfig map[string]Severity
+
+// Validate checks that the config only sets the known checks to the known severities
+func (c SeverityConfig) Validate() error {
+	checks := make([]string, 0, len(c))
+	for check := range c {
+		checks = append(checks, check)
+	}
+	sort.Strings(checks)
+	for _, check := range checks {
+		if _, ok := defaultSeverities[check]; !ok {
+			return fmt.Errorf("unknown check %q", check)
+		}
+		switch c[check] {
+		case SeverityOff, SeverityWarn, SeverityError:
+		default:
+			return fmt.Errorf("unknown severity %q of check %s, must be one of %s, %s or %s", c[check], check, SeverityOff, SeverityWarn, SeverityError)
+		}
+	}
+	return nil
+}
+
+// SeverityOf returns the severity of the check, the default one if the config doesn't set it
+func (c SeverityConfig) SeverityOf(check string) Severity {
+	if severity, ok := c[check]; ok {
+		return severity
+	}
+	if severity, ok := defaultSeverities[check]; ok {
+		return severity
+	}
+	return SeverityOff
+}
+
+// classify returns the findings of the check as the warnings or the errors of the result by its severity, the
+// error of the result is returned as well
+func (c SeverityConfig) classify(check string, findings []CueValidationError) (*ValidationResult, error) {
+	result := &ValidationResult{}
+	switch c.SeverityOf(check) {
+	case SeverityWarn:
+		result.Warnings = findings
+	case SeverityError:
+		result.Errors = findings
+	}
+	return result, result.Err()
+}
+
+// DefinitionCheckSeverities are the severities of the optional checks by their names, e.g.
+// parameter-disjunctions=error, used by ValidateDefinition when the context carries no SeverityConfig, e.g. in the
+// admission webhook
+var DefinitionCheckSeverities = map[string]string{}
+
+type severityConfigCtxKey struct{}
+
+// WithSeverityConfig returns the context carrying the severities of the optional checks run by ValidateDefinition
+func WithSeverityConfig(ctx context.Context, config SeverityConfig) context.Context {
+	return context.WithValue(ctx, severityConfigCtxKey{}, config)
+}
+
+// severityConfigOf returns the SeverityConfig carried by the context, the one of DefinitionCheckSeverities if it's
+// not set
+func severityConfigOf(ctx context.Context) SeverityConfig {
+	if config, ok := ctx.Value(severityConfigCtxKey{}).(SeverityConfig); ok {
+		return config
+	}
+	config := make(SeverityConfig, len(DefinitionCheckSeverities))
+	for check, severity := range DefinitionCheckSeverities {
+		config[check] = Severity(severity)
+	}
+	return config
+}
+
+// validateOptionalChecks runs the optional checks on the cue template of the definition at the severities of the
+// SeverityConfig carried by ctx, the checks set to off are skipped
+func validateOptionalChecks(ctx context.Context, cli client.Client, def runtime.Object) ([]string, error) {
+	config := severityConfigOf(ctx)
+	if err := config.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid severity config: %w", err)
+	}
+	_, schematic, err := definitionSchematicOf(def)
+	if err != nil {
+		return nil, err
+	}
+	if schematic == nil || schematic.CUE == nil {
+		return nil, nil
+	}
+	cueTemplate := schematic.CUE.Template
+	checks := []struct {
+		name string
+		run  func() ([]CueValidationError, error)
+	}{
+		{name: CheckOpenStructs, run: func() ([]CueValidationError, error) {
+			f, err := parser.ParseFile("-", cueTemplate)
+			if err != nil {
+				// the syntax error is reported by the validation
+				return nil, nil
+			}
+			return collectCueValidationErrors(aggregateErrors(checkOpenParameterStructs(f))), nil
+		}},
+		{name: CheckParameterDocs, run: func() ([]CueValidationError, error) {
+			result, err := ValidateParameterDocs(cueTemplate, ParameterDocsOptions{})
+			return result.Warnings, err
+		}},
+		{name: CheckParameterCompatibility, run: func() ([]CueValidationError, error) {
+			result, err := ValidateParameterCompatibility(ctx, cli, def)
+			return result.Warnings, err
+		}},
+		{name: CheckOutputsReferences, run: func() ([]CueValidationError, error) {
+			// the error only aggregates the dangling references, which are classified by the severity instead
+			result, _ := ValidateOutputsReferences(cueTemplate)
+			return append(result.Errors, result.Warnings...), nil
+		}},
+		{name: CheckBottomValues, run: func() ([]CueValidationError, error) {
+			result, err := ValidateBottomValues(cueTemplate)
+			return result.Warnings, err
+		}},
+		{name: CheckParameterDisjunctions, run: func() ([]CueValidationError, error) {
+			result, err := ValidateParameterDisjunctions(ctx, cueTemplate, ParameterDisjunctionOptions{})
+			return result.Warnings, err
+		}},
+		{name: CheckUnknownContextFields, run: func() ([]CueValidationError, error) {
+			if _, ok := def.(*v1beta1.WorkflowStepDefinition); ok {
+				// reported by ValidateWorkflowStepDefinition
+				return nil, nil
+			}
+			result, err := ValidateContextFields(def)
+			return result.Warnings, err
+		}},
+		{name: CheckNonDeterministicOutputs, run: func() ([]CueValidationError, error) {
+			result, err := ValidateDeterministicOutputs(ctx, cueTemplate)
+			return result.Warnings, err
+		}},
+	}
+	var warnings []string
+	for _, check := range checks {
+		if config.SeverityOf(check.name) == SeverityOff {
+			continue
+		}
+		findings, err := check.run()
+		if err != nil {
+			return warnings, err
+		}
+		result, err := config.classify(check.name, findings)
+		warnings = append(warnings, result.WarningMessages()...)
+		if err != nil {
+			return warnings, err
+		}
+	}
+	return warnings, nil
+}
@@ -0,0 +1,167 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	apicommon "github.com/oam-dev/kubevela/apis/core.oam.dev/common"
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
+	"github.com/oam-dev/kubevela/pkg/utils/common"
+)
+
+func TestSeverityConfigValidate(t *testing.T) {
+	cases := map[string]struct {
+		config  SeverityConfig
+		wantErr string
+	}{
+		"empty": {},
+		"valid": {
+			config: SeverityConfig{CheckOpenStructs: SeverityError, CheckUnknownContextFields: SeverityOff},
+		},
+		"unknownCheck": {
+			config:  SeverityConfig{"naming": SeverityWarn},
+			wantErr: `unknown check "naming"`,
+		},
+		"unknownSeverity": {
+			config:  SeverityConfig{CheckParameterDocs: "fatal"},
+			wantErr: `unknown severity "fatal" of check parameter-docs, must be one of off, warn or error`,
+		},
+	}
+
+	for caseName, cs := range cases {
+		t.Run(caseName, func(t *testing.T) {
+			err := cs.config.Validate()
+			if cs.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.EqualError(t, err, cs.wantErr)
+		})
+	}
+}
+
+func TestValidateDefinitionWithSeverityConfig(t *testing.T) {
+	defer setFakeCuexCompiler()()
+	cli := fake.NewClientBuilder().WithScheme(common.Scheme).WithRESTMapper(newWorkloadRESTMapper()).Build()
+	componentDef := &v1beta1.ComponentDefinition{
+		ObjectMeta: metav1.ObjectMeta{Name: "worker", Namespace: "default"},
+		Spec: v1beta1.ComponentDefinitionSpec{
+			Workload: apicommon.WorkloadTypeDescriptor{Type: "deployments.apps"},
+			Schematic: &apicommon.Schematic{CUE: &apicommon.CUE{Template: `
+output: {
+	apiVersion: "apps/v1"
+	kind:       "Deployment"
+	metadata: labels: parameter.labels
+}
+parameter: {
+	// +usage=the labels of the deployment
+	labels: {...}
+}
+`}},
+		},
+	}
+	stepDef := &v1beta1.WorkflowStepDefinition{
+		ObjectMeta: metav1.ObjectMeta{Name: "notify", Namespace: "default"},
+		Spec: v1beta1.WorkflowStepDefinitionSpec{
+			Schematic: &apicommon.Schematic{CUE: &apicommon.CUE{Template: `
+import "vela/builtin"
+
+log: builtin.#Log & {
+	$params: data: context.appname
+}`}},
+		},
+	}
+	sessionDef := componentDef.DeepCopy()
+	sessionDef.Spec.Schematic.CUE.Template = `
+output: {
+	apiVersion: "apps/v1"
+	kind:       "Deployment"
+	metadata: annotations: session: context.stepSessionID
+}
+`
+	const openStructs = "line 9: parameter.labels is an open struct, which is not allowed in strict mode"
+	const unknownContext = "WorkflowStepDefinition notify references context.appname which is not provided by the workflow runtime"
+	const unknownComponentContext = "ComponentDefinition worker references context.stepSessionID which is not provided by the component rendering"
+
+	cases := map[string]struct {
+		def          runtime.Object
+		config       SeverityConfig
+		wantWarnings []string
+		wantErr      string
+	}{
+		"defaultSkipsOpenStructs": {
+			def: componentDef,
+		},
+		"openStructsAsWarnings": {
+			def:          componentDef,
+			config:       SeverityConfig{CheckOpenStructs: SeverityWarn},
+			wantWarnings: []string{openStructs},
+		},
+		"openStructsAsErrors": {
+			def:     componentDef,
+			config:  SeverityConfig{CheckOpenStructs: SeverityError},
+			wantErr: "parameter.labels is an open struct, which is not allowed in strict mode",
+		},
+		"defaultWarnsUnknownContext": {
+			def:          stepDef,
+			wantWarnings: []string{unknownContext},
+		},
+		"unknownContextOff": {
+			def:    stepDef,
+			config: SeverityConfig{CheckUnknownContextFields: SeverityOff},
+		},
+		"unknownContextAsErrors": {
+			def:     stepDef,
+			config:  SeverityConfig{CheckUnknownContextFields: SeverityError},
+			wantErr: unknownContext,
+		},
+		"defaultWarnsUnknownComponentContext": {
+			def:          sessionDef,
+			wantWarnings: []string{unknownComponentContext},
+		},
+		"unknownComponentContextAsErrors": {
+			def:     sessionDef,
+			config:  SeverityConfig{CheckUnknownContextFields: SeverityError},
+			wantErr: unknownComponentContext,
+		},
+		"invalidConfig": {
+			def:     componentDef,
+			config:  SeverityConfig{"naming": SeverityWarn},
+			wantErr: `invalid severity config: unknown check "naming"`,
+		},
+	}
+
+	for caseName, cs := range cases {
+		t.Run(caseName, func(t *testing.T) {
+			ctx := WithSeverityConfig(context.Background(), cs.config)
+			warnings, err := ValidateDefinitionWithWarnings(ctx, cli, cs.def)
+			assert.Equal(t, cs.wantWarnings, warnings)
+			if cs.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorContains(t, err, cs.wantErr)
+		})
+	}
+}
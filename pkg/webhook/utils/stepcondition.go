@@ -0,0 +1,58 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"fmt"
+
+	"cuelang.org/go/cue"
+	"cuelang.org/go/cue/cuecontext"
+	"cuelang.org/go/cue/parser"
+	"github.com/kubevela/workflow/pkg/cue/model"
+)
+
+// stepConditionAlways is the if condition running the step regardless of the status of the other steps
+const stepConditionAlways = "always"
+
+// stepConditionStub declares the values the if condition of a workflow step can reference when it's evaluated by the
+// workflow runtime, i.e. the status of the steps, the inputs of the step, the context and the parameter
+var stepConditionStub = fmt.Sprintf("\nstatus: _\ninputs: _\n%s: _\n%s: _\n", model.ContextFieldName, parameterFieldName)
+
+// ValidateStepCondition checks that the if condition of a workflow step is a cue expression yielding a boolean, as the
+// workflow runtime evaluates it, so that a malformed condition is reported before the workflow runs. The empty
+// condition and always are accepted. The values only known at runtime, e.g. status.apply.succeeded, are not checked.
+func ValidateStepCondition(expr string) error {
+	if expr == "" || expr == stepConditionAlways {
+		return nil
+	}
+	if _, err := parser.ParseExpr("if", expr); err != nil {
+		return fmt.Errorf("%w %q: %s", ErrInvalidStepCondition, expr, checkError(err))
+	}
+	val := cuecontext.New().CompileString("if: " + expr + stepConditionStub)
+	if err := val.Err(); err != nil {
+		return fmt.Errorf("%w %q: %s", ErrInvalidStepCondition, expr, checkError(err))
+	}
+	v := val.LookupPath(cue.ParsePath("if"))
+	if err := v.Validate(cue.Concrete(false)); err != nil {
+		return fmt.Errorf("%w %q: %s", ErrInvalidStepCondition, expr, checkError(err))
+	}
+	// the kind of the condition depending on the values only known at runtime is not known either
+	if kind := v.IncompleteKind(); kind != cue.BottomKind && kind&cue.BoolKind == 0 {
+		return fmt.Errorf("%w %q: yields %s instead of bool", ErrInvalidStepCondition, expr, kind)
+	}
+	return nil
+}
@@ -0,0 +1,66 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateStepCondition(t *testing.T) {
+	cases := map[string]struct {
+		expr    string
+		wantErr string
+	}{
+		"empty": {},
+		"always": {
+			expr: "always",
+		},
+		"status": {
+			expr: `status.apply.succeeded || status.deploy.phase == "failed"`,
+		},
+		"inputsAndContext": {
+			expr: `inputs.replicas > 1 && context.name != "" && !parameter.skip`,
+		},
+		"syntaxError": {
+			expr:    `status.apply.phase ==`,
+			wantErr: "invalid if condition \"status.apply.phase ==\": expected operand, found 'EOF'",
+		},
+		"referenceNotFound": {
+			expr:    `apply.succeeded`,
+			wantErr: "invalid if condition \"apply.succeeded\": if: reference \"apply\" not found",
+		},
+		"notBoolean": {
+			expr:    `len("apply")`,
+			wantErr: "invalid if condition \"len(\\\"apply\\\")\": yields int instead of bool",
+		},
+	}
+
+	for caseName, cs := range cases {
+		t.Run(caseName, func(t *testing.T) {
+			err := ValidateStepCondition(cs.expr)
+			if cs.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.EqualError(t, err, cs.wantErr)
+			assert.True(t, errors.Is(err, ErrInvalidStepCondition))
+		})
+	}
+}
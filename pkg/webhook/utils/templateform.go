@@ -0,0 +1,145 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"fmt"
+
+	"cuelang.org/go/cue/ast"
+	"cuelang.org/go/cue/literal"
+	"cuelang.org/go/cue/parser"
+	"github.com/kubevela/workflow/pkg/cue/model"
+
+	"github.com/oam-dev/kubevela/pkg/cue/definition"
+)
+
+// templateWrapperFieldName is the field wrapping the template in the definition files of vela def, which keep the
+// metadata of the definition at the top level, e.g. `webservice: {type: "component"}` and `template: {output: ...}`
+const templateWrapperFieldName = "template"
+
+// templateRootFieldNames are the top-level fields that make a template inline, i.e. the cue of schematic.cue.template
+var templateRootFieldNames = map[string]bool{
+	model.ParameterFieldName:         true,
+	model.OutputFieldName:            true,
+	model.OutputsFieldName:           true,
+	definition.PatchFieldName:        true,
+	definition.PatchOutputsFieldName: true,
+	definition.ErrsFieldName:         true,
+}
+
+// definitionFileTypes are the types of the definitions declared in the headers of the definition files
+var definitionFileTypes = map[string]bool{
+	"component":     true,
+	"trait":         true,
+	"policy":        true,
+	"workflow-step": true,
+	"workload":      true,
+}
+
+// unwrapCueTemplate detects the form of the cue template and returns the template to validate. The definition file
+// of vela def, which has a header like `webservice: {type: "component"}` and wraps the template under the template
+// field, is reduced to the imports and the content of the template field, the rest of the file is blanked out so
+// that the positions of the errors are still the ones in the file. The other templates, e.g. the inline template
+// with one of templateRootFieldNames at the top level, are returned as is.
+// ErrUnrecognizedTemplate is returned for the definition file without a template field, or with a template field
+// that is not a struct.
+func unwrapCueTemplate(cueTemplate string) (string, error) {
+	f, err := parser.ParseFile("-", cueTemplate)
+	if err != nil {
+		// the syntax error is reported by the validation
+		return cueTemplate, nil
+	}
+	var wrapper, header *ast.Field
+	for _, decl := range f.Decls {
+		field, ok := decl.(*ast.Field)
+		if !ok {
+			continue
+		}
+		name, _, err := ast.LabelName(field.Label)
+		if err != nil {
+			continue
+		}
+		switch {
+		case templateRootFieldNames[name]:
+			return cueTemplate, nil
+		case name == templateWrapperFieldName:
+			if wrapper == nil {
+				wrapper = field
+			}
+		case header == nil && isDefinitionHeader(field):
+			header = field
+		}
+	}
+	if wrapper == nil {
+		if header == nil {
+			return cueTemplate, nil
+		}
+		name, _, _ := ast.LabelName(header.Label)
+		return "", fmt.Errorf("%w: definition %s is declared without a %s field, and no field of an inline template, e.g. %s, %s, %s or %s, is found",
+			ErrUnrecognizedTemplate, name, templateWrapperFieldName, model.OutputFieldName, model.OutputsFieldName,
+			definition.PatchFieldName, model.ParameterFieldName)
+	}
+	lit, ok := wrapper.Value.(*ast.StructLit)
+	if !ok {
+		return "", fmt.Errorf("%w: the %s field at line %d must be a struct", ErrUnrecognizedTemplate, templateWrapperFieldName, wrapper.Pos().Line())
+	}
+	src := []byte(cueTemplate)
+	keep := make([]bool, len(src))
+	for _, decl := range f.Decls {
+		if imp, ok := decl.(*ast.ImportDecl); ok {
+			markKept(keep, imp.Pos().Offset(), imp.End().Offset())
+		}
+	}
+	markKept(keep, lit.Lbrace.Offset()+1, lit.Rbrace.Offset())
+	for i := range src {
+		if !keep[i] && src[i] != '\n' {
+			src[i] = ' '
+		}
+	}
+	return string(src), nil
+}
+
+// isDefinitionHeader checks whether the field declares a definition in a definition file, i.e. it's a struct with
+// the type of a definition, e.g. `webservice: {type: "component"}`
+func isDefinitionHeader(field *ast.Field) bool {
+	lit, ok := field.Value.(*ast.StructLit)
+	if !ok {
+		return false
+	}
+	for _, elt := range lit.Elts {
+		f, ok := elt.(*ast.Field)
+		if !ok {
+			continue
+		}
+		if name, _, err := ast.LabelName(f.Label); err != nil || name != "type" {
+			continue
+		}
+		if typ, ok := f.Value.(*ast.BasicLit); ok {
+			unquoted, err := literal.Unquote(typ.Value)
+			return err == nil && definitionFileTypes[unquoted]
+		}
+	}
+	return false
+}
+
+func markKept(keep []bool, start, end int) {
+	for i := start; i < end && i < len(keep); i++ {
+		if i >= 0 {
+			keep[i] = true
+		}
+	}
+}
@@ -0,0 +1,98 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateCueTemplateForms(t *testing.T) {
+	cases := map[string]struct {
+		cueTemplate string
+		want        []CueValidationError
+		wantErr     string
+		wantIs      error
+	}{
+		"inline": {
+			cueTemplate: `
+output: {
+	apiVersion: "v1"
+	kind:       "ConfigMap"
+}`,
+		},
+		"wrapped": {
+			cueTemplate: `
+import "strings"
+
+configmap: {
+	type: "component"
+	attributes: workload: type: "autodetects.core.oam.dev"
+}
+template: {
+	output: {
+		apiVersion: "v1"
+		kind:       "ConfigMap"
+		data: name: strings.ToLower(parameter.name)
+		kind:       "Secret"
+	}
+	parameter: {
+		name: string
+		port: int
+	}
+}`,
+			want: []CueValidationError{{
+				Message: "output.kind: conflicting values \"Secret\" and \"ConfigMap\"",
+				Line:    11,
+				Column:  15,
+			}},
+		},
+		"wrappedNotStruct": {
+			cueTemplate: `
+configmap: type: "component"
+template: "output: {}"`,
+			wantErr: "unrecognized template form: the template field at line 3 must be a struct",
+			wantIs:  ErrUnrecognizedTemplate,
+		},
+		"definitionWithoutTemplate": {
+			cueTemplate: `
+configmap: {
+	type: "component"
+	description: "configmap"
+}`,
+			wantErr: "unrecognized template form: definition configmap is declared without a template field, " +
+				"and no field of an inline template, e.g. output, outputs, patch or parameter, is found",
+			wantIs: ErrUnrecognizedTemplate,
+		},
+	}
+
+	for caseName, cs := range cases {
+		t.Run(caseName, func(t *testing.T) {
+			got, err := ValidateCueTemplateDetailed(cs.cueTemplate)
+			if cs.wantIs != nil {
+				assert.True(t, errors.Is(err, cs.wantIs))
+			}
+			if cs.wantErr != "" {
+				assert.EqualError(t, err, cs.wantErr)
+				return
+			}
+			assert.Equal(t, cs.want, got)
+		})
+	}
+}
@@ -0,0 +1,85 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/labels"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/common"
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
+)
+
+const labelSelectorConflictPrefix = "labelSelector:"
+
+// ValidateTraitConflicts loads the TraitDefinitions of the traits attached to the component and returns an error for
+// every two traits that conflict, i.e. one of them declares the other in conflictsWith by the definition name,
+// the API resource, the API group or the label selector.
+// The traits whose definition is not found are skipped, which is reported by ValidateApplicationComponents.
+// The namespace to look up the definitions is taken from ctx.
+func ValidateTraitConflicts(ctx context.Context, cli client.Client, component common.ApplicationComponent) error {
+	var tds []*v1beta1.TraitDefinition
+	for _, trait := range component.Traits {
+		td := &v1beta1.TraitDefinition{}
+		found, err := definitionExists(ctx, cli, td, definitionNameOfType(trait.Type))
+		if err != nil {
+			return err
+		}
+		if found {
+			tds = append(tds, td)
+		}
+	}
+	var errs []error
+	for i := range tds {
+		for j := i + 1; j < len(tds); j++ {
+			if traitConflictsWith(tds[i], tds[j]) || traitConflictsWith(tds[j], tds[i]) {
+				errs = append(errs, fmt.Errorf("trait %s conflicts with trait %s in component %s", tds[i].Name, tds[j].Name, component.Name))
+			}
+		}
+	}
+	return aggregateErrors(errs)
+}
+
+// traitConflictsWith checks whether the conflictsWith rules of the trait match the other one
+func traitConflictsWith(td, other *v1beta1.TraitDefinition) bool {
+	for _, rule := range td.Spec.ConflictsWith {
+		switch {
+		case strings.HasPrefix(rule, labelSelectorConflictPrefix):
+			selector, err := labels.Parse(strings.TrimPrefix(rule, labelSelectorConflictPrefix))
+			if err == nil && selector.Matches(labels.Set(other.Labels)) {
+				return true
+			}
+		case strings.HasPrefix(rule, "*."):
+			if strings.HasSuffix(other.Spec.Reference.Name, rule[1:]) {
+				return true
+			}
+		case strings.Contains(rule, "."):
+			if other.Spec.Reference.Name == rule {
+				return true
+			}
+		default:
+			if other.Name == rule {
+				return true
+			}
+		}
+	}
+	return false
+}
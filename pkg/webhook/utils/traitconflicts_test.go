@@ -0,0 +1,89 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	apicommon "github.com/oam-dev/kubevela/apis/core.oam.dev/common"
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
+	"github.com/oam-dev/kubevela/pkg/oam"
+	"github.com/oam-dev/kubevela/pkg/utils/common"
+)
+
+func TestValidateTraitConflicts(t *testing.T) {
+	traitDef := func(name string, labels map[string]string, crd string, conflictsWith ...string) *v1beta1.TraitDefinition {
+		td := newTraitDefinition(name, oam.SystemDefinitionNamespace, "")
+		td.Labels = labels
+		td.Spec.Reference = apicommon.DefinitionReference{Name: crd}
+		td.Spec.ConflictsWith = conflictsWith
+		return td
+	}
+	cli := fake.NewClientBuilder().WithScheme(common.Scheme).WithObjects(
+		traitDef("scaler", map[string]string{"type": "scaling"}, ""),
+		traitDef("hpa", nil, "horizontalpodautoscalers.autoscaling", "scaler"),
+		traitDef("keda", nil, "", "labelSelector:type=scaling"),
+		traitDef("gateway", nil, "ingresses.networking.k8s.io"),
+		traitDef("expose", nil, "", "*.networking.k8s.io"),
+		traitDef("ingress", nil, "", "ingresses.networking.k8s.io"),
+	).Build()
+
+	cases := map[string]struct {
+		traits  []string
+		wantErr string
+	}{
+		"noConflict": {
+			traits: []string{"scaler", "gateway", "not-exist"},
+		},
+		"definitionName": {
+			traits:  []string{"scaler", "hpa"},
+			wantErr: "trait scaler conflicts with trait hpa in component api",
+		},
+		"labelSelector": {
+			traits:  []string{"keda", "scaler"},
+			wantErr: "trait keda conflicts with trait scaler in component api",
+		},
+		"apiGroupAndResource": {
+			traits: []string{"gateway", "expose", "ingress"},
+			wantErr: "[trait gateway conflicts with trait expose in component api, " +
+				"trait gateway conflicts with trait ingress in component api]",
+		},
+		"versionedType": {
+			traits:  []string{"hpa@v1", "scaler"},
+			wantErr: "trait hpa conflicts with trait scaler in component api",
+		},
+	}
+
+	for caseName, cs := range cases {
+		t.Run(caseName, func(t *testing.T) {
+			comp := apicommon.ApplicationComponent{Name: "api", Type: "webservice"}
+			for _, trait := range cs.traits {
+				comp.Traits = append(comp.Traits, apicommon.ApplicationTrait{Type: trait})
+			}
+			err := ValidateTraitConflicts(context.Background(), cli, comp)
+			if cs.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.EqualError(t, err, cs.wantErr)
+		})
+	}
+}
@@ -0,0 +1,42 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"fmt"
+
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
+)
+
+// ComponentMaxTraits is the max number of the traits of a component of an application, so that the pathological
+// applications are rejected before their traits are rendered. The limit is disabled if it's not positive.
+var ComponentMaxTraits = 0
+
+// ValidateComponentTraitCount checks that no component of the application has more traits than ComponentMaxTraits,
+// the errors of the components are reported together and match ErrTooManyTraits by errors.Is
+func ValidateComponentTraitCount(app *v1beta1.Application) error {
+	if ComponentMaxTraits <= 0 {
+		return nil
+	}
+	var errs []error
+	for _, comp := range app.Spec.Components {
+		if n := len(comp.Traits); n > ComponentMaxTraits {
+			errs = append(errs, withSentinel(fmt.Errorf("component %s has %d traits, exceeds limit %d", comp.Name, n, ComponentMaxTraits), ErrTooManyTraits))
+		}
+	}
+	return aggregateErrors(errs)
+}
@@ -0,0 +1,69 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/common"
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
+)
+
+func TestValidateComponentTraitCount(t *testing.T) {
+	defer func(limit int) { ComponentMaxTraits = limit }(ComponentMaxTraits)
+	component := func(name string, traits ...string) common.ApplicationComponent {
+		comp := common.ApplicationComponent{Name: name, Type: "webservice"}
+		for _, trait := range traits {
+			comp.Traits = append(comp.Traits, common.ApplicationTrait{Type: trait})
+		}
+		return comp
+	}
+	app := &v1beta1.Application{Spec: v1beta1.ApplicationSpec{Components: []common.ApplicationComponent{
+		component("frontend", "scaler", "gateway", "labels"),
+		component("backend", "scaler"),
+		component("worker", "scaler", "labels", "annotations", "sidecar"),
+	}}}
+
+	cases := map[string]struct {
+		limit   int
+		wantErr string
+	}{
+		"unlimitedByDefault": {},
+		"withinLimit": {
+			limit: 4,
+		},
+		"exceedsLimit": {
+			limit:   2,
+			wantErr: "[component frontend has 3 traits, exceeds limit 2, component worker has 4 traits, exceeds limit 2]",
+		},
+	}
+	for caseName, cs := range cases {
+		t.Run(caseName, func(t *testing.T) {
+			ComponentMaxTraits = cs.limit
+			err := ValidateComponentTraitCount(app)
+			if cs.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.EqualError(t, err, cs.wantErr)
+			assert.True(t, errors.Is(err, ErrTooManyTraits))
+		})
+	}
+}
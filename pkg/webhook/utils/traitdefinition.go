@@ -0,0 +1,228 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"cuelang.org/go/cue/ast"
+	"cuelang.org/go/cue/parser"
+	"github.com/pkg/errors"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
+	"github.com/oam-dev/kubevela/pkg/oam/util"
+)
+
+// ValidateTraitDefinition validates the trait's cue template, rejects the contradictory combinations of the
+// fields listed in traitSpecRules and checks that every definition referenced by appliesToWorkloads and
+// conflictsWith exists.
+// Wildcards, API resource/group references (e.g. deployments.apps, *.apps) and label selectors
+// are backed by CRDs rather than definitions, so they are skipped.
+// The trait patching the shared resources and outputting its own without a stage is returned with a warning
+// suggesting the stage to set.
+func ValidateTraitDefinition(ctx context.Context, cli client.Client, td *v1beta1.TraitDefinition) ([]string, error) {
+	if td.Spec.Schematic != nil && td.Spec.Schematic.CUE != nil && !isCueTemplateValidated(ctx) {
+		if err := ValidateCuexTemplate(WithDefinitionKind(WithDefinitionName(ctx, td.Name), v1beta1.TraitDefinitionKind), td.Spec.Schematic.CUE.Template); err != nil {
+			return nil, err
+		}
+	}
+
+	ctx = util.SetNamespaceInCtx(ctx, td.Namespace)
+	var missingWorkloads, missingTraits []string
+	for _, workload := range td.Spec.AppliesToWorkloads {
+		if !isDefinitionNameReference(workload) {
+			continue
+		}
+		found, err := workloadDefinitionExists(ctx, cli, workload)
+		if err != nil {
+			return nil, err
+		}
+		if !found {
+			missingWorkloads = append(missingWorkloads, workload)
+		}
+	}
+	for _, trait := range td.Spec.ConflictsWith {
+		if !isDefinitionNameReference(trait) {
+			continue
+		}
+		found, err := definitionExists(ctx, cli, &v1beta1.TraitDefinition{}, trait)
+		if err != nil {
+			return nil, err
+		}
+		if !found {
+			missingTraits = append(missingTraits, trait)
+		}
+	}
+
+	msgs := traitSpecContradictions(td)
+	if len(missingWorkloads) != 0 {
+		msgs = append(msgs, fmt.Sprintf("appliesToWorkloads references workloads that are not found: %s", strings.Join(missingWorkloads, ", ")))
+	}
+	if len(missingTraits) != 0 {
+		msgs = append(msgs, fmt.Sprintf("conflictsWith references traits that are not found: %s", strings.Join(missingTraits, ", ")))
+	}
+	if len(msgs) != 0 {
+		return nil, errors.Errorf("TraitDefinition %s is invalid: %s", td.Name, strings.Join(msgs, "; "))
+	}
+	return traitStageWarnings(td), nil
+}
+
+// isDefinitionNameReference checks whether the reference in appliesToWorkloads or conflictsWith
+// points to a definition name rather than a wildcard, API resource, API group or label selector
+func isDefinitionNameReference(ref string) bool {
+	return ref != "" && ref != "*" && !strings.Contains(ref, ".") && !strings.HasPrefix(ref, "labelSelector:")
+}
+
+// workloadDefinitionExists checks whether the workload is registered either by a ComponentDefinition or a WorkloadDefinition
+func workloadDefinitionExists(ctx context.Context, cli client.Client, name string) (bool, error) {
+	found, err := definitionExists(ctx, cli, &v1beta1.ComponentDefinition{}, name)
+	if err != nil || found {
+		return found, err
+	}
+	return definitionExists(ctx, cli, &v1beta1.WorkloadDefinition{}, name)
+}
+
+func definitionExists(ctx context.Context, cli client.Client, def client.Object, name string) (bool, error) {
+	if err := util.GetDefinition(ctx, cli, def, name); err != nil {
+		if apierrors.IsNotFound(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+// traitTemplateShape describes what the cue template of the trait renders
+type traitTemplateShape struct {
+	// patches is true if the template patches the workload
+	patches bool
+	// outputs is true if the template outputs resources of the trait
+	outputs bool
+	// patchesOutputs is true if the template patches the outputs of the component and the other traits
+	patchesOutputs bool
+}
+
+// traitSpecRule rejects a combination of the fields of the TraitDefinition for which violated returns true
+type traitSpecRule struct {
+	violated func(spec *v1beta1.TraitDefinitionSpec, shape traitTemplateShape) bool
+	message  func(spec *v1beta1.TraitDefinitionSpec) string
+}
+
+// traitSpecRules is the matrix of the field combinations rejected, every other combination is allowed.
+// The rules about the template shape only apply to the cue templates.
+//
+//	field              | allowed with
+//	-------------------+-------------------------------------------------------------------------------
+//	stage              | empty, PreDispatch, DefaultDispatch or PostDispatch
+//	stage (non-default)| a template with outputs, the stage orders the dispatch of the trait's resources
+//	revisionEnabled    | a template with outputs, the resources are aware of the component revision
+//	workloadRefPath    | a template with outputs, the workloadRef is set in the trait's resource
+//	manageWorkload     | a template with outputs, the trait's resources create the workload
+var traitSpecRules = []traitSpecRule{{
+	violated: func(spec *v1beta1.TraitDefinitionSpec, _ traitTemplateShape) bool {
+		return !isKnownTraitStage(spec.Stage)
+	},
+	message: func(spec *v1beta1.TraitDefinitionSpec) string {
+		return fmt.Sprintf("stage %s is not one of %s, %s and %s", spec.Stage, v1beta1.PreDispatch, v1beta1.DefaultDispatch, v1beta1.PostDispatch)
+	},
+}, {
+	violated: func(spec *v1beta1.TraitDefinitionSpec, shape traitTemplateShape) bool {
+		return isKnownTraitStage(spec.Stage) && spec.Stage != "" && spec.Stage != v1beta1.DefaultDispatch && isPatchOnly(shape)
+	},
+	message: func(spec *v1beta1.TraitDefinitionSpec) string {
+		return fmt.Sprintf("stage %s only orders the dispatch of the trait's outputs, but the trait only patches the workload", spec.Stage)
+	},
+}, {
+	violated: func(spec *v1beta1.TraitDefinitionSpec, shape traitTemplateShape) bool {
+		return spec.RevisionEnabled && isPatchOnly(shape)
+	},
+	message: func(*v1beta1.TraitDefinitionSpec) string {
+		return "revisionEnabled requires outputs aware of the component revision, but the trait only patches the workload in place"
+	},
+}, {
+	violated: func(spec *v1beta1.TraitDefinitionSpec, shape traitTemplateShape) bool {
+		return spec.WorkloadRefPath != "" && isPatchOnly(shape)
+	},
+	message: func(spec *v1beta1.TraitDefinitionSpec) string {
+		return fmt.Sprintf("workloadRefPath %s requires an output to set the workloadRef in, but the trait only patches the workload", spec.WorkloadRefPath)
+	},
+}, {
+	violated: func(spec *v1beta1.TraitDefinitionSpec, shape traitTemplateShape) bool {
+		return spec.ManageWorkload && isPatchOnly(shape)
+	},
+	message: func(*v1beta1.TraitDefinitionSpec) string {
+		return "manageWorkload requires outputs managing the workload, but the trait only patches the workload"
+	},
+}}
+
+// traitSpecContradictions returns the messages of the rules in traitSpecRules violated by the trait
+func traitSpecContradictions(td *v1beta1.TraitDefinition) []string {
+	shape := traitTemplateShapeOf(td)
+	var msgs []string
+	for _, rule := range traitSpecRules {
+		if rule.violated(&td.Spec, shape) {
+			msgs = append(msgs, rule.message(&td.Spec))
+		}
+	}
+	return msgs
+}
+
+// traitTemplateShapeOf finds the top-level patch, patchOutputs and outputs of the cue template of the trait, the shape is empty
+// if the trait has no cue template or the template can't be parsed
+func traitTemplateShapeOf(td *v1beta1.TraitDefinition) traitTemplateShape {
+	var shape traitTemplateShape
+	if td.Spec.Schematic == nil || td.Spec.Schematic.CUE == nil {
+		return shape
+	}
+	f, err := parser.ParseFile("-", td.Spec.Schematic.CUE.Template)
+	if err != nil {
+		return shape
+	}
+	for _, decl := range f.Decls {
+		field, ok := decl.(*ast.Field)
+		if !ok {
+			continue
+		}
+		switch name, _, _ := ast.LabelName(field.Label); name {
+		case "patch":
+			shape.patches = true
+		case "outputs", "output":
+			shape.outputs = true
+		case "patchOutputs":
+			shape.patchesOutputs = true
+		}
+	}
+	return shape
+}
+
+// isPatchOnly checks whether the template patches the workload without outputting any resource
+func isPatchOnly(shape traitTemplateShape) bool {
+	return shape.patches && !shape.outputs
+}
+
+func isKnownTraitStage(stage v1beta1.StageType) bool {
+	switch stage {
+	case "", v1beta1.PreDispatch, v1beta1.DefaultDispatch, v1beta1.PostDispatch:
+		return true
+	default:
+		return false
+	}
+}
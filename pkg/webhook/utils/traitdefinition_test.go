@@ -0,0 +1,198 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	apicommon "github.com/oam-dev/kubevela/apis/core.oam.dev/common"
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
+	"github.com/oam-dev/kubevela/pkg/oam"
+	"github.com/oam-dev/kubevela/pkg/utils/common"
+)
+
+func TestValidateTraitDefinition(t *testing.T) {
+	defer setFakeCuexCompiler()()
+
+	cli := fake.NewClientBuilder().WithScheme(common.Scheme).WithObjects(
+		&v1beta1.ComponentDefinition{ObjectMeta: metav1.ObjectMeta{Name: "webservice", Namespace: oam.SystemDefinitionNamespace}},
+		&v1beta1.ComponentDefinition{ObjectMeta: metav1.ObjectMeta{Name: "worker", Namespace: "default"}},
+		&v1beta1.WorkloadDefinition{ObjectMeta: metav1.ObjectMeta{Name: "legacy-workload", Namespace: oam.SystemDefinitionNamespace}},
+		&v1beta1.TraitDefinition{ObjectMeta: metav1.ObjectMeta{Name: "scaler", Namespace: oam.SystemDefinitionNamespace}},
+	).Build()
+
+	cases := map[string]struct {
+		spec         v1beta1.TraitDefinitionSpec
+		wantWarnings []string
+		wantErr      string
+	}{
+		"allReferencesResolve": {
+			spec: v1beta1.TraitDefinitionSpec{
+				AppliesToWorkloads: []string{"webservice", "worker", "legacy-workload"},
+				ConflictsWith:      []string{"scaler"},
+			},
+		},
+		"wildcardAndCRDReferences": {
+			spec: v1beta1.TraitDefinitionSpec{
+				AppliesToWorkloads: []string{"*", "deployments.apps", "*.apps"},
+				ConflictsWith:      []string{"services.k8s.io", "*.networking.k8s.io", "labelSelector:foo=bar"},
+			},
+		},
+		"missingReferences": {
+			spec: v1beta1.TraitDefinitionSpec{
+				AppliesToWorkloads: []string{"webservice", "webservise", "wrker"},
+				ConflictsWith:      []string{"scaler", "scalar"},
+			},
+			wantErr: "TraitDefinition test is invalid: appliesToWorkloads references workloads that are not found: webservise, wrker; conflictsWith references traits that are not found: scalar",
+		},
+		"contradictoryFields": {
+			spec: v1beta1.TraitDefinitionSpec{
+				RevisionEnabled:    true,
+				AppliesToWorkloads: []string{"wrker"},
+				Schematic:          &apicommon.Schematic{CUE: &apicommon.CUE{Template: "patch: spec: replicas: 1"}},
+			},
+			wantErr: "TraitDefinition test is invalid: revisionEnabled requires outputs aware of the component revision, but the trait only patches the workload in place; appliesToWorkloads references workloads that are not found: wrker",
+		},
+		"patchesWithoutStage": {
+			spec: v1beta1.TraitDefinitionSpec{
+				Schematic: &apicommon.Schematic{CUE: &apicommon.CUE{Template: "patch: metadata: labels: app: \"x\"\noutputs: service: kind: \"Service\""}},
+			},
+		},
+		"patchesReadingStatus": {
+			spec: v1beta1.TraitDefinitionSpec{
+				Schematic: &apicommon.Schematic{CUE: &apicommon.CUE{Template: `
+patch: metadata: labels: app: "x"
+outputs: service: {
+	kind: "Service"
+	if context.output.status != _|_ {
+		metadata: annotations: ready: "true"
+	}
+}
+`}},
+			},
+			wantWarnings: []string{"TraitDefinition test patches the workload and outputs resources without declaring a stage, " +
+				"set spec.stage to PostDispatch as it reads context.output.status, which is only set once the workload is dispatched"},
+		},
+		"patchesOutputsReadingStatus": {
+			spec: v1beta1.TraitDefinitionSpec{
+				Schematic: &apicommon.Schematic{CUE: &apicommon.CUE{Template: `
+patchOutputs: service: metadata: labels: app: "x"
+outputs: ingress: {
+	kind: "Ingress"
+	if context.output.status != _|_ {
+		metadata: annotations: ready: "true"
+	}
+}
+`}},
+			},
+			wantWarnings: []string{"TraitDefinition test patches the outputs and outputs resources without declaring a stage, " +
+				"set spec.stage to PostDispatch as it reads context.output.status, which is only set once the workload is dispatched"},
+		},
+		"patchesWithStage": {
+			spec: v1beta1.TraitDefinitionSpec{
+				Stage:     v1beta1.PostDispatch,
+				Schematic: &apicommon.Schematic{CUE: &apicommon.CUE{Template: "patch: metadata: labels: app: \"x\"\noutputs: service: kind: \"Service\""}},
+			},
+		},
+		"invalidCueTemplate": {
+			spec: v1beta1.TraitDefinitionSpec{
+				Schematic: &apicommon.Schematic{CUE: &apicommon.CUE{Template: "patch: hello: world"}},
+			},
+			wantErr: "patch.hello: reference \"world\" not found",
+		},
+	}
+
+	for caseName, cs := range cases {
+		t.Run(caseName, func(t *testing.T) {
+			td := &v1beta1.TraitDefinition{
+				ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "default"},
+				Spec:       cs.spec,
+			}
+			warnings, err := ValidateTraitDefinition(context.Background(), cli, td)
+			assert.Equal(t, cs.wantWarnings, warnings)
+			if cs.wantErr == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, cs.wantErr)
+			}
+		})
+	}
+}
+
+func TestTraitSpecContradictions(t *testing.T) {
+	patchOnly := &apicommon.Schematic{CUE: &apicommon.CUE{Template: "parameter: replicas: int\npatch: spec: replicas: parameter.replicas"}}
+	withOutputs := &apicommon.Schematic{CUE: &apicommon.CUE{Template: "patch: metadata: labels: app: \"x\"\noutputs: service: kind: \"Service\""}}
+
+	cases := map[string]struct {
+		spec v1beta1.TraitDefinitionSpec
+		want []string
+	}{
+		"patchOnly": {
+			spec: v1beta1.TraitDefinitionSpec{Schematic: patchOnly, PodDisruptive: true, Stage: v1beta1.DefaultDispatch},
+		},
+		"outputsWithEveryField": {
+			spec: v1beta1.TraitDefinitionSpec{
+				Schematic:       withOutputs,
+				Stage:           v1beta1.PostDispatch,
+				RevisionEnabled: true,
+				WorkloadRefPath: "spec.workloadRef",
+				ManageWorkload:  true,
+			},
+		},
+		"noCueTemplate": {
+			spec: v1beta1.TraitDefinitionSpec{RevisionEnabled: true, ManageWorkload: true},
+		},
+		"unknownStage": {
+			spec: v1beta1.TraitDefinitionSpec{Schematic: withOutputs, Stage: "PreRender"},
+			want: []string{"stage PreRender is not one of PreDispatch, DefaultDispatch and PostDispatch"},
+		},
+		"stageWithPatchOnly": {
+			spec: v1beta1.TraitDefinitionSpec{Schematic: patchOnly, Stage: v1beta1.PreDispatch},
+			want: []string{"stage PreDispatch only orders the dispatch of the trait's outputs, but the trait only patches the workload"},
+		},
+		"revisionEnabledWithPatchOnly": {
+			spec: v1beta1.TraitDefinitionSpec{Schematic: patchOnly, RevisionEnabled: true},
+			want: []string{"revisionEnabled requires outputs aware of the component revision, but the trait only patches the workload in place"},
+		},
+		"workloadRefPathWithPatchOnly": {
+			spec: v1beta1.TraitDefinitionSpec{Schematic: patchOnly, WorkloadRefPath: "spec.workloadRef"},
+			want: []string{"workloadRefPath spec.workloadRef requires an output to set the workloadRef in, but the trait only patches the workload"},
+		},
+		"manageWorkloadWithPatchOnly": {
+			spec: v1beta1.TraitDefinitionSpec{Schematic: patchOnly, ManageWorkload: true},
+			want: []string{"manageWorkload requires outputs managing the workload, but the trait only patches the workload"},
+		},
+		"multipleContradictions": {
+			spec: v1beta1.TraitDefinitionSpec{Schematic: patchOnly, Stage: v1beta1.PostDispatch, RevisionEnabled: true},
+			want: []string{
+				"stage PostDispatch only orders the dispatch of the trait's outputs, but the trait only patches the workload",
+				"revisionEnabled requires outputs aware of the component revision, but the trait only patches the workload in place",
+			},
+		},
+	}
+	for caseName, cs := range cases {
+		t.Run(caseName, func(t *testing.T) {
+			td := &v1beta1.TraitDefinition{ObjectMeta: metav1.ObjectMeta{Name: "test"}, Spec: cs.spec}
+			assert.Equal(t, cs.want, traitSpecContradictions(td))
+		})
+	}
+}
@@ -0,0 +1,200 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"cuelang.org/go/cue/ast"
+	"cuelang.org/go/cue/parser"
+	"github.com/kubevela/workflow/pkg/cue/model"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/common"
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
+	"github.com/oam-dev/kubevela/pkg/cue/definition"
+)
+
+// traitStageOrder is the order in which the resources of the traits are dispatched by their stages
+var traitStageOrder = map[v1beta1.StageType]int{
+	v1beta1.PreDispatch:     0,
+	v1beta1.DefaultDispatch: 1,
+	v1beta1.PostDispatch:    2,
+}
+
+// traitStageOf returns the stage of the trait, DefaultDispatch if it's not declared
+func traitStageOf(td *v1beta1.TraitDefinition) v1beta1.StageType {
+	if td.Spec.Stage == "" {
+		return v1beta1.DefaultDispatch
+	}
+	return td.Spec.Stage
+}
+
+// traitStageWarnings returns the warning for the trait that patches the resources shared with the component or the
+// other traits, i.e. the workload by patch or the other outputs by patchOutputs, and outputs resources of its own
+// reading the status of the workload without declaring a stage. Its resources are then dispatched along with the
+// workload by DefaultDispatch, before the status is set, so PostDispatch is suggested.
+func traitStageWarnings(td *v1beta1.TraitDefinition) []string {
+	shape := traitTemplateShapeOf(td)
+	if td.Spec.Stage != "" || !shape.outputs || !shape.patches && !shape.patchesOutputs {
+		return nil
+	}
+	if !readsWorkloadStatus(td.Spec.Schematic.CUE.Template) {
+		return nil
+	}
+	patched := "the workload"
+	if !shape.patches {
+		patched = "the outputs"
+	}
+	return []string{fmt.Sprintf("TraitDefinition %s patches %s and outputs resources without declaring a stage, set spec.stage to %s "+
+		"as it reads context.output.status, which is only set once the workload is dispatched", td.Name, patched, v1beta1.PostDispatch)}
+}
+
+// readsWorkloadStatus checks whether the cue template references context.output.status
+func readsWorkloadStatus(cueTemplate string) bool {
+	f, err := parser.ParseFile("-", cueTemplate)
+	if err != nil {
+		return false
+	}
+	found := false
+	ast.Walk(f, func(node ast.Node) bool {
+		sel, ok := node.(*ast.SelectorExpr)
+		if !ok || found {
+			return !found
+		}
+		if path := selectorPathOf(sel); len(path) >= 3 && path[0] == model.ContextFieldName && path[1] == model.OutputFieldName && path[2] == "status" {
+			found = true
+		}
+		return true
+	}, nil)
+	return found
+}
+
+// selectorPathOf returns the names of the reference, e.g. [context output status] for context.output.status, nil if
+// the root of the reference is not an identifier
+func selectorPathOf(sel *ast.SelectorExpr) []string {
+	root, selectors := selectorChainOf(sel)
+	id, ok := root.(*ast.Ident)
+	if !ok {
+		return nil
+	}
+	path := []string{id.Name}
+	for _, s := range selectors {
+		name, _, err := ast.LabelName(s.Sel)
+		if err != nil {
+			break
+		}
+		path = append(path, name)
+	}
+	return path
+}
+
+// ValidateTraitOrdering loads the TraitDefinitions of the traits attached to the component and checks that every
+// trait depending on the outputs of another one, i.e. referencing context.outputs.<key> or patching the key by
+// patchOutputs, is not dispatched in an earlier stage than the trait outputting the key. Otherwise the resources
+// of the trait are dispatched before the ones they depend on, and the error tells which stage to set.
+// The traits whose definition is not found are skipped, which is reported by ValidateApplicationComponents.
+// The namespace to look up the definitions is taken from ctx.
+func ValidateTraitOrdering(ctx context.Context, cli client.Client, component common.ApplicationComponent) error {
+	type traitOutputs struct {
+		td        *v1beta1.TraitDefinition
+		outputs   map[string]bool
+		dependsOn []string
+	}
+	var traits []traitOutputs
+	for _, trait := range component.Traits {
+		td := &v1beta1.TraitDefinition{}
+		found, err := definitionExists(ctx, cli, td, definitionNameOfType(trait.Type))
+		if err != nil {
+			return err
+		}
+		if found && td.Spec.Schematic != nil && td.Spec.Schematic.CUE != nil {
+			outputs, dependsOn := traitOutputDependenciesOf(td.Spec.Schematic.CUE.Template)
+			traits = append(traits, traitOutputs{td: td, outputs: outputs, dependsOn: dependsOn})
+		}
+	}
+	var errs []error
+	for _, trait := range traits {
+		for _, other := range traits {
+			if trait.td.Name == other.td.Name {
+				continue
+			}
+			stage, otherStage := traitStageOf(trait.td), traitStageOf(other.td)
+			if traitStageOrder[stage] >= traitStageOrder[otherStage] {
+				continue
+			}
+			for _, key := range trait.dependsOn {
+				if other.outputs[key] {
+					errs = append(errs, fmt.Errorf("trait %s in stage %s depends on outputs.%s of trait %s in stage %s in component %s, "+
+						"set spec.stage of TraitDefinition %s to %s", trait.td.Name, stage, key, other.td.Name, otherStage, component.Name,
+						trait.td.Name, otherStage))
+					break
+				}
+			}
+		}
+	}
+	return aggregateErrors(errs)
+}
+
+// traitOutputDependenciesOf returns the keys of the outputs of the trait template, and the sorted keys of the other
+// outputs it depends on, i.e. referenced by context.outputs.<key> or patched by patchOutputs
+func traitOutputDependenciesOf(cueTemplate string) (map[string]bool, []string) {
+	outputs := map[string]bool{}
+	f, err := parser.ParseFile("-", cueTemplate)
+	if err != nil {
+		// the syntax error is reported by the validation
+		return outputs, nil
+	}
+	dependsOn := map[string]bool{}
+	for _, decl := range f.Decls {
+		field, ok := decl.(*ast.Field)
+		if !ok {
+			continue
+		}
+		open := false
+		switch name, _, _ := ast.LabelName(field.Label); name {
+		case model.OutputsFieldName:
+			for _, key := range outputKeysOf(field.Value, &open) {
+				outputs[key.name] = true
+			}
+		case definition.PatchOutputsFieldName:
+			for _, key := range outputKeysOf(field.Value, &open) {
+				dependsOn[key.name] = true
+			}
+		}
+	}
+	ast.Walk(f, func(node ast.Node) bool {
+		sel, ok := node.(*ast.SelectorExpr)
+		if !ok {
+			return true
+		}
+		if path := selectorPathOf(sel); len(path) >= 3 && path[0] == model.ContextFieldName && path[1] == model.OutputsFieldName {
+			dependsOn[path[2]] = true
+		}
+		return false
+	}, nil)
+	keys := make([]string, 0, len(dependsOn))
+	for key := range dependsOn {
+		if !outputs[key] {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return outputs, keys
+}
@@ -0,0 +1,87 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	apicommon "github.com/oam-dev/kubevela/apis/core.oam.dev/common"
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
+	"github.com/oam-dev/kubevela/pkg/oam"
+	"github.com/oam-dev/kubevela/pkg/utils/common"
+)
+
+func TestValidateTraitOrdering(t *testing.T) {
+	traitDef := func(name string, stage v1beta1.StageType, template string) *v1beta1.TraitDefinition {
+		td := newTraitDefinition(name, oam.SystemDefinitionNamespace, template)
+		td.Spec.Stage = stage
+		return td
+	}
+	cli := fake.NewClientBuilder().WithScheme(common.Scheme).WithObjects(
+		traitDef("expose", "", `outputs: service: kind: "Service"`),
+		traitDef("gateway", v1beta1.PostDispatch, `outputs: ingress: kind: "Ingress"`),
+		traitDef("service-labels", "", `patchOutputs: service: metadata: labels: app: "x"`),
+		traitDef("service-monitor", v1beta1.PreDispatch, `
+outputs: monitor: {
+	kind: "ServiceMonitor"
+	spec: selector: matchLabels: context.outputs.service.metadata.labels
+}
+`),
+		traitDef("ingress-labels", v1beta1.DefaultDispatch, `patchOutputs: ingress: metadata: labels: app: "x"`),
+	).Build()
+
+	cases := map[string]struct {
+		traits  []string
+		wantErr string
+	}{
+		"sameStage": {
+			traits: []string{"expose", "service-labels", "not-exist"},
+		},
+		"laterStage": {
+			traits: []string{"service-labels", "gateway"},
+		},
+		"referencedByEarlierStage": {
+			traits: []string{"expose", "service-monitor"},
+			wantErr: "trait service-monitor in stage PreDispatch depends on outputs.service of trait expose in stage DefaultDispatch " +
+				"in component api, set spec.stage of TraitDefinition service-monitor to DefaultDispatch",
+		},
+		"patchedByEarlierStage": {
+			traits: []string{"ingress-labels", "gateway"},
+			wantErr: "trait ingress-labels in stage DefaultDispatch depends on outputs.ingress of trait gateway in stage PostDispatch " +
+				"in component api, set spec.stage of TraitDefinition ingress-labels to PostDispatch",
+		},
+	}
+
+	for caseName, cs := range cases {
+		t.Run(caseName, func(t *testing.T) {
+			comp := apicommon.ApplicationComponent{Name: "api", Type: "webservice"}
+			for _, trait := range cs.traits {
+				comp.Traits = append(comp.Traits, apicommon.ApplicationTrait{Type: trait})
+			}
+			err := ValidateTraitOrdering(context.Background(), cli, comp)
+			if cs.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.EqualError(t, err, cs.wantErr)
+		})
+	}
+}
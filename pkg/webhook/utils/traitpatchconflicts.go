@@ -0,0 +1,216 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"cuelang.org/go/cue/ast"
+	"cuelang.org/go/cue/parser"
+	"github.com/kubevela/workflow/pkg/cue/model/sets"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/common"
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
+	"github.com/oam-dev/kubevela/pkg/cue/definition"
+)
+
+// patchStrategyMerge is the strategy of the fields without a +patchStrategy marker, i.e. the strategic merge
+const patchStrategyMerge = "merge"
+
+var (
+	// rootPatchStrategies are the strategies honoured on the patch field itself
+	rootPatchStrategies = []string{sets.StrategyJSONPatch, sets.StrategyJSONMergePatch}
+	// fieldPatchStrategies are the strategies honoured on the fields under the patch
+	fieldPatchStrategies = []string{sets.StrategyRetainKeys, sets.StrategyReplace}
+)
+
+// TraitPatchConflictOptions configures ValidateTraitPatchConflicts
+type TraitPatchConflictOptions struct {
+	// AsErrors reports the conflicting patches as errors instead of warnings
+	AsErrors bool
+}
+
+// ValidateTraitPatchConflicts loads the TraitDefinitions of the traits attached to the component and reports every
+// two traits patching the same path of the workload with different strategies, e.g. one replaces the containers
+// that the other merges, as the result then depends on the order of the traits. The strategy of a field is set by
+// the +patchStrategy marker on it or on one of its parents, and is the strategic merge otherwise, including the
+// markers not applied by the patch, e.g. +patchStrategy=open or a retainKeys on the patch itself. A path overlaps
+// with the paths under it. The conflicts are returned as warnings since some of them are intentional, or as errors
+// if opts.AsErrors is set.
+// The traits whose definition is not found are skipped, which is reported by ValidateApplicationComponents.
+// The namespace to look up the definitions is taken from ctx.
+func ValidateTraitPatchConflicts(ctx context.Context, cli client.Client, component common.ApplicationComponent, opts TraitPatchConflictOptions) (*ValidationResult, error) {
+	type traitPatch struct {
+		name    string
+		targets map[string]string
+	}
+	var patches []traitPatch
+	for _, trait := range component.Traits {
+		td := &v1beta1.TraitDefinition{}
+		found, err := definitionExists(ctx, cli, td, definitionNameOfType(trait.Type))
+		if err != nil {
+			return nil, err
+		}
+		if found && td.Spec.Schematic != nil && td.Spec.Schematic.CUE != nil {
+			patches = append(patches, traitPatch{name: td.Name, targets: patchTargetsOf(td.Spec.Schematic.CUE.Template)})
+		}
+	}
+	var conflicts []CueValidationError
+	for i := range patches {
+		for j := i + 1; j < len(patches); j++ {
+			for _, msg := range patchConflictsOf(patches[i].name, patches[i].targets, patches[j].name, patches[j].targets) {
+				conflicts = append(conflicts, CueValidationError{Message: fmt.Sprintf("%s in component %s", msg, component.Name)})
+			}
+		}
+	}
+	result := &ValidationResult{}
+	if !opts.AsErrors {
+		result.Warnings = conflicts
+		return result, nil
+	}
+	result.Errors = conflicts
+	return result, result.Err()
+}
+
+// patchConflictsOf returns the messages of the overlapping paths patched by the traits with different strategies,
+// the paths under a reported one are not reported again
+func patchConflictsOf(name string, targets map[string]string, otherName string, otherTargets map[string]string) []string {
+	paths, otherPaths := sortedKeys(targets), sortedKeys(otherTargets)
+	var reported []string
+	var msgs []string
+	for _, path := range paths {
+		for _, otherPath := range otherPaths {
+			if targets[path] == otherTargets[otherPath] {
+				continue
+			}
+			shorter := path
+			if len(otherPath) < len(path) {
+				shorter = otherPath
+			}
+			if !isPathUnder(path, otherPath) && !isPathUnder(otherPath, path) || isUnderAny(shorter, reported) {
+				continue
+			}
+			reported = append(reported, shorter)
+			msgs = append(msgs, fmt.Sprintf("trait %s patches %s with %s but trait %s patches %s with %s",
+				name, path, targets[path], otherName, otherPath, otherTargets[otherPath]))
+		}
+	}
+	return msgs
+}
+
+// patchTargetsOf returns the strategies of the fields patched by the trait template by their paths, which are the
+// fields with a +patchStrategy marker and the leaves. The fields under a jsonPatch are not paths of the workload, and
+// a jsonMergePatch only takes the paths of its leaves since it merges the structs as well.
+func patchTargetsOf(cueTemplate string) map[string]string {
+	targets := map[string]string{}
+	f, err := parser.ParseFile("-", cueTemplate, parser.ParseComments)
+	if err != nil {
+		// the syntax error is reported by the validation
+		return targets
+	}
+	var collect func(field *ast.Field, path, strategy string, strategies []string)
+	collect = func(field *ast.Field, path, strategy string, strategies []string) {
+		marked := patchStrategyOf(field, strategies)
+		if marked != "" {
+			strategy = marked
+		}
+		lits := structLitsOf(field.Value)
+		if marked != "" && marked != sets.StrategyJSONMergePatch || len(lits) == 0 || strategy == sets.StrategyJSONPatch {
+			targets[path] = strategy
+		}
+		if strategy == sets.StrategyJSONPatch {
+			return
+		}
+		for _, field := range fieldsOf(lits) {
+			if name, _, err := ast.LabelName(field.Label); err == nil {
+				collect(field, path+"."+name, strategy, fieldPatchStrategies)
+			}
+		}
+	}
+	for _, decl := range f.Decls {
+		field, ok := decl.(*ast.Field)
+		if !ok {
+			continue
+		}
+		if name, _, err := ast.LabelName(field.Label); err == nil && name == definition.PatchFieldName {
+			collect(field, definition.PatchFieldName, patchStrategyMerge, rootPatchStrategies)
+		}
+	}
+	return targets
+}
+
+// fieldsOf returns the fields of the struct literals, including the ones in the comprehensions, e.g. if clauses
+func fieldsOf(lits []*ast.StructLit) []*ast.Field {
+	var fields []*ast.Field
+	for _, lit := range lits {
+		for _, elt := range lit.Elts {
+			switch x := elt.(type) {
+			case *ast.Field:
+				fields = append(fields, x)
+			case *ast.Comprehension:
+				fields = append(fields, fieldsOf(structLitsOf(x.Value))...)
+			}
+		}
+	}
+	return fields
+}
+
+// patchStrategyOf returns the strategy set by the +patchStrategy marker on the field, or empty if there is none or
+// it's not one of the strategies
+func patchStrategyOf(field *ast.Field, strategies []string) string {
+	marker := "+" + sets.TagPatchStrategy + "="
+	for _, group := range ast.Comments(field) {
+		for _, c := range group.List {
+			text := strings.TrimSpace(strings.TrimPrefix(c.Text, "//"))
+			if !strings.HasPrefix(text, marker) {
+				continue
+			}
+			if strategy := strings.TrimSpace(strings.TrimPrefix(text, marker)); slices.Contains(strategies, strategy) {
+				return strategy
+			}
+		}
+	}
+	return ""
+}
+
+// isPathUnder checks whether the path is the parent path or under it
+func isPathUnder(path, parent string) bool {
+	return path == parent || strings.HasPrefix(path, parent+".")
+}
+
+func isUnderAny(path string, parents []string) bool {
+	for _, parent := range parents {
+		if isPathUnder(path, parent) {
+			return true
+		}
+	}
+	return false
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,153 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"context"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/yaml"
+
+	apicommon "github.com/oam-dev/kubevela/apis/core.oam.dev/common"
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
+	"github.com/oam-dev/kubevela/pkg/oam"
+	"github.com/oam-dev/kubevela/pkg/utils/common"
+)
+
+func TestValidateTraitPatchConflicts(t *testing.T) {
+	cli := fake.NewClientBuilder().WithScheme(common.Scheme).WithObjects(
+		newTraitDefinition("scaler", oam.SystemDefinitionNamespace, `
+parameter: replicas: *1 | int
+patch: spec: replicas: parameter.replicas
+`),
+		newTraitDefinition("hpa", oam.SystemDefinitionNamespace, `
+patch: spec: replicas: 2
+`),
+		newTraitDefinition("sidecar", oam.SystemDefinitionNamespace, `
+parameter: name: string
+patch: spec: template: spec: {
+	// +patchKey=name
+	containers: [{name: parameter.name}]
+}
+`),
+		newTraitDefinition("command", oam.SystemDefinitionNamespace, `
+parameter: cmd: [...string]
+patch: spec: template: spec: {
+	// +patchStrategy=replace
+	containers: [{command: parameter.cmd}]
+}
+`),
+		newTraitDefinition("labels", oam.SystemDefinitionNamespace, `
+patch: {
+	// +patchStrategy=retainKeys
+	spec: template: {
+		metadata: labels: app: "test"
+		if true {
+			spec: hostname: "test"
+		}
+	}
+}
+`),
+		newTraitDefinition("json-patch", oam.SystemDefinitionNamespace, `
+parameter: operations: [...{...}]
+// +patchStrategy=jsonPatch
+patch: operations: parameter.operations
+`),
+	).Build()
+
+	cases := map[string]struct {
+		traits       []string
+		asErrors     bool
+		wantWarnings []string
+		wantErr      string
+	}{
+		"sameStrategy": {
+			traits: []string{"scaler", "hpa", "not-exist"},
+		},
+		"replaceAndMerge": {
+			traits: []string{"sidecar", "command"},
+			wantWarnings: []string{"trait sidecar patches patch.spec.template.spec.containers with merge " +
+				"but trait command patches patch.spec.template.spec.containers with replace in component api"},
+		},
+		"retainKeysParent": {
+			traits: []string{"labels", "sidecar", "command"},
+			wantWarnings: []string{
+				"trait labels patches patch.spec with retainKeys but trait sidecar patches patch.spec.template.spec.containers with merge in component api",
+				"trait labels patches patch.spec with retainKeys but trait command patches patch.spec.template.spec.containers with replace in component api",
+				"trait sidecar patches patch.spec.template.spec.containers with merge but trait command patches patch.spec.template.spec.containers with replace in component api",
+			},
+		},
+		"jsonPatch": {
+			traits: []string{"json-patch", "scaler"},
+			wantWarnings: []string{"trait json-patch patches patch with jsonPatch " +
+				"but trait scaler patches patch.spec.replicas with merge in component api"},
+		},
+		"asErrors": {
+			traits:   []string{"command", "sidecar"},
+			asErrors: true,
+			wantErr: "trait command patches patch.spec.template.spec.containers with replace " +
+				"but trait sidecar patches patch.spec.template.spec.containers with merge in component api",
+		},
+	}
+
+	for caseName, cs := range cases {
+		t.Run(caseName, func(t *testing.T) {
+			comp := apicommon.ApplicationComponent{Name: "api", Type: "webservice"}
+			for _, trait := range cs.traits {
+				comp.Traits = append(comp.Traits, apicommon.ApplicationTrait{Type: trait})
+			}
+			result, err := ValidateTraitPatchConflicts(context.Background(), cli, comp, TraitPatchConflictOptions{AsErrors: cs.asErrors})
+			assert.Equal(t, cs.wantWarnings, result.WarningMessages())
+			if cs.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.EqualError(t, err, cs.wantErr)
+		})
+	}
+}
+
+func TestValidateTraitPatchConflictsOfBuiltinTraits(t *testing.T) {
+	traits := []string{"command", "sidecar", "scaler", "pvc", "storage", "env", "container-image", "resource",
+		"labels", "annotations", "service-account", "k8s-update-strategy", "init-container", "securitycontext"}
+	var objs []client.Object
+	for _, trait := range traits {
+		data, err := os.ReadFile("../../../charts/vela-core/templates/defwithtemplate/" + trait + ".yaml")
+		require.NoError(t, err)
+		td := &v1beta1.TraitDefinition{}
+		require.NoError(t, yaml.Unmarshal([]byte(strings.Replace(string(data),
+			`{{ include "systemDefinitionNamespace" . }}`, oam.SystemDefinitionNamespace, 1)), td))
+		objs = append(objs, td)
+	}
+	cli := fake.NewClientBuilder().WithScheme(common.Scheme).WithObjects(objs...).Build()
+
+	for i := range traits {
+		for j := i + 1; j < len(traits); j++ {
+			comp := apicommon.ApplicationComponent{Name: "api", Type: "webservice",
+				Traits: []apicommon.ApplicationTrait{{Type: traits[i]}, {Type: traits[j]}}}
+			result, err := ValidateTraitPatchConflicts(context.Background(), cli, comp, TraitPatchConflictOptions{})
+			require.NoError(t, err)
+			assert.Empty(t, result.WarningMessages(), "traits %s and %s", traits[i], traits[j])
+		}
+	}
+}
@@ -0,0 +1,184 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"strings"
+
+	"cuelang.org/go/cue/ast"
+	cueErrors "cuelang.org/go/cue/errors"
+	"cuelang.org/go/cue/parser"
+	"cuelang.org/go/cue/token"
+	"github.com/kubevela/workflow/pkg/cue/model"
+)
+
+// declaredParameter is a field declared in the parameter schema
+type declaredParameter struct {
+	path string
+	pos  token.Pos
+}
+
+// ValidateUnusedParameters reports the fields of the parameter that are declared but never referenced in the
+// template, which are dead config of the definition. A field is used if it, one of its subfields or one of its
+// parent structs is referenced, e.g. a reference to parameter.resources uses parameter.resources.cpu, and a
+// reference to the whole parameter uses all of them. Only the outermost unused field is reported.
+// Nothing is reported if the parameter is never referenced at all, which is the schema of the definitions like
+// topology consumed by the controller. The references from outside the template, e.g. the custom status of the
+// definition, are not seen either, so the unused parameters are meant to be reported as warnings.
+func ValidateUnusedParameters(cueTemplate string) error {
+	f, err := parser.ParseFile("-", cueTemplate)
+	if err != nil {
+		// the syntax error is reported by the validation
+		return nil
+	}
+	var errs []error
+	for _, err := range unusedParametersOf(f) {
+		errs = append(errs, err)
+	}
+	return aggregateErrors(errs)
+}
+
+// unusedParametersOf returns the errors of the unused parameters in the parsed template
+func unusedParametersOf(f *ast.File) []cueErrors.Error {
+	var values []ast.Expr
+	for _, decl := range f.Decls {
+		if field, ok := decl.(*ast.Field); ok {
+			if name, _, err := ast.LabelName(field.Label); err == nil && name == model.ParameterFieldName {
+				values = append(values, field.Value)
+			}
+		}
+	}
+	if len(values) == 0 {
+		return nil
+	}
+	declared := declaredParametersOf(values, "")
+
+	used := map[string]bool{}
+	var collect func(node ast.Node) bool
+	collect = func(node ast.Node) bool {
+		switch x := node.(type) {
+		case *ast.Field:
+			// skip the label of the field, which declares rather than references the name
+			if _, ok := x.Label.(*ast.Ident); ok {
+				ast.Walk(x.Value, collect, nil)
+				return false
+			}
+		case *ast.SelectorExpr:
+			root, selectors := selectorChainOf(x)
+			if !isParameterRef(root) {
+				return true
+			}
+			var path []string
+			for _, sel := range selectors {
+				name, _, err := ast.LabelName(sel.Sel)
+				if err != nil {
+					break
+				}
+				path = append(path, name)
+			}
+			used[strings.Join(path, ".")] = true
+			return false
+		case *ast.Ident:
+			if isParameterRef(x) {
+				used[""] = true
+			}
+		}
+		return true
+	}
+	ast.Walk(f, collect, nil)
+	if len(used) == 0 {
+		// the parameter is only a schema consumed outside the template, e.g. by the controller for the policies
+		return nil
+	}
+
+	var errs []cueErrors.Error
+	reported := map[string]bool{}
+	for _, param := range declared {
+		if reported[param.path] || isParameterUsed(param.path, used) || hasReportedParent(param.path, reported) {
+			continue
+		}
+		reported[param.path] = true
+		errs = append(errs, cueErrors.Newf(param.pos, "%s.%s is declared but never referenced",
+			model.ParameterFieldName, param.path))
+	}
+	return errs
+}
+
+// declaredParametersOf collects the fields of the struct literals unified from the values, the parent fields
+// are collected before their subfields
+func declaredParametersOf(values []ast.Expr, prefix string) []declaredParameter {
+	var declared []declaredParameter
+	for len(values) != 0 {
+		value := values[0]
+		values = values[1:]
+		switch v := value.(type) {
+		case *ast.ParenExpr:
+			values = append(values, v.X)
+		case *ast.BinaryExpr:
+			if v.Op == token.AND {
+				values = append(values, v.X, v.Y)
+			}
+		case *ast.StructLit:
+			for _, elt := range v.Elts {
+				field, ok := elt.(*ast.Field)
+				if !ok {
+					continue
+				}
+				name, _, err := ast.LabelName(field.Label)
+				if err != nil {
+					continue
+				}
+				path := name
+				if prefix != "" {
+					path = prefix + "." + name
+				}
+				declared = append(declared, declaredParameter{path: path, pos: field.Pos()})
+				declared = append(declared, declaredParametersOf([]ast.Expr{field.Value}, path)...)
+			}
+		}
+	}
+	return declared
+}
+
+// isParameterRef checks whether the expression refers to the top-level parameter
+func isParameterRef(expr ast.Expr) bool {
+	id, ok := expr.(*ast.Ident)
+	if !ok || id.Name != model.ParameterFieldName {
+		return false
+	}
+	_, topLevel := id.Scope.(*ast.File)
+	return topLevel
+}
+
+// isParameterUsed checks whether the parameter at path, one of its subfields or one of its parents is used
+func isParameterUsed(path string, used map[string]bool) bool {
+	for ref := range used {
+		if ref == "" || ref == path || strings.HasPrefix(ref, path+".") || strings.HasPrefix(path, ref+".") {
+			return true
+		}
+	}
+	return false
+}
+
+func hasReportedParent(path string, reported map[string]bool) bool {
+	for parent := range reported {
+		if strings.HasPrefix(path, parent+".") {
+			return true
+		}
+	}
+	return false
+}
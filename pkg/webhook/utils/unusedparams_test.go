@@ -0,0 +1,147 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateUnusedParameters(t *testing.T) {
+	cases := map[string]struct {
+		template   string
+		wantErrMsg string
+	}{
+		"allUsed": {
+			template: `
+parameter: {
+	image: string
+	port?: int
+	env: [...{name: string, value: string}]
+}
+output: spec: {
+	image: parameter.image
+	if parameter.port != _|_ {
+		port: parameter.port
+	}
+	for e in parameter.env {
+		"\(e.name)": e.value
+	}
+}
+`,
+		},
+		"unused": {
+			template: `
+parameter: {
+	image: string
+	cmd?: [...string]
+}
+output: spec: image: parameter.image
+`,
+			wantErrMsg: "parameter.cmd is declared but never referenced",
+		},
+		"unusedNested": {
+			template: `
+parameter: resources: {
+	cpu:    string
+	memory: string
+}
+output: spec: cpu: parameter.resources.cpu
+`,
+			wantErrMsg: "parameter.resources.memory is declared but never referenced",
+		},
+		"unusedParentReportedOnce": {
+			template: `
+parameter: {
+	image: string
+	resources: {
+		cpu:    string
+		memory: string
+	}
+}
+output: spec: image: parameter.image
+`,
+			wantErrMsg: "parameter.resources is declared but never referenced",
+		},
+		"parentUsed": {
+			template: `
+parameter: resources: {
+	cpu:    string
+	memory: string
+}
+output: spec: resources: parameter.resources
+`,
+		},
+		"wholeParameterUsed": {
+			template: `
+parameter: {
+	image: string
+	port:  int
+}
+output: spec: parameter
+`,
+		},
+		"unifiedDeclarations": {
+			template: `
+parameter: {image: string} & {port: int}
+patch: spec: port: parameter.port
+`,
+			wantErrMsg: "parameter.image is declared but never referenced",
+		},
+		"shadowedParameter": {
+			template: `
+parameter: {
+	image: string
+	port:  int
+}
+output: spec: {
+	port: parameter.port
+	container: {
+		parameter: image: "nginx"
+		image: parameter.image
+	}
+}
+`,
+			wantErrMsg: "parameter.image is declared but never referenced",
+		},
+		"schemaOnly": {
+			template: `
+parameter: {
+	clusters?: [...string]
+	namespace?: string
+}
+`,
+		},
+		"noParameter": {
+			template: `output: spec: name: context.name`,
+		},
+		"syntaxError": {
+			template: `parameter: {`,
+		},
+	}
+	for caseName, cs := range cases {
+		t.Run(caseName, func(t *testing.T) {
+			err := ValidateUnusedParameters(cs.template)
+			if cs.wantErrMsg != "" {
+				assert.EqualError(t, err, cs.wantErrMsg)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
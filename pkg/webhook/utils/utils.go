@@ -19,101 +19,591 @@ package utils
 import (
 	"context"
 	"fmt"
+	"reflect"
 	"regexp"
-	"strconv"
 	"strings"
+	"time"
 
 	"github.com/kubevela/pkg/cue/cuex"
 
+	"cuelang.org/go/cue"
 	"cuelang.org/go/cue/cuecontext"
 	cueErrors "cuelang.org/go/cue/errors"
-	"github.com/pkg/errors"
+	"github.com/Masterminds/semver/v3"
+	"github.com/kubevela/workflow/pkg/cue/model"
+	admissionv1 "k8s.io/api/admission/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
 	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/util/retry"
+	"k8s.io/klog/v2"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
 
 	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
+	velacommon "github.com/oam-dev/kubevela/pkg/controller/common"
 	"github.com/oam-dev/kubevela/pkg/controller/core.oam.dev/v1beta1/core"
+	"github.com/oam-dev/kubevela/pkg/oam"
+	"github.com/oam-dev/kubevela/pkg/oam/util"
 )
 
-// ContextRegex to match '**: reference "context" not found'
-var ContextRegex = `^.+:\sreference\s\"context\"\snot\sfound$`
+// ContextRegex to match '**: reference "context" not found' and the qualified variants like
+// 'reference "context.output.foo" not found', with or without the field path prefix
+var ContextRegex = `^(.+:\s+)?reference\s\"context(\.[^"\s]+)*\"\snot\sfound$`
+
+// contextNotFoundRegex is ContextRegex compiled once, as it's matched against every error of every validation
+var contextNotFoundRegex = regexp.MustCompile(ContextRegex)
+
+// DefinitionRevisionValidationResult records the decisions made when validating the definitionRevision
+type DefinitionRevisionValidationResult struct {
+	// MutationAllowed indicates the immutability check is skipped as the definition has the allow-mutation annotation
+	MutationAllowed bool
+	// Warnings are the messages that should be reported to the user without denying the request
+	Warnings []string
+}
 
 // ValidateDefinitionRevision validate whether definition will modify the immutable object definitionRevision
 func ValidateDefinitionRevision(ctx context.Context, cli client.Client, def runtime.Object, defRevNamespacedName types.NamespacedName) error {
+	_, err := ValidateDefinitionRevisionWithResult(ctx, cli, def, defRevNamespacedName)
+	return err
+}
+
+// ValidateDefinitionRevisionWithResult validate whether definition will modify the immutable object definitionRevision,
+// and return the decisions made during the validation.
+// The returned errors wrap ErrInvalidRevisionName, ErrRevisionHashMismatch or ErrRevisionSpecDrift, which can be
+// inspected by errors.Is.
+// If AllowDefinitionRevisionMutation is set and the definition has the annotation
+// "definitionrevision.oam.dev/allow-mutation: true", the immutability check is skipped.
+// The specs are only compared when the revision hashes match, a different hash is reported with the diff of the specs
+// unless the change is cosmetic. The hash of the definition is computed by the algorithm recorded in the
+// definitionRevision, and the specs are compared directly if the algorithm is unknown.
+func ValidateDefinitionRevisionWithResult(ctx context.Context, cli client.Client, def runtime.Object, defRevNamespacedName types.NamespacedName) (*DefinitionRevisionValidationResult, error) {
+	return ValidateDefinitionRevisionWithOptions(ctx, cli, def, defRevNamespacedName, DefinitionRevisionValidationOptions{})
+}
+
+var (
+	// DefinitionRevisionGetRetries is the max number of retries of getting the definitionRevision on the transient
+	// errors of the API server, i.e. the server timeouts and the throttling. 0 disables the retries.
+	DefinitionRevisionGetRetries = 3
+	// definitionRevisionGetBackoff is the backoff between the retries of getting the definitionRevision
+	definitionRevisionGetBackoff = wait.Backoff{Duration: 50 * time.Millisecond, Factor: 2, Jitter: 0.1}
+)
+
+// ValidateDefinitionRevisionWithOptions validates the definitionRevision as ValidateDefinitionRevisionWithResult does,
+// the changes of the fields ignored by opts are not considered as changes of the definitionRevision. The missing
+// definitionRevision is reported with ErrRevisionNotFound if opts.ReportNotFound is set.
+// Getting the definitionRevision is retried with backoff up to DefinitionRevisionGetRetries times on the transient
+// errors of the API server.
+func ValidateDefinitionRevisionWithOptions(ctx context.Context, cli client.Client, def runtime.Object, defRevNamespacedName types.NamespacedName, opts DefinitionRevisionValidationOptions) (*DefinitionRevisionValidationResult, error) {
+	getRevision := func(key types.NamespacedName) (*v1beta1.DefinitionRevision, error) {
+		defRev := new(v1beta1.DefinitionRevision)
+		if err := getWithRetry(ctx, cli, key, defRev); err != nil {
+			return nil, client.IgnoreNotFound(err)
+		}
+		return defRev, nil
+	}
+	return validateDefinitionRevisionWith(getRevision, def, defRevNamespacedName, opts)
+}
+
+// getWithRetry gets the object, the transient errors of the API server are retried with definitionRevisionGetBackoff
+// until DefinitionRevisionGetRetries is reached or ctx is done
+func getWithRetry(ctx context.Context, cli client.Client, key types.NamespacedName, obj client.Object) error {
+	backoff := definitionRevisionGetBackoff
+	backoff.Steps = 1
+	if DefinitionRevisionGetRetries > 0 {
+		backoff.Steps += DefinitionRevisionGetRetries
+	}
+	retriable := func(err error) bool {
+		return (apierrors.IsServerTimeout(err) || apierrors.IsTooManyRequests(err)) && ctx.Err() == nil
+	}
+	return retry.OnError(backoff, retriable, func() error {
+		return cli.Get(ctx, key, obj)
+	})
+}
+
+// ValidateDefinitionRevisionWithCache validates the definitionRevision as ValidateDefinitionRevision does, with the
+// definitionRevision looked up in revIndex pre-fetched by the caller, e.g. by listing the definitionRevisions once
+// for a batch of definitions, instead of getting it from the cluster. The definitionRevision not in revIndex is
+// considered not created yet.
+func ValidateDefinitionRevisionWithCache(_ context.Context, revIndex map[types.NamespacedName]*v1beta1.DefinitionRevision, def runtime.Object, defRevNamespacedName types.NamespacedName) error {
+	getRevision := func(key types.NamespacedName) (*v1beta1.DefinitionRevision, error) {
+		return revIndex[key], nil
+	}
+	_, err := validateDefinitionRevisionWith(getRevision, def, defRevNamespacedName, DefinitionRevisionValidationOptions{})
+	return err
+}
+
+// validateDefinitionRevisionWith validates the definitionRevision returned by getRevision, which returns nil if the
+// definitionRevision doesn't exist
+func validateDefinitionRevisionWith(getRevision func(types.NamespacedName) (*v1beta1.DefinitionRevision, error), def runtime.Object, defRevNamespacedName types.NamespacedName, opts DefinitionRevisionValidationOptions) (result *DefinitionRevisionValidationResult, err error) {
+	observe := observeValidation(validatorDefinitionRevision, definitionKind(def))
+	defer func() { observe(err) }()
+	result = &DefinitionRevisionValidationResult{}
 	if errs := validation.IsQualifiedName(defRevNamespacedName.Name); len(errs) != 0 {
-		return errors.Errorf("invalid definitionRevision name %s:%s", defRevNamespacedName.Name, strings.Join(errs, ","))
+		msg := strings.Join(errs, ",")
+		if suggestion := SuggestValidName(defRevNamespacedName.Name); suggestion != "" {
+			msg += fmt.Sprintf("; did you mean '%s'?", suggestion)
+		}
+		return result, fmt.Errorf("%w %s:%s", ErrInvalidRevisionName, defRevNamespacedName.Name, msg)
 	}
-	defRev := new(v1beta1.DefinitionRevision)
-	if err := cli.Get(ctx, defRevNamespacedName, defRev); err != nil {
-		return client.IgnoreNotFound(err)
+	defRev, err := getRevision(defRevNamespacedName)
+	if err != nil {
+		return result, err
+	}
+	if defRev == nil {
+		if opts.ReportNotFound {
+			return result, fmt.Errorf("%w: %s", ErrRevisionNotFound, defRevNamespacedName.Name)
+		}
+		return result, nil
+	}
+
+	if isDefinitionRevisionMutationAllowed(def) {
+		msg := fmt.Sprintf("definitionRevision %s is allowed to be modified by annotation %s, the immutability check is skipped",
+			defRevNamespacedName.Name, oam.AnnotationAllowDefinitionRevisionMutation)
+		klog.Warning(msg)
+		result.MutationAllowed = true
+		result.Warnings = append(result.Warnings, msg)
+		return result, nil
 	}
 
 	newRev, _, err := core.GatherRevisionInfo(def)
 	if err != nil {
-		return err
+		return result, err
+	}
+	equal, err := opts.equalIgnoringFields(defRev, newRev)
+	if err != nil {
+		return result, err
 	}
-	if defRev.Spec.RevisionHash != newRev.Spec.RevisionHash {
-		return errors.New("the definition's spec is different with existing definitionRevision's spec")
+	newHash, err := core.ComputeDefinitionRevisionHash(newRev, defRev.Spec.RevisionHashAlgorithm)
+	if err != nil {
+		// the hash algorithm of the definitionRevision is unknown to this version, so the hashes can't be compared
+		klog.InfoS("The revision hash can't be recomputed, compare the specs instead", "definitionRevision",
+			klog.KRef(defRevNamespacedName.Namespace, defRevNamespacedName.Name), "err", err)
+		if equal || isCosmeticRevisionChange(defRev, newRev) {
+			return result, nil
+		}
+		return result, revisionDiffError(ErrRevisionHashMismatch, defRev, newRev)
 	}
-	if !core.DeepEqualDefRevision(defRev, newRev) {
-		return errors.New("the definition's spec is different with existing definitionRevision's spec")
+	if defRev.Spec.RevisionHash == newHash {
+		if equal || core.DeepEqualDefRevision(defRev, newRev) {
+			return result, nil
+		}
+		// the same hash must imply the same spec, otherwise the revision hash is broken
+		klog.ErrorS(ErrRevisionSpecDrift, "The revision hash matches but the spec differs, the revision hash may be broken",
+			"definitionRevision", klog.KRef(defRevNamespacedName.Namespace, defRevNamespacedName.Name), "revisionHash", newHash)
+		return result, revisionDiffError(ErrRevisionSpecDrift, defRev, newRev)
 	}
-	return nil
+	if equal || isCosmeticRevisionChange(defRev, newRev) {
+		// only the ignored fields, or whitespaces, comments or field ordering of the cue template are changed
+		return result, nil
+	}
+	return result, revisionDiffError(ErrRevisionHashMismatch, defRev, newRev)
+}
+
+// IsDefinitionSpecUnchanged reports whether the spec of the definition is the same as the one of its stored version,
+// e.g. the old object of an update request, so that the validation of the unchanged cue template can be skipped when
+// a catalog of definitions is re-applied. The revision hashes computed by GatherRevisionInfo are compared first, and
+// the specs only when the hashes match. Any error gathering the revisions is reported as a change.
+func IsDefinitionSpecUnchanged(def, stored runtime.Object) bool {
+	if stored == nil {
+		return false
+	}
+	newRev, _, err := core.GatherRevisionInfo(def)
+	if err != nil {
+		return false
+	}
+	storedRev, _, err := core.GatherRevisionInfo(stored)
+	if err != nil {
+		return false
+	}
+	return newRev.Spec.RevisionHash == storedRev.Spec.RevisionHash && core.DeepEqualDefRevision(newRev, storedRev)
+}
+
+// IsDefinitionUpdateUnchanged reports whether the update request keeps the spec of the stored definition, whose cue
+// template has been validated when it was applied, see IsDefinitionSpecUnchanged. The old object of the request is
+// decoded into a new object of the type of def, and any error decoding it is reported as a change.
+func IsDefinitionUpdateUnchanged(decoder admission.Decoder, req admission.Request, def client.Object) bool {
+	if req.Operation != admissionv1.Update || len(req.OldObject.Raw) == 0 {
+		return false
+	}
+	stored, ok := reflect.New(reflect.Indirect(reflect.ValueOf(def)).Type()).Interface().(client.Object)
+	if !ok {
+		return false
+	}
+	if err := decoder.DecodeRaw(req.OldObject, stored); err != nil {
+		return false
+	}
+	if !IsDefinitionSpecUnchanged(def, stored) {
+		return false
+	}
+	klog.V(velacommon.LogDebug).InfoS("Skip validating the unchanged cue template", "kind", definitionKind(def),
+		"name", def.GetName(), "namespace", def.GetNamespace())
+	return true
+}
+
+// revisionDiffError wraps the cause with the diff between the existing definitionRevision and the new one
+func revisionDiffError(cause error, old, new *v1beta1.DefinitionRevision) error {
+	diff, err := DiffDefinitionRevision(old, new)
+	if err != nil || diff == "" {
+		return cause
+	}
+	return fmt.Errorf("%w:\n%s", cause, diff)
+}
+
+// CueValidationError is a CUE validation error with the position where it occurs
+type CueValidationError struct {
+	Message  string `json:"message"`
+	Filename string `json:"filename,omitempty"`
+	Line     int    `json:"line,omitempty"`
+	Column   int    `json:"column,omitempty"`
+	// Concrete is true if the error only appears when the output of the template is evaluated concretely
+	Concrete bool `json:"concrete,omitempty"`
+}
+
+// Error implements error interface
+func (e CueValidationError) Error() string {
+	if e.Line == 0 {
+		return e.Message
+	}
+	return fmt.Sprintf("line %d: %s", e.Line, e.Message)
+}
+
+// isDefinitionRevisionMutationAllowed checks whether the definition opts in to modify its existing definitionRevision,
+// which is only honoured if AllowDefinitionRevisionMutation is set
+func isDefinitionRevisionMutationAllowed(def runtime.Object) bool {
+	if !AllowDefinitionRevisionMutation {
+		return false
+	}
+	accessor, err := meta.Accessor(def)
+	if err != nil {
+		return false
+	}
+	return accessor.GetAnnotations()[oam.AnnotationAllowDefinitionRevisionMutation] == "true"
 }
 
 // ValidateCueTemplate validate cueTemplate
 func ValidateCueTemplate(cueTemplate string) error {
+	_, err := ValidateCueTemplateDetailed(cueTemplate)
+	return err
+}
 
-	val := cuecontext.New().CompileString(cueTemplate)
-	if e := checkError(val.Err()); e != nil {
-		return e
+// ValidateCueTemplateDetailed validate cueTemplate and return every non-ignored error with its position.
+// The results are cached by the content of cueTemplate. ErrTemplateTooLarge is returned if the template is larger
+// than CueTemplateMaxBytes, and ErrValidationPanic if the validation panics.
+// Both the inline template and the definition file wrapping it under the template field are accepted, only the
+// template is validated in the latter, and ErrUnrecognizedTemplate is returned for a definition file without a
+// template struct.
+// The plain cue context resolves no import other than the CUE standard library, so the template importing the CueX
+// packages, e.g. vela/kube, is validated with the compiler used at runtime as ValidateCuexTemplateDetailed does,
+// see ValidateCueTemplateDetailedWithContext.
+func ValidateCueTemplateDetailed(cueTemplate string) ([]CueValidationError, error) {
+	return ValidateCueTemplateDetailedWithContext(context.Background(), cueTemplate)
+}
+
+// ValidateCueTemplateDetailedWithContext validates cueTemplate as ValidateCueTemplateDetailed does, the template
+// importing the CueX packages is validated with the namespace and the definition name carried by ctx.
+func ValidateCueTemplateDetailedWithContext(ctx context.Context, cueTemplate string) (errs []CueValidationError, err error) {
+	observe := observeValidation(validatorCueTemplate, definitionKindOf(ctx))
+	defer func() { observe(err) }()
+	defer recoverValidation(validatorCueTemplate, &err)
+	if err := checkTemplateSize(cueTemplate); err != nil {
+		return nil, err
+	}
+	if cueTemplate, err = unwrapCueTemplate(cueTemplate); err != nil {
+		return nil, err
 	}
+	if importsCuexPackages(cueTemplate) {
+		klog.V(velacommon.LogDebug).InfoS("Validate the cue template with the CueX compiler as it imports the CueX packages")
+		return validateCuexTemplate(ctx, cueTemplate)
+	}
+	return cachedValidation(cueTemplate, validateCueTemplate)
+}
 
-	err := val.Validate()
-	return checkError(err)
+func validateCueTemplate(cueTemplate string) ([]CueValidationError, error) {
+	compile := newCueCompileFunc(cuecontext.New())
+	return validateCueTemplateWith(cueTemplate, compile, compile)
 }
 
 // ValidateCuexTemplate validate cueTemplate with CueX for types utilising it
 func ValidateCuexTemplate(ctx context.Context, cueTemplate string) error {
-	val, err := cuex.DefaultCompiler.Get().CompileStringWithOptions(ctx, cueTemplate)
+	_, err := ValidateCuexTemplateDetailed(ctx, cueTemplate)
+	return err
+}
+
+// ValidateCuexTemplateDetailed validate cueTemplate with CueX and return every non-ignored error with its position.
+// The template is checked against the restrictions of the namespace set in ctx before it's compiled, see
+// validateCuexTemplate, and its forms are accepted as ValidateCueTemplateDetailed does.
+func ValidateCuexTemplateDetailed(ctx context.Context, cueTemplate string) (errs []CueValidationError, err error) {
+	observe := observeValidation(validatorCuexTemplate, definitionKindOf(ctx))
+	defer func() { observe(err) }()
+	defer recoverValidation(validatorCuexTemplate, &err)
+	if err := checkTemplateSize(cueTemplate); err != nil {
+		return nil, err
+	}
+	if cueTemplate, err = unwrapCueTemplate(cueTemplate); err != nil {
+		return nil, err
+	}
+	return validateCuexTemplate(ctx, cueTemplate)
+}
+
+// validateCuexTemplate validates the unwrapped cueTemplate with CueX.
+// The imports must be permitted by the allowlist of the namespace set in ctx, see CueImportAllowlistOf, and the
+// functions in the denylist of the namespace must not be called, see CueFunctionDenylistOf. The imports must not
+// form a cycle through the definition named in ctx, and the imported packages older than their minimum in
+// CuePackageMinVersions are rejected with ErrPackageTooOld, see ResolveCuexImports.
+// The template must fit in the complexity budget, and its evaluation is bounded by the deadline of ctx and
+// CueTemplateValidationTimeout, ErrTemplateTooComplex is returned otherwise. context.Canceled is returned if ctx is
+// cancelled before the evaluation finishes.
+// The provider functions called by the template must be registered in the compiler and the calls must declare the
+// credentials fields required by the functions, see RegisterProviderCredentials, but they are never executed.
+// The results are not cached, so they always reflect the current providers and imports of the compiler.
+func validateCuexTemplate(ctx context.Context, cueTemplate string) ([]CueValidationError, error) {
+	namespace := util.GetDefinitionNamespaceWithCtx(ctx)
+	if err := checkImportAllowlist(cueTemplate, CueImportAllowlistOf(namespace)); err != nil {
+		return nil, err
+	}
+	denylist := CueFunctionDenylistOf(namespace)
+	if err := checkImportedFunctionDenylist(cueTemplate, denylist); err != nil {
+		return nil, err
+	}
+	compiler := cuex.DefaultCompiler.Get()
+	if err := checkImportCycles(definitionNameOf(ctx), cueTemplate, compiler.GetImports()); err != nil {
+		return nil, err
+	}
+	if len(CuePackageMinVersions) != 0 {
+		packages, _ := resolveCuexImports(cueTemplate, compiler)
+		if err := aggregateErrors(checkCuePackageMinVersions(packages)); err != nil {
+			return nil, err
+		}
+	}
+	if err := checkTemplateComplexity(cueTemplate); err != nil {
+		return nil, err
+	}
+	return validateWithTimeout(ctx, func(ctx context.Context) ([]CueValidationError, error) {
+		return validateCueTemplateWith(cueTemplate,
+			func(src string) (cue.Value, error) {
+				val, err := compiler.CompileStringWithOptions(ctx, src, cuex.DisableResolveProviderFunctions{})
+				if err != nil {
+					return val, err
+				}
+				if err := checkProviderFunctionDenylist(val, denylist); err != nil {
+					return val, err
+				}
+				if err := checkProviderFunctions(val, compiler.GetProviders()); err != nil {
+					return val, err
+				}
+				if err := checkProviderCredentials(val); err != nil {
+					return val, err
+				}
+				return val, nil
+			},
+			func(src string) (cue.Value, error) {
+				return compiler.CompileStringWithOptions(ctx, src, cuex.DisableResolveProviderFunctions{})
+			})
+	})
+}
+
+// ValidateCueTemplateWithContext validate cueTemplate with the context filled by stub, e.g. a value with the name,
+// namespace, appName, output and outputs fields. Unlike ValidateCueTemplate, the errors caused by the context are
+// not ignored, so the type errors of the expressions using the context fields are reported as well.
+// The template is compiled in the cue context of stub, a new one is used if stub does not exist.
+func ValidateCueTemplateWithContext(cueTemplate string, stub cue.Value) error {
+	cueCtx := cuecontext.New()
+	if stub.Exists() {
+		cueCtx = stub.Context()
+	}
+	val := cueCtx.CompileString(cueTemplate + contextStub)
+	if val.Err() != nil {
+		return aggregateErrors(cueErrorsOf(val.Err()))
+	}
+	if stub.Exists() {
+		val = val.FillPath(cue.ParsePath(model.ContextFieldName), stub)
+	}
+	if err := val.Validate(); err != nil {
+		return aggregateErrors(cueErrorsOf(err))
+	}
+	_, err := validateParameterDefaultsOf(val)
+	return err
+}
+
+// cueErrorsOf splits the cue error into the errors it contains
+func cueErrorsOf(err error) []error {
+	var errs []error
+	for _, e := range cueErrors.Errors(err) {
+		errs = append(errs, cueErrors.New(e.Error()))
+	}
+	return errs
+}
+
+// cueCompileFunc compiles the cue source into cue.Value
+type cueCompileFunc func(src string) (cue.Value, error)
+
+// newCueCompileFunc returns the cueCompileFunc compiling cue source without imports in the given cue context
+func newCueCompileFunc(cueCtx *cue.Context) cueCompileFunc {
+	return func(src string) (cue.Value, error) {
+		return cueCtx.CompileString(src), nil
+	}
+}
+
+// validateCueTemplateWith validates the cueTemplate compiled by compile, the follow-up checks on the template
+// evaluated with the context stub use compileForCheck, which is not expected to have side effects.
+func validateCueTemplateWith(cueTemplate string, compile, compileForCheck cueCompileFunc) ([]CueValidationError, error) {
+	return validateTemplateWith(cueTemplate, compile, compileForCheck, true)
+}
+
+// validateTemplateWith is validateCueTemplateWith, the keys of the outputs are only checked if the outputs are
+// rendered to resources, which is not the case of the workflow steps
+func validateTemplateWith(cueTemplate string, compile, compileForCheck cueCompileFunc, resourceOutputs bool) ([]CueValidationError, error) {
+	val, errs, err := compileAndValidateWith(cueTemplate, compile, compileForCheck)
+	if err != nil {
+		return errs, err
+	}
+	if errs, err := validateParameterDefaultsOf(val); err != nil {
+		return errs, err
+	}
+	if resourceOutputs {
+		if errs, err := validateOutputKeysOf(val); err != nil {
+			return errs, err
+		}
+	}
+	return validateConcreteOutputsOf(val)
+}
+
+// compileAndValidate compiles the cueTemplate and runs the base validation of ValidateCueTemplate on it, i.e. the
+// cue errors except the context not found ones and the cycles of the parameter defaults. The template evaluated with
+// the context stub is returned, so that the follow-up checks share the value instead of compiling the template again.
+// The validation stops once ctx is done.
+func compileAndValidate(ctx context.Context, cueTemplate string) (cue.Value, error) {
+	if err := ctx.Err(); err != nil {
+		return cue.Value{}, err
+	}
+	compile := newCueCompileFunc(cuecontext.New())
+	val, _, err := compileAndValidateWith(cueTemplate, compile, compile)
+	return val, err
+}
+
+// compileAndValidateWith is compileAndValidate with the given compile functions, see validateCueTemplateWith. The
+// errors are returned with their positions as well.
+func compileAndValidateWith(cueTemplate string, compile, compileForCheck cueCompileFunc) (cue.Value, []CueValidationError, error) {
+	val, err := compile(cueTemplate)
 	if err != nil {
-		return err
+		return cue.Value{}, nil, err
 	}
 	if e := checkError(val.Err()); e != nil {
-		return e
+		return cue.Value{}, collectCueValidationErrors(val.Err()), e
 	}
 	err = val.Validate()
-	return checkError(err)
+	if e := checkError(err); e != nil {
+		return cue.Value{}, collectCueValidationErrors(err), e
+	}
+	if errs, err := validateParameterDefaultCycles(cueTemplate); err != nil {
+		return cue.Value{}, errs, err
+	}
+	val, err = compileForCheck(cueTemplate + contextStub)
+	if err != nil {
+		return cue.Value{}, nil, err
+	}
+	return val, nil, nil
 }
 
+// checkError collects all the cue errors except the context not found ones, so that a single validation
+// reports every problem of the template. A single error is returned as is, multiple ones are aggregated.
+// The ignored errors are logged at the debug level.
 func checkError(err error) error {
-	re := regexp.MustCompile(ContextRegex)
-	if err != nil {
-		// ignore context not found error
-		for _, e := range cueErrors.Errors(err) {
-			if !re.MatchString(e.Error()) {
-				return cueErrors.New(e.Error())
+	err, suppressed := CheckErrorVerbose(err)
+	if len(suppressed) != 0 {
+		klog.V(velacommon.LogDebug).InfoS("Ignored the context not found errors of the cue template", "errors", suppressed)
+	}
+	return err
+}
+
+// CheckErrorVerbose is the same as checkError, and returns the ignored context not found errors as well
+func CheckErrorVerbose(err error) (error, []error) { // nolint:revive
+	if err == nil {
+		return nil, nil
+	}
+	var errs, suppressed []error
+	for _, e := range cueErrors.Errors(err) {
+		if contextNotFoundRegex.MatchString(e.Error()) {
+			suppressed = append(suppressed, cueErrors.New(e.Error()))
+			continue
+		}
+		errs = append(errs, cueErrors.New(e.Error()))
+	}
+	return aggregateErrors(errs), suppressed
+}
+
+// aggregateErrors returns nil for no error, the error itself for a single one and an aggregate otherwise,
+// the nested aggregates are flattened
+func aggregateErrors(errs []error) error {
+	switch len(errs) {
+	case 0:
+		return nil
+	case 1:
+		return errs[0]
+	default:
+		return utilerrors.Flatten(utilerrors.NewAggregate(errs))
+	}
+}
+
+// validateParameterDefaultsOf checks the parameter defaults of the template evaluated with the context stub
+func validateParameterDefaultsOf(val cue.Value) ([]CueValidationError, error) {
+	if val.Err() != nil {
+		// the template has been validated, the error only comes from the context stub
+		return nil, nil
+	}
+	if errs := checkParameterDefaults(val); len(errs) != 0 {
+		return errs, cueErrors.New(errs[0].Message)
+	}
+	return nil, nil
+}
+
+// collectCueValidationErrors converts the non-ignored cue errors to CueValidationError,
+// the position is taken from the first valid position the error carries.
+func collectCueValidationErrors(err error) []CueValidationError {
+	if err == nil {
+		return nil
+	}
+	var errs []CueValidationError
+	for _, e := range cueErrors.Errors(err) {
+		if contextNotFoundRegex.MatchString(e.Error()) {
+			continue
+		}
+		ve := CueValidationError{Message: e.Error()}
+		for _, pos := range cueErrors.Positions(e) {
+			if pos.IsValid() {
+				ve.Filename = pos.Filename()
+				ve.Line = pos.Line()
+				ve.Column = pos.Column()
+				break
 			}
 		}
+		errs = append(errs, ve)
 	}
-	return nil
+	return errs
 }
 
-// ValidateSemanticVersion validates if a Definition's version includes all of
-// major,minor & patch version values.
+// ValidateSemanticVersion validates if a Definition's version is a valid SemVer 2.0.0 version,
+// which includes all of major,minor & patch version values and optional prerelease & build metadata.
+// The numeric identifiers must not have leading zeros or be negative, e.g. 1.01.0 and -1.2.0 are rejected.
 func ValidateSemanticVersion(version string) error {
 	if version != "" {
-		versionParts := strings.Split(version, ".")
-		if len(versionParts) != 3 {
-			return errors.New("Not a valid version")
+		if _, err := semver.StrictNewVersion(version); err != nil {
+			return ErrInvalidVersion
 		}
+	}
+	return nil
+}
 
-		for _, versionPart := range versionParts {
-			if _, err := strconv.Atoi(versionPart); err != nil {
-				return errors.New("Not a valid version")
-			}
+// ValidateSemanticVersionConstraint validates if a version range expression like ">=1.2.0 <2.0.0" is valid.
+func ValidateSemanticVersionConstraint(constraint string) error {
+	if constraint != "" {
+		if _, err := semver.NewConstraint(constraint); err != nil {
+			return fmt.Errorf("%w %q: %v", ErrInvalidVersionConstraint, constraint, err)
 		}
 	}
 	return nil
@@ -122,7 +612,55 @@ func ValidateSemanticVersion(version string) error {
 // ValidateMultipleDefVersionsNotPresent validates that both Name Annotation Revision and Spec.Version are not present
 func ValidateMultipleDefVersionsNotPresent(version, revisionName, objectType string) error {
 	if version != "" && revisionName != "" {
-		return fmt.Errorf("%s has both spec.version and revision name annotation. Only one can be present", objectType)
+		return withSentinel(fmt.Errorf("%s has both spec.version and revision name annotation. Only one can be present", objectType), ErrVersionConflict)
 	}
 	return nil
 }
+
+// revisionNameAnnotationRegex matches the revision name annotation like v3, 1.2.0 or my-def-v3, the optional prefix
+// is a DNS-1123 label followed by '-'
+var revisionNameAnnotationRegex = regexp.MustCompile(`^([a-z0-9]([-a-z0-9]*[a-z0-9])?-)?v?[0-9]+(\.[0-9]+)*$`)
+
+// ValidateRevisionNameAnnotation validates that the revision name annotation, if present, is a version number with an
+// optional 'v' and name prefix, e.g. v3 or my-def-v3, so that a malformed annotation doesn't produce an orphan
+// definitionRevision
+func ValidateRevisionNameAnnotation(revisionName string) error {
+	if revisionName == "" || revisionNameAnnotationRegex.MatchString(revisionName) {
+		return nil
+	}
+	return fmt.Errorf("%w %q of annotation %s: must be v<number> or <name>-v<number>, e.g. v3",
+		ErrInvalidRevisionNameAnnotation, revisionName, oam.AnnotationDefinitionRevisionName)
+}
+
+// ValidateVersionConsistency validates that the version derived from the annotation and spec.version are the same
+// when both of them are provided. Versions are compared semantically if both of them are valid SemVer.
+func ValidateVersionConsistency(annotationVersion, specVersion string) error {
+	if annotationVersion == "" || specVersion == "" || annotationVersion == specVersion {
+		return nil
+	}
+	annotationSemver, err := semver.NewVersion(annotationVersion)
+	if err == nil {
+		if specSemver, err := semver.NewVersion(specVersion); err == nil && annotationSemver.Equal(specSemver) {
+			return nil
+		}
+	}
+	return withSentinel(fmt.Errorf("annotation version %s conflicts with spec.version %s", annotationVersion, specVersion), ErrVersionConflict)
+}
+
+// invalidNameCharsRegex matches the runs of characters that are not allowed in a lowercase qualified name
+var invalidNameCharsRegex = regexp.MustCompile(`[^a-z0-9.]+`)
+
+// SuggestValidName sanitizes the name into a valid one by lowercasing, replacing the invalid characters with '-'
+// and trimming the leading and trailing non-alphanumeric characters, e.g. My_Def to my-def.
+// It returns empty if nothing valid is left.
+func SuggestValidName(name string) string {
+	suggestion := invalidNameCharsRegex.ReplaceAllString(strings.ToLower(name), "-")
+	if len(suggestion) > validation.DNS1123LabelMaxLength {
+		suggestion = suggestion[:validation.DNS1123LabelMaxLength]
+	}
+	suggestion = strings.Trim(suggestion, "-.")
+	if len(validation.IsQualifiedName(suggestion)) != 0 {
+		return ""
+	}
+	return suggestion
+}
@@ -19,16 +19,24 @@ package utils
 import (
 	"context"
 	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"strings"
+	"sync/atomic"
 	"testing"
 
 	"github.com/kubevela/pkg/cue/cuex"
 	"github.com/kubevela/pkg/util/singleton"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
 	dynamicfake "k8s.io/client-go/dynamic/fake"
 
+	"cuelang.org/go/cue"
+	"cuelang.org/go/cue/cuecontext"
 	"cuelang.org/go/cue/errors"
+	"cuelang.org/go/cue/token"
 	"github.com/crossplane/crossplane-runtime/pkg/test"
 	"github.com/google/go-cmp/cmp"
 	"github.com/stretchr/testify/assert"
@@ -54,6 +62,20 @@ func TestValidateCueTemplate(t *testing.T) {
 				}`,
 			want: nil,
 		},
+		"contextSubfieldsCueTemp": {
+			cueTemplate: `
+				output: {
+					metadata: {
+						name: context.name
+						namespace: context.namespace
+					}
+					spec: {
+						foo: context.output.foo
+						bar: context.output.spec.bar
+					}
+				}`,
+			want: nil,
+		},
 		"inValidCueTemp": {
 			cueTemplate: `
 				output: {
@@ -66,6 +88,34 @@ func TestValidateCueTemplate(t *testing.T) {
 				}`,
 			want: errors.New("output.hello: reference \"world\" not found"),
 		},
+		"invalidParameterDefaultCueTemp": {
+			cueTemplate: `
+				parameter: replicas: *1.5 | int
+				output: {
+					metadata: name: context.name
+					spec: replicas: parameter.replicas
+				}`,
+			want: errors.New("parameter.replicas default '1.5' is not an int"),
+		},
+		"multipleErrorsCueTemp": {
+			cueTemplate: `
+				output: {
+					metadata: name: context.name
+					hello: world
+					foo: bar
+				}`,
+			want: utilerrors.NewAggregate([]error{
+				errors.New("output.hello: reference \"world\" not found"),
+				errors.New("output.foo: reference \"bar\" not found"),
+			}),
+		},
+		"contextLikeReferenceCueTemp": {
+			cueTemplate: `
+				output: {
+					name: contextual.name 
+				}`,
+			want: errors.New("output.name: reference \"contextual\" not found"),
+		},
 	}
 
 	for caseName, cs := range cases {
@@ -78,6 +128,301 @@ func TestValidateCueTemplate(t *testing.T) {
 	}
 }
 
+func TestValidateCueTemplateWithImports(t *testing.T) {
+	defer setFakeCuexCompiler()()
+
+	cases := map[string]struct {
+		cueTemplate string
+		wantErr     string
+	}{
+		"stdlibImports": {
+			cueTemplate: `
+import "strings"
+
+output: name: strings.ToLower("Name")`,
+		},
+		"cuexImports": {
+			cueTemplate: `
+import "vela/base64"
+
+token: base64.#Encode & {$params: "token"}
+output: {apiVersion: "v1", kind: "Secret"}`,
+		},
+		"cuexImportsWithError": {
+			cueTemplate: `
+import "vela/base64"
+
+token: base64.#Encode & {$params: 1}
+output: {apiVersion: "v1", kind: "Secret"}`,
+			wantErr: "token.$params: conflicting values string and 1",
+		},
+	}
+
+	for caseName, cs := range cases {
+		t.Run(caseName, func(t *testing.T) {
+			err := ValidateCueTemplate(cs.cueTemplate)
+			if cs.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorContains(t, err, cs.wantErr)
+		})
+	}
+}
+
+func TestCompileAndValidate(t *testing.T) {
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	cases := map[string]struct {
+		ctx          context.Context
+		cueTemplate  string
+		wantReplicas int64
+		wantErr      string
+	}{
+		"valid": {
+			ctx: context.Background(),
+			cueTemplate: `
+parameter: replicas: *2 | int
+output: metadata: name: context.name`,
+			wantReplicas: 2,
+		},
+		"conflict": {
+			ctx:         context.Background(),
+			cueTemplate: `parameter: replicas: 1 & 2`,
+			wantErr:     "parameter.replicas: conflicting values 2 and 1",
+		},
+		"cancelled": {
+			ctx:         cancelled,
+			cueTemplate: `parameter: replicas: *2 | int`,
+			wantErr:     context.Canceled.Error(),
+		},
+	}
+
+	for caseName, cs := range cases {
+		t.Run(caseName, func(t *testing.T) {
+			val, err := compileAndValidate(cs.ctx, cs.cueTemplate)
+			if cs.wantErr != "" {
+				assert.ErrorContains(t, err, cs.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+			replicas, ok := val.LookupPath(cue.ParsePath("parameter.replicas")).Default()
+			assert.True(t, ok)
+			got, _ := replicas.Int64()
+			assert.Equal(t, cs.wantReplicas, got)
+		})
+	}
+}
+
+func TestCheckError(t *testing.T) {
+	cases := map[string]struct {
+		err  error
+		want error
+	}{
+		"nilError": {
+			err:  nil,
+			want: nil,
+		},
+		"contextNotFound": {
+			err:  errors.New("output.name: reference \"context\" not found"),
+			want: nil,
+		},
+		"contextNameNotFound": {
+			err:  errors.New("output.name: reference \"context.name\" not found"),
+			want: nil,
+		},
+		"contextNamespaceNotFound": {
+			err:  errors.New("output.metadata.namespace: reference \"context.namespace\" not found"),
+			want: nil,
+		},
+		"nestedContextOutputNotFound": {
+			err:  errors.New("output.spec.foo: reference \"context.output.foo\" not found"),
+			want: nil,
+		},
+		"contextNotFoundWithoutPath": {
+			err:  errors.New("reference \"context.appName\" not found"),
+			want: nil,
+		},
+		"otherReferenceNotFound": {
+			err:  errors.New("output.hello: reference \"world\" not found"),
+			want: errors.New("output.hello: reference \"world\" not found"),
+		},
+		"contextPrefixedReferenceNotFound": {
+			err:  errors.New("output.hello: reference \"contextual\" not found"),
+			want: errors.New("output.hello: reference \"contextual\" not found"),
+		},
+		"multipleErrors": {
+			err: errors.Append(errors.Append(
+				errors.Newf(token.NoPos, "output.hello: reference \"world\" not found"),
+				errors.Newf(token.NoPos, "output.name: reference \"context.name\" not found")),
+				errors.Newf(token.NoPos, "output.foo: reference \"bar\" not found")),
+			want: utilerrors.NewAggregate([]error{
+				errors.New("output.hello: reference \"world\" not found"),
+				errors.New("output.foo: reference \"bar\" not found"),
+			}),
+		},
+	}
+
+	for caseName, cs := range cases {
+		t.Run(caseName, func(t *testing.T) {
+			err := checkError(cs.err)
+			if diff := cmp.Diff(cs.want, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\ncheckError: -want , +got \n%s\n", cs.want, diff)
+			}
+		})
+	}
+}
+
+func TestCheckErrorVerbose(t *testing.T) {
+	err, suppressed := CheckErrorVerbose(errors.Append(errors.Append(
+		errors.Newf(token.NoPos, "output.hello: reference \"world\" not found"),
+		errors.Newf(token.NoPos, "output.name: reference \"context.name\" not found")),
+		errors.Newf(token.NoPos, "reference \"context\" not found")))
+	assert.EqualError(t, err, "output.hello: reference \"world\" not found")
+	assert.Equal(t, []error{
+		errors.New("output.name: reference \"context.name\" not found"),
+		errors.New("reference \"context\" not found"),
+	}, suppressed)
+
+	err, suppressed = CheckErrorVerbose(errors.New("reference \"context\" not found"))
+	assert.NoError(t, err)
+	assert.Len(t, suppressed, 1)
+
+	err, suppressed = CheckErrorVerbose(nil)
+	assert.NoError(t, err)
+	assert.Nil(t, suppressed)
+}
+
+func BenchmarkCheckError(b *testing.B) {
+	err := errors.Append(
+		errors.Newf(token.NoPos, "output.hello: reference \"world\" not found"),
+		errors.Newf(token.NoPos, "output.name: reference \"context.name\" not found"))
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		_ = checkError(err)
+	}
+}
+
+func TestValidateCueTemplateDetailed(t *testing.T) {
+	cases := map[string]struct {
+		cueTemplate string
+		want        []CueValidationError
+		wantErr     error
+	}{
+		"normalCueTemp": {
+			cueTemplate: "name: 'name'",
+		},
+		"contextNotFoundCueTemp": {
+			cueTemplate: `
+output: {
+	name: context.name
+}`,
+		},
+		"inValidCueTemp": {
+			cueTemplate: `
+output: {
+	name: context.name
+	hello: world
+}`,
+			want: []CueValidationError{{
+				Message: "output.hello: reference \"world\" not found",
+				Line:    4,
+				Column:  9,
+			}},
+			wantErr: errors.New("output.hello: reference \"world\" not found"),
+		},
+		"conflictingValues": {
+			cueTemplate: `
+a: 1
+a: 2`,
+			want: []CueValidationError{{
+				Message: "a: conflicting values 2 and 1",
+				Line:    2,
+				Column:  4,
+			}},
+			wantErr: errors.New("a: conflicting values 2 and 1"),
+		},
+	}
+
+	for caseName, cs := range cases {
+		t.Run(caseName, func(t *testing.T) {
+			got, err := ValidateCueTemplateDetailed(cs.cueTemplate)
+			if diff := cmp.Diff(cs.wantErr, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nValidateCueTemplateDetailed: -want , +got \n%s\n", cs.wantErr, diff)
+			}
+			assert.Equal(t, cs.want, got)
+		})
+	}
+}
+
+func TestValidateCueTemplateWithContext(t *testing.T) {
+	stub := cuecontext.New().CompileString(`
+name:      "test"
+namespace: "default"
+appName:   "app"
+output: {
+	apiVersion: "apps/v1"
+	kind:       "Deployment"
+	spec: replicas: 1
+}
+outputs: {}`)
+
+	cases := map[string]struct {
+		cueTemplate string
+		stub        cue.Value
+		wantErr     error
+	}{
+		"validContextUsage": {
+			cueTemplate: `
+output: {
+	metadata: name: context.name + "-" + context.appName
+	spec: replicas: context.output.spec.replicas + 1
+}`,
+			stub: stub,
+		},
+		"typeErrorMaskedByContext": {
+			cueTemplate: `
+output: {
+	metadata: name: context.name + 1
+}`,
+			stub:    stub,
+			wantErr: errors.New("output.metadata.name: invalid operands \"test\" and 1 to '+' (type string and int)"),
+		},
+		"conflictWithContext": {
+			cueTemplate: `
+output: {
+	spec: replicas: context.namespace & int
+}`,
+			stub:    stub,
+			wantErr: errors.New("output.spec.replicas: conflicting values \"default\" and int (mismatched types string and int)"),
+		},
+		"noStub": {
+			cueTemplate: `
+output: {
+	metadata: name: context.name
+}`,
+		},
+		"invalidCueTemp": {
+			cueTemplate: `
+output: {
+	hello: world
+}`,
+			stub:    stub,
+			wantErr: errors.New("output.hello: reference \"world\" not found"),
+		},
+	}
+
+	for caseName, cs := range cases {
+		t.Run(caseName, func(t *testing.T) {
+			err := ValidateCueTemplateWithContext(cs.cueTemplate, cs.stub)
+			if diff := cmp.Diff(cs.wantErr, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nValidateCueTemplateWithContext: -want , +got \n%s\n", cs.wantErr, diff)
+			}
+		})
+	}
+}
+
 func TestValidateCuexTemplate(t *testing.T) {
 	cases := map[string]struct {
 		cueTemplate string
@@ -172,6 +517,43 @@ func TestValidateCuexTemplate(t *testing.T) {
 	}
 }
 
+func TestValidateCuexTemplateNotExecutingProviders(t *testing.T) {
+	defer setFakeCuexCompiler()()
+	var requests atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+	}))
+	defer srv.Close()
+
+	cueTemplate := fmt.Sprintf(`
+import "vela/http"
+
+req: http.#Do & {
+	$params: {
+		method: "GET"
+		url:    %q
+	}
+}`, srv.URL)
+	assert.NoError(t, ValidateCuexTemplate(context.Background(), cueTemplate))
+	_, err := ValidateCueTemplateDetailed(cueTemplate)
+	assert.NoError(t, err)
+	assert.Equal(t, int32(0), requests.Load())
+}
+
+// setFakeCuexCompiler reloads the cuex default compiler with a fake dynamic client holding the given
+// objects, so that it works without a cluster. The returned func restores the default compiler.
+func setFakeCuexCompiler(objects ...runtime.Object) func() {
+	packagesGVR := schema.GroupVersionResource{Group: "cue.oam.dev", Version: "v1alpha1", Resource: "packages"}
+	dcl := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+		map[schema.GroupVersionResource]string{packagesGVR: "PackageList"}, objects...)
+	singleton.DynamicClient.Set(dcl)
+	cuex.DefaultCompiler.Reload()
+	return func() {
+		singleton.ReloadClients()
+		cuex.DefaultCompiler.Reload()
+	}
+}
+
 func TestValidateSemanticVersion(t *testing.T) {
 	cases := map[string]struct {
 		version string
@@ -181,14 +563,62 @@ func TestValidateSemanticVersion(t *testing.T) {
 			version: "1.2.3",
 			want:    nil,
 		},
-		"versionWithAlphabets": {
-			version: "1.2.3-alpha",
+		"prereleaseVersion": {
+			version: "1.2.0-rc.1",
+			want:    nil,
+		},
+		"buildMetadataVersion": {
+			version: "1.2.0+build.5",
+			want:    nil,
+		},
+		"prereleaseAndBuildMetadataVersion": {
+			version: "1.2.3-alpha.1+build.5",
+			want:    nil,
+		},
+		"invalidPrerelease": {
+			version: "1.2.3-alpha_1",
 			want:    errors.New("Not a valid version"),
 		},
 		"invalidVersion": {
 			version: "1.2",
 			want:    errors.New("Not a valid version"),
 		},
+		"versionWithPrefix": {
+			version: "v1.2.3",
+			want:    errors.New("Not a valid version"),
+		},
+		"versionWithWildcard": {
+			version: "v1.x",
+			want:    errors.New("Not a valid version"),
+		},
+		"leadingZeroMajor": {
+			version: "01.02.03",
+			want:    errors.New("Not a valid version"),
+		},
+		"leadingZeroMinor": {
+			version: "1.01.0",
+			want:    errors.New("Not a valid version"),
+		},
+		"leadingZeroPatch": {
+			version: "1.2.03",
+			want:    errors.New("Not a valid version"),
+		},
+		"leadingZeroPrerelease": {
+			version: "1.2.3-rc.01",
+			want:    errors.New("Not a valid version"),
+		},
+		"zeroVersion": {
+			version: "0.0.0",
+			want:    nil,
+		},
+		"negativeMajor": {
+			version: "-1.2.0",
+			want:    errors.New("Not a valid version"),
+		},
+		"negativeMinor": {
+			version: "1.-2.0",
+			want:    errors.New("Not a valid version"),
+		},
 	}
 	for caseName, cs := range cases {
 		t.Run(caseName, func(t *testing.T) {
@@ -203,6 +633,44 @@ func TestValidateSemanticVersion(t *testing.T) {
 	}
 }
 
+func TestValidateSemanticVersionConstraint(t *testing.T) {
+	cases := map[string]struct {
+		constraint string
+		wantErr    bool
+	}{
+		"emptyConstraint": {
+			constraint: "",
+		},
+		"rangeConstraint": {
+			constraint: ">=1.2.0 <2.0.0",
+		},
+		"tildeConstraint": {
+			constraint: "~1.2",
+		},
+		"orConstraint": {
+			constraint: "^1.2.0 || >=3.0.0-rc.1",
+		},
+		"invalidConstraint": {
+			constraint: ">=1.2.0 <<2.0.0",
+			wantErr:    true,
+		},
+		"garbageConstraint": {
+			constraint: "abc",
+			wantErr:    true,
+		},
+	}
+	for caseName, cs := range cases {
+		t.Run(caseName, func(t *testing.T) {
+			err := ValidateSemanticVersionConstraint(cs.constraint)
+			if cs.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
 func TestValidateMultipleDefVersionsNotPresent(t *testing.T) {
 	cases := map[string]struct {
 		version      string
@@ -237,3 +705,108 @@ func TestValidateMultipleDefVersionsNotPresent(t *testing.T) {
 		})
 	}
 }
+
+func TestValidateRevisionNameAnnotation(t *testing.T) {
+	cases := map[string]struct {
+		revisionName string
+		wantErr      bool
+	}{
+		"absent":        {revisionName: ""},
+		"number":        {revisionName: "3"},
+		"vNumber":       {revisionName: "v3"},
+		"dottedVersion": {revisionName: "1.0.0"},
+		"vDotted":       {revisionName: "v1.0.0"},
+		"namePrefixed":  {revisionName: "my-def-v3"},
+		"noNumber":      {revisionName: "test", wantErr: true},
+		"uppercase":     {revisionName: "V3", wantErr: true},
+		"trailingDash":  {revisionName: "v3-", wantErr: true},
+		"emptyPrefix":   {revisionName: "-v3", wantErr: true},
+		"prerelease":    {revisionName: "v1.0.0-alpha", wantErr: true},
+	}
+	for caseName, cs := range cases {
+		t.Run(caseName, func(t *testing.T) {
+			err := ValidateRevisionNameAnnotation(cs.revisionName)
+			if cs.wantErr {
+				assert.ErrorIs(t, err, ErrInvalidRevisionNameAnnotation)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestValidateVersionConsistency(t *testing.T) {
+	cases := map[string]struct {
+		annotationVersion string
+		specVersion       string
+		want              string
+	}{
+		"onlySpecVersion": {
+			specVersion: "1.2.0",
+		},
+		"onlyAnnotationVersion": {
+			annotationVersion: "1.2.0",
+		},
+		"sameVersion": {
+			annotationVersion: "1.2.0",
+			specVersion:       "1.2.0",
+		},
+		"semanticallySameVersion": {
+			annotationVersion: "v1.2.0",
+			specVersion:       "1.2.0",
+		},
+		"conflictingVersion": {
+			annotationVersion: "1.2.0",
+			specVersion:       "1.3.0",
+			want:              "annotation version 1.2.0 conflicts with spec.version 1.3.0",
+		},
+		"conflictingInvalidVersion": {
+			annotationVersion: "latest",
+			specVersion:       "1.3.0",
+			want:              "annotation version latest conflicts with spec.version 1.3.0",
+		},
+	}
+	for caseName, cs := range cases {
+		t.Run(caseName, func(t *testing.T) {
+			err := ValidateVersionConsistency(cs.annotationVersion, cs.specVersion)
+			if cs.want == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.EqualError(t, err, cs.want)
+		})
+	}
+}
+
+func TestSuggestValidName(t *testing.T) {
+	cases := map[string]struct {
+		name string
+		want string
+	}{
+		"alreadyValid": {
+			name: "scaler-v1",
+			want: "scaler-v1",
+		},
+		"upperCaseAndUnderscore": {
+			name: "My_Def",
+			want: "my-def",
+		},
+		"invalidCharsRun": {
+			name: "_scaler @ v1.0_",
+			want: "scaler-v1.0",
+		},
+		"tooLong": {
+			name: strings.Repeat("a", 70),
+			want: strings.Repeat("a", 63),
+		},
+		"nothingValid": {
+			name: "_@_",
+			want: "",
+		},
+	}
+	for caseName, cs := range cases {
+		t.Run(caseName, func(t *testing.T) {
+			assert.Equal(t, cs.want, SuggestValidName(cs.name))
+		})
+	}
+}
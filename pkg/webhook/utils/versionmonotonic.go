@@ -0,0 +1,131 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/pkg/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/common"
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
+	"github.com/oam-dev/kubevela/pkg/oam"
+	"github.com/oam-dev/kubevela/pkg/oam/util"
+)
+
+// ValidateVersionMonotonic checks that newVersion is strictly greater than the versions of the existing
+// DefinitionRevisions of the definition of defType in the namespace. Revisions without a valid version are ignored.
+func ValidateVersionMonotonic(ctx context.Context, cli client.Client, defType common.DefinitionType, defName, namespace, newVersion string) error {
+	version, err := semver.StrictNewVersion(newVersion)
+	if err != nil {
+		return ErrInvalidVersion
+	}
+	latest, err := latestPublishedVersion(ctx, cli, defType, defName, namespace)
+	if err != nil {
+		return err
+	}
+	if latest != nil && !version.GreaterThan(latest) {
+		return versionNotIncreasingError(defName, newVersion, latest)
+	}
+	return nil
+}
+
+// RequireVersionChangelog makes ValidateDefinitionVersionMonotonic require the changelog annotation on the
+// definitions bumping their versions, so that every new version records what changed
+var RequireVersionChangelog = false
+
+// ValidateDefinitionVersionMonotonic rejects the version of the definition lower than its latest published version,
+// unless the backport is allowed by annotation. Re-applying the latest version is left to the revision checks.
+// If RequireVersionChangelog is set, the version greater than the latest published one must come with a non-empty
+// annotation oam.AnnotationDefinitionChangelog, ErrMissingChangelog is returned otherwise. The first version of the
+// definition is not a bump and needs no changelog.
+func ValidateDefinitionVersionMonotonic(ctx context.Context, cli client.Client, def client.Object, version string) error {
+	if version == "" || def.GetAnnotations()[oam.AnnotationAllowVersionBackport] == "true" {
+		return nil
+	}
+	newVersion, err := semver.StrictNewVersion(version)
+	if err != nil {
+		return ErrInvalidVersion
+	}
+	defType, _, err := definitionSchematicOf(def)
+	if err != nil {
+		return err
+	}
+	latest, err := latestPublishedVersion(ctx, cli, defType, def.GetName(), def.GetNamespace())
+	if err != nil {
+		return err
+	}
+	if latest != nil && newVersion.LessThan(latest) {
+		return fmt.Errorf("%w, set annotation %s to true for an intentional backport",
+			versionNotIncreasingError(def.GetName(), version, latest), oam.AnnotationAllowVersionBackport)
+	}
+	if RequireVersionChangelog && latest != nil && newVersion.GreaterThan(latest) &&
+		strings.TrimSpace(def.GetAnnotations()[oam.AnnotationDefinitionChangelog]) == "" {
+		return withSentinel(fmt.Errorf("version %s of definition %s bumps the latest published version %s without a changelog, set annotation %s to describe the changes",
+			version, def.GetName(), latest.Original(), oam.AnnotationDefinitionChangelog), ErrMissingChangelog)
+	}
+	return nil
+}
+
+func versionNotIncreasingError(defName, newVersion string, latest *semver.Version) error {
+	return withSentinel(fmt.Errorf("version %s of definition %s must be greater than the latest published version %s",
+		newVersion, defName, latest.Original()), ErrVersionNotIncreasing)
+}
+
+// latestPublishedVersion returns the max version of the DefinitionRevisions of the definition of defType, nil if
+// none is versioned
+func latestPublishedVersion(ctx context.Context, cli client.Client, defType common.DefinitionType, defName, namespace string) (*semver.Version, error) {
+	revs := &v1beta1.DefinitionRevisionList{}
+	if err := cli.List(ctx, revs, client.InNamespace(namespace), client.MatchingLabels{util.DefinitionKindToNameLabel[defType]: defName}); err != nil {
+		return nil, errors.Wrapf(err, "failed to list DefinitionRevisions of definition %s", defName)
+	}
+	var latest *semver.Version
+	for i := range revs.Items {
+		rev := &revs.Items[i]
+		if rev.Spec.DefinitionType != defType {
+			continue
+		}
+		version, err := semver.NewVersion(revisionVersionOf(rev))
+		if err != nil {
+			continue
+		}
+		if latest == nil || version.GreaterThan(latest) {
+			latest = version
+		}
+	}
+	return latest, nil
+}
+
+// revisionVersionOf returns the version of the definition embedded in the DefinitionRevision
+func revisionVersionOf(rev *v1beta1.DefinitionRevision) string {
+	switch rev.Spec.DefinitionType {
+	case common.ComponentType:
+		return rev.Spec.ComponentDefinition.Spec.Version
+	case common.TraitType:
+		return rev.Spec.TraitDefinition.Spec.Version
+	case common.PolicyType:
+		return rev.Spec.PolicyDefinition.Spec.Version
+	case common.WorkflowStepType:
+		return rev.Spec.WorkflowStepDefinition.Spec.Version
+	default:
+		return ""
+	}
+}
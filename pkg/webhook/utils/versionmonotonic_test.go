@@ -0,0 +1,222 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/common"
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
+	"github.com/oam-dev/kubevela/pkg/oam"
+	pkgcommon "github.com/oam-dev/kubevela/pkg/utils/common"
+)
+
+func componentDefRevisionOfVersion(defName, version string) *v1beta1.DefinitionRevision {
+	return &v1beta1.DefinitionRevision{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      defName + "-v" + version,
+			Namespace: "default",
+			Labels:    map[string]string{oam.LabelComponentDefinitionName: defName},
+		},
+		Spec: v1beta1.DefinitionRevisionSpec{
+			DefinitionType: common.ComponentType,
+			ComponentDefinition: v1beta1.ComponentDefinition{
+				Spec: v1beta1.ComponentDefinitionSpec{Version: version},
+			},
+		},
+	}
+}
+
+func TestValidateVersionMonotonic(t *testing.T) {
+	// the revision of the trait of the same name doesn't count for the component
+	traitRev := &v1beta1.DefinitionRevision{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "webservice-v3.0.0",
+			Namespace: "default",
+			Labels:    map[string]string{oam.LabelTraitDefinitionName: "webservice"},
+		},
+		Spec: v1beta1.DefinitionRevisionSpec{
+			DefinitionType: common.TraitType,
+			TraitDefinition: v1beta1.TraitDefinition{
+				Spec: v1beta1.TraitDefinitionSpec{Version: "3.0.0"},
+			},
+		},
+	}
+	cli := fake.NewClientBuilder().WithScheme(pkgcommon.Scheme).WithObjects(
+		componentDefRevisionOfVersion("webservice", "1.2.0"),
+		componentDefRevisionOfVersion("webservice", "1.10.0"),
+		componentDefRevisionOfVersion("worker", "2.0.0"),
+		traitRev,
+	).Build()
+
+	cases := map[string]struct {
+		defName    string
+		newVersion string
+		wantErrMsg string
+	}{
+		"greater": {
+			defName:    "webservice",
+			newVersion: "1.10.1",
+		},
+		"equal": {
+			defName:    "webservice",
+			newVersion: "1.10.0",
+			wantErrMsg: "version 1.10.0 of definition webservice must be greater than the latest published version 1.10.0",
+		},
+		"lower": {
+			defName:    "webservice",
+			newVersion: "1.9.0",
+			wantErrMsg: "version 1.9.0 of definition webservice must be greater than the latest published version 1.10.0",
+		},
+		"noRevision": {
+			defName:    "task",
+			newVersion: "0.1.0",
+		},
+		"invalidVersion": {
+			defName:    "webservice",
+			newVersion: "1.x",
+			wantErrMsg: "Not a valid version",
+		},
+	}
+	for caseName, cs := range cases {
+		t.Run(caseName, func(t *testing.T) {
+			err := ValidateVersionMonotonic(context.Background(), cli, common.ComponentType, cs.defName, "default", cs.newVersion)
+			if cs.wantErrMsg != "" {
+				assert.EqualError(t, err, cs.wantErrMsg)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestValidateDefinitionVersionMonotonic(t *testing.T) {
+	cli := fake.NewClientBuilder().WithScheme(pkgcommon.Scheme).WithObjects(
+		componentDefRevisionOfVersion("webservice", "1.2.0"),
+	).Build()
+	componentDef := func(annotations map[string]string) *v1beta1.ComponentDefinition {
+		return &v1beta1.ComponentDefinition{
+			ObjectMeta: metav1.ObjectMeta{Name: "webservice", Namespace: "default", Annotations: annotations},
+		}
+	}
+
+	cases := map[string]struct {
+		def        *v1beta1.ComponentDefinition
+		version    string
+		wantErrMsg string
+	}{
+		"noVersion": {
+			def: componentDef(nil),
+		},
+		"reapplyLatest": {
+			def:     componentDef(nil),
+			version: "1.2.0",
+		},
+		"lower": {
+			def:     componentDef(nil),
+			version: "1.1.0",
+			wantErrMsg: "version 1.1.0 of definition webservice must be greater than the latest published version 1.2.0, " +
+				"set annotation definition.oam.dev/allow-version-backport to true for an intentional backport",
+		},
+		"backportAllowed": {
+			def:     componentDef(map[string]string{oam.AnnotationAllowVersionBackport: "true"}),
+			version: "1.1.0",
+		},
+	}
+	for caseName, cs := range cases {
+		t.Run(caseName, func(t *testing.T) {
+			err := ValidateDefinitionVersionMonotonic(context.Background(), cli, cs.def, cs.version)
+			if cs.wantErrMsg != "" {
+				assert.EqualError(t, err, cs.wantErrMsg)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestValidateDefinitionVersionChangelog(t *testing.T) {
+	defer func(required bool) { RequireVersionChangelog = required }(RequireVersionChangelog)
+	cli := fake.NewClientBuilder().WithScheme(pkgcommon.Scheme).WithObjects(
+		componentDefRevisionOfVersion("webservice", "1.2.0"),
+	).Build()
+	componentDef := func(name, changelog string) *v1beta1.ComponentDefinition {
+		def := &v1beta1.ComponentDefinition{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"}}
+		if changelog != "" {
+			def.Annotations = map[string]string{oam.AnnotationDefinitionChangelog: changelog}
+		}
+		return def
+	}
+
+	cases := map[string]struct {
+		required   bool
+		def        *v1beta1.ComponentDefinition
+		version    string
+		wantErrMsg string
+	}{
+		"notRequiredByDefault": {
+			def:     componentDef("webservice", ""),
+			version: "1.3.0",
+		},
+		"bumpWithChangelog": {
+			required: true,
+			def:      componentDef("webservice", "support the sidecars"),
+			version:  "1.3.0",
+		},
+		"bumpWithoutChangelog": {
+			required: true,
+			def:      componentDef("webservice", ""),
+			version:  "1.3.0",
+			wantErrMsg: "version 1.3.0 of definition webservice bumps the latest published version 1.2.0 without a changelog, " +
+				"set annotation definition.oam.dev/changelog to describe the changes",
+		},
+		"bumpWithBlankChangelog": {
+			required: true,
+			def:      componentDef("webservice", "  "),
+			version:  "2.0.0",
+			wantErrMsg: "version 2.0.0 of definition webservice bumps the latest published version 1.2.0 without a changelog, " +
+				"set annotation definition.oam.dev/changelog to describe the changes",
+		},
+		"reapplyLatest": {
+			required: true,
+			def:      componentDef("webservice", ""),
+			version:  "1.2.0",
+		},
+		"firstVersion": {
+			required: true,
+			def:      componentDef("worker", ""),
+			version:  "1.0.0",
+		},
+	}
+	for caseName, cs := range cases {
+		t.Run(caseName, func(t *testing.T) {
+			RequireVersionChangelog = cs.required
+			err := ValidateDefinitionVersionMonotonic(context.Background(), cli, cs.def, cs.version)
+			if cs.wantErrMsg != "" {
+				assert.EqualError(t, err, cs.wantErrMsg)
+				assert.ErrorIs(t, err, ErrMissingChangelog)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
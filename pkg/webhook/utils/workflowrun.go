@@ -0,0 +1,225 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"slices"
+
+	"cuelang.org/go/cue"
+	"github.com/kubevela/pkg/cue/cuex"
+	workflowv1alpha1 "github.com/kubevela/workflow/api/v1alpha1"
+	wfTypes "github.com/kubevela/workflow/pkg/types"
+	"github.com/pkg/errors"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
+	"github.com/oam-dev/kubevela/pkg/oam/util"
+	"github.com/oam-dev/kubevela/pkg/workflow/providers"
+)
+
+// ValidateWorkflowRun checks that the type of every step in the WorkflowRun, inline or from the referenced Workflow,
+// references an existing WorkflowStepDefinition, and that the properties of the step unify with the parameter of
+// the definition, and that the if condition of the step is valid, see ValidateStepCondition. The errors of all the
+// steps are reported together.
+// The inputs of the steps must come from the outputs of the steps, and the outputs taking their values from a field
+// path must start with an output declared by the definition, see WorkflowStepOutputsOf.
+// The builtin suspend and step-group steps are handled by the workflow runtime and are not resolved, the sub steps
+// of the step groups are validated as well.
+func ValidateWorkflowRun(ctx context.Context, cli client.Client, wr *workflowv1alpha1.WorkflowRun) error {
+	ctx = util.SetNamespaceInCtx(ctx, wr.Namespace)
+	steps, err := workflowRunSteps(ctx, cli, wr)
+	if err != nil {
+		return err
+	}
+	return validateWorkflowSteps(ctx, &workflowStepValidator{cli: cli, definitions: map[string]*stepDefinition{}}, steps)
+}
+
+// workflowRunSteps returns the inline steps of the WorkflowRun, or the steps of the Workflow it references
+func workflowRunSteps(ctx context.Context, cli client.Client, wr *workflowv1alpha1.WorkflowRun) ([]workflowv1alpha1.WorkflowStep, error) {
+	if wr.Spec.WorkflowSpec != nil {
+		return wr.Spec.WorkflowSpec.Steps, nil
+	}
+	return referencedWorkflowSteps(ctx, cli, wr.Namespace, wr.Spec.WorkflowRef, "WorkflowRun")
+}
+
+// referencedWorkflowSteps returns the steps of the Workflow referenced by the referrer, e.g. the WorkflowRun, or
+// nil if the ref is empty
+func referencedWorkflowSteps(ctx context.Context, cli client.Client, namespace, ref, referrer string) ([]workflowv1alpha1.WorkflowStep, error) {
+	if ref == "" {
+		return nil, nil
+	}
+	wf := &workflowv1alpha1.Workflow{}
+	if err := cli.Get(ctx, client.ObjectKey{Namespace: namespace, Name: ref}, wf); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, fmt.Errorf("workflow %s referenced by the %s is not found", ref, referrer)
+		}
+		return nil, err
+	}
+	return wf.Steps, nil
+}
+
+// validateWorkflowSteps validates the steps and their sub steps with the validator, the errors are reported together
+func validateWorkflowSteps(ctx context.Context, v *workflowStepValidator, steps []workflowv1alpha1.WorkflowStep) error {
+	var outputs []string
+	for _, step := range steps {
+		outputs = append(outputs, stepOutputNamesOf(step.WorkflowStepBase)...)
+		for _, subStep := range step.SubSteps {
+			outputs = append(outputs, stepOutputNamesOf(subStep)...)
+		}
+	}
+	var errs []error
+	for _, step := range steps {
+		if err := v.validate(ctx, step.WorkflowStepBase, outputs); err != nil {
+			errs = append(errs, err)
+		}
+		for _, subStep := range step.SubSteps {
+			if err := v.validate(ctx, subStep, outputs); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	return aggregateErrors(errs)
+}
+
+// stepOutputNamesOf returns the names of the outputs of the step
+func stepOutputNamesOf(step workflowv1alpha1.WorkflowStepBase) []string {
+	names := make([]string, 0, len(step.Outputs))
+	for _, output := range step.Outputs {
+		names = append(names, output.Name)
+	}
+	return names
+}
+
+// workflowStepValidator caches the WorkflowStepDefinitions shared by the steps
+type workflowStepValidator struct {
+	cli         client.Client
+	definitions map[string]*stepDefinition
+}
+
+// stepDefinition is the parameter and the declared outputs of a WorkflowStepDefinition, neither exists if the
+// definition has no cue template
+type stepDefinition struct {
+	parameter cue.Value
+	outputs   []string
+}
+
+func (v *workflowStepValidator) validate(ctx context.Context, step workflowv1alpha1.WorkflowStepBase, outputs []string) error {
+	if err := ValidateStepCondition(step.If); err != nil {
+		return errors.WithMessagef(err, "step %s", step.Name)
+	}
+	if err := validateStepInputs(step, outputs); err != nil {
+		return err
+	}
+	if step.Type == wfTypes.WorkflowStepTypeSuspend || step.Type == wfTypes.WorkflowStepTypeStepGroup {
+		return nil
+	}
+	defName := definitionNameOfType(step.Type)
+	def, err := v.definitionOf(ctx, defName)
+	if err != nil {
+		return errors.WithMessagef(err, "step %s", step.Name)
+	}
+	if def == nil {
+		return fmt.Errorf("step %s references WorkflowStepDefinition %s that is not found", step.Name, defName)
+	}
+	if err := validateStepOutputs(step, defName, def.outputs); err != nil {
+		return err
+	}
+	if step.Properties == nil || len(step.Properties.Raw) == 0 || !def.parameter.Exists() {
+		return nil
+	}
+	properties := def.parameter.Context().CompileBytes(step.Properties.Raw)
+	if err := properties.Err(); err != nil {
+		return errors.WithMessagef(err, "step %s has invalid properties", step.Name)
+	}
+	if err := def.parameter.Unify(properties).Validate(); err != nil {
+		return errors.WithMessagef(checkError(err), "step %s", step.Name)
+	}
+	return nil
+}
+
+// validateStepInputs checks that the inputs of the step come from the outputs of the steps
+func validateStepInputs(step workflowv1alpha1.WorkflowStepBase, outputs []string) error {
+	var errs []error
+	for _, input := range step.Inputs {
+		if slices.Contains(outputs, input.From) {
+			continue
+		}
+		msg := fmt.Sprintf("step %s has input from %s which is not an output of any step", step.Name, input.From)
+		if nearest := nearestName(input.From, outputs); nearest != "" {
+			msg += fmt.Sprintf(", did you mean %s?", nearest)
+		}
+		errs = append(errs, errors.New(msg))
+	}
+	return aggregateErrors(errs)
+}
+
+// stepOutputPathRegex matches the valueFrom of the step outputs that is a field path, e.g. output.value.status, the
+// other ones are cue expressions evaluated by the workflow runtime and not checked
+var stepOutputPathRegex = regexp.MustCompile(`^([A-Za-z_$][A-Za-z0-9_$]*)(\.[A-Za-z0-9_$-]+)*$`)
+
+// validateStepOutputs checks that the outputs of the step taking their values from a field path start with one of
+// the outputs declared by the definition
+func validateStepOutputs(step workflowv1alpha1.WorkflowStepBase, defName string, declared []string) error {
+	if declared == nil {
+		return nil
+	}
+	var errs []error
+	for _, output := range step.Outputs {
+		match := stepOutputPathRegex.FindStringSubmatch(output.ValueFrom)
+		if match == nil || slices.Contains(declared, match[1]) {
+			continue
+		}
+		msg := fmt.Sprintf("output %s of step %s takes its value from %s which is not declared by WorkflowStepDefinition %s",
+			output.Name, step.Name, match[1], defName)
+		if nearest := nearestName(match[1], declared); nearest != "" {
+			msg += fmt.Sprintf(", did you mean %s?", nearest)
+		}
+		errs = append(errs, errors.New(msg))
+	}
+	return aggregateErrors(errs)
+}
+
+// definitionOf returns the parameter and the outputs of the WorkflowStepDefinition, or nil if the definition is not
+// found
+func (v *workflowStepValidator) definitionOf(ctx context.Context, name string) (*stepDefinition, error) {
+	if def, ok := v.definitions[name]; ok {
+		return def, nil
+	}
+	wd := &v1beta1.WorkflowStepDefinition{}
+	if err := util.GetDefinition(ctx, v.cli, wd, name); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	def := &stepDefinition{}
+	if wd.Spec.Schematic != nil && wd.Spec.Schematic.CUE != nil {
+		val, err := providers.DefaultCompiler.Get().CompileStringWithOptions(ctx, wd.Spec.Schematic.CUE.Template+contextStub,
+			cuex.DisableResolveProviderFunctions{})
+		if err != nil {
+			return nil, errors.WithMessagef(err, "failed to compile WorkflowStepDefinition %s", name)
+		}
+		def.parameter = val.LookupPath(cue.ParsePath(parameterFieldName))
+		def.outputs = stepOutputsOf(val)
+	}
+	v.definitions[name] = def
+	return def, nil
+}
@@ -0,0 +1,158 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"context"
+	"testing"
+
+	workflowv1alpha1 "github.com/kubevela/workflow/api/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	apicommon "github.com/oam-dev/kubevela/apis/core.oam.dev/common"
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
+	"github.com/oam-dev/kubevela/pkg/oam"
+	pkgcommon "github.com/oam-dev/kubevela/pkg/utils/common"
+)
+
+func TestValidateWorkflowRun(t *testing.T) {
+	stepDef := &v1beta1.WorkflowStepDefinition{
+		ObjectMeta: metav1.ObjectMeta{Name: "print-message", Namespace: oam.SystemDefinitionNamespace},
+		Spec: v1beta1.WorkflowStepDefinitionSpec{
+			Schematic: &apicommon.Schematic{CUE: &apicommon.CUE{Template: `
+import "vela/builtin"
+
+print: builtin.#Log & {
+	$params: data: "\(context.stepName): \(parameter.message)"
+}
+parameter: {
+	message: string
+	times:   *1 | int
+}`}},
+		},
+	}
+	workflow := &workflowv1alpha1.Workflow{
+		ObjectMeta: metav1.ObjectMeta{Name: "print", Namespace: "default"},
+		WorkflowSpec: workflowv1alpha1.WorkflowSpec{
+			Steps: []workflowv1alpha1.WorkflowStep{{
+				WorkflowStepBase: workflowv1alpha1.WorkflowStepBase{Name: "print", Type: "print-message", Properties: &runtime.RawExtension{Raw: []byte(`{"message":1}`)}},
+			}},
+		},
+	}
+	cli := fake.NewClientBuilder().WithScheme(pkgcommon.Scheme).WithObjects(stepDef, workflow).Build()
+	step := func(name, typ, properties string) workflowv1alpha1.WorkflowStepBase {
+		s := workflowv1alpha1.WorkflowStepBase{Name: name, Type: typ}
+		if properties != "" {
+			s.Properties = &runtime.RawExtension{Raw: []byte(properties)}
+		}
+		return s
+	}
+	withInputs := func(s workflowv1alpha1.WorkflowStepBase, from ...string) workflowv1alpha1.WorkflowStepBase {
+		for _, f := range from {
+			s.Inputs = append(s.Inputs, workflowv1alpha1.InputItem{From: f})
+		}
+		return s
+	}
+	withOutputs := func(s workflowv1alpha1.WorkflowStepBase, outputs ...workflowv1alpha1.OutputItem) workflowv1alpha1.WorkflowStepBase {
+		s.Outputs = outputs
+		return s
+	}
+
+	cases := map[string]struct {
+		spec    workflowv1alpha1.WorkflowRunSpec
+		wantErr string
+	}{
+		"validInlineSteps": {
+			spec: workflowv1alpha1.WorkflowRunSpec{WorkflowSpec: &workflowv1alpha1.WorkflowSpec{Steps: []workflowv1alpha1.WorkflowStep{
+				{WorkflowStepBase: step("hello", "print-message", `{"message":"hello"}`)},
+				{WorkflowStepBase: step("wait", "suspend", `{"duration":"1m"}`)},
+				{WorkflowStepBase: step("group", "step-group", ""), SubSteps: []workflowv1alpha1.WorkflowStepBase{
+					step("world", "print-message@v1", `{"message":"world","times":2}`),
+				}},
+			}}},
+		},
+		"invalidInlineSteps": {
+			spec: workflowv1alpha1.WorkflowRunSpec{WorkflowSpec: &workflowv1alpha1.WorkflowSpec{Steps: []workflowv1alpha1.WorkflowStep{
+				{WorkflowStepBase: step("hello", "print-mesage", `{"message":"hello"}`)},
+				{WorkflowStepBase: step("group", "step-group", ""), SubSteps: []workflowv1alpha1.WorkflowStepBase{
+					step("world", "print-message", `{"message":"world","times":"2"}`),
+				}},
+			}}},
+			wantErr: "[step hello references WorkflowStepDefinition print-mesage that is not found, " +
+				"step world: [parameter.times: 2 errors in empty disjunction:, " +
+				"parameter.times: conflicting values \"2\" and 1 (mismatched types string and int), " +
+				"parameter.times: conflicting values \"2\" and int (mismatched types string and int)]]",
+		},
+		"invalidStepConditions": {
+			spec: workflowv1alpha1.WorkflowRunSpec{WorkflowSpec: &workflowv1alpha1.WorkflowSpec{Steps: []workflowv1alpha1.WorkflowStep{
+				{WorkflowStepBase: step("hello", "print-message", `{"message":"hello"}`)},
+				{WorkflowStepBase: workflowv1alpha1.WorkflowStepBase{Name: "wait", Type: "suspend", If: `status.hello.phase ==`}},
+				{WorkflowStepBase: step("group", "step-group", ""), SubSteps: []workflowv1alpha1.WorkflowStepBase{
+					{Name: "world", Type: "print-message", If: `status.hello.succeeded`},
+					{Name: "done", Type: "print-message", If: `"succeeded"`},
+				}},
+			}}},
+			wantErr: "[step wait: invalid if condition \"status.hello.phase ==\": expected operand, found 'EOF', " +
+				"step done: invalid if condition \"\\\"succeeded\\\"\": yields string instead of bool]",
+		},
+		"stepInputsAndOutputs": {
+			spec: workflowv1alpha1.WorkflowRunSpec{WorkflowSpec: &workflowv1alpha1.WorkflowSpec{Steps: []workflowv1alpha1.WorkflowStep{
+				{WorkflowStepBase: withOutputs(step("hello", "print-message", `{"message":"hello"}`),
+					workflowv1alpha1.OutputItem{Name: "hello-data", ValueFrom: "print.$params.data"},
+					workflowv1alpha1.OutputItem{Name: "hello-message", ValueFrom: `"\(parameter.message)!"`})},
+				{WorkflowStepBase: withInputs(step("group", "step-group", ""), "hello-message"), SubSteps: []workflowv1alpha1.WorkflowStepBase{
+					withInputs(step("world", "print-message", ""), "hello-data"),
+				}},
+			}}},
+		},
+		"invalidStepInputsAndOutputs": {
+			spec: workflowv1alpha1.WorkflowRunSpec{WorkflowSpec: &workflowv1alpha1.WorkflowSpec{Steps: []workflowv1alpha1.WorkflowStep{
+				{WorkflowStepBase: withOutputs(step("hello", "print-message", `{"message":"hello"}`),
+					workflowv1alpha1.OutputItem{Name: "hello-data", ValueFrom: "prnt.$params.data"})},
+				{WorkflowStepBase: withInputs(step("wait", "suspend", ""), "hello-date")},
+				{WorkflowStepBase: withInputs(step("world", "print-message", `{"message":"world"}`), "unknown-output")},
+			}}},
+			wantErr: "[output hello-data of step hello takes its value from prnt which is not declared by WorkflowStepDefinition print-message, did you mean print?, " +
+				"step wait has input from hello-date which is not an output of any step, did you mean hello-data?, " +
+				"step world has input from unknown-output which is not an output of any step]",
+		},
+		"workflowRef": {
+			spec:    workflowv1alpha1.WorkflowRunSpec{WorkflowRef: "print"},
+			wantErr: "step print: parameter.message: conflicting values string and 1 (mismatched types string and int)",
+		},
+		"workflowRefNotFound": {
+			spec:    workflowv1alpha1.WorkflowRunSpec{WorkflowRef: "not-exist"},
+			wantErr: "workflow not-exist referenced by the WorkflowRun is not found",
+		},
+		"noSteps": {},
+	}
+
+	for caseName, cs := range cases {
+		t.Run(caseName, func(t *testing.T) {
+			wr := &workflowv1alpha1.WorkflowRun{ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "default"}, Spec: cs.spec}
+			err := ValidateWorkflowRun(context.Background(), cli, wr)
+			if cs.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.EqualError(t, err, cs.wantErr)
+		})
+	}
+}
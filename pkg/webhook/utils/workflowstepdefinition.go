@@ -0,0 +1,130 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"context"
+	"sort"
+
+	"cuelang.org/go/cue"
+	"github.com/kubevela/pkg/cue/cuex"
+	"github.com/kubevela/workflow/pkg/cue/model"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
+	"github.com/oam-dev/kubevela/pkg/cue/process"
+	"github.com/oam-dev/kubevela/pkg/workflow/providers"
+)
+
+// WorkflowStepContextFields are the context fields provided by the workflow runtime to the workflow steps
+var WorkflowStepContextFields = []string{
+	process.ContextName,
+	process.ContextNamespace,
+	process.ContextAppName,
+	process.ContextAppRevision,
+	process.ContextAppRevisionNum,
+	process.ContextAppLabels,
+	process.ContextAppAnnotations,
+	process.ContextCompRevisionName,
+	process.ContextComponents,
+	process.ContextReplicaKey,
+	process.ContextCluster,
+	process.ContextClusterVersion,
+	process.ContextPublishVersion,
+	process.ContextWorkflowName,
+	model.ContextStepSessionID,
+	model.ContextStepName,
+	model.ContextStepGroupName,
+	model.ContextSpanID,
+}
+
+// ValidateWorkflowStepDefinition validates the step's cue template and returns warnings for the context fields
+// referenced by the template but not provided by the workflow runtime, see ValidateContextFields. The severity of
+// CheckUnknownContextFields in the SeverityConfig carried by ctx turns them into errors or skips them.
+// The template is compiled with the workflow providers but the provider functions are never executed.
+// The outputs declared by the template, which the steps of the workflows take their outputs from, are listed by
+// WorkflowStepOutputsOf.
+func ValidateWorkflowStepDefinition(ctx context.Context, _ client.Client, wd *v1beta1.WorkflowStepDefinition) ([]string, error) {
+	if wd.Spec.Schematic == nil || wd.Spec.Schematic.CUE == nil {
+		return nil, nil
+	}
+	if !isCueTemplateValidated(ctx) {
+		if err := validateWorkflowStepTemplate(ctx, wd.Name, wd.Spec.Schematic.CUE.Template); err != nil {
+			return nil, err
+		}
+	}
+
+	contextResult, err := ValidateContextFields(wd)
+	if err != nil {
+		return nil, err
+	}
+	result, err := severityConfigOf(ctx).classify(CheckUnknownContextFields, contextResult.Warnings)
+	return result.WarningMessages(), err
+}
+
+// validateWorkflowStepTemplate validates the cue template of the step named name compiled with the workflow providers
+func validateWorkflowStepTemplate(ctx context.Context, name, cueTemplate string) error {
+	compiler := providers.DefaultCompiler.Get()
+	if err := checkImportCycles(name, cueTemplate, compiler.GetImports()); err != nil {
+		return err
+	}
+	compile := func(src string) (cue.Value, error) {
+		return compiler.CompileStringWithOptions(ctx, src, cuex.DisableResolveProviderFunctions{})
+	}
+	// the outputs of the step are passed to the other steps rather than rendered to resources
+	_, err := validateTemplateWith(cueTemplate, compile, compile, false)
+	return err
+}
+
+// WorkflowStepOutputsOf returns the sorted outputs declared by the step's cue template, i.e. its top-level fields
+// other than the parameter and the context, e.g. output of apply-object, which the outputs of the workflow steps
+// take their values from, see the valueFrom of the step outputs. The template is compiled with the workflow
+// providers but the provider functions are never executed.
+func WorkflowStepOutputsOf(ctx context.Context, wd *v1beta1.WorkflowStepDefinition) ([]string, error) {
+	if wd.Spec.Schematic == nil || wd.Spec.Schematic.CUE == nil {
+		return nil, nil
+	}
+	val, err := providers.DefaultCompiler.Get().CompileStringWithOptions(ctx, wd.Spec.Schematic.CUE.Template+contextStub,
+		cuex.DisableResolveProviderFunctions{})
+	if err != nil {
+		return nil, err
+	}
+	return stepOutputsOf(val), nil
+}
+
+// stepOutputsOf returns the sorted top-level fields of the compiled step template other than the parameter and the
+// context
+func stepOutputsOf(val cue.Value) []string {
+	iter, err := val.Fields()
+	if err != nil {
+		return nil
+	}
+	var outputs []string
+	for iter.Next() {
+		sel := iter.Selector()
+		if sel.LabelType() != cue.StringLabel {
+			continue
+		}
+		name := sel.Unquoted()
+		if name == parameterFieldName || name == model.ContextFieldName {
+			continue
+		}
+		outputs = append(outputs, name)
+	}
+	sort.Strings(outputs)
+	return outputs
+}
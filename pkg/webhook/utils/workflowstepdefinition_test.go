@@ -0,0 +1,137 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	apicommon "github.com/oam-dev/kubevela/apis/core.oam.dev/common"
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
+)
+
+func TestValidateWorkflowStepDefinition(t *testing.T) {
+	cases := map[string]struct {
+		template     string
+		wantWarnings []string
+		wantErr      string
+	}{
+		"knownContextFields": {
+			template: `
+import "vela/builtin"
+
+log: builtin.#Log & {
+	$params: data: {
+		app:     context.appName
+		step:    context.stepName
+		session: context.stepSessionID
+		span:    context.spanID
+	}
+}`,
+		},
+		"unknownContextFields": {
+			template: `
+import "vela/builtin"
+
+log: builtin.#Log & {
+	$params: data: {
+		session: context.stepSesionID
+		ns:      context.namespace
+		app:     context.appname
+		again:   context.stepSesionID
+	}
+}`,
+			wantWarnings: []string{
+				"WorkflowStepDefinition test references context.appname which is not provided by the workflow runtime",
+				"WorkflowStepDefinition test references context.stepSesionID which is not provided by the workflow runtime",
+			},
+		},
+		"invalidCueTemplate": {
+			template: `
+import "vela/builtin"
+
+log: builtin.#Log & {
+	$params: data: hello
+}`,
+			wantErr: "log.$params.data: reference \"hello\" not found",
+		},
+		"noSchematic": {},
+	}
+
+	for caseName, cs := range cases {
+		t.Run(caseName, func(t *testing.T) {
+			wd := &v1beta1.WorkflowStepDefinition{ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "default"}}
+			if cs.template != "" {
+				wd.Spec.Schematic = &apicommon.Schematic{CUE: &apicommon.CUE{Template: cs.template}}
+			}
+			warnings, err := ValidateWorkflowStepDefinition(context.Background(), nil, wd)
+			if cs.wantErr != "" {
+				assert.EqualError(t, err, cs.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, cs.wantWarnings, warnings)
+		})
+	}
+}
+
+func TestWorkflowStepOutputsOf(t *testing.T) {
+	cases := map[string]struct {
+		template    string
+		wantOutputs []string
+		wantErr     string
+	}{
+		"outputs": {
+			template: `
+import "vela/kube"
+
+output: kube.#Apply & {
+	$params: value: parameter.value
+}
+wait: output.$returns.value.status != _|_
+#Hidden: string
+_hidden: string
+optional?: string
+parameter: value: {...}`,
+			wantOutputs: []string{"output", "wait"},
+		},
+		"invalidCueTemplate": {
+			template: `output: {`,
+			wantErr:  "expected '}', found 'EOF'",
+		},
+		"noSchematic": {},
+	}
+
+	for caseName, cs := range cases {
+		t.Run(caseName, func(t *testing.T) {
+			wd := &v1beta1.WorkflowStepDefinition{ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "default"}}
+			if cs.template != "" {
+				wd.Spec.Schematic = &apicommon.Schematic{CUE: &apicommon.CUE{Template: cs.template}}
+			}
+			outputs, err := WorkflowStepOutputsOf(context.Background(), wd)
+			if cs.wantErr != "" {
+				assert.ErrorContains(t, err, cs.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, cs.wantOutputs, outputs)
+		})
+	}
+}
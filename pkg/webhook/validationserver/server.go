@@ -0,0 +1,96 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package validationserver serves the validation of the definitions over HTTP, so that the external tools like IDE
+// plugins and CI pipelines can validate the definitions without deploying the admission webhook.
+package validationserver
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"k8s.io/apimachinery/pkg/runtime/serializer"
+	"k8s.io/klog/v2"
+
+	"github.com/oam-dev/kubevela/pkg/utils/common"
+	webhookutils "github.com/oam-dev/kubevela/pkg/webhook/utils"
+)
+
+// ValidatePath is the path to POST the definition in YAML or JSON to
+const ValidatePath = "/api/v1/validate"
+
+// MaxRequestBytes is the max size of the definition accepted
+var MaxRequestBytes int64 = 1 << 20
+
+var decoder = serializer.NewCodecFactory(common.Scheme).UniversalDeserializer()
+
+// NewServer returns the server listening on addr with the handler of NewHandler
+func NewServer(addr string) *http.Server {
+	return &http.Server{
+		Addr:         addr,
+		Handler:      NewHandler(),
+		ReadTimeout:  10 * time.Second,
+		WriteTimeout: 30 * time.Second,
+	}
+}
+
+// NewHandler returns the handler validating the definitions by webhookutils.ValidateDefinitionOfflineWithResult,
+// it doesn't connect to Kubernetes. The response is the JSON of the webhookutils.ValidationResult, the definition
+// is valid if there are no errors in it. The status code is only set to non-2xx if the request is malformed.
+func NewHandler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc(ValidatePath, validateDefinition)
+	return mux
+}
+
+func validateDefinition(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		writeResult(w, http.StatusMethodNotAllowed, errorResult(fmt.Errorf("method %s is not allowed", r.Method)))
+		return
+	}
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, MaxRequestBytes))
+	if err != nil {
+		writeResult(w, http.StatusBadRequest, errorResult(fmt.Errorf("failed to read the request: %w", err)))
+		return
+	}
+	def, _, err := decoder.Decode(body, nil, nil)
+	if err != nil {
+		writeResult(w, http.StatusBadRequest, errorResult(fmt.Errorf("failed to decode the definition: %w", err)))
+		return
+	}
+	result, err := webhookutils.ValidateDefinitionOfflineWithResult(def)
+	if err != nil && len(result.Errors) == 0 {
+		// the error doesn't come from the cue template
+		result.Errors = errorResult(err).Errors
+	}
+	writeResult(w, http.StatusOK, result)
+}
+
+func errorResult(err error) *webhookutils.ValidationResult {
+	return &webhookutils.ValidationResult{Errors: []webhookutils.CueValidationError{{Message: err.Error()}}}
+}
+
+func writeResult(w http.ResponseWriter, status int, result *webhookutils.ValidationResult) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(result); err != nil {
+		klog.ErrorS(err, "Failed to write the validation result")
+	}
+}
@@ -0,0 +1,165 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package validationserver
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	webhookutils "github.com/oam-dev/kubevela/pkg/webhook/utils"
+)
+
+func TestValidateDefinition(t *testing.T) {
+	cases := map[string]struct {
+		method     string
+		body       string
+		wantStatus int
+		want       *webhookutils.ValidationResult
+	}{
+		"valid": {
+			method: http.MethodPost,
+			body: `
+apiVersion: core.oam.dev/v1beta1
+kind: TraitDefinition
+metadata:
+  name: scaler
+spec:
+  version: 1.0.0
+  schematic:
+    cue:
+      template: |
+        parameter: replicas: *1 | int
+        patch: spec: replicas: parameter.replicas
+`,
+			wantStatus: http.StatusOK,
+			want:       &webhookutils.ValidationResult{},
+		},
+		"invalidTemplate": {
+			method: http.MethodPost,
+			body: `
+apiVersion: core.oam.dev/v1beta1
+kind: ComponentDefinition
+metadata:
+  name: worker
+spec:
+  schematic:
+    cue:
+      template: |
+        parameter: image: string
+        output: hello: world
+`,
+			wantStatus: http.StatusOK,
+			want: &webhookutils.ValidationResult{Errors: []webhookutils.CueValidationError{
+				{Message: `output.hello: reference "world" not found`, Filename: "-", Line: 2, Column: 16},
+			}},
+		},
+		"invalidVersion": {
+			method: http.MethodPost,
+			body: `{"apiVersion": "core.oam.dev/v1beta1", "kind": "WorkflowStepDefinition",
+"metadata": {"name": "deploy"}, "spec": {"version": "1.x"}}`,
+			wantStatus: http.StatusOK,
+			want: &webhookutils.ValidationResult{Errors: []webhookutils.CueValidationError{
+				{Message: "Not a valid version"},
+			}},
+		},
+		"unsupportedKind": {
+			method: http.MethodPost,
+			body: `
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: cm
+`,
+			wantStatus: http.StatusOK,
+			want: &webhookutils.ValidationResult{Errors: []webhookutils.CueValidationError{
+				{Message: "unsupported definition type *v1.ConfigMap"},
+			}},
+		},
+		"malformed": {
+			method:     http.MethodPost,
+			body:       `kind: [`,
+			wantStatus: http.StatusBadRequest,
+		},
+		"methodNotAllowed": {
+			method:     http.MethodGet,
+			wantStatus: http.StatusMethodNotAllowed,
+			want: &webhookutils.ValidationResult{Errors: []webhookutils.CueValidationError{
+				{Message: "method GET is not allowed"},
+			}},
+		},
+	}
+	handler := NewHandler()
+	for caseName, cs := range cases {
+		t.Run(caseName, func(t *testing.T) {
+			req := httptest.NewRequest(cs.method, ValidatePath, strings.NewReader(cs.body))
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+			assert.Equal(t, cs.wantStatus, rec.Code)
+			assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+			result := &webhookutils.ValidationResult{}
+			assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), result))
+			if cs.want == nil {
+				assert.NotEmpty(t, result.Errors)
+				return
+			}
+			assert.Equal(t, cs.want, result)
+		})
+	}
+}
+
+func TestValidateDefinitionNoOutboundCall(t *testing.T) {
+	var requests atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+	}))
+	defer srv.Close()
+
+	body := fmt.Sprintf(`
+apiVersion: core.oam.dev/v1beta1
+kind: PolicyDefinition
+metadata:
+  name: notify
+spec:
+  schematic:
+    cue:
+      template: |
+        import "vela/http"
+
+        parameter: message: string
+        req: http.#Do & {
+          $params: {
+            method: "POST"
+            url:    %q
+            request: body: parameter.message
+          }
+        }
+`, srv.URL)
+	rec := httptest.NewRecorder()
+	NewHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodPost, ValidatePath, strings.NewReader(body)))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	result := &webhookutils.ValidationResult{}
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), result))
+	assert.Empty(t, result.Errors)
+	assert.Equal(t, int32(0), requests.Load())
+}